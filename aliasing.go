@@ -0,0 +1,141 @@
+package ccopy
+
+import "reflect"
+
+// AliasedResultPolicy is what a copy does when a customizer returns a value sharing memory with the value it customizes.
+type AliasedResultPolicy int
+
+const (
+	// AliasedResultsAllow does not check the results of the customizers.
+	AliasedResultsAllow AliasedResultPolicy = iota
+	// AliasedResultsWarn reports a WarningAliasedResult warning.
+	AliasedResultsWarn
+	// AliasedResultsError fails the copy with an *ErrAliasedResult error.
+	AliasedResultsError
+)
+
+// checkResult applies the AliasedResults policy to the result v of the customizer name, called with ov.
+func (c *state) checkResult(name string, ov, v reflect.Value) error {
+	if c.aliasedResults == AliasedResultsAllow {
+		return nil
+	}
+	// the results copied are not aliased, and the results not copied explicitly are meant to be
+	if _, ok := c.copyResults[name]; ok {
+		return nil
+	}
+	if !aliases(ov, v) {
+		return nil
+	}
+	if c.aliasedResults == AliasedResultsError {
+		return &ErrAliasedResult{Tag: name, Path: c.pathString()}
+	}
+	c.warn(WarningAliasedResult, ov.Type(), "")
+	return nil
+}
+
+// region is the memory of the values a pointer, slice or map refers to.
+type region struct {
+	start, end uintptr
+}
+
+// aliases reports whether b refers to memory a refers to: the same pointed values, elements of slices or maps.
+// Strings are immutable, so sharing them is not reported.
+func aliases(a, b reflect.Value) bool {
+	var regions []region
+	walkRefs(a, make(map[region]bool), func(r region) bool {
+		regions = append(regions, r)
+		return true
+	})
+	if len(regions) == 0 {
+		return false
+	}
+	found := false
+	walkRefs(b, make(map[region]bool), func(r region) bool {
+		for _, o := range regions {
+			if r.start < o.end && o.start < r.end || r.start == o.start {
+				found = true
+				return false
+			}
+		}
+		return true
+	})
+	return found
+}
+
+// walkRefs calls fn with the regions v refers to, reachable through its exported and unexported parts,
+// until fn returns false. It reports whether the walk went through.
+func walkRefs(v reflect.Value, seen map[region]bool, fn func(region) bool) bool {
+	visit := func(r region) (bool, bool) {
+		if seen[r] {
+			return false, true
+		}
+		seen[r] = true
+		return true, fn(r)
+	}
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return true
+		}
+		size := v.Type().Elem().Size()
+		if size == 0 {
+			return true
+		}
+		first, ok := visit(region{v.Pointer(), v.Pointer() + size})
+		if !ok {
+			return false
+		}
+		if first {
+			return walkRefs(v.Elem(), seen, fn)
+		}
+	case reflect.Slice:
+		size := v.Type().Elem().Size()
+		if v.IsNil() || v.Cap() == 0 || size == 0 {
+			return true
+		}
+		first, ok := visit(region{v.Pointer(), v.Pointer() + uintptr(v.Cap())*size})
+		if !ok {
+			return false
+		}
+		if first {
+			for i := 0; i < v.Len(); i++ {
+				if !walkRefs(v.Index(i), seen, fn) {
+					return false
+				}
+			}
+		}
+	case reflect.Map:
+		if v.IsNil() {
+			return true
+		}
+		first, ok := visit(region{v.Pointer(), v.Pointer() + 1})
+		if !ok {
+			return false
+		}
+		if first {
+			iter := v.MapRange()
+			for iter.Next() {
+				if !walkRefs(iter.Key(), seen, fn) || !walkRefs(iter.Value(), seen, fn) {
+					return false
+				}
+			}
+		}
+	case reflect.Interface:
+		if !v.IsNil() {
+			return walkRefs(v.Elem(), seen, fn)
+		}
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if !walkRefs(v.Index(i), seen, fn) {
+				return false
+			}
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if !walkRefs(v.Field(i), seen, fn) {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,81 @@
+package ccopy
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestAliasedResults(t *testing.T) {
+	type Doc struct {
+		Lines []string `ccopy:"lines"`
+		Meta  *string  `ccopy:"meta"`
+		Attrs map[string]int
+	}
+	config := Config{
+		// a sub slice of the original, sharing its backing array
+		"lines": func(lines []string) []string { return lines[1:] },
+		"meta":  func(s *string) *string { v := "redacted"; return &v },
+	}
+	meta := "author"
+	obj := Doc{Lines: []string{"a", "b"}, Meta: &meta}
+
+	c, err := NewCopier(config, Options{AliasedResults: AliasedResultsError})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.Copy(obj)
+	var aliased *ErrAliasedResult
+	if !errors.As(err, &aliased) || aliased.Tag != "lines" || aliased.Path != "Doc.Lines" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var warnings []Warning
+	c, err = NewCopier(config, Options{AliasedResults: AliasedResultsWarn, OnWarning: func(w Warning) { warnings = append(warnings, w) }})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Copy(obj); err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != 1 || warnings[0].Kind != WarningAliasedResult || warnings[0].Path != "Doc.Lines" {
+		t.Fatalf("unexpected warnings: %v", warnings)
+	}
+
+	// the sharing is intended, or undone by copying the results
+	for _, copyResults := range []bool{false, true} {
+		c, err = NewCopier(config, Options{AliasedResults: AliasedResultsError, CopyResults: map[string]bool{"lines": copyResults}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := c.Copy(obj); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestAliases(t *testing.T) {
+	type node struct {
+		next *node
+		data []int
+	}
+	shared := []int{1, 2, 3}
+	a := &node{data: shared}
+	a.next = a
+	m := map[string]int{}
+	for _, tc := range []struct {
+		name string
+		a, b interface{}
+		want bool
+	}{
+		{"same pointer", a, a, true},
+		{"pointer to unexported slice", a, node{data: shared[2:]}, true},
+		{"distinct", a, &node{data: []int{1}}, false},
+		{"same map", m, []interface{}{m}, true},
+		{"strings", "abc", "abc", false},
+	} {
+		if got := aliases(reflect.ValueOf(tc.a), reflect.ValueOf(tc.b)); got != tc.want {
+			t.Errorf("%s: got %t", tc.name, got)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+// Package ccopylint defines an analyzer that reports struct fields of sensitive types
+// that are not customized by ccopy: fields without a ccopy tag or a //ccopy:name annotation,
+// whose type has no converter or handler registered with ccopy.RegisterConverter or ccopy.RegisterHandler,
+// and no customizer registered with Config.RegisterType.
+package ccopylint
+
+import (
+	"go/ast"
+	"go/types"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+const ccopyPath = "github.com/gadumitrachioaiei/ccopy"
+
+// Analyzer reports struct fields of sensitive types that are not customized.
+var Analyzer = &analysis.Analyzer{
+	Name:      "ccopylint",
+	Doc:       "report struct fields of sensitive types that are not customized by ccopy",
+	Run:       run,
+	Requires:  []*analysis.Analyzer{inspect.Analyzer},
+	FactTypes: []analysis.Fact{new(registered)},
+}
+
+// sensitive is the comma separated list of sensitive types,
+// each given by its name or by its package path and name, like example.com/pii.SSN.
+var sensitive string
+
+func init() {
+	Analyzer.Flags.StringVar(&sensitive, "types", "", "comma separated list of sensitive types, like EmailAddress,example.com/pii.SSN")
+}
+
+// registered is the fact of the types of the registeredTypes, handlers and type customizers registered by a package.
+type registered struct {
+	Types []string
+}
+
+func (*registered) AFact() {}
+
+func (r *registered) String() string {
+	return "registered(" + strings.Join(r.Types, ", ") + ")"
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	names := make(map[string]bool)
+	for _, name := range strings.Split(sensitive, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names[name] = true
+		}
+	}
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	reg := &registered{}
+	inspect.Preorder([]ast.Node{(*ast.CallExpr)(nil)}, func(n ast.Node) {
+		if t := registeredType(pass, n.(*ast.CallExpr)); t != "" {
+			reg.Types = append(reg.Types, t)
+		}
+	})
+	if len(reg.Types) > 0 {
+		sort.Strings(reg.Types)
+		pass.ExportPackageFact(reg)
+	}
+	if len(names) == 0 {
+		return nil, nil
+	}
+	registeredTypes := make(map[string]bool)
+	for _, t := range reg.Types {
+		registeredTypes[t] = true
+	}
+	for _, f := range pass.AllPackageFacts() {
+		for _, t := range f.Fact.(*registered).Types {
+			registeredTypes[t] = true
+		}
+	}
+
+	inspect.Preorder([]ast.Node{(*ast.StructType)(nil)}, func(n ast.Node) {
+		for _, field := range n.(*ast.StructType).Fields.List {
+			if customized(field) {
+				continue
+			}
+			named := namedType(pass.TypesInfo.TypeOf(field.Type))
+			if named == nil {
+				continue
+			}
+			qualified := qualifiedName(named)
+			if !names[qualified] && !names[named.Obj().Name()] || registeredTypes[qualified] {
+				continue
+			}
+			name := "embedded field"
+			if len(field.Names) > 0 {
+				name = "field " + field.Names[0].Name
+			}
+			pass.Reportf(field.Pos(), "%s of sensitive type %s has no ccopy tag", name, qualified)
+		}
+	})
+	return nil, nil
+}
+
+// customized reports whether a field has a ccopy tag, other than "shallow" or empty, or annotation.
+func customized(field *ast.Field) bool {
+	if field.Tag != nil {
+		if tag, err := strconv.Unquote(field.Tag.Value); err == nil {
+			// fields tagged "shallow" are copied as they are, and so are fields with an empty tag
+			if v := reflect.StructTag(tag).Get("ccopy"); v != "" && v != "shallow" {
+				return true
+			}
+		}
+	}
+	for _, cg := range []*ast.CommentGroup{field.Doc, field.Comment} {
+		if cg == nil {
+			continue
+		}
+		for _, c := range cg.List {
+			if strings.HasPrefix(c.Text, "//ccopy:") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// registrations are the functions and methods of ccopy registering the copy of every value of a type.
+var registrations = map[string]bool{
+	"RegisterConverter":   true,
+	"RegisterHandler":     true,
+	"Config.RegisterType": true,
+}
+
+// registeredType returns the type registered by a call to ccopy.RegisterConverter, ccopy.RegisterHandler
+// or Config.RegisterType, if call is one: the type of the last parameter of the registered function.
+func registeredType(pass *analysis.Pass, call *ast.CallExpr) string {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || len(call.Args) != 1 {
+		return ""
+	}
+	fn, ok := pass.TypesInfo.Uses[sel.Sel].(*types.Func)
+	if !ok || fn.Pkg() == nil || fn.Pkg().Path() != ccopyPath {
+		return ""
+	}
+	name := fn.Name()
+	if recv := fn.Type().(*types.Signature).Recv(); recv != nil {
+		named := namedType(recv.Type())
+		if named == nil {
+			return ""
+		}
+		name = named.Obj().Name() + "." + name
+	}
+	if !registrations[name] {
+		return ""
+	}
+	sig, ok := pass.TypesInfo.TypeOf(call.Args[0]).(*types.Signature)
+	if !ok || sig.Params().Len() == 0 {
+		return ""
+	}
+	if named := namedType(sig.Params().At(sig.Params().Len() - 1).Type()); named != nil {
+		return qualifiedName(named)
+	}
+	return ""
+}
+
+// namedType returns the named type of the values held by t, through pointers and containers.
+func namedType(t types.Type) *types.Named {
+	for {
+		switch u := t.(type) {
+		case *types.Named:
+			return u
+		case *types.Pointer:
+			t = u.Elem()
+		case *types.Slice:
+			t = u.Elem()
+		case *types.Array:
+			t = u.Elem()
+		case *types.Map:
+			t = u.Elem()
+		default:
+			return nil
+		}
+	}
+}
+
+func qualifiedName(t *types.Named) string {
+	if t.Obj().Pkg() == nil {
+		return t.Obj().Name()
+	}
+	return t.Obj().Pkg().Path() + "." + t.Obj().Name()
+}
@@ -0,0 +1,15 @@
+package ccopylint
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestAnalyzer(t *testing.T) {
+	if err := Analyzer.Flags.Set("types", "EmailAddress,pii.SSN,pii.Phone,pii.Passport,pii.IBAN"); err != nil {
+		t.Fatal(err)
+	}
+	defer Analyzer.Flags.Set("types", "")
+	analysistest.Run(t, analysistest.TestData(), Analyzer, "a")
+}
@@ -0,0 +1,21 @@
+package a
+
+import "pii"
+
+type EmailAddress string
+
+type User struct {
+	Email    EmailAddress  // want `field Email of sensitive type a.EmailAddress has no ccopy tag`
+	Backup   *EmailAddress `ccopy:"email"`
+	Previous EmailAddress  `ccopy:""` // want `field Previous of sensitive type a.EmailAddress has no ccopy tag`
+	SSNs     []pii.SSN     // want `field SSNs of sensitive type pii.SSN has no ccopy tag`
+	Phone    pii.Phone
+	Passport pii.Passport
+	IBANs    []pii.IBAN
+	Name     string
+	//ccopy:email
+	Work    EmailAddress
+	Details struct {
+		SSN pii.SSN `json:"ssn"` // want `field SSN of sensitive type pii.SSN has no ccopy tag`
+	}
+}
@@ -0,0 +1,11 @@
+package ccopy
+
+type Config map[string]interface{}
+
+type Args string
+
+func (c Config) RegisterType(fn interface{}) {}
+
+func RegisterConverter(fn interface{}) {}
+
+func RegisterHandler(fn interface{}) {}
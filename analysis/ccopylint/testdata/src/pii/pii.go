@@ -0,0 +1,17 @@
+package pii
+
+import "github.com/gadumitrachioaiei/ccopy"
+
+type SSN string
+
+type Phone string
+
+type Passport string
+
+type IBAN string
+
+func init() {
+	ccopy.RegisterConverter(func(p Phone) Phone { return "" })
+	ccopy.RegisterHandler(func(p Passport) (Passport, error) { return "", nil })
+	ccopy.Config{}.RegisterType(func(_ ccopy.Args, i IBAN) IBAN { return "" })
+}
@@ -0,0 +1,132 @@
+package ccopy
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// benchModel is the shape of a typical API model.
+type benchModel struct {
+	ID        int64
+	Name      string
+	Email     string `ccopy:"email"`
+	Active    bool
+	Score     float64
+	Created   time.Time
+	Nickname  *string
+	Tags      []string
+	Labels    map[string]string
+	Addresses []benchAddress
+}
+
+type benchAddress struct {
+	Street string
+	City   string
+	Zip    string
+}
+
+func newBenchModel() benchModel {
+	nick := "bob"
+	return benchModel{
+		ID: 1, Name: "Bob", Email: "bob@example.com", Active: true, Score: 4.5, Created: time.Now(), Nickname: &nick,
+		Tags:      []string{"a", "b", "c"},
+		Labels:    map[string]string{"team": "x", "role": "y"},
+		Addresses: []benchAddress{{"Main Street 1", "Springfield", "12345"}, {"High Street 2", "Shelbyville", "54321"}},
+	}
+}
+
+func BenchmarkCopyModel(b *testing.B) {
+	c, err := NewCopier(Config{"email": func(string) string { return "x@example.com" }}, Options{})
+	if err != nil {
+		b.Fatal(err)
+	}
+	obj := newBenchModel()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := c.Copy(obj); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCopyModelRules(b *testing.B) {
+	c, err := NewCopier(Config{"email": func(string) string { return "x@example.com" }}, Options{Rules: Rules{"benchModel.Addresses[].Street": "email"}})
+	if err != nil {
+		b.Fatal(err)
+	}
+	obj := newBenchModel()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := c.Copy(obj); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCopyModelManyRules(b *testing.B) {
+	rules := Rules{"benchModel.Addresses[].Street": "email"}
+	for i := 0; i < 100; i++ {
+		rules[fmt.Sprintf("benchModel.Addresses[].Extra%d", i)] = "email"
+		rules[fmt.Sprintf("benchAddress.Extra%d", i)] = "email"
+	}
+	c, err := NewCopier(Config{"email": func(string) string { return "x@example.com" }}, Options{Rules: rules})
+	if err != nil {
+		b.Fatal(err)
+	}
+	obj := newBenchModel()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := c.Copy(obj); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCopyModels(b *testing.B) {
+	c, err := NewCopier(Config{"email": func(string) string { return "x@example.com" }}, Options{})
+	if err != nil {
+		b.Fatal(err)
+	}
+	objs := make([]benchModel, 100)
+	for i := range objs {
+		objs[i] = newBenchModel()
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := c.Copy(objs); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkConfigCopy(b *testing.B) {
+	config := Config{"email": func(string) string { return "x@example.com" }}
+	obj := newBenchModel()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := config.Copy(obj); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCompiledCopy(b *testing.B) {
+	c, err := Config{"email": func(string) string { return "x@example.com" }}.Compile(benchModel{})
+	if err != nil {
+		b.Fatal(err)
+	}
+	obj := newBenchModel()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := c.Copy(obj); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
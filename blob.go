@@ -0,0 +1,109 @@
+package ccopy
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// BlobAction is what a copy does with a blob, a slice of bytes like []byte or json.RawMessage.
+type BlobAction int
+
+const (
+	// BlobCopy copies a blob as it is.
+	BlobCopy BlobAction = iota
+	// BlobZero leaves a blob out of the copy.
+	BlobZero
+	// BlobTruncate copies the first Size bytes of a blob.
+	// A json.RawMessage cut short is no longer valid JSON, so it is left out of the copy instead.
+	BlobTruncate
+	// BlobHash replaces a blob with its SHA-256 hash, so equal blobs can still be recognized.
+	// A json.RawMessage is replaced with the hash as a JSON string of hexadecimal digits, so it stays valid JSON.
+	BlobHash
+	// BlobCustomize customizes a blob with the customizer of the Config named Customizer.
+	BlobCustomize
+)
+
+// BlobPolicy is how blobs are copied, when they are not customized by tags or rules.
+// Images and documents make copies use a lot of memory, and may hold personal data of their own.
+type BlobPolicy struct {
+	Action BlobAction
+	// Size is the number of bytes kept by BlobTruncate.
+	Size int
+	// Customizer is the name of the customizer used by BlobCustomize.
+	Customizer string
+}
+
+func (p BlobPolicy) validate(c Config, t reflect.Type) error {
+	switch p.Action {
+	case BlobCopy, BlobZero, BlobHash:
+	case BlobTruncate:
+		if p.Size < 0 {
+			return fmt.Errorf("invalid blob policy for %s: negative size %d", t, p.Size)
+		}
+	case BlobCustomize:
+		if c[p.Customizer] == nil {
+			return fmt.Errorf("invalid blob policy for %s: missing customizer %q", t, p.Customizer)
+		}
+	default:
+		return fmt.Errorf("invalid blob policy for %s: unknown action %d", t, p.Action)
+	}
+	return nil
+}
+
+var rawMessageType = reflect.TypeOf(json.RawMessage(nil))
+
+// isBlob reports whether values of type t are blobs.
+func isBlob(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8
+}
+
+// blobPolicy returns the policy of the blobs of type t.
+func (c *Copier) blobPolicy(t reflect.Type) BlobPolicy {
+	if p, ok := c.blobTypes[t]; ok {
+		return p
+	}
+	return c.blobs
+}
+
+// copyBlob copies ov, a blob, with the policy of its type.
+func (c *state) copyBlob(ov reflect.Value) (reflect.Value, error) {
+	if ov.IsNil() {
+		return ov, nil
+	}
+	p := c.blobPolicy(ov.Type())
+	var b []byte
+	switch p.Action {
+	case BlobZero:
+		return reflect.Zero(ov.Type()), nil
+	case BlobTruncate:
+		if ov.Type() == rawMessageType {
+			return reflect.Zero(ov.Type()), nil
+		}
+		b = ov.Bytes()
+		if len(b) > p.Size {
+			b = b[:p.Size]
+		}
+		b = append([]byte{}, b...)
+	case BlobHash:
+		h := sha256.Sum256(ov.Bytes())
+		b = h[:]
+		if ov.Type() == rawMessageType {
+			b = []byte(`"` + hex.EncodeToString(b) + `"`)
+		}
+	case BlobCustomize:
+		return c.customize(p.Customizer, ov)
+	default:
+		b = append([]byte{}, ov.Bytes()...)
+	}
+	c.account(ov.Type(), len(b))
+	if err := c.limit(len(b)); err != nil {
+		return reflect.Zero(ov.Type()), err
+	}
+	// The elements of ov can be of a named byte type, so b is copied into a slice of the type of ov.
+	nv := reflect.MakeSlice(ov.Type(), len(b), len(b))
+	copy(nv.Bytes(), b)
+	return nv, nil
+}
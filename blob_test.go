@@ -0,0 +1,98 @@
+package ccopy
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestBlobs(t *testing.T) {
+	type Document struct {
+		Name    string
+		Content []byte
+		Raw     json.RawMessage
+		Tagged  []byte `ccopy:"upper"`
+		Empty   []byte
+	}
+	obj := Document{Name: "a", Content: []byte("hello world"), Raw: json.RawMessage(`{"a":1}`), Tagged: []byte("x")}
+	upper := func(b []byte) []byte { return bytes.ToUpper(b) }
+	hash := sha256.Sum256([]byte("hello world"))
+	rawHash := sha256.Sum256([]byte(`{"a":1}`))
+	tests := []struct {
+		name     string
+		options  Options
+		expected Document
+	}{
+		{"copy", Options{}, Document{Name: "a", Content: []byte("hello world"), Raw: json.RawMessage(`{"a":1}`), Tagged: []byte("X")}},
+		{"zero", Options{Blobs: BlobPolicy{Action: BlobZero}}, Document{Name: "a", Tagged: []byte("X")}},
+		{"truncate", Options{Blobs: BlobPolicy{Action: BlobTruncate, Size: 5}}, Document{Name: "a", Content: []byte("hello"), Tagged: []byte("X")}},
+		{"hash", Options{Blobs: BlobPolicy{Action: BlobHash}}, Document{Name: "a", Content: hash[:], Raw: json.RawMessage(`"` + hex.EncodeToString(rawHash[:]) + `"`), Tagged: []byte("X")}},
+		{"customize", Options{Blobs: BlobPolicy{Action: BlobCustomize, Customizer: "upper"}}, Document{Name: "a", Content: []byte("HELLO WORLD"), Raw: json.RawMessage(`{"A":1}`), Tagged: []byte("X")}},
+		{"types", Options{Blobs: BlobPolicy{Action: BlobZero}, BlobTypes: map[reflect.Type]BlobPolicy{reflect.TypeOf(json.RawMessage{}): {}}},
+			Document{Name: "a", Raw: json.RawMessage(`{"a":1}`), Tagged: []byte("X")}},
+	}
+	for _, test := range tests {
+		c, err := NewCopier(Config{"upper": upper}, test.options)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res, err := c.Copy(obj)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(res, test.expected); diff != "" {
+			t.Fatalf("%s: %s", test.name, diff)
+		}
+		if d := res.(Document); len(d.Content) > 0 && &d.Content[0] == &obj.Content[0] {
+			t.Fatalf("%s: expected the content to be copied", test.name)
+		}
+		if _, err := json.Marshal(res); err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+	}
+}
+
+func TestBlobPolicyInvalid(t *testing.T) {
+	for _, o := range []Options{
+		{Blobs: BlobPolicy{Action: BlobTruncate, Size: -1}},
+		{Blobs: BlobPolicy{Action: BlobCustomize, Customizer: "missing"}},
+		{BlobTypes: map[reflect.Type]BlobPolicy{reflect.TypeOf(""): {}}},
+	} {
+		if _, err := NewCopier(Config{}, o); err == nil {
+			t.Fatalf("expected an error for %v", o)
+		}
+	}
+}
+
+func TestBlobsNamedBytes(t *testing.T) {
+	type MyByte uint8
+	type Document struct {
+		B []MyByte
+	}
+	obj := Document{B: []MyByte("hello world")}
+	tests := []struct {
+		options  Options
+		expected []MyByte
+	}{
+		{Options{}, []MyByte("hello world")},
+		{Options{Blobs: BlobPolicy{Action: BlobTruncate, Size: 5}}, []MyByte("hello")},
+	}
+	for _, test := range tests {
+		c, err := NewCopier(Config{}, test.options)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res, err := c.Copy(obj)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(res.(Document).B, test.expected); diff != "" {
+			t.Fatal(diff)
+		}
+	}
+}
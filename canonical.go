@@ -0,0 +1,63 @@
+package ccopy
+
+import (
+	"math"
+	"reflect"
+	"strings"
+)
+
+// canonicalValue returns the canonical copy of ov, for the values that the Canonical option changes.
+func canonicalValue(ov reflect.Value) (reflect.Value, bool) {
+	switch ov.Kind() {
+	case reflect.String:
+		if s := strings.TrimSpace(ov.String()); len(s) != ov.Len() {
+			v := reflect.New(ov.Type()).Elem()
+			v.SetString(s)
+			return v, true
+		}
+	case reflect.Float32, reflect.Float64:
+		if f := canonicalFloat(ov.Float()); math.Float64bits(f) != math.Float64bits(ov.Float()) {
+			v := reflect.New(ov.Type()).Elem()
+			v.SetFloat(f)
+			return v, true
+		}
+	case reflect.Complex64, reflect.Complex128:
+		c := ov.Complex()
+		v := reflect.New(ov.Type()).Elem()
+		v.SetComplex(complex(canonicalFloat(real(c)), canonicalFloat(imag(c))))
+		return v, true
+	case reflect.Slice, reflect.Map:
+		if !ov.IsNil() && ov.Len() == 0 {
+			return reflect.Zero(ov.Type()), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+func canonicalFloat(f float64) float64 {
+	switch {
+	case math.IsNaN(f):
+		return math.NaN()
+	case f == 0:
+		return 0
+	}
+	return f
+}
+
+// Entry is an entry of a map.
+type Entry struct {
+	Key   interface{}
+	Value interface{}
+}
+
+// SortedEntries returns the entries of map m sorted by key, like the SortMapKeys option orders them,
+// so that an order of the entries can be captured, where needed, in a slice,
+// like by a customizer of a field holding a map, or before comparing two maps entry by entry.
+// It panics if m is not a map.
+func SortedEntries(m interface{}) []Entry {
+	var entries []Entry
+	for _, e := range sortedEntries(reflect.ValueOf(m)) {
+		entries = append(entries, Entry{Key: e[0].Interface(), Value: e[1].Interface()})
+	}
+	return entries
+}
@@ -0,0 +1,73 @@
+package ccopy
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type canonicalRecord struct {
+	Name     string
+	Note     *string
+	Tags     []string
+	Labels   map[string]int
+	Scores   []float64
+	At       time.Time
+	Verbatim string `ccopy:"keep"`
+}
+
+func TestCanonical(t *testing.T) {
+	note := "\tnote\n"
+	at := time.Date(2024, 1, 2, 3, 4, 5, 0, time.FixedZone("X", 3600))
+	obj := canonicalRecord{
+		Name:     " alice ",
+		Note:     &note,
+		Tags:     []string{},
+		Labels:   map[string]int{"a ": 1, "b": 2},
+		Scores:   []float64{math.Copysign(0, -1), 1.5},
+		At:       at,
+		Verbatim: " x ",
+	}
+	c, err := NewCopier(Config{"keep": func(s string) string { return s }}, Options{Canonical: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := c.Copy(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	canonical := "note"
+	expected := canonicalRecord{
+		Name:     "alice",
+		Note:     &canonical,
+		Labels:   map[string]int{"a": 1, "b": 2},
+		Scores:   []float64{0, 1.5},
+		At:       at.UTC(),
+		Verbatim: " x ",
+	}
+	got := res.(canonicalRecord)
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Fatal(diff)
+	}
+	if math.Signbit(got.Scores[0]) || got.At.Location() != time.UTC {
+		t.Fatalf("expected a zero and a time in UTC, got: %v, %v", got.Scores[0], got.At)
+	}
+	// the keys are sorted before they are trimmed, so the last one is "a"
+	v, warnings, err := c.CopyWarnings(map[string]int{"a": 1, " a": 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != 1 || warnings[0].Kind != WarningKeyCollision || v.(map[string]int)["a"] != 1 {
+		t.Fatalf("got: %v, %v, expected a collision of the trimmed keys", v, warnings)
+	}
+}
+
+func TestSortedEntries(t *testing.T) {
+	entries := SortedEntries(map[string]int{"b": 2, "a": 1, "c": 3})
+	expected := []Entry{{"a", 1}, {"b", 2}, {"c", 3}}
+	if diff := cmp.Diff(expected, entries); diff != "" {
+		t.Fatal(diff)
+	}
+}
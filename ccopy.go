@@ -2,98 +2,825 @@
 package ccopy
 
 import (
-	"errors"
+	"context"
 	"fmt"
 	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unsafe"
 )
 
 const tagCcopy = "ccopy"
 
 // Config represents the config for the customizable deep copy.
 // Maps between tag value and functions that receive the tagged data and return the same data type.
+// Two tags need no function: fields tagged with "-" are left out of the copies, as the zero values of their types,
+// and fields tagged with "shallow" are copied by assignment, sharing what they point to with the copied values.
+// The functions may take the *Scratch of the copy as first argument as well: func(*Scratch, T) T,
+// or a random generator, chosen by the Randomness option of a Copier: func(*rand.Rand, T) T.
+// Or they may take the context of the copy, given to CopyCtx, like to get request-scoped keys: func(context.Context, T) T.
+// Or they may take the arguments of the tag, following the name of the function and a comma, like "keep=4" in "mask,keep=4",
+// so a single function serves fields needing different parameters: func(Args, T) T.
+// A tag can chain functions as well, applied in order, each to the result of the previous one, like "trim,lowercase,hash":
+// the elements of a tag that are not keys of the config are the arguments of the function preceding them.
+// They may return an error as well, like when they call a service that can fail: func(T) (T, error).
+// The copy fails with an *ErrCustomizer error wrapping it.
+// Functions customizing every value of a type, whatever its tags, are added with RegisterType,
+// and functions customizing the values at a path, like the fields of types that cannot be tagged, with RegisterPath.
 type Config map[string]interface{}
 
 // Copy deep copies an object respecting the customizations provided in the config.
-// Unexported fields of a struct are ignored and will not be copied.
-// The types unsafe.Pointer and uintptr are not supported and they will cause a panic.
+// Unexported fields of a struct are ignored and will not be copied,
+// unless the type of the struct is registered with RegisterAtomic or RegisterConverter.
+// The types unsafe.Pointer and uintptr are not supported and they will cause an *ErrUnsupportedKind error.
+// The handles of resources, like *os.File or *sql.DB, cause an *ErrResource error, unless shared by the Resources option.
+// The errors about a value, like a field missing its customizer, are *FieldError errors holding its path.
 // A channel will point to the original channel.
 func (c Config) Copy(obj interface{}) (interface{}, error) {
-	ov := reflect.ValueOf(obj)
-	oc, err := c.copy(ov)
+	return c.copier().Copy(obj)
+}
+
+// CopyT deep copies obj like Config.Copy, returning the copy as a T, without type assertion.
+func CopyT[T any](c Config, obj T) (T, error) {
+	return CopyWith(c.copier(), obj)
+}
+
+// CopyWith deep copies obj with the Copier c, returning the copy as a T, without type assertion.
+// When T is an interface type, the copy holds a value of the dynamic type of obj.
+func CopyWith[T any](c *Copier, obj T) (T, error) {
+	v, err := c.Copy(obj)
+	copied, _ := v.(T)
+	return copied, err
+}
+
+// CopyBoth deep copies obj twice, for pipelines persisting the full record and publishing the redacted one:
+// full is the deep copy of obj as it is, and redacted its copy by c, like CopyWith.
+// The full copy is made by c without its customizations: it resolves no tags nor rules, registered or not,
+// and calls no customizers, but copies the values like c otherwise, with the handlers of their types,
+// the shared pointers, resources and infrastructure, and the Pointers, Strings and size limit options of c.
+// The unexported fields are copied too, like with the Unexported option.
+// The full copy is the zero value if either copy fails.
+func CopyBoth[T any](c *Copier, obj T) (full, redacted T, err error) {
+	if redacted, err = CopyWith(c, obj); err != nil {
+		return full, redacted, err
+	}
+	var zero T
+	if full, err = CopyWith(c.live().uncustomized(), obj); err != nil {
+		return zero, zero, err
+	}
+	return full, redacted, nil
+}
+
+// CopyCtx deep copies an object like Copy, within the context ctx: the customizers taking a context,
+// func(context.Context, T) T, are called with ctx, and the copy fails with an *ErrCanceled error
+// once ctx is done, checked every few hundred values.
+func (c Config) CopyCtx(ctx context.Context, obj interface{}) (interface{}, error) {
+	return c.copier().CopyCtx(ctx, obj)
+}
+
+// CopySession deep copies an object like Copy, within a session shared with other copies.
+// Fields tagged with "idmap=name" are customized by the customizer registered under name,
+// once per distinct value in the session: later occurrences of the same value, in this or other copies
+// of the session, are replaced by the same customized value, preserving the relationships between the objects.
+// Fields tagged with "name,unique", or "idmap=name,unique", get customized values that are unique in the session:
+// distinct values are never customized to the same value, as needed for usernames or emails.
+func (c Config) CopySession(s *Session, obj interface{}) (interface{}, error) {
+	return c.copier().CopySession(s, obj)
+}
+
+// copier returns a Copier for the config, without options.
+func (c Config) copier() *Copier {
+	cp := &Copier{config: c, rules: c.pathRules()}
+	cp.plans.config, cp.plans.rules = c, cp.rules
+	return cp
+}
+
+// Options represents settings of a Copier, beyond the customizations of its Config.
+type Options struct {
+	// Rules applies customizations of the Config to fields that are selected by path, instead of by tag.
+	Rules Rules
+	// Scopes applies customizations of the Config to the types of some packages, after the Rules.
+	Scopes []Scope
+	// OnWarning, if not nil, is called for the surprising but not failing behaviors of the copies.
+	OnWarning func(Warning)
+	// FieldOrder, if not nil, returns the order in which the fields of a struct type are copied,
+	// which is the order of their declaration otherwise.
+	// It is called once per type with the exported fields of the type, and must return all of them.
+	// The order matters to customizers with side effects, like the ones of fields tagged with "idmap=name".
+	FieldOrder func(t reflect.Type, fields []reflect.StructField) []reflect.StructField
+	// SortMapKeys copies the entries of maps in the order of their keys, instead of in random order,
+	// so the customizers are called in the same order for the same input.
+	// Keys are ordered by value for basic kinds, and field by field, or element by element, for structs and arrays.
+	SortMapKeys bool
+	// NaNKeys is what to do with the map entries whose keys are or contain NaN values.
+	// Such entries cannot be looked up, and every one of them is a distinct entry, in the original and in the copy.
+	NaNKeys NaNKeyPolicy
+	// KeyCollisions is what to do when the copies of two keys of a map are equal,
+	// because the keys, or values in them, are customized.
+	// Which of the keys comes first is only known with SortMapKeys.
+	KeyCollisions KeyCollisionPolicy
+	// Blobs is how slices of bytes, like []byte or json.RawMessage, are copied, when no tag or rule customizes them.
+	Blobs BlobPolicy
+	// BlobTypes overrides the Blobs policy for the given blob types.
+	BlobTypes map[reflect.Type]BlobPolicy
+	// FlatStructs copies the struct types holding no pointers, strings, slices, maps or interfaces,
+	// down to their nested structs and arrays, by assignment: their unexported fields are copied as well,
+	// instead of being left out. It does not apply to the types having tagged fields or matched by rules.
+	FlatStructs bool
+	// Factories transform the values of interface fields, and other interface values, instead of copying them:
+	// each factory is a func(T) I, T implementing the interface I, called with the values of type T held by
+	// values of type I, and returning the value of the copy, which can be of another type, like a stub in place
+	// of a client of a live service. A factory func(I) I applies to all the values of type I,
+	// unless a factory is set for the type they hold.
+	Factories []interface{}
+	// Canonical makes canonical copies, for hashing, diffing and golden tests: strings are trimmed of
+	// leading and trailing white space, empty slices and maps become nil, negative zeros become zeros,
+	// all NaN values the same NaN, and time.Time values are in UTC, without monotonic clock readings.
+	// It implies SortMapKeys, and the KeyCollisions policy applies to the keys trimmed to the same string.
+	// The values returned by customizers are kept as they are, and FlatStructs does not apply.
+	Canonical bool
+	// Messages, if not nil, formats the errors of the copies, which are returned as *ErrMessage errors
+	// with the text it returns, wrapping the errors it formats, so they can be localized or rephrased
+	// for end users. The warnings passed to OnWarning can be formatted the same way with DescribeWarning.
+	Messages func(Message) string
+	// Timeout, if not zero, is the longest a copy can take: copies taking longer fail with an *ErrTimeout error.
+	// It is a guardrail against pathological inputs, like huge graphs of objects, and does not depend on contexts.
+	// The time is checked every few hundred values, so the copies can exceed it by the time of a few customizers.
+	Timeout time.Duration
+	// CostLimits, if set, are the estimated costs of struct types beyond which the copies of their values
+	// report WarningCostly warnings. The costs are estimated once per type, when its plan is compiled.
+	CostLimits CostLimits
+	// CopyResults is whether the values returned by customizers, by name in the config, are deep copied as well.
+	// The results are used as they are by default, so a customizer returning a value holding pointers,
+	// like a value it caches, shares its memory with all the copies it customized.
+	// The deep copies of the results are not customized again.
+	// Setting false states the results of a customizer are meant to be shared, which AliasedResults does not check.
+	CopyResults map[string]bool
+	// AliasedResults is what to do when a customizer returns a value sharing memory with the value it customizes,
+	// like the same pointer or a slice of the same backing array, which silently defeats the purpose of the copy.
+	// The check walks both values, for every call of a customizer, so it is meant for tests and debug builds.
+	AliasedResults AliasedResultPolicy
+	// RuleConflicts is what to do with the rules that can match the same values with different customizers:
+	// apply the first of them, in the documented order of RuleConflictPolicy, or fail.
+	RuleConflicts RuleConflictPolicy
+	// Randomness is the source of the random generator passed to the customizers taking one, func(*rand.Rand, T) T,
+	// by name of customizer: the generator of the session of the copy, by default.
+	Randomness map[string]Randomness
+	// Pointers is what to do with the pointers pointing to the same value: copy the value for each of them,
+	// by default, or once, sharing the copy like the original.
+	Pointers PointerPolicy
+	// MaxElements, if not zero, is the most elements of slices and entries of maps a copy can hold,
+	// and MaxBytes, if not zero, the most memory it can allocate, estimated like by CopyReport:
+	// bigger copies fail with an *ErrTooBig error. They are guardrails against inputs that ballooned.
+	MaxElements int
+	MaxBytes    int64
+	// TooBigBreakdown, if not zero, is the number of subtrees holding the most elements reported by *ErrTooBig errors,
+	// so developers see which field ballooned. Counting the elements of every path slows the copies down.
+	TooBigBreakdown int
+	// WarmModels compiles the plans of the models registered with RegisterModel in a background goroutine,
+	// started by NewCopier, so the first copies of large models do not compile them, like by Warm.
+	WarmModels bool
+	// Unexported copies the unexported fields of structs as well, like the exported ones, reading and setting them
+	// through their addresses with package unsafe, for types holding private state, like bytes.Buffer.
+	// The unexported fields are customized by their tags and the rules matching them as well,
+	// and the copies of the types registered with RegisterAtomic or RegisterConverter are not affected.
+	Unexported bool
+	// Intern is whether the values returned by customizers, by name in the config, are interned within a copy:
+	// the customizer is called once per distinct value it customizes, and its result is shared by all the values equal to it,
+	// like the hash of an email found in many records of a batch. It is meant for customizers returning immutable values,
+	// whose results depend only on the value customized, as the scratch, the random generator and the path of the other values
+	// are not seen. The values of incomparable types, like slices, are customized every time.
+	Intern map[string]bool
+	// Resources is what to do with the handles of resources, like *os.File, *net.TCPConn or *sql.DB,
+	// and the types registered with RegisterResource: fail the copies holding them, by default, or share them.
+	Resources ResourcePolicy
+	// Infrastructure are types of the infrastructure of programs, like the clients of services, shared by the copies
+	// like the types registered with RegisterInfrastructure: their values, and the pointers to them, are copied by assignment.
+	Infrastructure []reflect.Type
+	// Strings is what to do with the strings copied as they are: share their bytes with the original, by default,
+	// or clone them, so small copies of huge buffers do not keep them in memory. Cloning the strings at some paths only
+	// takes a rule naming a customizer of strings.Clone, like Rules{"Event.Payload.**:string": "clone"}.
+	Strings StringPolicy
+	// KeysInPaths writes the keys of maps as they are in the paths of the errors, warnings and traces of the copies,
+	// like Order.Meta["john@example.com"].Email. By default, the keys that are not integers, which can be personal data,
+	// are written as a hash of their value, like Order.Meta[#091b0ca1].Email, telling the entries apart only.
+	KeysInPaths bool
+}
+
+// NaNKeyPolicy is what a copy does with the map entries whose keys are or contain NaN values.
+type NaNKeyPolicy int
+
+const (
+	// NaNKeysKeep copies the entries, reporting a WarningNaNKey warning.
+	NaNKeysKeep NaNKeyPolicy = iota
+	// NaNKeysDrop leaves the entries out of the copy.
+	NaNKeysDrop
+	// NaNKeysError fails the copy with an *ErrNaNKey error.
+	NaNKeysError
+)
+
+// KeyCollisionPolicy is what a copy does when the copies of two keys of a map are equal.
+type KeyCollisionPolicy int
+
+const (
+	// KeyCollisionsKeepLast keeps the entry copied last, reporting a WarningKeyCollision warning,
+	// like assigning the entries to the copy one by one does.
+	KeyCollisionsKeepLast KeyCollisionPolicy = iota
+	// KeyCollisionsKeepFirst keeps the entry copied first, reporting a WarningKeyCollision warning.
+	KeyCollisionsKeepFirst
+	// KeyCollisionsError fails the copy with an *ErrKeyCollision error.
+	KeyCollisionsError
+)
+
+// Copier deep copies objects like Config.Copy does, using the given options.
+type Copier struct {
+	plans     plans
+	config    Config
+	rules     []*rule
+	onWarning func(Warning)
+
+	sortMapKeys    bool
+	nanKeys        NaNKeyPolicy
+	keyCollisions  KeyCollisionPolicy
+	blobs          BlobPolicy
+	blobTypes      map[reflect.Type]BlobPolicy
+	factories      map[factoryKey]reflect.Value
+	canonical      bool
+	messages       func(Message) string
+	timeout        time.Duration
+	copyResults    map[string]bool
+	aliasedResults AliasedResultPolicy
+	ruleConflicts  RuleConflictPolicy
+	randomness     map[string]Randomness
+	pointers       PointerPolicy
+	intern         map[string]bool
+	resources      ResourcePolicy
+	infrastructure map[reflect.Type]bool
+	keysInPaths    bool
+	// maxElements, maxBytes and tooBigBreakdown are the options limiting the size of the copies
+	maxElements     int
+	maxBytes        int64
+	tooBigBreakdown int
+
+	// options are the options of the Copier, and swapped the Copier of the config of the last Swap, if any
+	options Options
+	swap    sync.Mutex
+	swapped atomic.Value
+	// full is the Copier making the full copies of CopyBoth, once created
+	full atomic.Value
+}
+
+// NewCopier returns a Copier for the config and options.
+// It returns an error if the options are not valid.
+func NewCopier(c Config, o Options) (*Copier, error) {
+	rules, err := o.Rules.parse()
 	if err != nil {
 		return nil, err
 	}
+	rules = append(rules, c.pathRules()...)
+	for _, s := range o.Scopes {
+		scoped, err := s.parse()
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, scoped...)
+	}
+	cp := &Copier{config: c, rules: rules, onWarning: o.OnWarning, sortMapKeys: o.SortMapKeys || o.Canonical, nanKeys: o.NaNKeys, keyCollisions: o.KeyCollisions, canonical: o.Canonical, messages: o.Messages,
+		timeout: o.Timeout, copyResults: o.CopyResults, aliasedResults: o.AliasedResults, ruleConflicts: o.RuleConflicts,
+		randomness: o.Randomness, pointers: o.Pointers, intern: o.Intern, resources: o.Resources, keysInPaths: o.KeysInPaths, maxElements: o.MaxElements, maxBytes: o.MaxBytes, tooBigBreakdown: o.TooBigBreakdown,
+		options: o}
+	if o.RuleConflicts == RuleConflictsError {
+		registered, _ := registeredRules.v.Load().([]*rule)
+		if a, b := conflict(append(rules[:len(rules):len(rules)], registered...)); a != nil {
+			return nil, &ErrRuleConflict{Rules: []string{a.path, b.path}}
+		}
+	}
+	if err := validateCopyResults(c, o.CopyResults); err != nil {
+		return nil, err
+	}
+	if err := validateIntern(c, o.Intern); err != nil {
+		return nil, err
+	}
+	if err := validateRandomness(c, o.Randomness); err != nil {
+		return nil, err
+	}
+	if err := o.Blobs.validate(c, reflect.TypeOf([]byte(nil))); err != nil {
+		return nil, err
+	}
+	cp.blobs = o.Blobs
+	for t, p := range o.BlobTypes {
+		if !isBlob(t) {
+			return nil, fmt.Errorf("invalid blob policy for %s: not a slice of bytes", t)
+		}
+		if err := p.validate(c, t); err != nil {
+			return nil, err
+		}
+	}
+	cp.blobTypes = o.BlobTypes
+	for _, t := range o.Infrastructure {
+		if cp.infrastructure == nil {
+			cp.infrastructure = make(map[reflect.Type]bool)
+		}
+		cp.infrastructure[t] = true
+	}
+	if cp.factories, err = parseFactories(o.Factories); err != nil {
+		return nil, err
+	}
+	cp.plans.config, cp.plans.order = c, o.FieldOrder
+	cp.plans.flatStructs, cp.plans.rules = o.FlatStructs && !o.Canonical, rules
+	cp.plans.canonical, cp.plans.costLimits = o.Canonical, o.CostLimits
+	cp.plans.unexported = o.Unexported
+	cp.plans.cloneStrings = o.Strings == StringsClone
+	if o.WarmModels {
+		go cp.warmModels()
+	}
+	return cp, nil
+}
+
+// uncustomized returns the Copier making the full copies of CopyBoth: c without its config, tags and rules,
+// nor the options changing the values, like Canonical or Blobs, and copying the unexported fields.
+func (c *Copier) uncustomized() *Copier {
+	if full, ok := c.full.Load().(*Copier); ok {
+		return full
+	}
+	o := c.options
+	o.Rules, o.Scopes, o.OnWarning = nil, nil, nil
+	o.Blobs, o.BlobTypes, o.Canonical, o.CopyResults, o.AliasedResults = BlobPolicy{}, nil, false, nil, AliasedResultsAllow
+	o.RuleConflicts, o.Randomness, o.Intern, o.WarmModels = RuleConflictsPriority, nil, nil, false
+	o.Unexported = true
+	full, err := NewCopier(Config{}, o)
+	if err != nil {
+		// the options left are valid for any config
+		panic("ccopy: full copier: " + err.Error())
+	}
+	full.plans.uncustomized = true
+	c.full.Store(full)
+	return full
+}
+
+// Copy deep copies an object, like Config.Copy.
+// Fields that are tagged are customized by their tag, even if some rule matches them as well.
+//
+// The values are copied in a deterministic order: struct fields in the order of their declaration,
+// or in the FieldOrder option, elements of slices and arrays by index, and entries of maps
+// in the order of their keys if the SortMapKeys option is set.
+// So with deterministic customizers, the same input gives the same output and the same customizer calls.
+func (c *Copier) Copy(obj interface{}) (interface{}, error) {
+	return c.CopySession(NewSession(), obj)
+}
+
+// CopyCtx deep copies an object, like Config.CopyCtx.
+func (c *Copier) CopyCtx(ctx context.Context, obj interface{}) (interface{}, error) {
+	return c.copyRoot(&state{Copier: c.live(), onWarning: c.onWarning, ctx: ctx, done: ctx.Done()}, obj)
+}
+
+// CopySession deep copies an object within a session, like Config.CopySession.
+// A nil session is the same as a new session.
+func (c *Copier) CopySession(s *Session, obj interface{}) (interface{}, error) {
+	return c.copyRoot(&state{Copier: c.live(), session: s, onWarning: c.onWarning}, obj)
+}
+
+// CopyWarnings deep copies an object, like Copy, and returns the warnings of the copy as well.
+// The warnings are also passed to the OnWarning option, if any.
+func (c *Copier) CopyWarnings(obj interface{}) (interface{}, []Warning, error) {
+	var warnings []Warning
+	st := &state{Copier: c.live(), onWarning: func(w Warning) {
+		warnings = append(warnings, w)
+		if c.onWarning != nil {
+			c.onWarning(w)
+		}
+	}}
+	v, err := c.copyRoot(st, obj)
+	return v, warnings, err
+}
+
+// CopyScratch deep copies an object, like Copy, and returns the scratch shared by the customizers of the copy.
+func (c *Copier) CopyScratch(obj interface{}) (interface{}, *Scratch, error) {
+	st := &state{Copier: c.live(), onWarning: c.onWarning, scratch: &Scratch{}}
+	v, err := c.copyRoot(st, obj)
+	return v, st.scratch, err
+}
+
+// CopyPartial deep copies an object, like Copy, but does not stop at the errors of struct fields,
+// elements of slices and arrays and entries of maps: what fails to be copied is left out of the copy,
+// as the zero value of its type, and the errors are returned.
+// So the copy holds only what was copied and customized successfully, which is safer to log than nothing at all.
+// The copy is nil if the object itself fails to be copied.
+func (c *Copier) CopyPartial(obj interface{}) (interface{}, []error) {
+	st := &state{Copier: c.live(), onWarning: c.onWarning, partial: true}
+	v, err := c.copyRoot(st, obj)
+	for i := range st.errs {
+		st.errs[i] = c.message(st.errs[i])
+	}
+	if err != nil {
+		return nil, append(st.errs, err)
+	}
+	return v, st.errs
+}
+
+func (c *Copier) copyRoot(st *state, obj interface{}) (interface{}, error) {
+	if st.session == nil {
+		st.session = NewSession()
+	}
+	if c.timeout > 0 {
+		st.deadline = time.Now().Add(c.timeout)
+	}
+	if st.ctx != nil && st.ctx.Err() != nil {
+		return nil, c.message(&ErrCanceled{Err: st.ctx.Err()})
+	}
+	ov := reflect.ValueOf(obj)
+	if ov.IsValid() {
+		st.root = ov.Type()
+	}
+	oc, err := st.copy(ov, nil)
+	if err != nil {
+		return nil, c.message(err)
+	}
 	return oc.Interface(), nil
 }
 
-func (c Config) copy(ov reflect.Value) (reflect.Value, error) {
+// state holds what is specific to a single copy.
+type state struct {
+	*Copier
+	session *Session
+	root    reflect.Type
+	path    []pathElem
+
+	onWarning func(Warning)
+	warned    map[warningKey]bool
+
+	scratch *Scratch
+
+	// partial is set for partial copies, which collect the errors in errs instead of failing
+	partial bool
+	errs    []error
+
+	// deadline is the end of the Timeout option, if any, and done the channel of the context of CopyCtx, if any,
+	// checked every timeoutCheck values
+	deadline time.Time
+	ctx      context.Context
+	done     <-chan struct{}
+	values   int
+
+	// report is the report of CopyReport
+	report *Report
+
+	// tracing is set by CopyTrace, which records the calls of the customizers in trace
+	tracing bool
+	trace   []Invocation
+
+	// shared holds the copies of the values pointed to, with PointersShare
+	shared map[sharedPointer]reflect.Value
+	// inProgress holds the copies of the values pointed to that are being copied
+	inProgress map[cyclePointer]reflect.Value
+
+	// elements and bytes are the size of the copy, and breakdown the elements by pattern path,
+	// checked by the MaxElements and MaxBytes options
+	elements  int
+	bytes     int64
+	breakdown map[string]int
+
+	// interned holds the results of the customizers of the Intern option
+	interned map[internKey]reflect.Value
+}
+
+const timeoutCheck = 256
+
+// tolerate reports whether the copy goes on after err, collecting it, leaving out what failed to be copied.
+func (c *state) tolerate(err error) bool {
+	if !c.partial {
+		return false
+	}
+	switch err.(type) {
+	case *ErrTimeout, *ErrTooBig:
+		return false
+	}
+	c.errs = append(c.errs, err)
+	return true
+}
+
+func (c *state) push(e pathElem) {
+	c.path = append(c.path, e)
+}
+
+func (c *state) pop() {
+	c.path = c.path[:len(c.path)-1]
+}
+
+func (c *state) pathString() string {
+	return formatPath(c.root, c.path, c.keysInPaths)
+}
+
+func (c *state) copy(ov reflect.Value, active []match) (reflect.Value, error) {
 	if !ov.IsValid() {
-		return reflect.Value{}, errors.New("invalid value")
+		return reflect.Value{}, ErrInvalidValue
 	}
+	if !c.deadline.IsZero() || c.done != nil {
+		if c.values++; c.values%timeoutCheck == 0 {
+			if !c.deadline.IsZero() && time.Now().After(c.deadline) {
+				return reflect.Zero(ov.Type()), &ErrTimeout{Timeout: c.timeout, Path: c.pathString()}
+			}
+			select {
+			case <-c.done:
+				return reflect.Zero(ov.Type()), &ErrCanceled{Path: c.pathString(), Err: c.ctx.Err()}
+			default:
+			}
+		}
+	}
+	// the bytes of pointers and of the results of customizers are checked at the next value
+	if c.maxBytes > 0 && c.bytes > c.maxBytes {
+		return reflect.Zero(ov.Type()), c.limit(0)
+	}
+	active = c.plans.reach(active, ov.Type())
+	if r := matchedRule(active); r != nil {
+		if c.ruleConflicts == RuleConflictsError {
+			if o := conflicting(active, r); o != nil {
+				return reflect.Zero(ov.Type()), &ErrRuleConflict{Rules: []string{r.path, o.path}, Path: c.pathString()}
+			}
+		}
+		return c.customize(r.name, ov)
+	}
+	if name, ok := c.plans.typeCustomizers()[ov.Type()]; ok {
+		return c.customize(name, ov)
+	}
+	active = c.anchor(active, ov.Type())
 
-	if t := ov.Type(); t.PkgPath() == "time" && t.Name() == "Time" {
-		return c.copyTime(ov)
+	if fn, ok := typeHandler(ov.Type()); ok {
+		if !fn.IsValid() {
+			if c.canonical && ov.Type() == timeType {
+				return reflect.ValueOf(ov.Interface().(time.Time).UTC().Round(0)), nil
+			}
+			return ov, nil
+		}
+		if isRawHandler(fn) {
+			return c.callHandler(fn, ov)
+		}
+		return fn.Call([]reflect.Value{ov})[0], nil
+	}
+	if c.isInfrastructure(ov.Type()) {
+		return ov, nil
+	}
+	if a := collectionOf(ov.Type()); a != nil {
+		return c.copyCollection(a, ov, active)
+	}
+	if isResource(ov.Type()) {
+		return c.copyResource(ov)
+	}
+	if c.canonical {
+		if v, ok := canonicalValue(ov); ok {
+			return v, nil
+		}
+	}
+	// blobs whose bytes are matched by rules are copied as slices
+	if isBlob(ov.Type()) && len(c.plans.advance(active, &indexStep)) == 0 {
+		return c.copyBlob(ov)
 	}
 	switch ov.Kind() {
 	case reflect.Struct:
-		return c.copyStruct(ov)
+		return c.copyStruct(ov, active)
 	case reflect.Ptr:
-		return c.copyPointer(ov)
+		return c.copyPointer(ov, active)
 	case reflect.Slice:
-		return c.copySlice(ov)
+		return c.copySlice(ov, active)
 	case reflect.Map:
-		return c.copyMap(ov)
+		return c.copyMap(ov, active)
 	case reflect.Interface:
-		return c.copyInterface(ov)
+		return c.copyInterface(ov, active)
 	case reflect.Array:
-		return c.copyArray(ov)
+		return c.copyArray(ov, active)
+	case reflect.Func, reflect.Chan:
+		if !ov.IsNil() {
+			c.warn(WarningAliased, ov.Type(), "")
+		}
+		return ov, nil
 	case reflect.Int, reflect.String, reflect.Int64, reflect.Float64, reflect.Bool, reflect.Uint, reflect.Uint64,
-		reflect.Func, reflect.Chan, reflect.Float32,
+		reflect.Float32,
 		reflect.Int8, reflect.Int16, reflect.Int32,
 		reflect.Complex64, reflect.Complex128,
 		reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		if ov.Kind() == reflect.String && c.plans.cloneStrings {
+			return c.cloneString(ov), nil
+		}
 		return ov, nil
 	}
-	panic(fmt.Sprintf("unsupported type: %s", ov.Kind()))
+	return reflect.Zero(ov.Type()), &ErrUnsupportedKind{Kind: ov.Kind(), Path: c.pathString()}
 }
 
-func (c Config) copyStruct(ov reflect.Value) (reflect.Value, error) {
-	oc := reflect.New(ov.Type()).Elem()
-	ot := ov.Type()
-	for i := 0; i < ot.NumField(); i++ {
-		// skip unexported fields
-		if !ov.Field(i).CanInterface() {
-			continue
+// callHandler calls fn, registered with RegisterHandler, to copy ov.
+func (c *state) callHandler(fn reflect.Value, ov reflect.Value) (reflect.Value, error) {
+	atomic.AddUint64(&c.plans.handlerCalls, 1)
+	out := fn.Call([]reflect.Value{ov})
+	if err, _ := out[1].Interface().(error); err != nil {
+		return reflect.Zero(ov.Type()), &ErrHandler{Type: ov.Type(), Path: c.pathString(), Err: err}
+	}
+	return out[0], nil
+}
+
+// customize calls the customizer registered in the config under the given name.
+// For names of the form "idmap=name", the customizer is called once per distinct value in the session.
+// For names of the form "name,unique", the customized values are unique in the session.
+// For names of the form "dive,name", the elements of slices and arrays, and the values of maps, are customized with name.
+// For names of the form "name1,name2", the customizers are applied in order, each to the result of the previous one.
+func (c *state) customize(name string, ov reflect.Value) (reflect.Value, error) {
+	if strings.HasPrefix(name, divePrefix) {
+		return c.customizeElems(name[len(divePrefix):], ov)
+	}
+	if strings.HasPrefix(name, idmapPrefix) {
+		return c.customizeMapped(name[len(idmapPrefix):], ov)
+	}
+	if base, ok := strings.CutSuffix(name, uniqueOption); ok {
+		return c.customizeUnique(base, ov)
+	}
+	if _, ok := c.config[name]; !ok {
+		if links := c.config.chain(name); len(links) > 1 {
+			return c.customizeChain(links, ov)
 		}
-		tag := ot.Field(i).Tag.Get(tagCcopy)
-		if tag == "" {
-			// cannot set zero values, in case of pointers
-			if v, err := c.copy(ov.Field(i)); err != nil {
-				return reflect.Zero(ov.Type()), err
-			} else if !v.IsZero() {
-				oc.Field(i).Set(v)
+	}
+	fn := c.config[name]
+	if base, _ := splitTag(name); fn == nil && base != name {
+		fn = c.config[base]
+	}
+	if fn == nil {
+		return reflect.Zero(ov.Type()), &ErrMissingCustomizer{Tag: name, Path: c.pathString()}
+	}
+	fv := reflect.ValueOf(fn)
+	sig, ok := signatureOf(fv.Type(), ov.Type())
+	if !ok {
+		return reflect.Zero(ov.Type()), &ErrBadCustomizerSignature{Tag: name, Path: c.pathString(), Customizer: fv.Type(), Type: ov.Type()}
+	}
+	return c.apply(name, fv, sig, ov)
+}
+
+// apply customizes ov with the customizer fn, of signature sig, of the tag: its name, and its arguments, if any.
+func (c *state) apply(tag string, fv reflect.Value, sig signature, ov reflect.Value) (reflect.Value, error) {
+	name, args := splitTag(tag)
+	if _, ok := c.config[tag]; ok {
+		name, args = tag, ""
+	}
+	key, intern := c.internKeyOf(name, tag, ov)
+	if v, ok := c.interned[key]; intern && ok {
+		return v, nil
+	}
+	if c.tracing {
+		c.trace = append(c.trace, Invocation{Tag: name, Path: c.pathString()})
+	}
+	v, err := c.call(name, args, fv, sig, ov)
+	if err != nil {
+		return v, err
+	}
+	if err := c.checkResult(name, ov, v); err != nil {
+		return reflect.Zero(ov.Type()), err
+	}
+	if c.copyResults[name] {
+		v = clone(v, make(map[uintptr]reflect.Value))
+	}
+	if v.Kind() == reflect.String && c.plans.cloneStrings {
+		v = c.cloneString(v)
+	}
+	if intern {
+		if c.interned == nil {
+			c.interned = make(map[internKey]reflect.Value)
+		}
+		c.interned[key] = v
+	}
+	return v, nil
+}
+
+const idmapPrefix = "idmap="
+
+func (c *state) customizeMapped(name string, ov reflect.Value) (reflect.Value, error) {
+	if !ov.Type().Comparable() {
+		return reflect.Zero(ov.Type()), &ErrIncomparable{Tag: name, Path: c.pathString(), Type: ov.Type()}
+	}
+	table := strings.TrimSuffix(name, uniqueOption)
+	m, err := c.session.mapValue(table, ov.Interface(), func(interface{}) (interface{}, error) {
+		v, err := c.customize(name, ov)
+		if err != nil {
+			return nil, err
+		}
+		return v.Interface(), nil
+	})
+	if err != nil {
+		return reflect.Zero(ov.Type()), err
+	}
+	if m == nil {
+		return reflect.Zero(ov.Type()), nil
+	}
+	return reflect.ValueOf(m), nil
+}
+
+func (c *state) copyStruct(ov reflect.Value, active []match) (reflect.Value, error) {
+	oc := reflect.New(ov.Type()).Elem()
+	sp := c.plans.structPlan(ov.Type())
+	if sp.flat && len(active) == 0 {
+		oc.Set(ov)
+		return oc, nil
+	}
+	if sp.exposed && !ov.CanAddr() {
+		// the unexported fields are read through their addresses
+		addressable := reflect.New(ov.Type()).Elem()
+		addressable.Set(ov)
+		ov = addressable
+	}
+	for _, name := range sp.unexported {
+		c.warn(WarningUnexportedField, ov.Type(), name)
+	}
+	if sp.costly {
+		c.warn(WarningCostly, ov.Type(), "")
+	}
+	branch := -1
+	if sp.union != nil {
+		branch = sp.union.active(ov)
+	}
+	for i := range sp.fields {
+		f := &sp.fields[i]
+		if sp.union != nil {
+			if f.index == sp.union.discriminator {
+				oc.Field(f.index).Set(ov.Field(f.index))
+				continue
 			}
-		} else {
-			fn := c[tag]
-			if fn == nil {
-				return reflect.Zero(ov.Type()), fmt.Errorf("missing copy customiser for: %s", tag)
+			if sp.union.isBranch[f.index] && f.index != branch {
+				continue
 			}
-			values := reflect.ValueOf(fn).Call([]reflect.Value{ov.Field(i)})
-			// cannot set zero values, in case of pointers
-			if !values[0].IsZero() {
-				oc.Field(i).Set(values[0])
+		}
+		src, dst := ov.Field(f.index), oc.Field(f.index)
+		if f.unexported {
+			src, dst = exposed(ov, f.index), exposed(oc, f.index)
+		}
+		if f.fast != nil && (len(active) == 0 || len(c.plans.advance(active, &f.step)) == 0) &&
+			(src.Kind() != reflect.Ptr || c.pointers == PointersDuplicate && registeredShared() == nil) {
+			f.fast(dst, src)
+			c.accountFast(dst)
+			if c.limited() {
+				c.push(pathElem{kind: segField, field: f.step.name})
+				err := c.limit(fastElements(dst))
+				c.pop()
+				if err != nil {
+					return reflect.Zero(ov.Type()), err
+				}
 			}
+			continue
+		}
+		switch f.tag {
+		case tagSkip:
+			continue
+		case tagShallow:
+			dst.Set(src)
+			continue
+		}
+		var v reflect.Value
+		var err error
+		c.push(pathElem{kind: segField, field: f.step.name})
+		switch {
+		case f.customizer.IsValid():
+			v, err = c.apply(f.tag, f.customizer, f.sig, src)
+		case f.tag != "":
+			v, err = c.customize(f.tag, src)
+		default:
+			v, err = c.copy(src, c.plans.advance(active, &f.step))
+		}
+		c.pop()
+		if err != nil {
+			if c.tolerate(err) {
+				continue
+			}
+			return reflect.Zero(ov.Type()), err
+		}
+		// cannot set zero values, in case of pointers
+		if !v.IsZero() {
+			dst.Set(v)
 		}
 	}
 	return oc, nil
 }
 
-func (c Config) copyPointer(ov reflect.Value) (reflect.Value, error) {
-	if ov.IsNil() {
+// exposed returns the field i of the addressable struct v, which can be read and set even if it is unexported.
+func exposed(v reflect.Value, i int) reflect.Value {
+	f := v.Field(i)
+	return reflect.NewAt(f.Type(), unsafe.Pointer(f.UnsafeAddr())).Elem()
+}
+
+func (c *state) copyPointer(ov reflect.Value, active []match) (reflect.Value, error) {
+	if ov.IsNil() || isShared(ov) {
 		return ov, nil
 	}
+	if c.pointers == PointersShare {
+		if oc, ok := c.sharedCopy(ov, active); ok {
+			return oc, nil
+		}
+	}
+	if oc, ok := c.copying(ov); ok {
+		return oc, nil
+	}
 	oc := reflect.New(ov.Type().Elem())
-	v, err := c.copy(ov.Elem())
+	if c.pointers == PointersShare {
+		c.share(ov, oc, active)
+	}
+	defer c.enter(ov, oc)()
+	c.account(ov.Type(), int(ov.Type().Elem().Size()))
+	v, err := c.copy(ov.Elem(), active)
 	if err != nil {
 		return reflect.Zero(ov.Type()), err
 	}
@@ -103,12 +830,22 @@ func (c Config) copyPointer(ov reflect.Value) (reflect.Value, error) {
 	return oc, nil
 }
 
-func (c Config) copyInterface(ov reflect.Value) (reflect.Value, error) {
+func (c *state) copyInterface(ov reflect.Value, active []match) (reflect.Value, error) {
 	if ov.IsNil() {
 		return ov, nil
 	}
 	oc := reflect.New(ov.Type()).Elem()
-	v, err := c.copy(ov.Elem())
+	if fn, ok := c.factory(ov.Type(), ov.Elem().Type()); ok {
+		arg := ov.Elem()
+		if fn.Type().In(0) == ov.Type() {
+			arg = ov
+		}
+		if v := fn.Call([]reflect.Value{arg})[0]; !v.IsNil() {
+			oc.Set(v)
+		}
+		return oc, nil
+	}
+	v, err := c.copy(ov.Elem(), active)
 	if err != nil {
 		return reflect.Zero(ov.Type()), err
 	}
@@ -116,54 +853,120 @@ func (c Config) copyInterface(ov reflect.Value) (reflect.Value, error) {
 	return oc, nil
 }
 
-func (c Config) copySlice(ov reflect.Value) (reflect.Value, error) {
+func (c *state) copySlice(ov reflect.Value, active []match) (reflect.Value, error) {
 	if ov.IsNil() {
 		return ov, nil
 	}
-	oc := reflect.MakeSlice(ov.Type(), 0, ov.Len())
-	for i := 0; i < ov.Len(); i++ {
-		v, err := c.copy(ov.Index(i))
-		if err != nil {
-			return reflect.Zero(ov.Type()), err
-		}
-		oc = reflect.Append(oc, v)
+	oc := reflect.MakeSlice(ov.Type(), ov.Len(), ov.Len())
+	c.account(ov.Type(), ov.Len()*int(ov.Type().Elem().Size()))
+	if err := c.limit(ov.Len()); err != nil {
+		return reflect.Zero(ov.Type()), err
+	}
+	active = c.plans.advance(active, &indexStep)
+	if len(active) == 0 && !c.canonical && c.plain(ov.Type().Elem()) {
+		reflect.Copy(oc, ov)
+		return oc, nil
+	}
+	if err := c.copyElems(oc, ov, active); err != nil {
+		return reflect.Zero(ov.Type()), err
 	}
 	return oc, nil
 }
 
-func (c Config) copyArray(ov reflect.Value) (reflect.Value, error) {
+func (c *state) copyArray(ov reflect.Value, active []match) (reflect.Value, error) {
 	oc := reflect.New(ov.Type()).Elem()
-	slice := oc.Slice3(0, 0, ov.Len())
+	active = c.plans.advance(active, &indexStep)
+	if len(active) == 0 && !c.canonical && c.plain(ov.Type().Elem()) {
+		oc.Set(ov)
+		return oc, nil
+	}
+	if err := c.copyElems(oc, ov, active); err != nil {
+		return reflect.Zero(ov.Type()), err
+	}
+	return oc, nil
+}
+
+// copyElems copies the elements of the slice or array ov to the ones of oc, of the same length,
+// leaving the elements that fail to be copied as zero values, for partial copies.
+func (c *state) copyElems(oc, ov reflect.Value, active []match) error {
 	for i := 0; i < ov.Len(); i++ {
-		v, err := c.copy(ov.Index(i))
+		c.push(pathElem{kind: segIndex, index: i})
+		v, err := c.copy(ov.Index(i), active)
+		c.pop()
 		if err != nil {
-			return reflect.Zero(ov.Type()), err
+			if !c.tolerate(err) {
+				return err
+			}
+			continue
 		}
-		slice = reflect.Append(slice, v)
+		oc.Index(i).Set(v)
 	}
-	return oc, nil
+	return nil
+}
+
+func (c *state) copyMap(ov reflect.Value, active []match) (reflect.Value, error) {
+	values := c.plans.advance(active, &keyStep)
+	return c.copyEntries(ov, func(value reflect.Value) (reflect.Value, error) {
+		return c.copy(value, values)
+	})
 }
 
-func (c Config) copyMap(ov reflect.Value) (reflect.Value, error) {
+// copyEntries copies the map ov, copying its keys and its values with copyValue.
+func (c *state) copyEntries(ov reflect.Value, copyValue func(reflect.Value) (reflect.Value, error)) (reflect.Value, error) {
 	if ov.IsNil() {
 		return ov, nil
 	}
 	oc := reflect.MakeMapWithSize(ov.Type(), ov.Len())
-	iter := ov.MapRange()
-	for iter.Next() {
-		k, err := c.copy(iter.Key())
+	c.account(ov.Type(), ov.Len()*int(ov.Type().Key().Size()+ov.Type().Elem().Size()))
+	if err := c.limit(ov.Len()); err != nil {
+		return reflect.Zero(ov.Type()), err
+	}
+	copyEntry := func(key, value reflect.Value) error {
+		c.push(pathElem{kind: segKey, key: key})
+		defer c.pop()
+		if containsNaN(key) {
+			switch c.nanKeys {
+			case NaNKeysDrop:
+				return nil
+			case NaNKeysError:
+				return &ErrNaNKey{Path: c.pathString()}
+			}
+			c.warn(WarningNaNKey, ov.Type(), "")
+		}
+		k, err := c.copy(key, nil)
 		if err != nil {
-			return reflect.Zero(ov.Type()), err
+			return err
 		}
-		v, err := c.copy(iter.Value())
+		if oc.MapIndex(k).IsValid() {
+			switch c.keyCollisions {
+			case KeyCollisionsError:
+				return &ErrKeyCollision{Path: c.pathString()}
+			case KeyCollisionsKeepFirst:
+				c.warn(WarningKeyCollision, ov.Type(), "")
+				return nil
+			}
+			c.warn(WarningKeyCollision, ov.Type(), "")
+		}
+		v, err := copyValue(value)
 		if err != nil {
-			return reflect.Zero(ov.Type()), err
+			return err
 		}
 		oc.SetMapIndex(k, v)
+		return nil
+	}
+	if c.sortMapKeys {
+		for _, e := range sortedEntries(ov) {
+			if err := copyEntry(e[0], e[1]); err != nil && !c.tolerate(err) {
+				return reflect.Zero(ov.Type()), err
+			}
+		}
+		return oc, nil
+	}
+	iter := ov.MapRange()
+	for iter.Next() {
+		if err := copyEntry(iter.Key(), iter.Value()); err != nil && !c.tolerate(err) {
+			return reflect.Zero(ov.Type()), err
+		}
 	}
 	return oc, nil
 }
-
-func (c Config) copyTime(ov reflect.Value) (reflect.Value, error) {
-	return ov, nil
-}
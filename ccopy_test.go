@@ -1,7 +1,11 @@
 package ccopy
 
 import (
+	"bytes"
+	"context"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 )
@@ -80,3 +84,179 @@ func TestAnonymiseNilField(t *testing.T) {
 		t.Fatalf("got value: %v, expected int pointer to 1", v)
 	}
 }
+
+func TestCopyT(t *testing.T) {
+	c := Config{"AnonymiseName": AnonymiseName, "AnonymiseData": AnonymiseData}
+	v, err := CopyT(c, &T{Name: "important", Data: A{Data: []string{"1", "2"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(&T{Name: "not important", Data: A{Data: []string{"1"}}}, v); diff != "" {
+		t.Fatal(diff)
+	}
+	var s fmt.Stringer
+	if s, err = CopyT[fmt.Stringer](c, nil); err != ErrInvalidValue || s != nil {
+		t.Fatalf("got: %v, %v, expected nil and ErrInvalidValue", s, err)
+	}
+	if s, err = CopyT[fmt.Stringer](c, time.Second); err != nil || s != time.Second {
+		t.Fatalf("got: %v, %v, expected: %v", s, err, time.Second)
+	}
+}
+
+func TestCopyBoth(t *testing.T) {
+	c, err := NewCopier(Config{"AnonymiseName": AnonymiseName, "AnonymiseData": AnonymiseData}, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	obj := &T{Name: "important", Data: A{Data: []string{"1", "2"}}}
+	full, redacted, err := CopyBoth(c, obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(obj, full); diff != "" {
+		t.Fatal(diff)
+	}
+	if diff := cmp.Diff(&T{Name: "not important", Data: A{Data: []string{"1"}}}, redacted); diff != "" {
+		t.Fatal(diff)
+	}
+	if full == obj || &full.Data.Data[0] == &obj.Data.Data[0] {
+		t.Fatal("expected the full copy not to share memory with the object")
+	}
+	if full, _, err := CopyBoth(c, struct {
+		Name string `ccopy:"missing"`
+	}{"name"}); err == nil || full.Name != "" {
+		t.Fatalf("got: %v, %v, expected a zero full copy and an error for the missing customizer", full, err)
+	}
+
+	// the full copy is made by the engine, without the customizations
+	type Token string
+	type Record struct {
+		Token   Token
+		Email   string `ccopy:"AnonymiseName"`
+		Config  *sharedConfig
+		Ctx     context.Context
+		private []string
+	}
+	config := Config{"AnonymiseName": AnonymiseName}
+	config.RegisterType(func(Token) Token { return "" })
+	c, err = NewCopier(config, Options{Rules: Rules{"Record.private": "AnonymiseName"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	global := &sharedConfig{Name: "global"}
+	RegisterShared(global)
+	defer UnregisterShared(global)
+	ctx := context.WithValue(context.Background(), copyBothKey{}, "value")
+	record := Record{Token: "token", Email: "john@example.com", Config: global, Ctx: ctx, private: []string{"a"}}
+	fullRecord, redactedRecord, err := CopyBoth(c, record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fullRecord.Token != "token" || fullRecord.Email != record.Email || fullRecord.Config != global || fullRecord.Ctx != ctx ||
+		len(fullRecord.private) != 1 || &fullRecord.private[0] == &record.private[0] {
+		t.Fatalf("got: %+v, expected the full copy of the record", fullRecord)
+	}
+	if redactedRecord.Token != "" || redactedRecord.Email != "not important" || redactedRecord.Config != global {
+		t.Fatalf("got: %+v, expected the redacted copy of the record", redactedRecord)
+	}
+}
+
+type copyBothKey struct{}
+
+func TestCopyPartial(t *testing.T) {
+	type Inner struct {
+		Secret string `ccopy:"missing"`
+		Public string
+	}
+	type T struct {
+		Name   string
+		Inners []Inner
+		ByKey  map[string]string `ccopy:"missing"`
+		Array  [2]interface{}
+		Ptr    uintptr
+	}
+	c, err := NewCopier(Config{}, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	obj := T{Name: "a", Inners: []Inner{{"s", "p"}}, ByKey: map[string]string{"k": "v"}, Array: [2]interface{}{1, uintptr(2)}, Ptr: 3}
+	res, errs := c.CopyPartial(obj)
+	expected := T{Name: "a", Inners: []Inner{{Public: "p"}}, Array: [2]interface{}{1, nil}}
+	if diff := cmp.Diff(res, expected); diff != "" {
+		t.Fatal(diff)
+	}
+	if len(errs) != 4 {
+		t.Fatalf("got %v, expected 4 errors", errs)
+	}
+	if _, err := c.Copy(obj); err == nil {
+		t.Fatal("expected the copy to fail")
+	}
+	if res, errs := c.CopyPartial(uintptr(1)); res != nil || len(errs) != 1 {
+		t.Fatalf("got %v and %v, expected the root to fail", res, errs)
+	}
+}
+
+func TestCopyUnexported(t *testing.T) {
+	type inner struct {
+		token string `ccopy:"redact"`
+		tags  []string
+	}
+	type T struct {
+		Name    string
+		count   int
+		secrets map[string]*inner
+		buf     *bytes.Buffer
+	}
+	c, err := NewCopier(Config{"redact": func(string) string { return "" }}, Options{Unexported: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	obj := T{Name: "name", count: 2, secrets: map[string]*inner{"a": {token: "t", tags: []string{"x"}}}, buf: bytes.NewBufferString("data")}
+	v, err := CopyWith(c, obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := T{Name: "name", count: 2, secrets: map[string]*inner{"a": {tags: []string{"x"}}}, buf: bytes.NewBufferString("data")}
+	if diff := cmp.Diff(expected, v, cmp.AllowUnexported(T{}, inner{}, bytes.Buffer{})); diff != "" {
+		t.Fatal(diff)
+	}
+	if v.secrets["a"] == obj.secrets["a"] || &v.secrets["a"].tags[0] == &obj.secrets["a"].tags[0] || v.buf == obj.buf {
+		t.Fatal("expected the unexported fields to be deep copied")
+	}
+	v.buf.WriteString(" more")
+	if obj.buf.String() != "data" {
+		t.Fatalf("got: %s, expected the buffer of the object unchanged", obj.buf)
+	}
+
+	// without the option, the unexported fields are left out
+	if v, err := CopyT(Config{}, obj); err != nil || v.count != 0 || v.buf != nil {
+		t.Fatalf("got: %+v, %v, expected the unexported fields left out", v, err)
+	}
+}
+
+func TestCopyDirectives(t *testing.T) {
+	type Inner struct {
+		Name string `ccopy:"redact"`
+	}
+	type T struct {
+		Name   string
+		Debug  *Inner          `ccopy:"-"`
+		Cache  map[string]bool `ccopy:"shallow"`
+		Parent *Inner          `ccopy:"shallow"`
+	}
+	c, err := NewCopier(Config{}, Options{Rules: Rules{"T.Debug.Name": "missing"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	obj := T{Name: "name", Debug: &Inner{Name: "debug"}, Cache: map[string]bool{"a": true}, Parent: &Inner{Name: "parent"}}
+	v, err := CopyWith(c, obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(T{Name: "name", Cache: obj.Cache, Parent: obj.Parent}, v); diff != "" {
+		t.Fatal(diff)
+	}
+	if v.Parent != obj.Parent {
+		t.Fatal("expected the shallow field to point to the value of the object")
+	}
+}
@@ -0,0 +1,37 @@
+package ccopy
+
+import (
+	"reflect"
+	"strings"
+)
+
+// chain returns the tags of the customizers applied in order by tag, a comma separated list of names of customizers
+// of c, each followed by its arguments, if any, like "trim" and "mask,keep=4" for "trim,mask,keep=4".
+// The elements of tag that are not names of customizers of c are arguments, but for the first one.
+func (c Config) chain(tag string) []string {
+	elems := strings.Split(tag, ",")
+	links := elems[:1]
+	for _, e := range elems[1:] {
+		if _, ok := c[e]; ok {
+			links = append(links, e)
+			continue
+		}
+		links[len(links)-1] += "," + e
+	}
+	return links
+}
+
+// customizeChain customizes ov with the customizers of links in order, each customizing the result of the previous one.
+func (c *state) customizeChain(links []string, ov reflect.Value) (reflect.Value, error) {
+	v := ov
+	for _, link := range links {
+		out, err := c.customize(link, v)
+		if err != nil {
+			return reflect.Zero(ov.Type()), err
+		}
+		// the results are assignable to the type of ov, like interfaces holding it, but can be of other types
+		v = reflect.New(ov.Type()).Elem()
+		v.Set(out)
+	}
+	return v, nil
+}
@@ -0,0 +1,13 @@
+module github.com/gadumitrachioaiei/ccopy/cmd/ccopy
+
+go 1.22.0
+
+require github.com/gadumitrachioaiei/ccopy/policy v0.0.0-20261015025859-791e0c53c2ea
+
+require (
+	github.com/gadumitrachioaiei/ccopy v0.0.0-20261015025656-45a8b2362908 // indirect
+	golang.org/x/mod v0.22.0 // indirect
+	golang.org/x/sync v0.10.0 // indirect
+	golang.org/x/tools v0.29.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
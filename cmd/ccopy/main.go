@@ -0,0 +1,199 @@
+// Command ccopy inspects how ccopy copies the types of Go packages, for policy reviews without writing Go code.
+//
+// Usage:
+//
+//	ccopy explain -type pkg.TypeName -rules rules.yaml [packages]
+//	ccopy rules-diff old.yaml new.yaml [-types packages]
+//	ccopy scan [-rules rules.yaml] [packages]
+//
+// The explain command prints, per path of the values of the type, the action copying them,
+// the customizer and what decides the action: a tag, a rule, a scope, a type handler or the default copy.
+// The rules file is a policy, in the YAML format of the policy package.
+// The type is looked for in the packages, ./... by default, and their dependencies.
+//
+// The rules-diff command prints the paths whose action changes from the old policy to the new one,
+// for all the struct types declared in the packages, a comma separated list of patterns, ./... by default.
+//
+// The scan command reports, per exported struct type of the packages, the exported fields tagged for ccopy,
+// the tags naming customizers unknown to the policy, if one is given, and the untagged fields
+// whose name suggests they hold personal data: it is the discovery step before onboarding a codebase.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/gadumitrachioaiei/ccopy/policy"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+	fs := flag.NewFlagSet(os.Args[1], flag.ExitOnError)
+	var run func(args []string) error
+	switch os.Args[1] {
+	case "explain":
+		var typeName, rules string
+		fs.StringVar(&typeName, "type", "", "type to explain, as pkgpath.Name or Name")
+		fs.StringVar(&rules, "rules", "", "policy file")
+		run = func(args []string) error {
+			if typeName == "" || rules == "" {
+				usage()
+			}
+			return explain(typeName, rules, patterns(args))
+		}
+	case "rules-diff":
+		var types string
+		fs.StringVar(&types, "types", "./...", "comma separated list of package patterns")
+		run = func(args []string) error {
+			if len(args) != 2 {
+				usage()
+			}
+			return rulesDiff(args[0], args[1], strings.Split(types, ","))
+		}
+	case "scan":
+		var rules string
+		fs.StringVar(&rules, "rules", "", "policy file, whose customizers are the known ones")
+		run = func(args []string) error {
+			return scan(rules, patterns(args))
+		}
+	default:
+		usage()
+	}
+	if err := run(parse(fs, os.Args[2:])); err != nil {
+		fail(err)
+	}
+}
+
+// parse parses the flags of args, which can be given after the other arguments, and returns the other arguments.
+func parse(fs *flag.FlagSet, args []string) []string {
+	var rest []string
+	for {
+		fs.Parse(args)
+		args = fs.Args()
+		if len(args) == 0 {
+			return rest
+		}
+		rest = append(rest, args[0])
+		args = args[1:]
+	}
+}
+
+func patterns(args []string) []string {
+	if len(args) == 0 {
+		return []string{"./..."}
+	}
+	return args
+}
+
+func explain(typeName, rules string, patterns []string) error {
+	p, err := policy.Read(rules)
+	if err != nil {
+		return err
+	}
+	pkgs, err := policy.Load(".", patterns...)
+	if err != nil {
+		return err
+	}
+	t, err := policy.FindType(pkgs, typeName)
+	if err != nil {
+		return err
+	}
+	actions, err := p.Explain(t)
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "PATH\tACTION\tCUSTOMIZER\tSOURCE\tRULE")
+	for _, a := range actions {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", a.Path, a.Action, a.Customizer, a.Source, a.Rule)
+	}
+	return w.Flush()
+}
+
+func rulesDiff(oldPath, newPath string, patterns []string) error {
+	old, err := policy.Read(oldPath)
+	if err != nil {
+		return err
+	}
+	new, err := policy.Read(newPath)
+	if err != nil {
+		return err
+	}
+	pkgs, err := policy.Load(".", patterns...)
+	if err != nil {
+		return err
+	}
+	changes, err := policy.Diff(old, new, policy.StructTypes(pkgs, false))
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "PATH\tOLD\tNEW")
+	for _, c := range changes {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", c.Path, describe(c.Old), describe(c.New))
+	}
+	return w.Flush()
+}
+
+// describe describes an action as action[:customizer] (source), or - if there is none.
+func describe(a *policy.FieldAction) string {
+	if a == nil {
+		return "-"
+	}
+	s := a.Action
+	if a.Customizer != "" {
+		s += ":" + a.Customizer
+	}
+	return s + " (" + string(a.Source) + ")"
+}
+
+func scan(rules string, patterns []string) error {
+	var known []string
+	if rules != "" {
+		p, err := policy.Read(rules)
+		if err != nil {
+			return err
+		}
+		known = append([]string{}, p.Customizers...)
+	}
+	pkgs, err := policy.Load(".", patterns...)
+	if err != nil {
+		return err
+	}
+	s := policy.ScanTypes(pkgs, known)
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "TYPE\tTAGGED\tUNKNOWN TAGS\tSUSPECT FIELDS")
+	for _, t := range s.Types {
+		var unknown []string
+		for _, f := range t.Unknown {
+			unknown = append(unknown, f.Field+":"+f.Tag)
+		}
+		fmt.Fprintf(w, "%s\t%d/%d\t%s\t%s\n", t.Type, len(t.Tagged), t.Fields, strings.Join(unknown, ","), strings.Join(t.Suspect, ","))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	coverage := 0.0
+	if s.Fields() > 0 {
+		coverage = 100 * float64(s.Tagged()) / float64(s.Fields())
+	}
+	_, err = fmt.Printf("\n%d types, %d/%d fields tagged (%.1f%%)\n", len(s.Types), s.Tagged(), s.Fields(), coverage)
+	return err
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: ccopy explain -type pkg.TypeName -rules rules.yaml [packages]")
+	fmt.Fprintln(os.Stderr, "       ccopy rules-diff old.yaml new.yaml [-types packages]")
+	fmt.Fprintln(os.Stderr, "       ccopy scan [-rules rules.yaml] [packages]")
+	os.Exit(2)
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "ccopy:", err)
+	os.Exit(1)
+}
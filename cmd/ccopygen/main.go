@@ -0,0 +1,114 @@
+// Command ccopygen generates Go code for ccopy, from the struct types of the package in the current directory.
+//
+// Usage:
+//
+//	ccopygen annotations [-o file]
+//	ccopygen config [-o file] [-type T1,T2]
+//	ccopygen stubs [-o file] [-known name1,name2]
+//	ccopygen copiers [-o file] [-type T1,T2]
+//
+// The annotations command generates a file registering ccopy rules for the fields
+// annotated with comments of the form //ccopy:name, for types whose fields cannot be tagged.
+// It is meant to be run by go generate:
+//
+//	//go:generate ccopygen annotations
+//
+// The config command generates a typed builder of ccopy.Config for each struct type,
+// with a method per tagged field taking a customizer of the type of the field,
+// so binding customizers to tags is checked by the compiler:
+//
+//	cfg := UserCopyConfig{}.WithName(func(name string) string { return "" }).Config()
+//
+// The stubs command generates a skeleton file with a stub customizer, of the signature the tagged fields need,
+// for each tag that is not a key of the ccopy.Config literals of the package, nor of the known customizers,
+// defined elsewhere. The file is meant to be edited, to customize the values.
+//
+// The copiers command generates a copy function for each struct type, copying its values with the customizers
+// of their tags only, without reflection, for the copies whose throughput matters:
+//
+//	copied, err := CopyUser(cfg, user)
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gadumitrachioaiei/ccopy/codegen"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+	fs := flag.NewFlagSet(os.Args[1], flag.ExitOnError)
+	var generate func(p *codegen.Package) ([]byte, error)
+	var output string
+	switch os.Args[1] {
+	case "annotations":
+		generate = (*codegen.Package).GenerateAnnotations
+		fs.StringVar(&output, "o", "ccopy_annotations.go", "output file")
+	case "config":
+		var types string
+		fs.StringVar(&output, "o", "ccopy_config.go", "output file")
+		fs.StringVar(&types, "type", "", "comma separated list of struct types, all struct types with tagged fields if empty")
+		generate = func(p *codegen.Package) ([]byte, error) {
+			var names []string
+			if types != "" {
+				names = strings.Split(types, ",")
+			}
+			return p.GenerateConfigBuilders(names...)
+		}
+	case "stubs":
+		var known string
+		fs.StringVar(&output, "o", "ccopy_stubs.go", "output file")
+		fs.StringVar(&known, "known", "", "comma separated list of customizers defined outside the config literals of the package")
+		generate = func(p *codegen.Package) ([]byte, error) {
+			var names []string
+			if known != "" {
+				names = strings.Split(known, ",")
+			}
+			return p.GenerateStubs(names...)
+		}
+	case "copiers":
+		var types string
+		fs.StringVar(&output, "o", "ccopy_copiers.go", "output file")
+		fs.StringVar(&types, "type", "", "comma separated list of struct types, all struct types if empty")
+		generate = func(p *codegen.Package) ([]byte, error) {
+			var names []string
+			if types != "" {
+				names = strings.Split(types, ",")
+			}
+			return p.GenerateCopiers(names...)
+		}
+	default:
+		usage()
+	}
+	fs.Parse(os.Args[2:])
+
+	p, err := codegen.ParseDir(".")
+	if err != nil {
+		fail(err)
+	}
+	src, err := generate(p)
+	if err != nil {
+		fail(err)
+	}
+	if err := os.WriteFile(output, src, 0o644); err != nil {
+		fail(err)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: ccopygen annotations [-o file]")
+	fmt.Fprintln(os.Stderr, "       ccopygen config [-o file] [-type T1,T2]")
+	fmt.Fprintln(os.Stderr, "       ccopygen stubs [-o file] [-known name1,name2]")
+	fmt.Fprintln(os.Stderr, "       ccopygen copiers [-o file] [-type T1,T2]")
+	os.Exit(2)
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "ccopygen:", err)
+	os.Exit(1)
+}
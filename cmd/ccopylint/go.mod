@@ -0,0 +1,13 @@
+module github.com/gadumitrachioaiei/ccopy/cmd/ccopylint
+
+go 1.22.0
+
+require (
+	github.com/gadumitrachioaiei/ccopy/analysis/ccopylint v0.0.0-20261015030425-0f73207c8403
+	golang.org/x/tools v0.29.0
+)
+
+require (
+	golang.org/x/mod v0.22.0 // indirect
+	golang.org/x/sync v0.10.0 // indirect
+)
@@ -0,0 +1,15 @@
+// Command ccopylint reports struct fields of sensitive types that are not customized by ccopy.
+//
+// Usage:
+//
+//	ccopylint -types EmailAddress,example.com/pii.SSN ./...
+package main
+
+import (
+	"github.com/gadumitrachioaiei/ccopy/analysis/ccopylint"
+	"golang.org/x/tools/go/analysis/singlechecker"
+)
+
+func main() {
+	singlechecker.Main(ccopylint.Analyzer)
+}
@@ -0,0 +1,478 @@
+// Package codegen generates Go code for ccopy, from the struct types of a package.
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// annotationPrefix starts the comments annotating a field with the name of its customizer.
+const annotationPrefix = "//ccopy:"
+
+// Package represents the struct types of a package, parsed from its source files.
+type Package struct {
+	Name  string
+	Fset  *token.FileSet
+	Files []*ast.File
+}
+
+// ParseDir parses the Go files of the package in dir, excluding tests.
+func ParseDir(dir string) (*Package, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(fi fs.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	if len(pkgs) != 1 {
+		return nil, fmt.Errorf("expected one package in %s, found %d", dir, len(pkgs))
+	}
+	p := &Package{Fset: fset}
+	for name, pkg := range pkgs {
+		p.Name = name
+		var names []string
+		for filename := range pkg.Files {
+			names = append(names, filename)
+		}
+		sort.Strings(names)
+		for _, filename := range names {
+			p.Files = append(p.Files, pkg.Files[filename])
+		}
+	}
+	return p, nil
+}
+
+// Structs calls fn for every struct type declared at the top level of the package, in source order.
+func (p *Package) Structs(fn func(name string, st *ast.StructType)) {
+	for _, f := range p.Files {
+		for _, decl := range f.Decls {
+			gd, ok := decl.(*ast.GenDecl)
+			if !ok || gd.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gd.Specs {
+				ts := spec.(*ast.TypeSpec)
+				if st, ok := ts.Type.(*ast.StructType); ok {
+					fn(ts.Name.Name, st)
+				}
+			}
+		}
+	}
+}
+
+// Annotations returns the rules given by comments of the form //ccopy:name, in the doc or line comment of a field.
+// The keys are the paths of the fields, like "User.Email", and the values are the names of their customizers.
+func (p *Package) Annotations() (map[string]string, error) {
+	rules := make(map[string]string)
+	var err error
+	p.Structs(func(name string, st *ast.StructType) {
+		if err == nil {
+			err = p.annotations(rules, name, st)
+		}
+	})
+	return rules, err
+}
+
+func (p *Package) annotations(rules map[string]string, path string, st *ast.StructType) error {
+	for _, field := range st.Fields.List {
+		names := fieldNames(field)
+		customizer, err := p.annotation(field)
+		if err != nil {
+			return err
+		}
+		for _, name := range names {
+			if customizer != "" {
+				rules[path+"."+name] = customizer
+			} else if inner, ok := field.Type.(*ast.StructType); ok {
+				if err := p.annotations(rules, path+"."+name, inner); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// annotation returns the customizer of a field given by its comments, if any.
+func (p *Package) annotation(field *ast.Field) (string, error) {
+	var customizer string
+	for _, cg := range []*ast.CommentGroup{field.Doc, field.Comment} {
+		if cg == nil {
+			continue
+		}
+		for _, c := range cg.List {
+			if !strings.HasPrefix(c.Text, annotationPrefix) {
+				continue
+			}
+			name := strings.TrimSpace(c.Text[len(annotationPrefix):])
+			if name == "" || customizer != "" && name != customizer {
+				return "", fmt.Errorf("%s: invalid ccopy annotation: %s", p.Fset.Position(c.Pos()), c.Text)
+			}
+			customizer = name
+		}
+	}
+	return customizer, nil
+}
+
+// fieldNames returns the names of a field, which is the name of the type for embedded fields.
+func fieldNames(field *ast.Field) []string {
+	if len(field.Names) > 0 {
+		var names []string
+		for _, n := range field.Names {
+			names = append(names, n.Name)
+		}
+		return names
+	}
+	t := field.Type
+	if star, ok := t.(*ast.StarExpr); ok {
+		t = star.X
+	}
+	switch t := t.(type) {
+	case *ast.Ident:
+		return []string{t.Name}
+	case *ast.SelectorExpr:
+		return []string{t.Sel.Name}
+	}
+	return nil
+}
+
+// header returns the header of a generated file of package name.
+func header(name string) *bytes.Buffer {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by ccopygen; DO NOT EDIT.\n\npackage %s\n\n", name)
+	return &b
+}
+
+// GenerateAnnotations returns the source of a file registering the annotations of the package as ccopy rules.
+func (p *Package) GenerateAnnotations() ([]byte, error) {
+	rules, err := p.Annotations()
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for path := range rules {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	b := header(p.Name)
+	b.WriteString("import (\n\t\"reflect\"\n\n\t\"github.com/gadumitrachioaiei/ccopy\"\n)\n\n")
+	// the rules start from the types of the package only, whose import path is the one of a type declared here
+	b.WriteString("type ccopyPackage struct{}\n\n")
+	b.WriteString("func init() {\n\tccopy.RegisterRules(reflect.TypeOf(ccopyPackage{}).PkgPath(), ccopy.Rules{\n")
+	for _, path := range paths {
+		fmt.Fprintf(b, "\t\t%q: %q,\n", path, rules[path])
+	}
+	b.WriteString("\t})\n}\n")
+	return format.Source(b.Bytes())
+}
+
+// qualifier matches the package names of qualified identifiers.
+var qualifier = regexp.MustCompile(`\b([A-Za-z_][A-Za-z0-9_]*)\.`)
+
+// builderField is a tagged field reachable from a struct type.
+type builderField struct {
+	method string
+	key    string
+	typ    string
+	path   string
+	// args is set for the fields whose tags have arguments, whose customizers take them: func(ccopy.Args, T) T
+	args bool
+}
+
+// GenerateConfigBuilders returns the source of a file defining, for each of the named struct types,
+// or for every struct type with tagged fields if no name is given, a typed builder of ccopy.Config:
+//
+//	type UserCopyConfig ccopy.Config
+//	func (c UserCopyConfig) WithName(fn func(string) string) UserCopyConfig
+//
+// There is a method per tagged field reachable from the type through types of the package,
+// named after the path of the field, so binding customizers to fields is checked by the compiler,
+// and a method per customizer chained by the tag after the first one, named after the field and its key, like WithNameLowercase.
+// It returns an error if fields tagged alike, reachable from the same type, have different types.
+func (p *Package) GenerateConfigBuilders(names ...string) ([]byte, error) {
+	structs := make(map[string]*ast.StructType)
+	var order []string
+	imports := p.imports()
+	p.Structs(func(name string, st *ast.StructType) {
+		structs[name] = st
+		order = append(order, name)
+	})
+	if len(names) == 0 {
+		names = order
+	}
+
+	b := header(p.Name)
+	var body bytes.Buffer
+	used := map[string]bool{"ccopy": true}
+	imports["ccopy"] = "github.com/gadumitrachioaiei/ccopy"
+	for _, name := range names {
+		st, ok := structs[name]
+		if !ok {
+			return nil, fmt.Errorf("struct type not found: %s", name)
+		}
+		var fields []builderField
+		if err := p.builderFields(&fields, structs, map[string]bool{name: true}, "", name, st); err != nil {
+			return nil, err
+		}
+		if len(fields) == 0 && len(names) == len(order) {
+			continue
+		}
+		for _, f := range fields {
+			if err := useImports(used, imports, f); err != nil {
+				return nil, err
+			}
+		}
+		builder := name + "CopyConfig"
+		fmt.Fprintf(&body, "// %s builds a ccopy.Config for the tagged fields of %s.\n", builder, name)
+		fmt.Fprintf(&body, "type %s ccopy.Config\n\n", builder)
+		fmt.Fprintf(&body, "// Config returns the built config.\nfunc (c %s) Config() ccopy.Config {\n\treturn ccopy.Config(c)\n}\n\n", builder)
+		// a single method per tag, as the config is keyed by tags, so the fields tagged alike must have the same type
+		paths := make(map[string][]string)
+		first := make(map[string]*builderField)
+		for i := range fields {
+			f := &fields[i]
+			if ff := first[f.key]; ff == nil {
+				first[f.key] = f
+			} else if ff.typ != f.typ {
+				return nil, fmt.Errorf("fields %s (%s) and %s (%s) are tagged %q, but their types differ: they need tags of their own", ff.path, ff.typ, f.path, f.typ, f.key)
+			} else {
+				// the customizer takes the arguments of any of the tags
+				ff.args = ff.args || f.args
+			}
+			paths[f.key] = append(paths[f.key], f.path)
+		}
+		for _, f := range fields {
+			if paths[f.key] == nil {
+				continue
+			}
+			f = *first[f.key]
+			fmt.Fprintf(&body, "// %s sets the customizer tagged %q, of %s.\n", f.method, f.key, strings.Join(paths[f.key], ", "))
+			fmt.Fprintf(&body, "func (c %s) %s(fn func(%s%s) %s) %s {\n", builder, f.method, argsParam(f, ""), f.typ, f.typ, builder)
+			fmt.Fprintf(&body, "\tif c == nil {\n\t\tc = %s{}\n\t}\n\tc[%q] = fn\n\treturn c\n}\n\n", builder, f.key)
+			delete(paths, f.key)
+		}
+	}
+	writeImports(b, used, imports)
+	b.Write(body.Bytes())
+	return format.Source(b.Bytes())
+}
+
+// imports returns the paths of the packages imported by the files of the package, by name.
+func (p *Package) imports() map[string]string {
+	imports := make(map[string]string)
+	for _, f := range p.Files {
+		for _, spec := range f.Imports {
+			path := strings.Trim(spec.Path.Value, `"`)
+			name := path[strings.LastIndex(path, "/")+1:]
+			if spec.Name != nil {
+				name = spec.Name.Name
+			}
+			imports[name] = path
+		}
+	}
+	return imports
+}
+
+// useImports marks as used the packages qualifying the identifiers of the type of f.
+func useImports(used map[string]bool, imports map[string]string, f builderField) error {
+	for _, m := range qualifier.FindAllStringSubmatch(f.typ, -1) {
+		if imports[m[1]] == "" {
+			return fmt.Errorf("unknown package %s, in type of %s", m[1], f.path)
+		}
+		used[m[1]] = true
+	}
+	return nil
+}
+
+// writeImports writes the import declaration of the used packages.
+func writeImports(b *bytes.Buffer, used map[string]bool, imports map[string]string) {
+	if len(used) == 0 {
+		return
+	}
+	var pkgs []string
+	for pkg := range used {
+		pkgs = append(pkgs, pkg)
+	}
+	sort.Strings(pkgs)
+	b.WriteString("import (\n")
+	for _, pkg := range pkgs {
+		path := imports[pkg]
+		if path[strings.LastIndex(path, "/")+1:] == pkg {
+			fmt.Fprintf(b, "\t%q\n", path)
+		} else {
+			fmt.Fprintf(b, "\t%s %q\n", pkg, path)
+		}
+	}
+	b.WriteString(")\n\n")
+}
+
+// builderFields appends the tagged fields reachable from st, found at path, with methods prefixed by prefix.
+func (p *Package) builderFields(fields *[]builderField, structs map[string]*ast.StructType, visiting map[string]bool, prefix, path string, st *ast.StructType) error {
+	for _, field := range st.Fields.List {
+		for _, name := range fieldNames(field) {
+			if !ast.IsExported(name) || directive(field) {
+				continue
+			}
+			if links := tagLinks(field); len(links) > 0 {
+				typ, err := p.customizedType(field, path+"."+name)
+				if err != nil {
+					return err
+				}
+				// the chained customizers have methods of their own, named after their keys
+				for i, l := range links {
+					method := "With" + prefix + name
+					if i > 0 {
+						method += exportedName(l.key)
+					}
+					*fields = append(*fields, builderField{method: method, key: l.key, typ: typ, path: path + "." + name, args: l.args})
+				}
+				continue
+			}
+			inner, innerName := structType(field.Type, structs)
+			if inner == nil || visiting[innerName] {
+				continue
+			}
+			if innerName != "" {
+				visiting[innerName] = true
+			}
+			err := p.builderFields(fields, structs, visiting, prefix+name, path+"."+name, inner)
+			delete(visiting, innerName)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// tag returns the ccopy tag of a field.
+func tag(field *ast.Field) string {
+	if field.Tag == nil {
+		return ""
+	}
+	return reflect.StructTag(strings.Trim(field.Tag.Value, "`")).Get("ccopy")
+}
+
+// directive reports whether the ccopy tag of a field is a directive needing no customizer:
+// "-", leaving the field out of the copies, or "shallow", copying it by assignment.
+func directive(field *ast.Field) bool {
+	key := tag(field)
+	return key == "-" || key == "shallow"
+}
+
+// tagChain returns the ccopy tag of a field without the directives preceding and following its customizers,
+// like "mask,keep=4" for "dive,mask,keep=4" and "user" for "idmap=user,unique".
+func tagChain(field *ast.Field) string {
+	key := tag(field)
+	for strings.HasPrefix(key, "dive,") {
+		key = key[len("dive,"):]
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(key, "idmap="), ",unique")
+}
+
+// tagKey returns the key in the config of the customizer of a field, given by its ccopy tag,
+// the first one if the tag chains customizers.
+func tagKey(field *ast.Field) string {
+	// the arguments of the tag and the chained customizers, if any, follow the key
+	key, _, _ := strings.Cut(tagChain(field), ",")
+	return key
+}
+
+// link is a customizer chained by a tag.
+type link struct {
+	key string
+	// args is set for the customizers followed by arguments in the tag
+	args bool
+}
+
+// tagLinks returns the customizers chained by the ccopy tag of a field, in order, like "trim" and "mask" for "trim,mask,keep=4".
+// The config is not known here, so the elements of the tag following the first one are told apart by their form:
+// the options, of the form "key=value", are the arguments of the customizer preceding them,
+// and the others are the names of chained customizers.
+func tagLinks(field *ast.Field) []link {
+	chain := tagChain(field)
+	if chain == "" {
+		return nil
+	}
+	elems := strings.Split(chain, ",")
+	links := []link{{key: elems[0]}}
+	for _, e := range elems[1:] {
+		if strings.Contains(e, "=") {
+			links[len(links)-1].args = true
+			continue
+		}
+		links = append(links, link{key: e})
+	}
+	return links
+}
+
+// argsParam returns the parameter of the arguments of the tag of f, named name if not empty,
+// preceding the customized value in the signature of its customizer.
+func argsParam(f builderField, name string) string {
+	if !f.args {
+		return ""
+	}
+	if name == "" {
+		return "ccopy.Args, "
+	}
+	return name + " ccopy.Args, "
+}
+
+// customizedType returns the source of the type of the values customized by the tag of a field, at path:
+// the type of the field, or the type of its elements, for tags of the form "dive,name", through pointers.
+func (p *Package) customizedType(field *ast.Field, path string) (string, error) {
+	expr := field.Type
+	for key := tag(field); strings.HasPrefix(key, "dive,"); key = key[len("dive,"):] {
+		for {
+			star, ok := expr.(*ast.StarExpr)
+			if !ok {
+				break
+			}
+			expr = star.X
+		}
+		switch t := expr.(type) {
+		case *ast.ArrayType:
+			expr = t.Elt
+		case *ast.MapType:
+			expr = t.Value
+		default:
+			return "", fmt.Errorf("cannot dive into the type of %s", path)
+		}
+	}
+	var typ bytes.Buffer
+	if err := format.Node(&typ, p.Fset, expr); err != nil {
+		return "", err
+	}
+	return typ.String(), nil
+}
+
+// structType returns the struct type of the values held by a field of type expr, through pointers and containers,
+// and its name if it is a struct type of the package.
+func structType(expr ast.Expr, structs map[string]*ast.StructType) (*ast.StructType, string) {
+	for {
+		switch t := expr.(type) {
+		case *ast.StarExpr:
+			expr = t.X
+		case *ast.ArrayType:
+			expr = t.Elt
+		case *ast.MapType:
+			expr = t.Value
+		case *ast.StructType:
+			return t, ""
+		case *ast.Ident:
+			return structs[t.Name], t.Name
+		default:
+			return nil, ""
+		}
+	}
+}
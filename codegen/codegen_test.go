@@ -0,0 +1,177 @@
+package codegen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestAnnotations(t *testing.T) {
+	p, err := ParseDir("testdata/annotated")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rules, err := p.Annotations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{
+		"User.Email":       "maskEmail",
+		"User.Name":        "redact",
+		"User.Home.Street": "redact",
+		"User.Home.City":   "redact",
+	}
+	if diff := cmp.Diff(rules, expected); diff != "" {
+		t.Fatal(diff)
+	}
+	src, err := p.GenerateAnnotations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"// Code generated by ccopygen; DO NOT EDIT.", "package annotated", "ccopy.RegisterRules(reflect.TypeOf(ccopyPackage{}).PkgPath(), ccopy.Rules{", `"User.Email":       "maskEmail",`} {
+		if !strings.Contains(string(src), s) {
+			t.Fatalf("got source: %s, expected it to contain: %s", src, s)
+		}
+	}
+}
+
+func TestGenerateConfigBuilders(t *testing.T) {
+	p, err := ParseDir("testdata/tagged")
+	if err != nil {
+		t.Fatal(err)
+	}
+	src, err := p.GenerateConfigBuilders()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{
+		`pii "net/mail"`,
+		"type UserCopyConfig ccopy.Config",
+		"func (c UserCopyConfig) WithName(fn func(string) string) UserCopyConfig {",
+		`c["user"] = fn`,
+		`// WithHomeStreet sets the customizer tagged "street", of User.Home.Street, User.Work.Street.`,
+		"func (c UserCopyConfig) WithContact(fn func(*pii.Address) *pii.Address) UserCopyConfig {",
+		"func (c AddressCopyConfig) WithSince(fn func(time.Time) time.Time) AddressCopyConfig {",
+		`c["mask"] = fn`,
+		"func (c CompanyCopyConfig) WithIBAN(fn func(ccopy.Args, string) string) CompanyCopyConfig {",
+		"func (c CompanyCopyConfig) WithAlias(fn func(string) string) CompanyCopyConfig {",
+		`// WithAlias sets the customizer tagged "trim", of Company.Alias, Company.Notice.`,
+		"func (c CompanyCopyConfig) WithAliasLowercase(fn func(string) string) CompanyCopyConfig {",
+		`c["lowercase"] = fn`,
+	} {
+		if !strings.Contains(string(src), s) {
+			t.Fatalf("got source: %s, expected it to contain: %s", src, s)
+		}
+	}
+	for _, s := range []string{"WithWorkStreet", "PlainCopyConfig", "secret", "EventCopyConfig", "WithNoticeMask", "WithAlias(fn func(ccopy.Args"} {
+		if strings.Contains(string(src), s) {
+			t.Fatalf("got source: %s, expected it not to contain: %s", src, s)
+		}
+	}
+	if _, err := p.GenerateConfigBuilders("Missing"); err == nil {
+		t.Fatal("expected error for missing type")
+	}
+
+	p, err = ParseDir("testdata/mismatched")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.GenerateConfigBuilders(); err == nil || !strings.Contains(err.Error(), "User.Name (string) and User.Score (int)") {
+		t.Fatalf("got error: %v, expected an error for the fields tagged alike with different types", err)
+	}
+}
+
+func TestGenerateStubs(t *testing.T) {
+	p, err := ParseDir("testdata/tagged")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"street"}, p.ConfigKeys()); diff != "" {
+		t.Fatal(diff)
+	}
+	src, err := p.GenerateStubs("since")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{
+		"package tagged",
+		`//		"anonymiseName": customizeAnonymiseName,`,
+		"// customizeAnonymiseName customizes User.Name.",
+		`// The fields Company.Name (int) are tagged "anonymiseName" as well, but their types differ: they need tags of their own.`,
+		"func customizeAnonymiseName(v string) string {",
+		"func customizeUser(v int) int {",
+		"func customizeContact(v *pii.Address) *pii.Address {",
+		"// customizeMaskEmail customizes Company.Owner.Email.",
+		"func customizePhone(v string) string {",
+		`"github.com/gadumitrachioaiei/ccopy"`,
+		"func customizeMask(args ccopy.Args, v string) string {",
+		"// customizeTrim customizes Company.Alias, Company.Notice.",
+		"func customizeTrim(v string) string {",
+		"func customizeLowercase(v string) string {",
+	} {
+		if !strings.Contains(string(src), s) {
+			t.Fatalf("got source: %s, expected it to contain: %s", src, s)
+		}
+	}
+	for _, s := range []string{"customizeStreet", "customizeSince", "customizeSecret", `"time"`, "func customize("} {
+		if strings.Contains(string(src), s) {
+			t.Fatalf("got source: %s, expected it not to contain: %s", src, s)
+		}
+	}
+}
+
+func TestGenerateCopiers(t *testing.T) {
+	p, err := ParseDir("testdata/copiers")
+	if err != nil {
+		t.Fatal(err)
+	}
+	src, err := p.GenerateCopiers("User")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{
+		"// Code generated by ccopygen; DO NOT EDIT.",
+		`"net/mail"`,
+		"func CopyUser(cfg ccopy.Config, v User) (User, error) {",
+		"if err := ccopy.CheckGenerated[User](cfg); err != nil {",
+		`if c.f1, err = ccopy.Customizer[string](cfg, "street", "User.Home.Street"); err != nil {`,
+		"cp.Name = c.f0(v.Name)",
+		"cp.Born = v.Born",
+		"if p1, err = c.copyAddress(*v.Home); err != nil {",
+		"copy(s4, v.Tags)",
+		"s10[i11] = c.f2((*e7)[i11])",
+		"if p12, err = ccopy.CopyT(c.cfg, *v.Contact); err != nil {",
+		"cp.Scores = v.Scores",
+		"cp.Owner.Email = c.f0(v.Owner.Email)",
+		"cp.Since = v.Since",
+	} {
+		if !strings.Contains(string(src), s) {
+			t.Fatalf("got source: %s, expected it to contain: %s", src, s)
+		}
+	}
+	for _, s := range []string{"secret", `"time"`, "CopyAddress", "copySession"} {
+		if strings.Contains(string(src), s) {
+			t.Fatalf("got source: %s, expected it not to contain: %s", src, s)
+		}
+	}
+	src, err = p.GenerateCopiers("Event")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(src), "cp.Payload = v.Payload") || strings.Contains(string(src), "Debug") {
+		t.Fatalf("got source: %s, expected Payload assigned and Debug left out", src)
+	}
+	if _, err := p.GenerateCopiers(); err == nil || !strings.Contains(err.Error(), "Session.ID") {
+		t.Fatalf("got error: %v, expected error for the idmap tag of Session.ID", err)
+	}
+	if _, err := p.GenerateCopiers("Tags"); err == nil {
+		t.Fatal("expected error for a type that is not a struct type")
+	}
+	if p, err = ParseDir("testdata/annotated"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.GenerateCopiers("User"); err == nil || !strings.Contains(err.Error(), "User.Email") {
+		t.Fatalf("got error: %v, expected error for the annotation of User.Email", err)
+	}
+}
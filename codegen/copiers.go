@@ -0,0 +1,468 @@
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/token"
+	"sort"
+	"strings"
+)
+
+// GenerateCopiers returns the source of a file defining, for each of the named struct types,
+// or for every struct type of the package if no name is given, a copy function without reflection:
+//
+//	func CopyUser(cfg ccopy.Config, v User) (User, error)
+//
+// It deep copies v calling the customizers of cfg for the tagged fields, which must be of the form func(T) T.
+// They are looked up once per copy, so a copy fails with a missing customizer even if the values
+// of the tagged fields are not reached, like through nil pointers.
+// The tags are the only customizations applied: generation fails for the fields annotated with //ccopy:name,
+// and a copy fails, checked by ccopy.CheckGenerated, when the values it reaches are customized otherwise,
+// by rules, customizers registered with Config.RegisterType or Config.RegisterPath,
+// or converters, handlers and atomic types registered for the types of the package.
+// The values of types declared in other packages, but for the value types of package time,
+// and the values of interfaces are copied with ccopy.CopyT.
+// Unlike with ccopy, values reached through several pointers are copied once per pointer, and cycles of pointers are not supported.
+// Tags of the form "idmap=name" or "name,unique" need a session and are not supported either,
+// nor tags with arguments, like "mask,keep=4".
+func (p *Package) GenerateCopiers(names ...string) ([]byte, error) {
+	g := &copiers{
+		p:       p,
+		imports: p.imports(),
+		used:    map[string]bool{"ccopy": true},
+		types:   make(map[string]*ast.TypeSpec),
+		index:   make(map[string]int),
+		helpers: make(map[string]*copyHelper),
+	}
+	g.imports["ccopy"] = "github.com/gadumitrachioaiei/ccopy"
+	var order []string
+	for _, f := range p.Files {
+		for _, decl := range f.Decls {
+			gd, ok := decl.(*ast.GenDecl)
+			if !ok || gd.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gd.Specs {
+				ts := spec.(*ast.TypeSpec)
+				g.types[ts.Name.Name] = ts
+				if _, ok := ts.Type.(*ast.StructType); ok && ts.TypeParams == nil && !ts.Assign.IsValid() {
+					order = append(order, ts.Name.Name)
+				}
+			}
+		}
+	}
+	if len(names) == 0 {
+		names = order
+	}
+	for _, name := range names {
+		if g.structType(name) == nil {
+			return nil, fmt.Errorf("struct type not found: %s", name)
+		}
+		if _, err := g.helper(name); err != nil {
+			return nil, err
+		}
+	}
+
+	var body bytes.Buffer
+	body.WriteString("// ccopyCopier holds the config of a copy, and the customizers of the tagged fields.\n")
+	body.WriteString("type ccopyCopier struct {\n\tcfg ccopy.Config\n")
+	for i, cz := range g.customizers {
+		fmt.Fprintf(&body, "\tf%d func(%s) %s // %q\n", i, cz.typ, cz.typ, cz.key)
+	}
+	body.WriteString("}\n\n")
+	for _, name := range names {
+		g.root(&body, name)
+	}
+	for _, name := range g.order {
+		h := g.helpers[name]
+		fmt.Fprintf(&body, "func (c *ccopyCopier) copy%s(v %s) (%s, error) {\n\tvar cp %s\n", name, name, name, name)
+		if h.fallible {
+			body.WriteString("\tvar err error\n")
+		}
+		body.Write(h.body.Bytes())
+		body.WriteString("\treturn cp, nil\n}\n\n")
+	}
+	b := header(p.Name)
+	writeImports(b, g.used, g.imports)
+	b.Write(body.Bytes())
+	return format.Source(b.Bytes())
+}
+
+// copiers generates the copy functions of the struct types of a package.
+type copiers struct {
+	p       *Package
+	imports map[string]string
+	used    map[string]bool
+	// types are the types declared in the package, by name
+	types map[string]*ast.TypeSpec
+	// customizers are the customizers of the generated copies, whose positions are in index, by key and type
+	customizers []copyCustomizer
+	index       map[string]int
+	// helpers are the copy methods of the struct types, in order
+	helpers map[string]*copyHelper
+	order   []string
+	// expanding are the named types whose underlying types are being generated, to stop at recursive types
+	expanding map[string]bool
+}
+
+// copyCustomizer is the customizer of a tag, for values of type typ.
+type copyCustomizer struct {
+	key string
+	typ string
+}
+
+// copyHelper is the copy method of a struct type.
+type copyHelper struct {
+	name     string
+	body     bytes.Buffer
+	fallible bool
+	vars     int
+	// uses are the customizers called by the method, and deps the methods it calls, with the paths of their fields
+	uses []copyUse
+	deps []copyUse
+}
+
+// copyUse is the use of a customizer, or of the method of a struct type, by a field at path.
+type copyUse struct {
+	customizer int
+	helper     string
+	path       string
+}
+
+// structType returns the struct type declared in the package as name, if any.
+func (g *copiers) structType(name string) *ast.StructType {
+	ts := g.types[name]
+	if ts == nil || ts.TypeParams != nil || ts.Assign.IsValid() {
+		return nil
+	}
+	st, _ := ts.Type.(*ast.StructType)
+	return st
+}
+
+// helper returns the copy method of the struct type name, generating it if needed.
+func (g *copiers) helper(name string) (*copyHelper, error) {
+	if h, ok := g.helpers[name]; ok {
+		return h, nil
+	}
+	h := &copyHelper{name: name}
+	g.helpers[name] = h
+	g.order = append(g.order, name)
+	return h, g.fields(h, "cp", "v", g.structType(name), name, "")
+}
+
+// root writes the copy function of the struct type name, looking up the customizers of the fields reachable from it.
+func (g *copiers) root(b *bytes.Buffer, name string) {
+	var uses []copyUse
+	seen := map[int]bool{}
+	visited := map[string]bool{}
+	var visit func(name, path string)
+	visit = func(name, path string) {
+		visited[name] = true
+		h := g.helpers[name]
+		for _, u := range h.uses {
+			if !seen[u.customizer] {
+				seen[u.customizer] = true
+				uses = append(uses, copyUse{customizer: u.customizer, path: path + u.path})
+			}
+		}
+		for _, d := range h.deps {
+			if !visited[d.helper] {
+				visit(d.helper, path+d.path)
+			}
+		}
+	}
+	visit(name, name)
+	sort.SliceStable(uses, func(i, j int) bool { return uses[i].customizer < uses[j].customizer })
+
+	fmt.Fprintf(b, "// Copy%s deep copies v with the customizers of the tags of its fields in cfg, without reflection.\n", name)
+	fmt.Fprintf(b, "func Copy%s(cfg ccopy.Config, v %s) (%s, error) {\n\tc := &ccopyCopier{cfg: cfg}\n", name, name, name)
+	fmt.Fprintf(b, "\tif err := ccopy.CheckGenerated[%s](cfg); err != nil {\n\t\treturn %s{}, err\n\t}\n", name, name)
+	if len(uses) > 0 {
+		b.WriteString("\tvar err error\n")
+	}
+	for _, u := range uses {
+		cz := g.customizers[u.customizer]
+		fmt.Fprintf(b, "\tif c.f%d, err = ccopy.Customizer[%s](cfg, %q, %q); err != nil {\n\t\treturn %s{}, err\n\t}\n",
+			u.customizer, cz.typ, cz.key, u.path, name)
+	}
+	fmt.Fprintf(b, "\treturn c.copy%s(v)\n}\n\n", name)
+}
+
+// fields writes the copies of the exported fields of st, from the struct src to the struct dst, found at path,
+// whose paths are relative to rel in the helper h.
+func (g *copiers) fields(h *copyHelper, dst, src string, st *ast.StructType, path, rel string) error {
+	for _, field := range st.Fields.List {
+		key := tag(field)
+		dives := 0
+		for strings.HasPrefix(key, "dive,") {
+			key = key[len("dive,"):]
+			dives++
+		}
+		for _, name := range fieldNames(field) {
+			if !ast.IsExported(name) {
+				continue
+			}
+			switch {
+			case key == "-":
+				continue
+			case key == "shallow":
+				fmt.Fprintf(&h.body, "%s.%s = %s.%s\n", operand(dst), name, operand(src), name)
+				continue
+			}
+			if customizer, err := g.p.annotation(field); err != nil {
+				return err
+			} else if customizer != "" {
+				return fmt.Errorf("%s.%s is annotated with %s, which generated copies do not apply", path, name, annotationPrefix+customizer)
+			}
+			if strings.HasPrefix(key, "idmap=") || strings.HasSuffix(key, ",unique") {
+				return fmt.Errorf("the tag of %s.%s needs a session, which generated copies do not have", path, name)
+			}
+			if strings.Contains(key, ",") {
+				return fmt.Errorf("the tag of %s.%s has arguments, which generated copies do not pass", path, name)
+			}
+			err := g.value(h, operand(dst)+"."+name, operand(src)+"."+name, field.Type, path+"."+name, rel+"."+name, key, dives)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// value writes the copy of src, of type expr, to dst, customizing it with the customizer named key, if any,
+// after diving dives times into the elements of containers.
+func (g *copiers) value(h *copyHelper, dst, src string, expr ast.Expr, path, rel, key string, dives int) error {
+	if key != "" && dives == 0 {
+		typ, err := g.typ(expr, path)
+		if err != nil {
+			return err
+		}
+		i, ok := g.index[key+" "+typ]
+		if !ok {
+			i = len(g.customizers)
+			g.index[key+" "+typ] = i
+			g.customizers = append(g.customizers, copyCustomizer{key: key, typ: typ})
+		}
+		h.uses = append(h.uses, copyUse{customizer: i, path: rel})
+		fmt.Fprintf(&h.body, "%s = c.f%d(%s)\n", dst, i, src)
+		return nil
+	}
+	switch t := expr.(type) {
+	case *ast.ParenExpr:
+		return g.value(h, dst, src, t.X, path, rel, key, dives)
+	case *ast.Ident:
+		if basic[t.Name] && dives == 0 {
+			fmt.Fprintf(&h.body, "%s = %s\n", dst, src)
+			return nil
+		}
+		if t.Name == "any" || t.Name == "error" {
+			break
+		}
+		ts := g.types[t.Name]
+		if ts == nil || ts.TypeParams != nil {
+			break
+		}
+		if g.structType(t.Name) != nil {
+			if dives > 0 {
+				break
+			}
+			if _, err := g.helper(t.Name); err != nil {
+				return err
+			}
+			h.deps = append(h.deps, copyUse{helper: t.Name, path: rel})
+			h.fallible = true
+			fmt.Fprintf(&h.body, "if %s, err = c.copy%s(%s); err != nil {\n\treturn %s{}, err\n}\n", dst, t.Name, src, h.name)
+			return nil
+		}
+		// the underlying types of named types are assignable to them, but for other named types
+		if _, named := ts.Type.(*ast.Ident); named && !basic[ts.Type.(*ast.Ident).Name] || g.expanding[t.Name] {
+			break
+		}
+		if g.expanding == nil {
+			g.expanding = make(map[string]bool)
+		}
+		g.expanding[t.Name] = true
+		defer delete(g.expanding, t.Name)
+		return g.value(h, dst, src, ts.Type, path, rel, key, dives)
+	case *ast.SelectorExpr:
+		if x, ok := t.X.(*ast.Ident); ok && g.imports[x.Name] == "time" && timeValues[t.Sel.Name] && dives == 0 {
+			fmt.Fprintf(&h.body, "%s = %s\n", dst, src)
+			return nil
+		}
+	case *ast.StarExpr:
+		typ, err := g.typ(t.X, path)
+		if err != nil {
+			return err
+		}
+		v := h.newVar("p")
+		fmt.Fprintf(&h.body, "if %s != nil {\nvar %s %s\n", src, v, typ)
+		if err := g.value(h, v, "*"+operand(src), t.X, path, rel, key, dives); err != nil {
+			return err
+		}
+		fmt.Fprintf(&h.body, "%s = &%s\n}\n", dst, v)
+		return nil
+	case *ast.ArrayType:
+		if dives > 0 {
+			dives--
+		}
+		if t.Len != nil {
+			if key == "" && g.plain(t.Elt) {
+				fmt.Fprintf(&h.body, "%s = %s\n", dst, src)
+				return nil
+			}
+			i := h.newVar("i")
+			fmt.Fprintf(&h.body, "for %s := range %s {\n", i, src)
+			if err := g.value(h, operand(dst)+"["+i+"]", operand(src)+"["+i+"]", t.Elt, path, rel, key, dives); err != nil {
+				return err
+			}
+			h.body.WriteString("}\n")
+			return nil
+		}
+		typ, err := g.typ(t, path)
+		if err != nil {
+			return err
+		}
+		s := h.newVar("s")
+		fmt.Fprintf(&h.body, "if %s != nil {\n%s := make(%s, len(%s))\n", src, s, typ, src)
+		if key == "" && g.plain(t.Elt) {
+			fmt.Fprintf(&h.body, "copy(%s, %s)\n", s, src)
+		} else {
+			i := h.newVar("i")
+			fmt.Fprintf(&h.body, "for %s := range %s {\n", i, src)
+			if err := g.value(h, s+"["+i+"]", operand(src)+"["+i+"]", t.Elt, path, rel, key, dives); err != nil {
+				return err
+			}
+			h.body.WriteString("}\n")
+		}
+		fmt.Fprintf(&h.body, "%s = %s\n}\n", dst, s)
+		return nil
+	case *ast.MapType:
+		if dives > 0 {
+			dives--
+		}
+		typ, err := g.typ(t, path)
+		if err != nil {
+			return err
+		}
+		m, k, e := h.newVar("m"), h.newVar("k"), h.newVar("e")
+		fmt.Fprintf(&h.body, "if %s != nil {\n%s := make(%s, len(%s))\nfor %s, %s := range %s {\n", src, m, typ, src, k, e, src)
+		if !g.plain(t.Key) {
+			ktyp, err := g.typ(t.Key, path)
+			if err != nil {
+				return err
+			}
+			ck := h.newVar("k")
+			fmt.Fprintf(&h.body, "var %s %s\n", ck, ktyp)
+			if err := g.value(h, ck, k, t.Key, path, rel, "", 0); err != nil {
+				return err
+			}
+			k = ck
+		}
+		if key != "" || !g.plain(t.Value) {
+			vtyp, err := g.typ(t.Value, path)
+			if err != nil {
+				return err
+			}
+			ce := h.newVar("e")
+			fmt.Fprintf(&h.body, "var %s %s\n", ce, vtyp)
+			if err := g.value(h, ce, e, t.Value, path, rel, key, dives); err != nil {
+				return err
+			}
+			e = ce
+		}
+		fmt.Fprintf(&h.body, "%s[%s] = %s\n}\n%s = %s\n}\n", m, k, e, dst, m)
+		return nil
+	case *ast.StructType:
+		if dives > 0 {
+			break
+		}
+		return g.fields(h, dst, src, t, path, rel)
+	case *ast.FuncType, *ast.ChanType:
+		if dives == 0 {
+			fmt.Fprintf(&h.body, "%s = %s\n", dst, src)
+			return nil
+		}
+	case *ast.InterfaceType:
+		// nil interfaces are not copied by ccopy.CopyT
+		if dives == 0 {
+			h.fallible = true
+			fmt.Fprintf(&h.body, "if %s != nil {\nif %s, err = ccopy.CopyT(c.cfg, %s); err != nil {\n\treturn %s{}, err\n}\n}\n", src, dst, src, h.name)
+			return nil
+		}
+	}
+	if dives > 0 {
+		return fmt.Errorf("cannot dive into the type of %s", path)
+	}
+	if _, err := g.typ(expr, path); err != nil {
+		return err
+	}
+	h.fallible = true
+	if id, ok := expr.(*ast.Ident); ok && (id.Name == "any" || id.Name == "error") {
+		fmt.Fprintf(&h.body, "if %s != nil {\nif %s, err = ccopy.CopyT(c.cfg, %s); err != nil {\n\treturn %s{}, err\n}\n}\n", src, dst, src, h.name)
+		return nil
+	}
+	fmt.Fprintf(&h.body, "if %s, err = ccopy.CopyT(c.cfg, %s); err != nil {\n\treturn %s{}, err\n}\n", dst, src, h.name)
+	return nil
+}
+
+// plain reports whether the values of type expr are copied by assignment.
+func (g *copiers) plain(expr ast.Expr) bool {
+	switch t := expr.(type) {
+	case *ast.ParenExpr:
+		return g.plain(t.X)
+	case *ast.Ident:
+		if basic[t.Name] {
+			return true
+		}
+		ts := g.types[t.Name]
+		if ts == nil || ts.TypeParams != nil || g.expanding[t.Name] {
+			return false
+		}
+		id, ok := ts.Type.(*ast.Ident)
+		return ok && basic[id.Name]
+	case *ast.SelectorExpr:
+		x, ok := t.X.(*ast.Ident)
+		return ok && g.imports[x.Name] == "time" && timeValues[t.Sel.Name]
+	}
+	return false
+}
+
+// typ returns the source of the type expr, found at path, marking the packages qualifying it as used.
+func (g *copiers) typ(expr ast.Expr, path string) (string, error) {
+	var b bytes.Buffer
+	if err := format.Node(&b, g.p.Fset, expr); err != nil {
+		return "", err
+	}
+	f := builderField{typ: b.String(), path: path}
+	if err := useImports(g.used, g.imports, f); err != nil {
+		return "", err
+	}
+	return f.typ, nil
+}
+
+// newVar returns the name of a new variable of the method, starting with prefix.
+func (h *copyHelper) newVar(prefix string) string {
+	h.vars++
+	return fmt.Sprintf("%s%d", prefix, h.vars)
+}
+
+// operand returns the expression x as an operand of selectors and index expressions.
+func operand(x string) string {
+	if strings.HasPrefix(x, "*") {
+		return "(" + x + ")"
+	}
+	return x
+}
+
+// basic are the predeclared types copied by assignment.
+var basic = map[string]bool{
+	"bool": true, "string": true, "byte": true, "rune": true,
+	"int": true, "int8": true, "int16": true, "int32": true, "int64": true,
+	"uint": true, "uint8": true, "uint16": true, "uint32": true, "uint64": true,
+	"float32": true, "float64": true, "complex64": true, "complex128": true,
+}
+
+// timeValues are the types of package time copied by assignment, as ccopy does.
+var timeValues = map[string]bool{"Time": true, "Duration": true, "Month": true, "Weekday": true}
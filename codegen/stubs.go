@@ -0,0 +1,194 @@
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/token"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ConfigKeys returns the string keys of the ccopy.Config literals of the package, sorted by appearance.
+func (p *Package) ConfigKeys() []string {
+	var keys []string
+	seen := make(map[string]bool)
+	for _, f := range p.Files {
+		name := ccopyImport(f)
+		if name == "" {
+			continue
+		}
+		ast.Inspect(f, func(n ast.Node) bool {
+			lit, ok := n.(*ast.CompositeLit)
+			if !ok {
+				return true
+			}
+			sel, ok := lit.Type.(*ast.SelectorExpr)
+			if !ok || sel.Sel.Name != "Config" {
+				return true
+			}
+			if x, ok := sel.X.(*ast.Ident); !ok || x.Name != name {
+				return true
+			}
+			for _, elt := range lit.Elts {
+				kv, ok := elt.(*ast.KeyValueExpr)
+				if !ok {
+					continue
+				}
+				if key, ok := kv.Key.(*ast.BasicLit); ok && key.Kind == token.STRING {
+					if k, err := strconv.Unquote(key.Value); err == nil && !seen[k] {
+						seen[k] = true
+						keys = append(keys, k)
+					}
+				}
+			}
+			return true
+		})
+	}
+	return keys
+}
+
+// ccopyImport returns the name of the ccopy package in file f, empty if f does not import it.
+func ccopyImport(f *ast.File) string {
+	for _, spec := range f.Imports {
+		if strings.Trim(spec.Path.Value, `"`) != "github.com/gadumitrachioaiei/ccopy" {
+			continue
+		}
+		if spec.Name != nil {
+			return spec.Name.Name
+		}
+		return "ccopy"
+	}
+	return ""
+}
+
+// GenerateStubs returns the source of a file defining a stub customizer for each tag of the fields
+// of the struct types of the package that is neither a key of the ccopy.Config literals of the package nor in known.
+// A stub returns its argument, of the type of the first field tagged with its tag, and is meant to be edited:
+//
+//	// customizeName customizes User.Name.
+//	func customizeName(v string) string
+//
+// The fields tagged alike whose types differ are listed in the comment of the stub, as they need tags of their own.
+func (p *Package) GenerateStubs(known ...string) ([]byte, error) {
+	isKnown := make(map[string]bool)
+	for _, k := range append(p.ConfigKeys(), known...) {
+		isKnown[k] = true
+	}
+	var fields []builderField
+	var err error
+	p.Structs(func(name string, st *ast.StructType) {
+		if err == nil {
+			err = p.taggedFields(&fields, name, st)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	var keys []string
+	byKey := make(map[string][]builderField)
+	for _, f := range fields {
+		if isKnown[f.key] {
+			continue
+		}
+		if byKey[f.key] == nil {
+			keys = append(keys, f.key)
+		}
+		byKey[f.key] = append(byKey[f.key], f)
+	}
+
+	imports := p.imports()
+	used := make(map[string]bool)
+	var body bytes.Buffer
+	for _, key := range keys {
+		first := byKey[key][0]
+		if err := useImports(used, imports, first); err != nil {
+			return nil, err
+		}
+		var paths, others []string
+		for _, f := range byKey[key] {
+			if f.typ == first.typ {
+				paths = append(paths, f.path)
+				// the stub takes the arguments of any of the tags
+				first.args = first.args || f.args
+			} else {
+				others = append(others, f.path+" ("+f.typ+")")
+			}
+		}
+		fmt.Fprintf(&body, "// %s customizes %s.\n", first.method, strings.Join(paths, ", "))
+		if len(others) > 0 {
+			fmt.Fprintf(&body, "// The fields %s are tagged %q as well, but their types differ: they need tags of their own.\n", strings.Join(others, ", "), key)
+		}
+		if first.args {
+			imports["ccopy"], used["ccopy"] = "github.com/gadumitrachioaiei/ccopy", true
+		}
+		fmt.Fprintf(&body, "func %s(%sv %s) %s {\n\t// TODO: customize v.\n\treturn v\n}\n\n", first.method, argsParam(first, "args"), first.typ, first.typ)
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "package %s\n\n", p.Name)
+	writeImports(&b, used, imports)
+	if len(keys) > 0 {
+		b.WriteString("// The stub customizers of the tags missing from the config, to add to it:\n//\n//\tccopy.Config{\n")
+		for _, key := range keys {
+			fmt.Fprintf(&b, "//\t\t%q: %s,\n", key, byKey[key][0].method)
+		}
+		b.WriteString("//\t}\n\n")
+	}
+	b.Write(body.Bytes())
+	return format.Source(b.Bytes())
+}
+
+// taggedFields appends the exported tagged fields of st, found at path, looking into the struct literal types of fields,
+// with the method set to the name of the stub of their tag.
+func (p *Package) taggedFields(fields *[]builderField, path string, st *ast.StructType) error {
+	for _, field := range st.Fields.List {
+		for _, name := range fieldNames(field) {
+			if !ast.IsExported(name) || directive(field) {
+				continue
+			}
+			links := tagLinks(field)
+			if len(links) == 0 {
+				if inner, ok := field.Type.(*ast.StructType); ok {
+					if err := p.taggedFields(fields, path+"."+name, inner); err != nil {
+						return err
+					}
+				}
+				continue
+			}
+			typ, err := p.customizedType(field, path+"."+name)
+			if err != nil {
+				return err
+			}
+			for _, l := range links {
+				*fields = append(*fields, builderField{method: stubName(l.key), key: l.key, typ: typ, path: path + "." + name, args: l.args})
+			}
+		}
+	}
+	return nil
+}
+
+// stubName returns the name of the stub customizer of tag key, like customizeMaskEmail for mask-email.
+func stubName(key string) string {
+	return "customize" + exportedName(key)
+}
+
+// exportedName returns tag key as an exported identifier, like MaskEmail for mask-email.
+func exportedName(key string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range key {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
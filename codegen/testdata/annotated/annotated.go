@@ -0,0 +1,18 @@
+package annotated
+
+type User struct {
+	// Email is where we write to.
+	//ccopy:maskEmail
+	Email string
+	Name  string //ccopy:redact
+	Age   int
+	Home  struct {
+		//ccopy:redact
+		Street, City string
+	}
+}
+
+type Order struct {
+	User
+	Total int
+}
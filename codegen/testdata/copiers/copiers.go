@@ -0,0 +1,42 @@
+package copiers
+
+import (
+	"net/mail"
+	"time"
+)
+
+type User struct {
+	Name     string `ccopy:"redact"`
+	Age      int
+	Born     time.Time
+	Home     *Address
+	Work     []Address
+	Tags     Tags
+	Phones   map[string]*[]string `ccopy:"dive,dive,phone"`
+	Contact  *mail.Address
+	Extra    interface{}
+	Scores   [2]float64
+	Children []*User
+	Owner    struct {
+		Email string `ccopy:"redact"`
+	}
+	secret string
+}
+
+type Address struct {
+	Street string `ccopy:"street"`
+	Since  Since
+}
+
+type Tags []string
+
+type Since time.Duration
+
+type Session struct {
+	ID int `ccopy:"idmap=id"`
+}
+
+type Event struct {
+	Payload []byte `ccopy:"shallow"`
+	Debug   *Event `ccopy:"-"`
+}
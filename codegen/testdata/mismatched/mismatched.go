@@ -0,0 +1,6 @@
+package mismatched
+
+type User struct {
+	Name  string `ccopy:"redact"`
+	Score int    `ccopy:"redact"`
+}
@@ -0,0 +1,7 @@
+package tagged
+
+import "github.com/gadumitrachioaiei/ccopy"
+
+var config = ccopy.Config{
+	"street": func(s string) string { return "" },
+}
@@ -0,0 +1,42 @@
+package tagged
+
+import (
+	"time"
+
+	pii "net/mail"
+)
+
+type User struct {
+	Name    string `ccopy:"anonymiseName"`
+	ID      int    `ccopy:"idmap=user"`
+	Born    time.Time
+	Home    *Address
+	Work    []Address
+	Contact *pii.Address `ccopy:"contact"`
+	secret  string       `ccopy:"secret"`
+}
+
+type Address struct {
+	Street string    `ccopy:"street"`
+	Since  time.Time `ccopy:"since"`
+}
+
+type Plain struct {
+	A int
+}
+
+type Company struct {
+	Name   int                  `ccopy:"anonymiseName"`
+	Phones map[string]*[]string `ccopy:"dive,dive,phone"`
+	Owner  struct {
+		Email string `ccopy:"mask-email"`
+	}
+	IBAN   string `ccopy:"mask,keep=4"`
+	Alias  string `ccopy:"trim,lowercase"`
+	Notice string `ccopy:"trim,mask,keep=2"`
+}
+
+type Event struct {
+	Payload []byte   `ccopy:"shallow"`
+	Debug   *Address `ccopy:"-"`
+}
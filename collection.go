@@ -0,0 +1,195 @@
+package ccopy
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// CollectionAdapter copies the values of a collection type, like a ring buffer or a fixed-size cache,
+// whose elements are laid out in a way the copies of its kind do not know, like an array and the index of its head.
+// The copies of the values of a registered collection type get its elements in logical order from Elements,
+// copy them like the elements of a slice, with their paths, and build the copy of the value from them with Build.
+type CollectionAdapter interface {
+	// Check returns an error if the adapter cannot copy the values of type t.
+	Check(t reflect.Type) error
+	// Elements returns the elements held by v, in logical order, like from the oldest to the newest.
+	// v is addressable, so its unexported fields can be read.
+	Elements(v reflect.Value) []reflect.Value
+	// Build returns the copy of v holding elems, the copies of its elements, in the order of Elements.
+	Build(v reflect.Value, elems []reflect.Value) reflect.Value
+}
+
+// collections holds the adapters registered with RegisterCollection, by type.
+var collections = struct {
+	sync.RWMutex
+	m map[reflect.Type]CollectionAdapter
+}{m: make(map[reflect.Type]CollectionAdapter)}
+
+// RegisterCollection registers the type of v as a collection copied by the adapter a, like RingBuffer.
+// The elements of the collection are reached by the paths of rules like the elements of a slice,
+// by their index in logical order: "Metrics.Samples[]" matches the elements of the Samples field, of a collection type.
+// Collections must be registered before the first copy of their values by a Copier.
+// It panics if v is nil or a cannot copy the values of its type.
+func RegisterCollection(v interface{}, a CollectionAdapter) {
+	t := reflect.TypeOf(v)
+	if t == nil {
+		panic("ccopy: register collection nil")
+	}
+	if err := a.Check(t); err != nil {
+		panic(fmt.Sprintf("ccopy: register collection %s: %v", t, err))
+	}
+	collections.Lock()
+	defer collections.Unlock()
+	collections.m[t] = a
+}
+
+func collectionOf(t reflect.Type) CollectionAdapter {
+	collections.RLock()
+	defer collections.RUnlock()
+	return collections.m[t]
+}
+
+// copyCollection copies ov, a value of a collection type, with its adapter a.
+func (c *state) copyCollection(a CollectionAdapter, ov reflect.Value, active []match) (reflect.Value, error) {
+	if !ov.CanAddr() {
+		addressable := reflect.New(ov.Type()).Elem()
+		addressable.Set(ov)
+		ov = addressable
+	}
+	elems := a.Elements(ov)
+	if err := c.limit(len(elems)); err != nil {
+		return reflect.Zero(ov.Type()), err
+	}
+	active = c.plans.advance(active, &indexStep)
+	copies := make([]reflect.Value, len(elems))
+	for i, e := range elems {
+		c.push(pathElem{kind: segIndex, index: i})
+		v, err := c.copy(e, active)
+		c.pop()
+		if err != nil {
+			if !c.tolerate(err) {
+				return reflect.Zero(ov.Type()), err
+			}
+			v = reflect.Zero(e.Type())
+		}
+		copies[i] = v
+	}
+	return a.Build(ov, copies), nil
+}
+
+// RingBuffer is the adapter of the ring buffers: structs holding their elements in an array or a slice,
+// from the index of the head, the oldest element, wrapping around at the end, and the number of elements.
+// The fields can be unexported. The copies hold the elements in the same slots as the original, or from the first one,
+// when Compact is set, with a head of 0, and the slots not holding elements are zero, so stale elements are not copied.
+// The other fields of the struct are copied by assignment, like counters.
+type RingBuffer struct {
+	// Buffer is the name of the field of the array or slice of the elements.
+	Buffer string
+	// Head is the name of the field of the index of the oldest element, of an integer type.
+	Head string
+	// Len is the name of the field of the number of elements, of an integer type.
+	Len string
+	// Compact moves the elements of the copies to the first slots, in logical order.
+	Compact bool
+}
+
+// Check implements CollectionAdapter.
+func (r RingBuffer) Check(t reflect.Type) error {
+	if t.Kind() != reflect.Struct {
+		return fmt.Errorf("not a struct")
+	}
+	if f, ok := t.FieldByName(r.Buffer); !ok || len(f.Index) != 1 || f.Type.Kind() != reflect.Array && f.Type.Kind() != reflect.Slice {
+		return fmt.Errorf("no array or slice field %s", r.Buffer)
+	}
+	for _, name := range []string{r.Head, r.Len} {
+		if f, ok := t.FieldByName(name); !ok || len(f.Index) != 1 || !isInt(f.Type.Kind()) {
+			return fmt.Errorf("no integer field %s", name)
+		}
+	}
+	return nil
+}
+
+func isInt(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+// namedField returns the field name of the addressable struct v, which can be read and set even if it is unexported.
+func namedField(v reflect.Value, name string) reflect.Value {
+	f, _ := v.Type().FieldByName(name)
+	return exposed(v, f.Index[0])
+}
+
+func intOf(v reflect.Value) int {
+	if v.CanInt() {
+		return int(v.Int())
+	}
+	return int(v.Uint())
+}
+
+func setInt(v reflect.Value, n int) {
+	if v.CanInt() {
+		v.SetInt(int64(n))
+	} else {
+		v.SetUint(uint64(n))
+	}
+}
+
+// Elements implements CollectionAdapter.
+// The head and the number of elements are taken modulo the length of the buffer, as corrupted buffers cannot be trusted.
+func (r RingBuffer) Elements(v reflect.Value) []reflect.Value {
+	buf := namedField(v, r.Buffer)
+	size := buf.Len()
+	if size == 0 {
+		return nil
+	}
+	head, n := intOf(namedField(v, r.Head))%size, intOf(namedField(v, r.Len))
+	if head < 0 {
+		head += size
+	}
+	if n < 0 || n > size {
+		n = size
+	}
+	elems := make([]reflect.Value, n)
+	for i := range elems {
+		elems[i] = buf.Index((head + i) % size)
+	}
+	return elems
+}
+
+// Build implements CollectionAdapter.
+func (r RingBuffer) Build(v reflect.Value, elems []reflect.Value) reflect.Value {
+	oc := reflect.New(v.Type()).Elem()
+	oc.Set(v)
+	buf := namedField(oc, r.Buffer)
+	size := buf.Len()
+	var copied reflect.Value
+	if buf.Kind() == reflect.Slice {
+		if buf.IsNil() {
+			return oc
+		}
+		copied = reflect.MakeSlice(buf.Type(), size, size)
+	} else {
+		copied = reflect.New(buf.Type()).Elem()
+	}
+	head := 0
+	if !r.Compact && size > 0 {
+		if head = intOf(namedField(v, r.Head)) % size; head < 0 {
+			head += size
+		}
+	}
+	for i, e := range elems {
+		if !e.IsZero() {
+			copied.Index((head + i) % size).Set(e)
+		}
+	}
+	buf.Set(copied)
+	setInt(namedField(oc, r.Head), head)
+	setInt(namedField(oc, r.Len), len(elems))
+	return oc
+}
@@ -0,0 +1,70 @@
+package ccopy
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type sampleRing struct {
+	buf   [4]string
+	head  int
+	n     uint8
+	Total int
+}
+
+type compactRing struct {
+	Samples []*int
+	Head    int
+	Len     int
+}
+
+func TestCopyCollections(t *testing.T) {
+	RegisterCollection(sampleRing{}, RingBuffer{Buffer: "buf", Head: "head", Len: "n"})
+	RegisterCollection(compactRing{}, RingBuffer{Buffer: "Samples", Head: "Head", Len: "Len", Compact: true})
+	type Metrics struct {
+		Latencies sampleRing
+		Counts    *compactRing
+	}
+	one, two, stale := 1, 2, 3
+	m := Metrics{
+		// the slot 1 holds a stale element
+		Latencies: sampleRing{buf: [4]string{"c", "stale", "a", "b"}, head: 2, n: 3, Total: 7},
+		Counts:    &compactRing{Samples: []*int{&two, &stale, &one}, Head: 2, Len: 2},
+	}
+	c, err := NewCopier(Config{"latency": func(s string) string { return s + "!" }}, Options{Rules: Rules{"Metrics.Latencies[]": "latency"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := c.Copy(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	copied := v.(Metrics)
+	if diff := cmp.Diff(sampleRing{buf: [4]string{"c!", "", "a!", "b!"}, head: 2, n: 3, Total: 7}, copied.Latencies, cmp.AllowUnexported(sampleRing{})); diff != "" {
+		t.Fatal(diff)
+	}
+	counts := copied.Counts
+	if counts.Head != 0 || counts.Len != 2 || len(counts.Samples) != 3 || *counts.Samples[0] != 1 || *counts.Samples[1] != 2 || counts.Samples[2] != nil {
+		t.Fatalf("unexpected compacted copy: %+v", counts)
+	}
+	if counts.Samples[0] == &one {
+		t.Fatal("expected the elements to be deep copied")
+	}
+
+	a, err := c.ResolveAction(reflect.TypeOf(m), "Metrics.Latencies")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Kind != ActionCollection {
+		t.Fatalf("got action: %v, expected collection", a.Kind)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic for a type the adapter cannot copy")
+		}
+	}()
+	RegisterCollection(Metrics{}, RingBuffer{Buffer: "Latencies", Head: "Counts", Len: "Counts"})
+}
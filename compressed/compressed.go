@@ -0,0 +1,119 @@
+// Package compressed provides ccopy customizer factories for compressed blobs, like event payloads
+// stored gzip or zstd compressed: the blob is decompressed, customized by an inner customizer,
+// and compressed again with the same algorithm.
+//
+// A blob that cannot be decompressed, or whose content is larger than MaxSize, is left out of the copy,
+// so that data that was not customized never leaks into the copy, and decompression bombs cannot exhaust the memory.
+package compressed
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// MaxSize is the size of the largest decompressed content customized, in bytes.
+const MaxSize = 64 << 20
+
+var errTooLarge = errors.New("compressed: content larger than MaxSize")
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// Gzip returns a customizer applying inner to the decompressed content of gzip compressed blobs.
+func Gzip(inner func([]byte) []byte) func([]byte) []byte {
+	return func(b []byte) []byte {
+		if b == nil {
+			return nil
+		}
+		out, err := gunzipApply(b, inner)
+		if err != nil {
+			return nil
+		}
+		return out
+	}
+}
+
+func gunzipApply(b []byte, inner func([]byte) []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	content, err := io.ReadAll(io.LimitReader(r, MaxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(content) > MaxSize {
+		return nil, errTooLarge
+	}
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	// keep the name and times of the original header
+	w.Header = r.Header
+	if _, err := w.Write(inner(content)); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// zstd encoders and decoders are expensive to create, and safe for concurrent use of EncodeAll and DecodeAll.
+var codecs struct {
+	once    sync.Once
+	encoder *zstd.Encoder
+	decoder *zstd.Decoder
+	err     error
+}
+
+func zstdCodecs() (*zstd.Encoder, *zstd.Decoder, error) {
+	codecs.once.Do(func() {
+		if codecs.encoder, codecs.err = zstd.NewWriter(nil); codecs.err != nil {
+			return
+		}
+		codecs.decoder, codecs.err = zstd.NewReader(nil, zstd.WithDecoderMaxMemory(MaxSize))
+	})
+	return codecs.encoder, codecs.decoder, codecs.err
+}
+
+// Zstd returns a customizer applying inner to the decompressed content of zstd compressed blobs.
+func Zstd(inner func([]byte) []byte) func([]byte) []byte {
+	return func(b []byte) []byte {
+		if b == nil {
+			return nil
+		}
+		enc, dec, err := zstdCodecs()
+		if err != nil {
+			return nil
+		}
+		content, err := dec.DecodeAll(b, nil)
+		if err != nil {
+			return nil
+		}
+		return enc.EncodeAll(inner(content), nil)
+	}
+}
+
+// Auto returns a customizer applying inner to blobs that are gzip or zstd compressed,
+// recognized by their magic numbers, like Gzip and Zstd do, and to other blobs as they are.
+func Auto(inner func([]byte) []byte) func([]byte) []byte {
+	gz, zs := Gzip(inner), Zstd(inner)
+	return func(b []byte) []byte {
+		switch {
+		case bytes.HasPrefix(b, gzipMagic):
+			return gz(b)
+		case bytes.HasPrefix(b, zstdMagic):
+			return zs(b)
+		case b == nil:
+			return nil
+		}
+		return inner(b)
+	}
+}
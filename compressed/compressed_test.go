@@ -0,0 +1,114 @@
+package compressed
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/gadumitrachioaiei/ccopy"
+	"github.com/klauspost/compress/zstd"
+)
+
+func redact(b []byte) []byte {
+	return bytes.ReplaceAll(b, []byte("secret"), []byte("******"))
+}
+
+func gzipped(t *testing.T, s string) []byte {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	w.Name = "event.json"
+	w.Write([]byte(s))
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func gunzipped(t *testing.T, b []byte) (string, string) {
+	r, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(content), r.Name
+}
+
+func zstded(t *testing.T, s string) []byte {
+	enc, err := zstd.NewWriter(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return enc.EncodeAll([]byte(s), nil)
+}
+
+func unzstded(t *testing.T, b []byte) string {
+	dec, err := zstd.NewReader(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := dec.DecodeAll(b, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(content)
+}
+
+func TestGzip(t *testing.T) {
+	content, name := gunzipped(t, Gzip(redact)(gzipped(t, `{"token":"secret"}`)))
+	if content != `{"token":"******"}` || name != "event.json" {
+		t.Fatalf("got %q named %q, expected the redacted content", content, name)
+	}
+	if b := Gzip(redact)([]byte("secret")); b != nil {
+		t.Fatalf("got %q, expected invalid data to be left out", b)
+	}
+}
+
+func TestZstd(t *testing.T) {
+	if content := unzstded(t, Zstd(redact)(zstded(t, `{"token":"secret"}`))); content != `{"token":"******"}` {
+		t.Fatalf("got %q, expected the redacted content", content)
+	}
+	if b := Zstd(redact)([]byte("secret")); b != nil {
+		t.Fatalf("got %q, expected invalid data to be left out", b)
+	}
+}
+
+func TestAuto(t *testing.T) {
+	type Event struct {
+		Gzip  []byte `ccopy:"payload"`
+		Zstd  []byte `ccopy:"payload"`
+		Plain []byte `ccopy:"payload"`
+	}
+	res, err := ccopy.Config{"payload": Auto(redact)}.Copy(Event{
+		Gzip:  gzipped(t, "a secret"),
+		Zstd:  zstded(t, "another secret"),
+		Plain: []byte("no secret"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := res.(Event)
+	if content, _ := gunzipped(t, e.Gzip); content != "a ******" {
+		t.Fatalf("got %q, expected the redacted gzip content", content)
+	}
+	if content := unzstded(t, e.Zstd); content != "another ******" {
+		t.Fatalf("got %q, expected the redacted zstd content", content)
+	}
+	if string(e.Plain) != "no ******" {
+		t.Fatalf("got %q, expected the redacted content", e.Plain)
+	}
+}
+
+func TestMaxSize(t *testing.T) {
+	bomb := strings.Repeat("0", MaxSize+1)
+	if b := Gzip(redact)(gzipped(t, bomb)); b != nil {
+		t.Fatalf("got %d bytes, expected content larger than MaxSize to be left out", len(b))
+	}
+	if b := Zstd(redact)(zstded(t, bomb)); b != nil {
+		t.Fatalf("got %d bytes, expected content larger than MaxSize to be left out", len(b))
+	}
+}
@@ -0,0 +1,8 @@
+module github.com/gadumitrachioaiei/ccopy/compressed
+
+go 1.22.0
+
+require (
+	github.com/gadumitrachioaiei/ccopy v0.0.0-20261015025656-45a8b2362908
+	github.com/klauspost/compress v1.17.11
+)
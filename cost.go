@@ -0,0 +1,118 @@
+package ccopy
+
+import "reflect"
+
+// Cost is the worst case cost of copying the values of a type, estimated from the type alone.
+// The values held by interfaces are unknown, so they count as one indirection and one level of depth.
+// The values customized as a whole, like tagged fields and the values of types with registered handlers, cost nothing.
+type Cost struct {
+	// Depth is the deepest nesting of values, counting every struct, pointer, slice, array, map and interface.
+	Depth int
+	// Indirections counts the pointers, slices, maps and interfaces of the type and of the types it holds,
+	// which are as many allocations and pointers to follow for every value.
+	Indirections int
+	// Maps counts the map types of the type and of the types it holds, which are copied entry by entry.
+	Maps int
+	// Recursive reports whether the type holds itself, so its values have no bounded depth.
+	Recursive bool
+}
+
+// CostLimits are the thresholds of the costs of struct types beyond which copies report WarningCostly warnings,
+// so that heavyweight subtrees are found and left out of the copies, or registered as atomic.
+// Zero limits are no limits.
+type CostLimits struct {
+	Depth        int
+	Indirections int
+	Maps         int
+	// Recursive reports the recursive types.
+	Recursive bool
+}
+
+func (l CostLimits) set() bool {
+	return l != CostLimits{}
+}
+
+// exceeded reports whether c exceeds the limits.
+func (l CostLimits) exceeded(c Cost) bool {
+	return l.Depth > 0 && c.Depth > l.Depth ||
+		l.Indirections > 0 && c.Indirections > l.Indirections ||
+		l.Maps > 0 && c.Maps > l.Maps ||
+		l.Recursive && c.Recursive
+}
+
+// EstimateCost returns the estimated worst case cost of copying the values of type t.
+func EstimateCost(t reflect.Type) Cost {
+	e := &estimator{seen: make(map[reflect.Type]bool), costs: make(map[reflect.Type]Cost)}
+	return e.cost(t)
+}
+
+type estimator struct {
+	// seen are the types being estimated, to detect recursion
+	seen map[reflect.Type]bool
+	// costs are the costs of the types estimated, that are not recursive
+	costs map[reflect.Type]Cost
+}
+
+func (e *estimator) cost(t reflect.Type) Cost {
+	if c, ok := e.costs[t]; ok {
+		return c
+	}
+	if _, ok := typeHandler(t); ok {
+		return Cost{}
+	}
+	if e.seen[t] {
+		return Cost{Recursive: true}
+	}
+	e.seen[t] = true
+	defer delete(e.seen, t)
+	var c Cost
+	switch t.Kind() {
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if f.PkgPath != "" || f.Tag.Get(tagCcopy) != "" {
+				continue
+			}
+			c = c.add(e.cost(f.Type))
+		}
+		c.Depth++
+	case reflect.Ptr:
+		c = e.cost(t.Elem()).indirect()
+	case reflect.Slice:
+		if isBlob(t) {
+			c = Cost{Depth: 1, Indirections: 1}
+			break
+		}
+		c = e.cost(t.Elem()).indirect()
+	case reflect.Array:
+		c = e.cost(t.Elem())
+		c.Depth++
+	case reflect.Map:
+		c = e.cost(t.Key()).add(e.cost(t.Elem())).indirect()
+		c.Maps++
+	case reflect.Interface:
+		c = Cost{}.indirect()
+	}
+	if !c.Recursive {
+		e.costs[t] = c
+	}
+	return c
+}
+
+// add returns the cost of a value holding values of costs c and o.
+func (c Cost) add(o Cost) Cost {
+	if o.Depth > c.Depth {
+		c.Depth = o.Depth
+	}
+	c.Indirections += o.Indirections
+	c.Maps += o.Maps
+	c.Recursive = c.Recursive || o.Recursive
+	return c
+}
+
+// indirect returns the cost of a value pointing to a value of cost c.
+func (c Cost) indirect() Cost {
+	c.Depth++
+	c.Indirections++
+	return c
+}
@@ -0,0 +1,63 @@
+package ccopy
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type costNode struct {
+	Value    int
+	Children []*costNode
+}
+
+type costOrder struct {
+	ID      int
+	Created time.Time
+	Lines   []struct {
+		SKU   string
+		Attrs map[string]string
+	}
+	Meta   map[string]interface{}
+	Secret *string `ccopy:"redact"`
+}
+
+func TestEstimateCost(t *testing.T) {
+	for _, test := range []struct {
+		v        interface{}
+		expected Cost
+	}{
+		{0, Cost{}},
+		{costOrder{}, Cost{Depth: 4, Indirections: 4, Maps: 2}},
+		{costNode{}, Cost{Depth: 3, Indirections: 2, Recursive: true}},
+		{[]byte(nil), Cost{Depth: 1, Indirections: 1}},
+	} {
+		if c := EstimateCost(reflect.TypeOf(test.v)); c != test.expected {
+			t.Fatalf("%T: expected: %+v, got: %+v", test.v, test.expected, c)
+		}
+	}
+}
+
+func TestCostLimits(t *testing.T) {
+	var warnings []Warning
+	c, err := NewCopier(Config{"redact": func(s *string) *string { return nil }}, Options{
+		CostLimits: CostLimits{Maps: 1, Recursive: true},
+		OnWarning:  func(w Warning) { warnings = append(warnings, w) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Copy(costOrder{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Copy(&costNode{Children: []*costNode{{}}}); err != nil {
+		t.Fatal(err)
+	}
+	expected := []Warning{
+		{Kind: WarningCostly, Path: "costOrder", Type: reflect.TypeOf(costOrder{})},
+		{Kind: WarningCostly, Path: "costNode", Type: reflect.TypeOf(costNode{})},
+	}
+	if !reflect.DeepEqual(warnings, expected) {
+		t.Fatalf("expected: %v, got: %v", expected, warnings)
+	}
+}
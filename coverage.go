@@ -0,0 +1,282 @@
+package ccopy
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// suspectNames are words of field names that suggest personal data.
+var suspectNames = []string{
+	"email", "mail", "phone", "mobile", "name", "username", "firstname", "lastname", "surname", "fullname",
+	"address", "street", "city", "cities", "zip", "zipcode", "postal", "postcode", "ip",
+	"ssn", "passport", "birth", "birthday", "birthdate", "dob", "card", "iban", "account", "password", "token", "secret",
+}
+
+// suspectName matches the suspect names as words of a field name, in camel case, upper case or snake case,
+// followed by a plural or an IP version, like Email, HomeAddress, ZIPCode, client_ip or AllowedIPs,
+// but not as a part of other words, like Capacity, Hostname, Discard, Unzip, Tokenizer or Recipient.
+var suspectName = regexp.MustCompile(suspectPattern(suspectNames))
+
+// suspectPattern returns the pattern of suspectName, matching names as words: starting the name,
+// or following a character that is not a letter, in any case, or following a lower case letter or a digit,
+// capitalized or in upper case, and followed by the end of the name or a character that is not a lower case letter.
+func suspectPattern(names []string) string {
+	var all, upper []string
+	for _, n := range names {
+		upper = append(upper, strings.ToUpper(n[:1])+n[1:], strings.ToUpper(n))
+		all = append(all, n)
+	}
+	all = append(all, upper...)
+	const suffix = `(?:s|es|v4|v6)?(?:$|[^a-z])`
+	return `(?:^|[^A-Za-z])(?:` + strings.Join(all, "|") + `)` + suffix + `|[a-z0-9](?:` + strings.Join(upper, "|") + `)` + suffix
+}
+
+// SuspectField reports whether the name of a field suggests it holds personal data, like Email, HomeAddress or ClientIP.
+func SuspectField(name string) bool {
+	return suspectName.MatchString(name)
+}
+
+// FieldCoverage describes whether a field is customized.
+type FieldCoverage struct {
+	// Type is the struct type that has the field.
+	Type reflect.Type
+	// Field is the name of the field.
+	Field string
+	// Path is the path of the field, from the type whose coverage was requested.
+	Path string
+	// Customizer is the name of the customizer of the field, empty if the field is copied as it is.
+	Customizer string
+	// Suspect reports whether the field name suggests it holds personal data.
+	Suspect bool
+}
+
+// Covered reports whether the field is customized.
+func (f FieldCoverage) Covered() bool {
+	return f.Customizer != ""
+}
+
+// Coverage reports which of the fields that may hold personal data are customized:
+// the fields holding strings and the fields whose name is suspect.
+type Coverage struct {
+	Fields []FieldCoverage
+
+	// edges between struct types, for the graph
+	edges map[[2]reflect.Type]bool
+}
+
+// PackageCoverage aggregates the coverage of the fields of the struct types of a package.
+type PackageCoverage struct {
+	Package        string
+	Fields         int
+	Covered        int
+	Suspect        int
+	SuspectCovered int
+}
+
+// Percent returns the percentage of covered fields.
+func (p PackageCoverage) Percent() float64 {
+	if p.Fields == 0 {
+		return 100
+	}
+	return 100 * float64(p.Covered) / float64(p.Fields)
+}
+
+// Coverage returns the coverage of the fields of the given types, and of the types reachable from them.
+// Fields under an interface are not known without values, so they are not part of the coverage.
+func (c *Copier) Coverage(types ...interface{}) *Coverage {
+	cv := &Coverage{edges: make(map[[2]reflect.Type]bool)}
+	w := &coverageWalker{Copier: c.live(), cv: cv, seen: make(map[reflect.Type]bool)}
+	for _, t := range types {
+		rt := reflect.TypeOf(t)
+		w.walk(rt, typePath(rt), nil, nil, "", 0)
+	}
+	return cv
+}
+
+// maxCoverageDepth limits the walk of recursive types that are matched by rules.
+const maxCoverageDepth = 32
+
+type coverageWalker struct {
+	*Copier
+	cv   *Coverage
+	seen map[reflect.Type]bool
+}
+
+// walk walks type t found at path, in the field of struct type parent.
+func (w *coverageWalker) walk(t reflect.Type, path string, active []match, parent reflect.Type, field string, depth int) {
+	if depth > maxCoverageDepth {
+		return
+	}
+	active = w.plans.reach(active, t)
+	if name, ok := matched(active); ok {
+		w.record(t, path, parent, field, name)
+		return
+	}
+	if name, ok := w.plans.typeCustomizers()[t]; ok {
+		w.record(t, path, parent, field, name)
+		return
+	}
+	if fn, ok := typeHandler(t); ok {
+		customizer := ""
+		if isRawHandler(fn) {
+			customizer = "handler"
+		}
+		w.record(t, path, parent, field, customizer)
+		return
+	}
+	active = w.anchor(active, t)
+	switch t.Kind() {
+	case reflect.Struct:
+		if parent != nil {
+			w.cv.edges[[2]reflect.Type{parent, t}] = true
+		}
+		if len(active) == 0 {
+			if w.seen[t] {
+				return
+			}
+			w.seen[t] = true
+		}
+		fields := w.plans.structPlan(t).fields
+		for i := range fields {
+			f := &fields[i]
+			ft := t.Field(f.index)
+			fpath := path + "." + ft.Name
+			// the fields copied by assignment are not customized
+			if f.tag == tagShallow {
+				w.record(ft.Type, fpath, t, ft.Name, "")
+				continue
+			}
+			if f.tag != "" {
+				w.record(ft.Type, fpath, t, ft.Name, f.tag)
+				continue
+			}
+			w.walk(ft.Type, fpath, w.plans.advance(active, &f.step), t, ft.Name, depth+1)
+		}
+	case reflect.Ptr:
+		w.walk(t.Elem(), path, active, parent, field, depth+1)
+	case reflect.Slice, reflect.Array:
+		w.walk(t.Elem(), path+"[]", w.plans.advance(active, &indexStep), parent, field, depth+1)
+	case reflect.Map:
+		w.walk(t.Elem(), path+"{}", w.plans.advance(active, &keyStep), parent, field, depth+1)
+	case reflect.Interface, reflect.Func, reflect.Chan:
+	default:
+		w.record(t, path, parent, field, "")
+	}
+}
+
+// record records the coverage of a field of type t, if it holds strings or its name is suspect.
+func (w *coverageWalker) record(t reflect.Type, path string, parent reflect.Type, field, customizer string) {
+	if parent == nil {
+		return
+	}
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+		t = t.Elem()
+	}
+	s := SuspectField(field)
+	if t.Kind() == reflect.String || s {
+		w.cv.Fields = append(w.cv.Fields, FieldCoverage{Type: parent, Field: field, Path: path, Customizer: customizer, Suspect: s})
+	}
+}
+
+// typePath returns the name a path starts with, for values of type t.
+func typePath(t reflect.Type) string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Name() != "" {
+		return t.Name()
+	}
+	return t.String()
+}
+
+// Packages returns the coverage aggregated per package, sorted by package path.
+func (cv *Coverage) Packages() []PackageCoverage {
+	m := make(map[string]*PackageCoverage)
+	for _, f := range cv.Fields {
+		p := m[f.Type.PkgPath()]
+		if p == nil {
+			p = &PackageCoverage{Package: f.Type.PkgPath()}
+			m[p.Package] = p
+		}
+		p.Fields++
+		if f.Covered() {
+			p.Covered++
+		}
+		if f.Suspect {
+			p.Suspect++
+			if f.Covered() {
+				p.SuspectCovered++
+			}
+		}
+	}
+	packages := make([]PackageCoverage, 0, len(m))
+	for _, p := range m {
+		packages = append(packages, *p)
+	}
+	sort.Slice(packages, func(i, j int) bool { return packages[i].Package < packages[j].Package })
+	return packages
+}
+
+// WriteDOT writes the coverage as a graph in the DOT language, with a node per struct type and
+// an edge from every struct type to the struct types of its fields.
+// Types having uncovered suspect fields are red, types having uncovered fields are orange,
+// and fully covered types are green.
+func (cv *Coverage) WriteDOT(w io.Writer) error {
+	type node struct {
+		fields, covered int
+		suspect         bool
+	}
+	nodes := make(map[reflect.Type]*node)
+	var types []reflect.Type
+	add := func(t reflect.Type) *node {
+		n := nodes[t]
+		if n == nil {
+			n = &node{}
+			nodes[t] = n
+			types = append(types, t)
+		}
+		return n
+	}
+	for _, f := range cv.Fields {
+		n := add(f.Type)
+		n.fields++
+		if f.Covered() {
+			n.covered++
+		} else if f.Suspect {
+			n.suspect = true
+		}
+	}
+	var edges [][2]string
+	for e := range cv.edges {
+		add(e[0])
+		add(e[1])
+		edges = append(edges, [2]string{e[0].String(), e[1].String()})
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i].String() < types[j].String() })
+	sort.Slice(edges, func(i, j int) bool {
+		return edges[i][0] < edges[j][0] || edges[i][0] == edges[j][0] && edges[i][1] < edges[j][1]
+	})
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "digraph coverage {")
+	for _, t := range types {
+		n := nodes[t]
+		color := "green"
+		if n.suspect {
+			color = "red"
+		} else if n.covered < n.fields {
+			color = "orange"
+		}
+		fmt.Fprintf(bw, "\t%q [label=\"%s\\n%d/%d\" color=%s];\n", t.String(), t.String(), n.covered, n.fields, color)
+	}
+	for _, e := range edges {
+		fmt.Fprintf(bw, "\t%q -> %q;\n", e[0], e[1])
+	}
+	fmt.Fprintln(bw, "}")
+	return bw.Flush()
+}
@@ -0,0 +1,117 @@
+package ccopy
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type Profile struct {
+	Nick    string `ccopy:"redact"`
+	Bio     string
+	Phone   int
+	Born    time.Time
+	Account *Account
+}
+
+type Account struct {
+	Email string
+	Plan  string
+	Next  *Account
+}
+
+func TestCoverage(t *testing.T) {
+	c, err := NewCopier(Config{}, Options{Rules: Rules{"Profile.Account.Email": "redact"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cv := c.Coverage(Profile{})
+	var paths []string
+	for _, f := range cv.Fields {
+		paths = append(paths, f.Path+"="+f.Customizer)
+	}
+	expected := []string{
+		"Profile.Nick=redact",
+		"Profile.Bio=",
+		"Profile.Phone=",
+		"Profile.Account.Email=redact",
+		"Profile.Account.Plan=",
+		"Profile.Account.Next.Email=",
+		"Profile.Account.Next.Plan=",
+	}
+	if diff := cmp.Diff(paths, expected); diff != "" {
+		t.Fatal(diff)
+	}
+	packages := cv.Packages()
+	if len(packages) != 1 {
+		t.Fatalf("got packages: %v, expected one", packages)
+	}
+	p := packages[0]
+	if p.Fields != 7 || p.Covered != 2 || p.Suspect != 3 || p.SuspectCovered != 1 {
+		t.Fatalf("got package coverage: %+v", p)
+	}
+	var b bytes.Buffer
+	if err := cv.WriteDOT(&b); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{`"ccopy.Profile" [label="ccopy.Profile\n1/3" color=red];`, `"ccopy.Profile" -> "ccopy.Account";`, `"ccopy.Account" -> "ccopy.Account";`} {
+		if !strings.Contains(b.String(), s) {
+			t.Fatalf("got graph: %s, expected it to contain: %s", b.String(), s)
+		}
+	}
+
+	// the fields of the types customized by the config are covered
+	type Secret string
+	type Vault struct {
+		Key Secret
+	}
+	config := Config{}
+	config.RegisterType(func(Secret) Secret { return "" })
+	if c, err = NewCopier(config, Options{}); err != nil {
+		t.Fatal(err)
+	}
+	if f := c.Coverage(Vault{}).Fields; len(f) != 1 || !f[0].Covered() || f[0].Customizer != "type:github.com/gadumitrachioaiei/ccopy.Secret" {
+		t.Fatalf("got fields: %+v, expected Vault.Key covered by its type", f)
+	}
+}
+
+func TestSuspectField(t *testing.T) {
+	for name, expected := range map[string]bool{
+		"Email":        true,
+		"HomeAddress":  true,
+		"IP":           true,
+		"ClientIP":     true,
+		"ClientIp":     true,
+		"IPAddress":    true,
+		"RemoteIPv4":   true,
+		"AllowedIPs":   true,
+		"client_ip":    true,
+		"Description":  false,
+		"Recipient":    false,
+		"Shipping":     false,
+		"Relationship": false,
+		"Tip":          false,
+		"VIPLevel":     false,
+		"ZIPCode":      true,
+		"zip_code":     true,
+		"DateOfBirth":  true,
+		"Passwords":    true,
+		"HostName":     true,
+		"Capacity":     false,
+		"Velocity":     false,
+		"Opacity":      false,
+		"Hostname":     false,
+		"Filename":     false,
+		"Discard":      false,
+		"Unzip":        false,
+		"Tokenizer":    false,
+		"Mailbox":      false,
+	} {
+		if got := SuspectField(name); got != expected {
+			t.Errorf("got: %v for %s, expected: %v", got, name, expected)
+		}
+	}
+}
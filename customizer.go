@@ -0,0 +1,285 @@
+package ccopy
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// signature is the shape of a customizer function.
+type signature int
+
+const (
+	// sigPlain is func(T) T.
+	sigPlain signature = iota
+	// sigScratch is func(*Scratch, T) T.
+	sigScratch
+	// sigRand is func(*rand.Rand, T) T.
+	sigRand
+	// sigContext is func(context.Context, T) T.
+	sigContext
+	// sigArgs is func(Args, T) T.
+	sigArgs
+)
+
+var (
+	scratchType = reflect.TypeOf((*Scratch)(nil))
+	randType    = reflect.TypeOf((*rand.Rand)(nil))
+	contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+	argsType    = reflect.TypeOf(Args(""))
+)
+
+// signatureOf returns the signature of fn, if it is a function that can customize values of type t.
+func signatureOf(fn reflect.Type, t reflect.Type) (signature, bool) {
+	if fn.Kind() != reflect.Func || fn.IsVariadic() || fn.NumOut() < 1 || fn.NumOut() > 2 || !fn.Out(0).AssignableTo(t) ||
+		fn.NumOut() == 2 && fn.Out(1) != errorType {
+		return 0, false
+	}
+	switch {
+	case fn.NumIn() == 1 && t.AssignableTo(fn.In(0)):
+		return sigPlain, true
+	case fn.NumIn() == 2 && fn.In(0) == scratchType && t.AssignableTo(fn.In(1)):
+		return sigScratch, true
+	case fn.NumIn() == 2 && fn.In(0) == randType && t.AssignableTo(fn.In(1)):
+		return sigRand, true
+	case fn.NumIn() == 2 && fn.In(0) == contextType && t.AssignableTo(fn.In(1)):
+		return sigContext, true
+	case fn.NumIn() == 2 && fn.In(0) == argsType && t.AssignableTo(fn.In(1)):
+		return sigArgs, true
+	}
+	return 0, false
+}
+
+// Customize customizes v with the customizer named name in the config of the Copier, within session s,
+// like a field of v tagged with name: name can be of the form "idmap=name" or "name,unique" as well.
+// It is meant for the values that are not copied, but shown on their own, like in templates.
+// A nil session is the same as a new session.
+func (c *Copier) Customize(s *Session, name string, v interface{}) (interface{}, error) {
+	ov := reflect.ValueOf(v)
+	if !ov.IsValid() {
+		return nil, c.message(ErrInvalidValue)
+	}
+	if s == nil {
+		s = NewSession()
+	}
+	st := &state{Copier: c.live(), session: s, onWarning: c.onWarning, root: ov.Type()}
+	out, err := st.customize(name, ov)
+	if err != nil {
+		return nil, c.message(err)
+	}
+	return out.Interface(), nil
+}
+
+// Customizer returns the customizer of the config c named tag, of the values of type T found at path,
+// for the copies generated by ccopygen, which call it without reflection.
+// Only customizers of the form func(T) T can be called that way.
+func Customizer[T any](c Config, tag, path string) (func(T) T, error) {
+	fn := c[tag]
+	if fn == nil {
+		return nil, &ErrMissingCustomizer{Tag: tag, Path: path}
+	}
+	customizer, ok := fn.(func(T) T)
+	if !ok {
+		return nil, &ErrBadCustomizerSignature{Tag: tag, Path: path, Customizer: reflect.TypeOf(fn), Type: reflect.TypeOf((*T)(nil)).Elem()}
+	}
+	return customizer, nil
+}
+
+// CheckGenerated returns an error if the copies of the config c customize the values reachable from a T
+// otherwise than with the tags of the fields of the package of T, for the copies generated by ccopygen,
+// which only call the customizers of the tags: if a rule, registered with RegisterRules or Config.RegisterPath,
+// matches one of the values, a customizer registered with Config.RegisterType customizes one of their types,
+// or a type of the package of T is registered with RegisterConverter, RegisterHandler or RegisterAtomic.
+func CheckGenerated[T any](c Config) error {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	w := &generatedChecker{Copier: c.copier(), pkg: t.PkgPath(), seen: make(map[reflect.Type]bool)}
+	return w.check(t, typePath(t), nil, 0)
+}
+
+type generatedChecker struct {
+	*Copier
+	// pkg is the path of the package whose types are copied by the generated copies
+	pkg  string
+	seen map[reflect.Type]bool
+}
+
+// check checks the values of type t found at path, active being the matching rules.
+func (c *generatedChecker) check(t reflect.Type, path string, active []match, depth int) error {
+	if depth > maxCoverageDepth {
+		return nil
+	}
+	active = c.plans.reach(active, t)
+	if name, ok := matched(active); ok {
+		return fmt.Errorf("ccopy: %s is customized by the rule %q, which generated copies do not apply", path, name)
+	}
+	if name, ok := c.plans.typeCustomizers()[t]; ok {
+		return fmt.Errorf("ccopy: %s is customized by %q, which generated copies do not apply", path, name)
+	}
+	// the values of other packages and of interfaces are copied by CopyT, starting a new match of the rules
+	if t.Name() != "" && t.PkgPath() != "" && t.PkgPath() != c.pkg || t.Kind() == reflect.Interface {
+		if len(active) > 0 {
+			return fmt.Errorf("ccopy: %s is reached by rules, which generated copies do not apply", path)
+		}
+		return nil
+	}
+	if _, ok := typeHandler(t); ok {
+		return fmt.Errorf("ccopy: the type of %s, %s, has a registered copy, which generated copies do not call", path, t)
+	}
+	active = c.anchor(active, t)
+	switch t.Kind() {
+	case reflect.Struct:
+		if len(active) == 0 {
+			if c.seen[t] {
+				return nil
+			}
+			c.seen[t] = true
+		}
+		sp := c.plans.structPlan(t)
+		for i := range sp.fields {
+			f := &sp.fields[i]
+			// tags win over rules, and customize the values they hold
+			if f.tag != "" {
+				continue
+			}
+			if err := c.check(t.Field(f.index).Type, path+"."+f.step.name, c.plans.advance(active, &f.step), depth+1); err != nil {
+				return err
+			}
+		}
+	case reflect.Ptr:
+		return c.check(t.Elem(), path, active, depth+1)
+	case reflect.Slice, reflect.Array:
+		return c.check(t.Elem(), path+"[]", c.plans.advance(active, &indexStep), depth+1)
+	case reflect.Map:
+		if err := c.check(t.Key(), path+"{}", nil, depth+1); err != nil {
+			return err
+		}
+		return c.check(t.Elem(), path+"{}", c.plans.advance(active, &keyStep), depth+1)
+	}
+	return nil
+}
+
+// Args are the arguments of a tag, following the name of its customizer and a comma, like "keep=4" in "mask,keep=4",
+// passed to the customizers taking them as first argument: func(Args, T) T.
+// So a single customizer can serve many fields, with different parameters.
+// They are a comma separated list of options, of the form "key=value", or "key" for options without values.
+type Args string
+
+// Lookup returns the value of the option key, and whether it is set.
+func (a Args) Lookup(key string) (string, bool) {
+	for _, opt := range strings.Split(string(a), ",") {
+		if k, v, _ := strings.Cut(opt, "="); k == key {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// Get returns the value of the option key, empty if it is not set.
+func (a Args) Get(key string) string {
+	v, _ := a.Lookup(key)
+	return v
+}
+
+// Int returns the value of the option key as an int, or def if it is not set.
+// It returns an error if the value is not an int.
+func (a Args) Int(key string, def int) (int, error) {
+	v, ok := a.Lookup(key)
+	if !ok {
+		return def, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return def, fmt.Errorf("invalid argument %s: %w", key, err)
+	}
+	return n, nil
+}
+
+// splitTag splits a tag into the name of its customizer and its arguments, like "mask" and "keep=4" for "mask,keep=4".
+func splitTag(tag string) (string, Args) {
+	name, args, _ := strings.Cut(tag, ",")
+	return name, Args(args)
+}
+
+// call calls the customizer fn, named name, of signature sig, with ov and the arguments args.
+// It returns an *ErrCustomizer error if fn returns an error.
+func (c *state) call(name string, args Args, fn reflect.Value, sig signature, ov reflect.Value) (reflect.Value, error) {
+	if args != "" && sig != sigArgs {
+		return reflect.Zero(ov.Type()), &ErrBadCustomizerSignature{Tag: name + "," + string(args), Path: c.pathString(), Customizer: fn.Type(), Type: ov.Type()}
+	}
+	var out []reflect.Value
+	switch sig {
+	case sigScratch:
+		if c.scratch == nil {
+			c.scratch = &Scratch{}
+		}
+		out = fn.Call([]reflect.Value{reflect.ValueOf(c.scratch), ov})
+	case sigRand:
+		out = fn.Call([]reflect.Value{reflect.ValueOf(c.rand(name)), ov})
+	case sigContext:
+		ctx := c.ctx
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		out = fn.Call([]reflect.Value{reflect.ValueOf(ctx), ov})
+	case sigArgs:
+		out = fn.Call([]reflect.Value{reflect.ValueOf(args), ov})
+	default:
+		out = fn.Call([]reflect.Value{ov})
+	}
+	if len(out) == 2 {
+		if err, _ := out[1].Interface().(error); err != nil {
+			return reflect.Zero(ov.Type()), &ErrCustomizer{Tag: name, Path: c.pathString(), Err: err}
+		}
+	}
+	v := out[0]
+	if v.Kind() == reflect.String {
+		c.account(v.Type(), v.Len())
+	}
+	return v, nil
+}
+
+// Scratch is a store private to a single copy, for customizers to share data within the copy,
+// like counting the values they redacted or remembering a key derived once per object.
+// Customizers get the scratch of the copy by taking it as first argument: func(*Scratch, T) T.
+// It is not safe for concurrent use.
+type Scratch struct {
+	m map[interface{}]interface{}
+}
+
+// Get returns the value stored under key, if any.
+func (s *Scratch) Get(key interface{}) (interface{}, bool) {
+	v, ok := s.m[key]
+	return v, ok
+}
+
+// Set stores value under key.
+func (s *Scratch) Set(key, value interface{}) {
+	if s.m == nil {
+		s.m = make(map[interface{}]interface{})
+	}
+	s.m[key] = value
+}
+
+// Add adds n to the int stored under key, which is 0 if nothing is stored under key, and returns the sum.
+func (s *Scratch) Add(key interface{}, n int) int {
+	v, _ := s.m[key].(int)
+	v += n
+	s.Set(key, v)
+	return v
+}
+
+// Append appends value to the slice stored under key and returns the slice.
+func (s *Scratch) Append(key interface{}, value interface{}) []interface{} {
+	v, _ := s.m[key].([]interface{})
+	v = append(v, value)
+	s.Set(key, v)
+	return v
+}
+
+// Len returns the number of keys stored.
+func (s *Scratch) Len() int {
+	return len(s.m)
+}
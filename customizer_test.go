@@ -0,0 +1,333 @@
+package ccopy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCopyScratch(t *testing.T) {
+	type T struct {
+		Email  string   `ccopy:"redact"`
+		Phones []string `ccopy:"redactAll"`
+	}
+	redact := func(s *Scratch, v string) string {
+		s.Add("redacted", 1)
+		s.Append("removed", v)
+		return ""
+	}
+	c, err := NewCopier(Config{
+		"redact": redact,
+		"redactAll": func(s *Scratch, v []string) []string {
+			for _, p := range v {
+				redact(s, p)
+			}
+			return nil
+		},
+	}, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	vi, s, err := c.CopyScratch(T{Email: "a@b.c", Phones: []string{"1", "2"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(vi, T{}); diff != "" {
+		t.Fatal(diff)
+	}
+	if n, _ := s.Get("redacted"); n != 3 {
+		t.Fatalf("got redacted: %v, expected 3", n)
+	}
+	if removed, _ := s.Get("removed"); !cmp.Equal(removed, []interface{}{"a@b.c", "1", "2"}) {
+		t.Fatalf("got removed: %v", removed)
+	}
+	// the scratch is private to every copy
+	_, s, err = c.CopyScratch(T{Email: "a@b.c"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := s.Get("redacted"); n != 1 {
+		t.Fatalf("got redacted: %v, expected 1", n)
+	}
+}
+
+func TestCustomize(t *testing.T) {
+	n := 0
+	c, err := NewCopier(Config{"user": func(string) string { n++; return fmt.Sprintf("user%d", n) }}, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewSession()
+	var got []interface{}
+	for _, v := range []string{"ann", "bob", "ann"} {
+		u, err := c.Customize(s, "idmap=user", v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, u)
+	}
+	if diff := cmp.Diff([]interface{}{"user1", "user2", "user1"}, got); diff != "" {
+		t.Fatal(diff)
+	}
+	var missing *ErrMissingCustomizer
+	if _, err := c.Customize(nil, "other", "ann"); !errors.As(err, &missing) || missing.Path != "string" {
+		t.Fatalf("got error: %v, expected *ErrMissingCustomizer", err)
+	}
+	if _, err := c.Customize(nil, "user", nil); err != ErrInvalidValue {
+		t.Fatalf("got error: %v, expected: %v", err, ErrInvalidValue)
+	}
+}
+
+func TestCopyRandomness(t *testing.T) {
+	type T struct {
+		Fixture int64 `ccopy:"fixture"`
+		Secret  int64 `ccopy:"secret"`
+	}
+	random := func(r *rand.Rand, _ int64) int64 { return r.Int63() }
+	config := Config{"fixture": random, "secret": random}
+	c, err := NewCopier(config, Options{Randomness: map[string]Randomness{"secret": RandCrypto}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := c.CopySession(NewSeededSession(1), T{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := c.CopySession(NewSeededSession(1), T{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.(T).Fixture != b.(T).Fixture || a.(T).Fixture != NewSeededSession(1).Rand().Int63() {
+		t.Fatalf("got fixtures: %d and %d, expected the first output of the seeded session", a.(T).Fixture, b.(T).Fixture)
+	}
+	if a.(T).Secret == b.(T).Secret {
+		t.Fatalf("got the same secrets: %d, expected crypto random ones", a.(T).Secret)
+	}
+
+	for _, r := range []map[string]Randomness{{"other": RandCrypto}, {"secret": Randomness(5)}} {
+		if _, err := NewCopier(config, Options{Randomness: r}); err == nil {
+			t.Fatalf("expected error for randomness: %v", r)
+		}
+	}
+}
+
+func TestCopyFallibleCustomizer(t *testing.T) {
+	type Card struct {
+		Number string   `ccopy:"tokenize"`
+		Notes  []string `ccopy:"dive,tokenize"`
+	}
+	errUnavailable := errors.New("tokenization service unavailable")
+	tokenize := func(s string) (string, error) {
+		if s == "fail" {
+			return "", errUnavailable
+		}
+		return "tok-" + s, nil
+	}
+	c := Config{"tokenize": tokenize}
+	v, err := CopyT(c, Card{Number: "4111", Notes: []string{"a"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(Card{Number: "tok-4111", Notes: []string{"tok-a"}}, v); diff != "" {
+		t.Fatal(diff)
+	}
+	_, err = CopyT(c, Card{Number: "4111", Notes: []string{"a", "fail"}})
+	var failed *ErrCustomizer
+	if !errors.As(err, &failed) || failed.Tag != "tokenize" || failed.Path != "Card.Notes[1]" || !errors.Is(err, errUnavailable) {
+		t.Fatalf("got error: %v, expected *ErrCustomizer wrapping the error of the customizer", err)
+	}
+	if m := Describe(err); m.Key != MessageCustomizer || m.Field != "Notes" {
+		t.Fatalf("got message: %+v, expected a customizer message on Notes", m)
+	}
+
+	scratch := Config{"tokenize": func(s *Scratch, v string) (string, error) {
+		s.Add("calls", 1)
+		return v, nil
+	}}
+	cp, _ := NewCopier(scratch, Options{})
+	if _, sc, err := cp.CopyScratch(Card{Number: "1", Notes: []string{"2"}}); err != nil || sc.Add("calls", 0) != 2 {
+		t.Fatalf("got error: %v, expected the scratch customizer returning an error to be called twice", err)
+	}
+
+	bad := Config{"tokenize": func(s string) (string, string) { return s, "" }}
+	var signature *ErrBadCustomizerSignature
+	if _, err := CopyT(bad, Card{Number: "1"}); !errors.As(err, &signature) {
+		t.Fatalf("got error: %v, expected *ErrBadCustomizerSignature for a second result that is not an error", err)
+	}
+}
+
+func TestCopyCtx(t *testing.T) {
+	type tenantKey struct{}
+	type Record struct {
+		Email string `ccopy:"tokenize"`
+		Name  string `ccopy:"redact"`
+	}
+	c := Config{
+		"tokenize": func(ctx context.Context, s string) (string, error) {
+			tenant, ok := ctx.Value(tenantKey{}).(string)
+			if !ok {
+				return "", errors.New("no tenant")
+			}
+			return tenant + ":" + s, nil
+		},
+		"redact": func(string) string { return "" },
+	}
+	ctx := context.WithValue(context.Background(), tenantKey{}, "acme")
+	v, err := c.CopyCtx(ctx, Record{Email: "a@b.c", Name: "name"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(Record{Email: "acme:a@b.c"}, v); diff != "" {
+		t.Fatal(diff)
+	}
+	// copies without a context call the customizers with context.Background()
+	var failed *ErrCustomizer
+	if _, err := c.Copy(Record{Email: "a@b.c"}); !errors.As(err, &failed) || failed.Path != "Record.Email" {
+		t.Fatalf("got error: %v, expected *ErrCustomizer for the missing tenant", err)
+	}
+}
+
+func TestCustomizer(t *testing.T) {
+	c := Config{"name": func(s string) string { return "x" + s }, "age": func(int) string { return "" }}
+	fn, err := Customizer[string](c, "name", "User.Name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := fn("a"); v != "xa" {
+		t.Fatalf("got: %s, expected: xa", v)
+	}
+	var missing *ErrMissingCustomizer
+	if _, err := Customizer[string](c, "email", "User.Email"); !errors.As(err, &missing) || missing.Path != "User.Email" {
+		t.Fatalf("got error: %v, expected *ErrMissingCustomizer", err)
+	}
+	var bad *ErrBadCustomizerSignature
+	if _, err := Customizer[int](c, "age", "User.Age"); !errors.As(err, &bad) || bad.Type != reflect.TypeOf(0) {
+		t.Fatalf("got error: %v, expected *ErrBadCustomizerSignature", err)
+	}
+}
+
+func TestCheckGenerated(t *testing.T) {
+	type CGAmount struct {
+		Cents int
+	}
+	type CGAddress struct {
+		Street string
+	}
+	type CGUser struct {
+		Name    string `ccopy:"redact"`
+		Home    *CGAddress
+		Total   CGAmount
+		Created time.Time
+	}
+	RegisterConverter(func(a CGAmount) CGAmount { return a })
+	redact := func(string) string { return "" }
+	if err := CheckGenerated[CGUser](Config{"redact": redact}); err == nil || !strings.Contains(err.Error(), "CGUser.Total") {
+		t.Fatalf("got error: %v, expected error for the converter of CGAmount", err)
+	}
+	type CGOrder struct {
+		User  CGUser `ccopy:"shallow"`
+		Notes []string
+		Home  CGAddress
+	}
+	if err := CheckGenerated[CGOrder](Config{}); err != nil {
+		t.Fatal(err)
+	}
+	c := Config{"redact": redact}
+	c.RegisterPath("CGOrder.Home.Street", redact)
+	if err := CheckGenerated[CGOrder](c); err == nil || !strings.Contains(err.Error(), "CGOrder.Home.Street") {
+		t.Fatalf("got error: %v, expected error for the path customizer", err)
+	}
+	c = Config{}
+	c.RegisterType(redact)
+	if err := CheckGenerated[CGOrder](c); err == nil || !strings.Contains(err.Error(), "CGOrder.Notes[]") {
+		t.Fatalf("got error: %v, expected error for the type customizer", err)
+	}
+}
+
+func TestCopyTagArgs(t *testing.T) {
+	type Account struct {
+		Card   string   `ccopy:"mask,keep=4"`
+		IBAN   string   `ccopy:"mask,keep=2,char=#"`
+		Phone  string   `ccopy:"mask"`
+		Emails []string `ccopy:"dive,mask,keep=1"`
+	}
+	mask := func(args Args, s string) (string, error) {
+		keep, err := args.Int("keep", 0)
+		if err != nil {
+			return "", err
+		}
+		char := "*"
+		if c, ok := args.Lookup("char"); ok {
+			char = c
+		}
+		if keep > len(s) {
+			keep = len(s)
+		}
+		return strings.Repeat(char, len(s)-keep) + s[len(s)-keep:], nil
+	}
+	c := Config{"mask": mask}
+	v, err := c.Copy(Account{Card: "4111111111111111", IBAN: "RO49AAAA", Phone: "0712", Emails: []string{"ab"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := Account{Card: "************1111", IBAN: "######AA", Phone: "****", Emails: []string{"*b"}}
+	if diff := cmp.Diff(expected, v); diff != "" {
+		t.Fatal(diff)
+	}
+
+	var failed *ErrCustomizer
+	if _, err := c.Copy(struct {
+		Card string `ccopy:"mask,keep=four"`
+	}{Card: "4111"}); !errors.As(err, &failed) || failed.Tag != "mask" {
+		t.Fatalf("got error: %v, expected *ErrCustomizer for the invalid argument", err)
+	}
+	// customizers not taking arguments cannot be given any
+	var bad *ErrBadCustomizerSignature
+	c = Config{"mask": func(s string) string { return "" }}
+	if _, err := c.Copy(Account{}); !errors.As(err, &bad) || bad.Tag != "mask,keep=4" || bad.Path != "Account.Card" {
+		t.Fatalf("got error: %v, expected *ErrBadCustomizerSignature", err)
+	}
+}
+
+func TestCopyChainedCustomizers(t *testing.T) {
+	type Contact struct {
+		Email string   `ccopy:"trim,lower,hash"`
+		Code  string   `ccopy:"mask,keep=2,lower"`
+		Tags  []string `ccopy:"dive,trim,lower"`
+	}
+	var calls []string
+	c := Config{
+		"trim":  func(s string) string { calls = append(calls, "trim"); return strings.TrimSpace(s) },
+		"lower": func(s string) string { calls = append(calls, "lower"); return strings.ToLower(s) },
+		"hash":  func(s string) string { calls = append(calls, "hash"); return fmt.Sprintf("h(%s)", s) },
+		"mask": func(args Args, s string) (string, error) {
+			calls = append(calls, "mask")
+			keep, err := args.Int("keep", 0)
+			return strings.Repeat("*", len(s)-keep) + s[len(s)-keep:], err
+		},
+	}
+	v, err := c.Copy(Contact{Email: " Ann@B.C ", Code: "ABCD", Tags: []string{" X "}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(Contact{Email: "h(ann@b.c)", Code: "**cd", Tags: []string{"x"}}, v); diff != "" {
+		t.Fatal(diff)
+	}
+	if diff := cmp.Diff([]string{"trim", "lower", "hash", "mask", "lower", "trim", "lower"}, calls); diff != "" {
+		t.Fatal(diff)
+	}
+
+	var missing *ErrMissingCustomizer
+	if _, err := c.Copy(struct {
+		Email string `ccopy:"strip,lower"`
+	}{}); !errors.As(err, &missing) || missing.Tag != "strip" {
+		t.Fatalf("got error: %v, expected *ErrMissingCustomizer for the first customizer", err)
+	}
+}
@@ -0,0 +1,57 @@
+package ccopy
+
+import "reflect"
+
+// divePrefix starts the names customizing the elements of containers, one level down per prefix:
+// a field of type [][]string tagged with "dive,dive,name" has each of its strings customized with name.
+const divePrefix = "dive,"
+
+// customizeElems customizes the elements of the slice or array ov, or the values of the map ov, with name.
+// Pointers to containers are followed, and the keys of maps are copied, like the keys of the maps that are not customized.
+func (c *state) customizeElems(name string, ov reflect.Value) (reflect.Value, error) {
+	switch ov.Kind() {
+	case reflect.Ptr:
+		if ov.IsNil() {
+			return ov, nil
+		}
+		v, err := c.customizeElems(name, ov.Elem())
+		if err != nil {
+			return reflect.Zero(ov.Type()), err
+		}
+		oc := reflect.New(ov.Type().Elem())
+		oc.Elem().Set(v)
+		return oc, nil
+	case reflect.Slice, reflect.Array:
+		var oc reflect.Value
+		if ov.Kind() == reflect.Slice {
+			if ov.IsNil() {
+				return ov, nil
+			}
+			oc = reflect.MakeSlice(ov.Type(), ov.Len(), ov.Len())
+			c.account(ov.Type(), ov.Len()*int(ov.Type().Elem().Size()))
+			if err := c.limit(ov.Len()); err != nil {
+				return reflect.Zero(ov.Type()), err
+			}
+		} else {
+			oc = reflect.New(ov.Type()).Elem()
+		}
+		for i := 0; i < ov.Len(); i++ {
+			c.push(pathElem{kind: segIndex, index: i})
+			v, err := c.customize(name, ov.Index(i))
+			c.pop()
+			if err != nil {
+				if !c.tolerate(err) {
+					return reflect.Zero(ov.Type()), err
+				}
+				continue
+			}
+			oc.Index(i).Set(v)
+		}
+		return oc, nil
+	case reflect.Map:
+		return c.copyEntries(ov, func(value reflect.Value) (reflect.Value, error) {
+			return c.customize(name, value)
+		})
+	}
+	return reflect.Zero(ov.Type()), &ErrCannotDive{Tag: name, Path: c.pathString(), Type: ov.Type()}
+}
@@ -0,0 +1,117 @@
+package ccopy
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDive(t *testing.T) {
+	type Contact struct {
+		Emails  [2]string            `ccopy:"dive,email"`
+		Phones  *[]string            `ccopy:"dive,phone"`
+		Aliases map[string][]string  `ccopy:"dive,dive,email"`
+		IDs     []int                `ccopy:"dive,idmap=id"`
+		Matrix  [2][2]string         `ccopy:"dive,dive,email"`
+		Nested  map[int]*[1]struct{} `ccopy:"dive,dive,empty"`
+	}
+	config := Config{
+		"email": func(s string) string { return strings.ToUpper(s) },
+		"phone": func(s string) string { return "***" },
+		"id":    func(id int) int { return id * 10 },
+		"empty": func(v struct{}) struct{} { return v },
+	}
+	phones := []string{"123", "456"}
+	obj := Contact{
+		Emails:  [2]string{"a@x", "b@x"},
+		Phones:  &phones,
+		Aliases: map[string][]string{"work": {"w@x"}},
+		IDs:     []int{1, 2, 1},
+		Matrix:  [2][2]string{{"a", "b"}, {"c", "d"}},
+		Nested:  map[int]*[1]struct{}{1: {}},
+	}
+	v, err := config.Copy(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := Contact{
+		Emails:  [2]string{"A@X", "B@X"},
+		Phones:  &[]string{"***", "***"},
+		Aliases: map[string][]string{"work": {"W@X"}},
+		IDs:     []int{10, 20, 10},
+		Matrix:  [2][2]string{{"A", "B"}, {"C", "D"}},
+		Nested:  map[int]*[1]struct{}{1: {}},
+	}
+	if !reflect.DeepEqual(v, expected) {
+		t.Fatalf("expected: %+v, got: %+v", expected, v)
+	}
+	if phones[0] != "123" {
+		t.Fatal("expected the original to be unchanged")
+	}
+
+	_, err = Config{"email": config["email"]}.Copy(struct {
+		Email string `ccopy:"dive,email"`
+	}{Email: "a"})
+	if err == nil || !strings.Contains(err.Error(), "cannot dive into values of type string for: email") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, err = config.Copy(struct {
+		Emails []string `ccopy:"dive,missing"`
+	}{Emails: []string{"a"}})
+	var missing *ErrMissingCustomizer
+	if !errors.As(err, &missing) || !strings.HasSuffix(missing.Path, ".Emails[0]") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestArrays(t *testing.T) {
+	type Cell struct {
+		Label string `ccopy:"label"`
+		Value float64
+	}
+	type Sheet struct {
+		Cells  [2][2]Cell
+		Pixels [3][4]uint8
+		Rows   [][3]int
+		Names  [2][2]string
+	}
+	config := Config{"label": func(s string) string { return "L" + s }}
+	obj := Sheet{
+		Cells:  [2][2]Cell{{{Label: "a", Value: 1}, {Label: "b"}}, {{Label: "c"}, {Label: "d", Value: 4}}},
+		Pixels: [3][4]uint8{{1, 2, 3, 4}, {5}},
+		Rows:   [][3]int{{1, 2, 3}, {4, 5, 6}},
+		Names:  [2][2]string{{"a", "b"}, {"c", "d"}},
+	}
+	c, err := NewCopier(config, Options{Rules: Rules{"Sheet.Names[][]": "label"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := c.Copy(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := obj
+	expected.Cells = [2][2]Cell{{{Label: "La", Value: 1}, {Label: "Lb"}}, {{Label: "Lc"}, {Label: "Ld", Value: 4}}}
+	expected.Names = [2][2]string{{"La", "Lb"}, {"Lc", "Ld"}}
+	if !reflect.DeepEqual(v, expected) {
+		t.Fatalf("expected: %+v, got: %+v", expected, v)
+	}
+	if rows := v.(Sheet).Rows; &rows[0] == &obj.Rows[0] {
+		t.Fatal("expected the rows to be copied")
+	}
+
+	for _, tc := range []struct {
+		t     reflect.Type
+		plain bool
+	}{
+		{reflect.TypeOf([4][4]float64{}), true},
+		{reflect.TypeOf([2]string{}), true},
+		{reflect.TypeOf([2]*int{}), false},
+		{reflect.TypeOf([2]Cell{}), false},
+	} {
+		if plainType(tc.t) != tc.plain {
+			t.Errorf("%s: expected plain to be %t", tc.t, tc.plain)
+		}
+	}
+}
@@ -0,0 +1,21 @@
+// Package echocopy renders JSON responses of github.com/labstack/echo/v4 handlers customized by ccopy Copiers,
+// so the values are redacted when they are rendered, whatever the handler.
+package echocopy
+
+import (
+	"net/http"
+
+	"github.com/gadumitrachioaiei/ccopy"
+	"github.com/labstack/echo/v4"
+)
+
+// JSON renders the copy of obj made by profile as JSON, like c.JSON.
+// If the copy fails, obj is not rendered, and the error is returned as the internal error
+// of an *echo.HTTPError of status 500.
+func JSON(c echo.Context, code int, obj interface{}, profile *ccopy.Copier) error {
+	v, err := profile.Copy(obj)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError).SetInternal(err)
+	}
+	return c.JSON(code, v)
+}
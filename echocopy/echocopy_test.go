@@ -0,0 +1,42 @@
+package echocopy
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gadumitrachioaiei/ccopy"
+	"github.com/labstack/echo/v4"
+)
+
+type user struct {
+	Name  string
+	Email string `ccopy:"email"`
+}
+
+func TestJSON(t *testing.T) {
+	profile, err := ccopy.NewCopier(ccopy.Config{"email": func(string) string { return "redacted" }}, ccopy.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := echo.New()
+	rec := httptest.NewRecorder()
+	c := e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec)
+	if err := JSON(c, http.StatusCreated, user{Name: "Ann", Email: "ann@example.com"}, profile); err != nil {
+		t.Fatal(err)
+	}
+	if body := strings.TrimSpace(rec.Body.String()); rec.Code != http.StatusCreated || body != `{"Name":"Ann","Email":"redacted"}` {
+		t.Fatalf("got: %d %s", rec.Code, body)
+	}
+
+	rec = httptest.NewRecorder()
+	c = e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec)
+	err = JSON(c, http.StatusOK, user{Email: "ann@example.com"}, &ccopy.Copier{})
+	var he *echo.HTTPError
+	var missing *ccopy.ErrMissingCustomizer
+	if !errors.As(err, &he) || he.Code != http.StatusInternalServerError || !errors.As(err, &missing) || rec.Body.Len() != 0 {
+		t.Fatalf("got: %v, body: %s", err, rec.Body)
+	}
+}
@@ -0,0 +1,328 @@
+package ccopy
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// ErrInvalidValue is returned when copying an invalid value, like a nil interface.
+var ErrInvalidValue = errors.New("invalid value")
+
+// ErrMissingCustomizer is returned when a field is tagged, or matched by a rule,
+// with the name of a customizer that is not in the config.
+type ErrMissingCustomizer struct {
+	Tag  string
+	Path string
+}
+
+func (e *ErrMissingCustomizer) Error() string {
+	return fmt.Sprintf("missing copy customiser for: %s, at: %s", e.Tag, e.Path)
+}
+
+// ErrUnsupportedKind is returned when copying a value of a kind that cannot be copied, like unsafe.Pointer.
+type ErrUnsupportedKind struct {
+	Kind reflect.Kind
+	Path string
+}
+
+func (e *ErrUnsupportedKind) Error() string {
+	return fmt.Sprintf("unsupported type: %s, at: %s", e.Kind, e.Path)
+}
+
+// ErrResource is returned when copying the handle of a resource, like an *os.File, with the ResourcesError policy.
+type ErrResource struct {
+	// Type is the type of the handle.
+	Type reflect.Type
+	Path string
+}
+
+func (e *ErrResource) Error() string {
+	return fmt.Sprintf("cannot copy resource of type %s, at: %s", e.Type, e.Path)
+}
+
+// ErrIncomparable is returned when the values customized with the idmap= prefix or the unique option
+// are of an incomparable type, like slices, which cannot be looked up in the tables of a session.
+type ErrIncomparable struct {
+	Tag  string
+	Path string
+	// Type is the type of the values.
+	Type reflect.Type
+	// Unique is set for the unique option, unset for the idmap= prefix.
+	Unique bool
+}
+
+func (e *ErrIncomparable) Error() string {
+	if e.Unique {
+		return fmt.Sprintf("cannot make values of incomparable type %s unique for: %s, at: %s", e.Type, e.Tag, e.Path)
+	}
+	return fmt.Sprintf("cannot map values of incomparable type %s for: %s, at: %s", e.Type, e.Tag, e.Path)
+}
+
+// ErrCannotDive is returned when a value whose tag starts with the dive, prefix is not a slice, an array or a map.
+type ErrCannotDive struct {
+	Tag  string
+	Path string
+	// Type is the type of the value.
+	Type reflect.Type
+}
+
+func (e *ErrCannotDive) Error() string {
+	return fmt.Sprintf("cannot dive into values of type %s for: %s, at: %s", e.Type, e.Tag, e.Path)
+}
+
+// ErrConfig is returned by Config.Validate with the problems of a config, like *ErrMissingCustomizer errors.
+type ErrConfig struct {
+	Errs []error
+}
+
+func (e *ErrConfig) Error() string {
+	msgs := make([]string, len(e.Errs))
+	for i, err := range e.Errs {
+		msgs[i] = err.Error()
+	}
+	return "invalid config: " + strings.Join(msgs, "; ")
+}
+
+func (e *ErrConfig) Unwrap() []error {
+	return e.Errs
+}
+
+// FieldError is the error of a copy failing at a value, like a field, returned by the copies wrapping their errors
+// that are about a value, like *ErrMissingCustomizer or *ErrCustomizer errors, so callers can tell where
+// a large object failed without knowing the type of the error. The wrapped errors are still found by errors.As.
+type FieldError struct {
+	// Path is the path of the value, like Order.Items[3].Buyer.Email.
+	Path string
+	// Tag is the name of the customizer of the value, for the errors of customizers.
+	Tag string
+	// Err is the error of the copy.
+	Err error
+}
+
+func (e *FieldError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// fieldError returns err wrapped in a *FieldError, if it is about a value and not one already.
+func fieldError(err error) error {
+	var fe *FieldError
+	if err == nil || errors.As(err, &fe) {
+		return err
+	}
+	if m := Describe(err); m.Path != "" {
+		return &FieldError{Path: m.Path, Tag: m.Tag, Err: err}
+	}
+	return err
+}
+
+// ErrBadCustomizerSignature is returned when a customizer cannot be called with the value it customizes,
+// or with the arguments of its tag, or returns a value that cannot replace it.
+type ErrBadCustomizerSignature struct {
+	Tag  string
+	Path string
+	// Customizer is the type of the customizer.
+	Customizer reflect.Type
+	// Type is the type of the customized value.
+	Type reflect.Type
+}
+
+func (e *ErrBadCustomizerSignature) Error() string {
+	return fmt.Sprintf("bad signature of copy customiser for: %s, at: %s: expected func([*ccopy.Scratch, *rand.Rand, context.Context or ccopy.Args, ]%s) %s or (%s, error), got: %s", e.Tag, e.Path, e.Type, e.Type, e.Type, e.Customizer)
+}
+
+// ErrDestination is returned by CopyInto when the copy cannot be written into the destination.
+type ErrDestination struct {
+	// Type is the type of the copied value.
+	Type reflect.Type
+	// Destination is the type of the destination, a pointer unless it is not one.
+	Destination reflect.Type
+	// Field is the field of the destination that cannot be written, if any.
+	Field string
+}
+
+func (e *ErrDestination) Error() string {
+	if e.Field != "" {
+		return fmt.Sprintf("cannot copy %s into %s: incompatible field: %s", e.Type, e.Destination, e.Field)
+	}
+	return fmt.Sprintf("cannot copy %s into %s", e.Type, e.Destination)
+}
+
+// ErrNaNKey is returned when copying a map entry whose key is or contains a NaN value,
+// with the NaNKeysError policy.
+type ErrNaNKey struct {
+	Path string
+}
+
+func (e *ErrNaNKey) Error() string {
+	return fmt.Sprintf("map key with NaN value, at: %s", e.Path)
+}
+
+// ErrKeyCollision is returned when the copy of a map key is equal to the copy of another key of the map,
+// with the KeyCollisionsError policy.
+type ErrKeyCollision struct {
+	Path string
+}
+
+func (e *ErrKeyCollision) Error() string {
+	return fmt.Sprintf("copied map key collides with the copy of another key, at: %s", e.Path)
+}
+
+// ErrAliasedResult is returned when a customizer returns a value sharing memory with the value it customizes,
+// with the AliasedResultsError policy.
+type ErrAliasedResult struct {
+	Tag  string
+	Path string
+}
+
+func (e *ErrAliasedResult) Error() string {
+	return fmt.Sprintf("copy customiser result shares memory with the original for: %s, at: %s", e.Tag, e.Path)
+}
+
+// ErrRuleConflict is returned when rules with different customizers can match the same values,
+// with the RuleConflictsError policy.
+type ErrRuleConflict struct {
+	// Rules are the paths of the rules.
+	Rules []string
+	// Path is the path of the value the rules match, empty for the rules found conflicting by NewCopier.
+	Path string
+}
+
+func (e *ErrRuleConflict) Error() string {
+	if e.Path == "" {
+		return fmt.Sprintf("conflicting rules: %s", strings.Join(e.Rules, ", "))
+	}
+	return fmt.Sprintf("conflicting rules: %s, at: %s", strings.Join(e.Rules, ", "), e.Path)
+}
+
+// ErrHandler is returned when a handler registered with RegisterHandler fails.
+type ErrHandler struct {
+	Type reflect.Type
+	Path string
+	Err  error
+}
+
+func (e *ErrHandler) Error() string {
+	return fmt.Sprintf("handler of %s failed, at: %s: %v", e.Type, e.Path, e.Err)
+}
+
+// Unwrap returns the error of the handler.
+func (e *ErrHandler) Unwrap() error {
+	return e.Err
+}
+
+// ErrCustomizer is returned when a customizer returning an error, of the form func(T) (T, error), fails.
+type ErrCustomizer struct {
+	Tag  string
+	Path string
+	Err  error
+}
+
+func (e *ErrCustomizer) Error() string {
+	return fmt.Sprintf("copy customiser for: %s failed, at: %s: %v", e.Tag, e.Path, e.Err)
+}
+
+// Unwrap returns the error of the customizer.
+func (e *ErrCustomizer) Unwrap() error {
+	return e.Err
+}
+
+// ErrTimeout is returned when a copy takes longer than the Timeout option of its Copier.
+type ErrTimeout struct {
+	Timeout time.Duration
+	// Path is the path of the value the copy reached.
+	Path string
+}
+
+func (e *ErrTimeout) Error() string {
+	return fmt.Sprintf("copy timed out after %s, at: %s", e.Timeout, e.Path)
+}
+
+// ErrCanceled is returned when the context of a copy, given to CopyCtx, is done before the copy.
+type ErrCanceled struct {
+	// Path is the path of the value the copy reached.
+	Path string
+	// Err is the error of the context.
+	Err error
+}
+
+func (e *ErrCanceled) Error() string {
+	return fmt.Sprintf("copy canceled, at: %s: %v", e.Path, e.Err)
+}
+
+// Unwrap returns the error of the context.
+func (e *ErrCanceled) Unwrap() error {
+	return e.Err
+}
+
+// ErrTooBig is returned when a copy exceeds the MaxElements or MaxBytes options.
+type ErrTooBig struct {
+	// Elements and Bytes are the elements and the bytes of the copy when it failed.
+	Elements int
+	Bytes    int64
+	// MaxElements and MaxBytes are the options.
+	MaxElements int
+	MaxBytes    int64
+	// Path is the path of the value the copy reached.
+	Path string
+	// Breakdown are the subtrees holding the most elements, with the TooBigBreakdown option.
+	Breakdown []Subtree
+}
+
+func (e *ErrTooBig) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "copy too big: %d elements, %d bytes, at: %s", e.Elements, e.Bytes, e.Path)
+	for _, s := range e.Breakdown {
+		fmt.Fprintf(&b, "\n\t%s: %d elements", s.Path, s.Elements)
+	}
+	return b.String()
+}
+
+// pathElem is a step from a value to one of its parts.
+type pathElem struct {
+	kind  segKind
+	field string
+	index int
+	key   reflect.Value
+}
+
+// formatPath formats a path from a value of type root, like Order.Items[3].Buyer.Email.
+// The keys of maps that are not integers are written as a hash of their value, unless keys is set.
+func formatPath(root reflect.Type, path []pathElem, keys bool) string {
+	var b strings.Builder
+	if root != nil {
+		b.WriteString(typePath(root))
+	}
+	for _, e := range path {
+		switch e.kind {
+		case segField:
+			b.WriteString(".")
+			b.WriteString(e.field)
+		case segIndex:
+			fmt.Fprintf(&b, "[%d]", e.index)
+		case segKey:
+			switch {
+			case !e.key.CanInterface():
+				b.WriteString("[?]")
+			case isInt(e.key.Kind()):
+				fmt.Fprintf(&b, "[%v]", e.key.Interface())
+			case !keys:
+				h := fnv.New32a()
+				fmt.Fprint(h, e.key.Interface())
+				fmt.Fprintf(&b, "[#%08x]", h.Sum32())
+			case e.key.Kind() == reflect.String:
+				fmt.Fprintf(&b, "[%q]", e.key.String())
+			default:
+				fmt.Fprintf(&b, "[%v]", e.key.Interface())
+			}
+		}
+	}
+	return b.String()
+}
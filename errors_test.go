@@ -0,0 +1,222 @@
+package ccopy
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+	"unsafe"
+)
+
+type Item struct {
+	Buyer struct {
+		Email string `ccopy:"email"`
+	}
+}
+
+type Order struct {
+	Items []Item
+	Meta  map[string]Item
+}
+
+func TestErrMissingCustomizer(t *testing.T) {
+	order := Order{Items: []Item{{}, {}}}
+	_, err := Config{}.Copy(order)
+	var e *ErrMissingCustomizer
+	if !errors.As(err, &e) {
+		t.Fatalf("got error: %v, expected *ErrMissingCustomizer", err)
+	}
+	if e.Tag != "email" || e.Path != "Order.Items[0].Buyer.Email" {
+		t.Fatalf("got error: %+v", e)
+	}
+	// the keys of maps, which can be personal data, are hashed unless written as they are by the KeysInPaths option
+	_, err = Config{}.Copy(Order{Meta: map[string]Item{"a": {}}})
+	if !errors.As(err, &e) || e.Path != "Order.Meta[#e40c292c].Buyer.Email" {
+		t.Fatalf("got error: %v", err)
+	}
+	c, err := NewCopier(Config{}, Options{KeysInPaths: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.Copy(Order{Meta: map[string]Item{"a": {}}})
+	if !errors.As(err, &e) || e.Path != `Order.Meta["a"].Buyer.Email` {
+		t.Fatalf("got error: %v", err)
+	}
+	_, err = Config{}.Copy(map[int]Item{3: {}})
+	if !errors.As(err, &e) || e.Path != "map[int]ccopy.Item[3].Buyer.Email" {
+		t.Fatalf("got error: %v", err)
+	}
+}
+
+func TestErrBadCustomizerSignature(t *testing.T) {
+	_, err := Config{"email": func(int) int { return 0 }}.Copy(Order{Items: []Item{{}}})
+	var e *ErrBadCustomizerSignature
+	if !errors.As(err, &e) {
+		t.Fatalf("got error: %v, expected *ErrBadCustomizerSignature", err)
+	}
+	if e.Tag != "email" || e.Type != reflect.TypeOf("") || e.Customizer != reflect.TypeOf(func(int) int { return 0 }) {
+		t.Fatalf("got error: %+v", e)
+	}
+}
+
+func TestErrUnsupportedKind(t *testing.T) {
+	type T struct {
+		P unsafe.Pointer
+	}
+	_, err := Config{}.Copy(T{})
+	var e *ErrUnsupportedKind
+	if !errors.As(err, &e) || e.Kind != reflect.UnsafePointer || e.Path != "T.P" {
+		t.Fatalf("got error: %v, expected *ErrUnsupportedKind at T.P", err)
+	}
+}
+
+func TestErrInvalidValue(t *testing.T) {
+	if _, err := (Config{}).Copy(nil); err != ErrInvalidValue {
+		t.Fatalf("got error: %v, expected: %v", err, ErrInvalidValue)
+	}
+}
+
+func TestErrTimeout(t *testing.T) {
+	type T struct {
+		Items []Item
+	}
+	slow := func(s string) string {
+		time.Sleep(50 * time.Microsecond)
+		return s
+	}
+	c, err := NewCopier(Config{"email": slow}, Options{Timeout: 5 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	obj := T{Items: make([]Item, 2000)}
+	for _, copy := range []func() error{
+		func() error { _, err := c.Copy(obj); return err },
+		func() error { _, errs := c.CopyPartial(obj); return errs[len(errs)-1] },
+	} {
+		err := copy()
+		var e *ErrTimeout
+		if !errors.As(err, &e) || !strings.HasPrefix(e.Path, "T.Items[") {
+			t.Fatalf("got error: %v, expected *ErrTimeout in T.Items", err)
+		}
+	}
+	if _, err := c.Copy(T{Items: make([]Item, 10)}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestErrCanceled(t *testing.T) {
+	type T struct {
+		Items []Item
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	calls := 0
+	c := Config{"email": func(s string) string {
+		if calls++; calls == 100 {
+			cancel()
+		}
+		return s
+	}}
+	_, err := c.CopyCtx(ctx, T{Items: make([]Item, 2000)})
+	var e *ErrCanceled
+	if !errors.As(err, &e) || !strings.HasPrefix(e.Path, "T.Items[") || !errors.Is(err, context.Canceled) {
+		t.Fatalf("got error: %v, expected *ErrCanceled in T.Items", err)
+	}
+	if calls > 100+timeoutCheck {
+		t.Fatalf("got %d calls, expected the copy to stop within %d values of the cancellation", calls, timeoutCheck)
+	}
+	if _, err := c.CopyCtx(ctx, T{}); !errors.As(err, &e) {
+		t.Fatalf("got error: %v, expected *ErrCanceled for a context done before the copy", err)
+	}
+}
+
+func TestFieldError(t *testing.T) {
+	type Buyer struct {
+		Email string `ccopy:"email"`
+	}
+	type Item struct {
+		Buyer *Buyer
+	}
+	type Order struct {
+		Items []Item
+	}
+	order := Order{Items: []Item{{}, {}, {}, {Buyer: &Buyer{Email: "a@b.c"}}}}
+	_, err := Config{}.Copy(order)
+	var fe *FieldError
+	if !errors.As(err, &fe) || fe.Path != "Order.Items[3].Buyer.Email" || fe.Tag != "email" {
+		t.Fatalf("got error: %v, expected *FieldError", err)
+	}
+	var missing *ErrMissingCustomizer
+	if !errors.As(err, &missing) || fe.Err != missing {
+		t.Fatalf("got error: %v, expected it to wrap *ErrMissingCustomizer", err)
+	}
+	if err.Error() != missing.Error() {
+		t.Fatalf("got message: %s, expected the message of the wrapped error", err)
+	}
+
+	failing := errors.New("unavailable")
+	c := Config{"email": func(string) (string, error) { return "", failing }}
+	cp, err := NewCopier(c, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, errs := cp.CopyPartial(order)
+	if len(errs) != 1 || !errors.As(errs[0], &fe) || fe.Path != "Order.Items[3].Buyer.Email" || !errors.Is(errs[0], failing) {
+		t.Fatalf("got errors: %v, expected a *FieldError wrapping the error of the customizer", errs)
+	}
+
+	// the errors that are not about a value are returned as they are
+	if _, err := c.Copy(nil); err != ErrInvalidValue {
+		t.Fatalf("got error: %v, expected ErrInvalidValue", err)
+	}
+
+	type Mapped struct {
+		IDs []string `ccopy:"idmap=ids"`
+	}
+	type Unique struct {
+		IDs []string `ccopy:"ids,unique"`
+	}
+	type Dive struct {
+		ID string `ccopy:"dive,ids"`
+	}
+	type Tags struct {
+		Tags []string
+	}
+	type Contact struct {
+		Email string
+	}
+	type Card struct {
+		Contact Contact
+	}
+	ids := func(s []string) []string { return s }
+	email := func(s string) string { return s }
+	c = Config{"ids": ids, "email": email, "other": email}
+	for _, test := range []struct {
+		options Options
+		obj     interface{}
+		key     MessageKey
+		path    string
+	}{
+		{obj: Mapped{}, key: MessageIncomparable, path: "Mapped.IDs"},
+		{obj: Unique{}, key: MessageIncomparable, path: "Unique.IDs"},
+		{obj: Dive{}, key: MessageCannotDive, path: "Dive.ID"},
+		{options: Options{MaxElements: 2}, obj: Tags{Tags: []string{"a", "b", "c"}}, key: MessageTooBig, path: "Tags.Tags"},
+		{
+			options: Options{RuleConflicts: RuleConflictsError, Rules: Rules{"Card.Contact.Email": "email", "Contact.Email": "other"}},
+			obj:     Card{},
+			key:     MessageRuleConflict,
+			path:    "Card.Contact.Email",
+		},
+	} {
+		cp, err := NewCopier(c, test.options)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = cp.Copy(test.obj)
+		if !errors.As(err, &fe) || fe.Path != test.path || Describe(err).Key != test.key {
+			t.Errorf("got error: %v, expected a *FieldError at %s described by %s", err, test.path, test.key)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+// Package exif provides a ccopy customizer stripping the metadata of images, like the GPS location
+// saved by cameras, without decoding or re-encoding the pixels.
+//
+// JPEG and PNG images are supported. Other data is kept as it is, and malformed images are dropped.
+package exif
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+)
+
+var (
+	jpegSignature = []byte{0xff, 0xd8}
+	pngSignature  = []byte("\x89PNG\r\n\x1a\n")
+
+	exifHeader = []byte("Exif\x00\x00")
+	xmpHeader  = []byte("http://ns.adobe.com/xap/1.0/\x00")
+)
+
+// ErrMalformed is returned by Clean for images that cannot be parsed.
+var ErrMalformed = errors.New("exif: malformed image")
+
+// Strip is a customizer removing the EXIF and XMP metadata of JPEG and PNG images.
+// Data that is not a JPEG or PNG image is copied as it is. Images that cannot be parsed, like truncated ones,
+// are left out of the copy, as their metadata cannot be removed; use Clean to know about them.
+func Strip(b []byte) []byte {
+	clean, err := Clean(b)
+	if err != nil {
+		return nil
+	}
+	if len(clean) > 0 && &clean[0] == &b[0] {
+		return append([]byte(nil), b...)
+	}
+	return clean
+}
+
+// Clean returns a copy of a JPEG or PNG image without its EXIF and XMP metadata:
+// the APP1 segments of JPEG images, and the eXIf and textual chunks of PNG images.
+// Other data is returned as it is, and malformed images return ErrMalformed.
+func Clean(b []byte) ([]byte, error) {
+	switch {
+	case bytes.HasPrefix(b, jpegSignature):
+		return cleanJPEG(b)
+	case bytes.HasPrefix(b, pngSignature):
+		return cleanPNG(b)
+	}
+	return b, nil
+}
+
+func cleanJPEG(b []byte) ([]byte, error) {
+	out := append([]byte{}, jpegSignature...)
+	i := len(jpegSignature)
+	for {
+		if i+2 > len(b) || b[i] != 0xff {
+			return nil, ErrMalformed
+		}
+		marker := b[i+1]
+		switch {
+		case marker == 0xff:
+			// fill byte
+			i++
+			continue
+		case marker == 0xd9:
+			// end of image
+			return append(out, b[i:]...), nil
+		case marker == 0x01 || 0xd0 <= marker && marker <= 0xd7:
+			// markers without a length
+			out = append(out, b[i:i+2]...)
+			i += 2
+			continue
+		}
+		if i+4 > len(b) {
+			return nil, ErrMalformed
+		}
+		end := i + 2 + int(binary.BigEndian.Uint16(b[i+2:]))
+		if end > len(b) || end < i+4 {
+			return nil, ErrMalformed
+		}
+		if marker == 0xda {
+			// start of scan: the compressed data follows, up to the end of the image
+			return append(out, b[i:]...), nil
+		}
+		payload := b[i+4 : end]
+		if marker != 0xe1 || !bytes.HasPrefix(payload, exifHeader) && !bytes.HasPrefix(payload, xmpHeader) {
+			out = append(out, b[i:end]...)
+		}
+		i = end
+	}
+}
+
+// strippedChunks are the PNG chunks holding metadata.
+var strippedChunks = map[string]bool{"eXIf": true, "tEXt": true, "iTXt": true, "zTXt": true}
+
+func cleanPNG(b []byte) ([]byte, error) {
+	out := append([]byte{}, pngSignature...)
+	i := len(pngSignature)
+	for i < len(b) {
+		if i+12 > len(b) {
+			return nil, ErrMalformed
+		}
+		end := i + 12 + int(binary.BigEndian.Uint32(b[i:]))
+		if end > len(b) || end < i+12 {
+			return nil, ErrMalformed
+		}
+		typ := string(b[i+4 : i+8])
+		if binary.BigEndian.Uint32(b[end-4:]) != crc32.ChecksumIEEE(b[i+4:end-4]) {
+			return nil, ErrMalformed
+		}
+		if !strippedChunks[typ] {
+			out = append(out, b[i:end]...)
+		}
+		i = end
+		if typ == "IEND" {
+			break
+		}
+	}
+	return out, nil
+}
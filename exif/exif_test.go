@@ -0,0 +1,91 @@
+package exif
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/gadumitrachioaiei/ccopy"
+)
+
+func testImage() image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, 8, 8))
+	for x := 0; x < 8; x++ {
+		img.Set(x, x, color.RGBA{R: 255, A: 255})
+	}
+	return img
+}
+
+func jpegWithExif(t *testing.T) []byte {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, testImage(), nil); err != nil {
+		t.Fatal(err)
+	}
+	b := buf.Bytes()
+	payload := append(append([]byte{}, exifHeader...), []byte("GPS 48.8584N 2.2945E")...)
+	segment := []byte{0xff, 0xe1, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+	return append(append(append([]byte{}, b[:2]...), append(segment, payload...)...), b[2:]...)
+}
+
+func pngWithExif(t *testing.T) []byte {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, testImage()); err != nil {
+		t.Fatal(err)
+	}
+	b := buf.Bytes()
+	data := []byte("GPS 48.8584N 2.2945E")
+	chunk := make([]byte, 8, 12+len(data))
+	binary.BigEndian.PutUint32(chunk, uint32(len(data)))
+	copy(chunk[4:], "eXIf")
+	chunk = append(chunk, data...)
+	chunk = binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(chunk[4:]))
+	// after the IHDR chunk
+	return append(append(append([]byte{}, b[:33]...), chunk...), b[33:]...)
+}
+
+func TestStrip(t *testing.T) {
+	for name, b := range map[string][]byte{"jpeg": jpegWithExif(t), "png": pngWithExif(t)} {
+		if _, _, err := image.Decode(bytes.NewReader(b)); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		clean, err := Clean(b)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if bytes.Contains(clean, []byte("GPS")) {
+			t.Fatalf("%s: expected the metadata to be stripped", name)
+		}
+		if _, _, err := image.Decode(bytes.NewReader(clean)); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if _, err := Clean(b[:len(b)/2]); name == "png" && err != ErrMalformed {
+			t.Fatalf("%s: got %v, expected %v", name, err, ErrMalformed)
+		}
+		// the metadata of malformed images cannot be removed, so they are dropped
+		if stripped := Strip(b[:40]); stripped != nil {
+			t.Fatalf("%s: got %q, expected the malformed image to be dropped", name, stripped)
+		}
+	}
+	if b := Strip([]byte("not an image")); string(b) != "not an image" {
+		t.Fatalf("got %q, expected the data to be kept", b)
+	}
+}
+
+func TestCopy(t *testing.T) {
+	type Photo struct {
+		Data []byte `ccopy:"image"`
+	}
+	res, err := ccopy.Config{"image": Strip}.Copy(Photo{Data: jpegWithExif(t)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(res.(Photo).Data, exifHeader) {
+		t.Fatal("expected the metadata to be stripped")
+	}
+}
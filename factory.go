@@ -0,0 +1,43 @@
+package ccopy
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// factoryKey is the interface type and the type of the values a factory applies to.
+type factoryKey struct {
+	iface, impl reflect.Type
+}
+
+// parseFactories returns the factories of the Factories option by the types they apply to.
+func parseFactories(factories []interface{}) (map[factoryKey]reflect.Value, error) {
+	if len(factories) == 0 {
+		return nil, nil
+	}
+	m := make(map[factoryKey]reflect.Value, len(factories))
+	for _, f := range factories {
+		fv := reflect.ValueOf(f)
+		ft := fv.Type()
+		if fv.Kind() != reflect.Func || fv.IsNil() || ft.IsVariadic() || ft.NumIn() != 1 || ft.NumOut() != 1 ||
+			ft.Out(0).Kind() != reflect.Interface || !ft.In(0).Implements(ft.Out(0)) {
+			return nil, fmt.Errorf("invalid factory: expected func(T) I, with T implementing the interface I, got: %T", f)
+		}
+		key := factoryKey{iface: ft.Out(0), impl: ft.In(0)}
+		if _, ok := m[key]; ok {
+			return nil, fmt.Errorf("invalid factory: duplicate factory of %s for %s", key.iface, key.impl)
+		}
+		m[key] = fv
+	}
+	return m, nil
+}
+
+// factory returns the factory of the values of interface type iface holding a value of type impl, if any:
+// the factory of impl, or else the factory of all the values of iface.
+func (c *Copier) factory(iface, impl reflect.Type) (reflect.Value, bool) {
+	if fn, ok := c.factories[factoryKey{iface: iface, impl: impl}]; ok {
+		return fn, true
+	}
+	fn, ok := c.factories[factoryKey{iface: iface, impl: iface}]
+	return fn, ok
+}
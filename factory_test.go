@@ -0,0 +1,66 @@
+package ccopy
+
+import (
+	"testing"
+)
+
+type repository interface {
+	Find(id int) string
+}
+
+type liveRepository struct {
+	dsn string
+}
+
+func (r *liveRepository) Find(id int) string { return "live" }
+
+type stubRepository map[int]string
+
+func (r stubRepository) Find(id int) string { return r[id] }
+
+type cachedRepository struct {
+	repository
+}
+
+func TestFactories(t *testing.T) {
+	type Service struct {
+		Name  string
+		Repo  repository
+		Cache repository
+		Any   interface{}
+	}
+	c, err := NewCopier(Config{}, Options{Factories: []interface{}{
+		func(r *liveRepository) repository { return stubRepository{1: "stub"} },
+		func(r repository) repository { return cachedRepository{r} },
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	obj := Service{Name: "a", Repo: &liveRepository{dsn: "postgres://"}, Cache: stubRepository{}, Any: &liveRepository{}}
+	res, err := c.Copy(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := res.(Service)
+	if stub, ok := s.Repo.(stubRepository); !ok || stub.Find(1) != "stub" {
+		t.Fatalf("got %T, expected the stub", s.Repo)
+	}
+	if _, ok := s.Cache.(cachedRepository); !ok {
+		t.Fatalf("got %T, expected the factory of all repositories to apply", s.Cache)
+	}
+	if _, ok := s.Any.(*liveRepository); !ok || s.Any == obj.Any {
+		t.Fatalf("got %T, expected a copy of the repository held by an empty interface", s.Any)
+	}
+}
+
+func TestFactoriesInvalid(t *testing.T) {
+	for _, f := range []interface{}{
+		func(r *liveRepository) *liveRepository { return r },
+		func(s string) repository { return nil },
+		"not a func",
+	} {
+		if _, err := NewCopier(Config{}, Options{Factories: []interface{}{f}}); err == nil {
+			t.Fatalf("expected an error for %T", f)
+		}
+	}
+}
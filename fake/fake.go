@@ -0,0 +1,158 @@
+// Package fake provides ccopy customizers replacing personal data with plausible fake data,
+// localized for the country of the copied object.
+//
+// The country is configured, or inferred from a sibling field holding a country code:
+// the Country customizer of a Faker records the country of the object being copied,
+// for the customizers of the fields copied after it, so the fields holding the country
+// must be copied first, for example with the CountryFirst field order.
+package fake
+
+import (
+	"math/rand"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gadumitrachioaiei/ccopy"
+)
+
+// Faker generates fake data.
+type Faker struct {
+	r        *rand.Rand
+	fallback *Locale
+}
+
+// New returns a Faker using the random generator r, like the generator of a ccopy.Session,
+// and the locale of country when the country of an object is not known.
+// It panics if there is no locale for country.
+func New(r *rand.Rand, country string) *Faker {
+	l := Locales[strings.ToUpper(country)]
+	if l == nil {
+		panic("fake: no locale for country: " + country)
+	}
+	return &Faker{r: r, fallback: l}
+}
+
+// localeKey is the key of the scratch under which the locale of the copied object is stored.
+type localeKey struct{}
+
+func (f *Faker) locale(s *ccopy.Scratch) *Locale {
+	if l, ok := s.Get(localeKey{}); ok {
+		return l.(*Locale)
+	}
+	return f.fallback
+}
+
+// Country is a customizer that keeps a country code as it is and makes its locale the locale
+// of the values generated afterwards in the copy, up to the next country.
+// Unknown countries select the fallback locale of the Faker.
+func (f *Faker) Country(s *ccopy.Scratch, country string) string {
+	l := Locales[strings.ToUpper(strings.TrimSpace(country))]
+	if l == nil {
+		l = f.fallback
+	}
+	s.Set(localeKey{}, l)
+	return country
+}
+
+func (f *Faker) pick(values []string) string {
+	return values[f.r.Intn(len(values))]
+}
+
+// FirstName replaces a string with a first name.
+func (f *Faker) FirstName(s *ccopy.Scratch, _ string) string {
+	return f.pick(f.locale(s).FirstNames)
+}
+
+// LastName replaces a string with a last name.
+func (f *Faker) LastName(s *ccopy.Scratch, _ string) string {
+	return f.pick(f.locale(s).LastNames)
+}
+
+// Name replaces a string with a full name.
+func (f *Faker) Name(s *ccopy.Scratch, _ string) string {
+	l := f.locale(s)
+	return f.pick(l.FirstNames) + " " + f.pick(l.LastNames)
+}
+
+// City replaces a string with a city name.
+func (f *Faker) City(s *ccopy.Scratch, _ string) string {
+	return f.pick(f.locale(s).Cities)
+}
+
+// Street replaces a string with a street address, a street name and a house number.
+func (f *Faker) Street(s *ccopy.Scratch, _ string) string {
+	l := f.locale(s)
+	number := strconv.Itoa(1 + f.r.Intn(150))
+	if l.NumberFirst {
+		return number + " " + f.pick(l.Streets)
+	}
+	return f.pick(l.Streets) + " " + number
+}
+
+// Phone replaces a string with a phone number, in international format.
+func (f *Faker) Phone(s *ccopy.Scratch, _ string) string {
+	return f.format(f.locale(s).PhoneFormat)
+}
+
+// PostalCode replaces a string with a postal code.
+func (f *Faker) PostalCode(s *ccopy.Scratch, _ string) string {
+	return f.format(f.locale(s).PostalFormat)
+}
+
+func (f *Faker) format(format string) string {
+	b := []byte(format)
+	for i, c := range b {
+		switch c {
+		case '#':
+			b[i] = '0' + byte(f.r.Intn(10))
+		case '?':
+			b[i] = 'A' + byte(f.r.Intn(26))
+		}
+	}
+	return string(b)
+}
+
+// Config returns the customizers of the Faker, under the names
+// "country", "first_name", "last_name", "name", "city", "street", "phone" and "postal_code".
+func (f *Faker) Config() ccopy.Config {
+	return ccopy.Config{
+		"country":     f.Country,
+		"first_name":  f.FirstName,
+		"last_name":   f.LastName,
+		"name":        f.Name,
+		"city":        f.City,
+		"street":      f.Street,
+		"phone":       f.Phone,
+		"postal_code": f.PostalCode,
+	}
+}
+
+// CountryFirst is a ccopy field order copying first the fields named Country or CountryCode,
+// or whose json name is country or country_code, so that the other fields of a struct
+// are generated in its locale.
+func CountryFirst(t reflect.Type, fields []reflect.StructField) []reflect.StructField {
+	isCountry := func(f reflect.StructField) bool {
+		switch f.Name {
+		case "Country", "CountryCode":
+			return true
+		}
+		switch strings.Split(f.Tag.Get("json"), ",")[0] {
+		case "country", "country_code":
+			return true
+		}
+		return false
+	}
+	var ordered []reflect.StructField
+	for _, f := range fields {
+		if isCountry(f) {
+			ordered = append(ordered, f)
+		}
+	}
+	for _, f := range fields {
+		if !isCountry(f) {
+			ordered = append(ordered, f)
+		}
+	}
+	return ordered
+}
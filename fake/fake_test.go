@@ -0,0 +1,86 @@
+package fake
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/gadumitrachioaiei/ccopy"
+)
+
+type Customer struct {
+	Name    string `ccopy:"name"`
+	Phone   string `ccopy:"phone"`
+	Street  string `ccopy:"street"`
+	Postal  string `ccopy:"postal_code"`
+	Country string `ccopy:"country"`
+}
+
+func contains(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func TestLocales(t *testing.T) {
+	f := New(rand.New(rand.NewSource(1)), "us")
+	c, err := ccopy.NewCopier(f.Config(), ccopy.Options{FieldOrder: CountryFirst})
+	if err != nil {
+		t.Fatal(err)
+	}
+	obj := []Customer{
+		{Name: "Hans Meier", Phone: "0301234", Country: "DE"},
+		{Name: "Taro Yamada", Phone: "0312345", Country: "jp"},
+		{Name: "John Doe", Phone: "555", Country: "XX"},
+	}
+	res, err := c.Copy(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, country := range []string{"DE", "JP", "US"} {
+		cu := res.([]Customer)[i]
+		l := Locales[country]
+		names := strings.SplitN(cu.Name, " ", 2)
+		if !contains(l.FirstNames, names[0]) || !contains(l.LastNames, names[1]) {
+			t.Fatalf("got name %q, expected a name of %s", cu.Name, country)
+		}
+		prefix := strings.SplitN(l.PhoneFormat, " ", 2)[0]
+		if !strings.HasPrefix(cu.Phone, prefix) || len(cu.Phone) != len(l.PhoneFormat) {
+			t.Fatalf("got phone %q, expected the format %s", cu.Phone, l.PhoneFormat)
+		}
+		if len(cu.Postal) != len(l.PostalFormat) {
+			t.Fatalf("got postal code %q, expected the format %s", cu.Postal, l.PostalFormat)
+		}
+		if cu.Country != obj[i].Country {
+			t.Fatalf("got country %q, expected %q", cu.Country, obj[i].Country)
+		}
+	}
+	if street := res.([]Customer)[0].Street; !contains(Locales["DE"].Streets, street[:strings.LastIndex(street, " ")]) {
+		t.Fatalf("got street %q, expected the number after the street name", street)
+	}
+}
+
+func TestFallback(t *testing.T) {
+	f := New(rand.New(rand.NewSource(1)), "GB")
+	type Contact struct {
+		Name string `ccopy:"name"`
+	}
+	res, err := f.Config().Copy(Contact{Name: "Jane"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := strings.SplitN(res.(Contact).Name, " ", 2)
+	if !contains(Locales["GB"].FirstNames, names[0]) {
+		t.Fatalf("got %q, expected a name of GB", res.(Contact).Name)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected a panic for an unknown country")
+		}
+	}()
+	New(rand.New(rand.NewSource(1)), "XX")
+}
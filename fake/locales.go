@@ -0,0 +1,80 @@
+package fake
+
+// Locale is the data fake values are generated from, for a country.
+type Locale struct {
+	// Country is the ISO 3166-1 alpha-2 code of the country, like "DE".
+	Country    string
+	FirstNames []string
+	LastNames  []string
+	Cities     []string
+	Streets    []string
+	// NumberFirst puts the house number before the street name, like in "12 Main Street".
+	NumberFirst bool
+	// PhoneFormat is the format of phone numbers, where every '#' is replaced by a random digit.
+	PhoneFormat string
+	// PostalFormat is the format of postal codes, where every '#' is replaced by a random digit
+	// and every '?' by a random uppercase letter.
+	PostalFormat string
+}
+
+// Locales are the built-in locales, by country code.
+var Locales = map[string]*Locale{
+	"US": {
+		Country:      "US",
+		FirstNames:   []string{"James", "Mary", "Robert", "Patricia", "Michael", "Linda", "David", "Jennifer"},
+		LastNames:    []string{"Smith", "Johnson", "Williams", "Brown", "Jones", "Miller", "Davis", "Wilson"},
+		Cities:       []string{"Springfield", "Riverside", "Franklin", "Greenville", "Madison"},
+		Streets:      []string{"Main Street", "Oak Avenue", "Maple Drive", "Cedar Lane", "Park Road"},
+		NumberFirst:  true,
+		PhoneFormat:  "+1 ###-###-####",
+		PostalFormat: "#####",
+	},
+	"GB": {
+		Country:      "GB",
+		FirstNames:   []string{"Oliver", "Amelia", "George", "Isla", "Harry", "Ava", "Jack", "Emily"},
+		LastNames:    []string{"Taylor", "Evans", "Thomas", "Roberts", "Walker", "Wright", "Hughes", "Green"},
+		Cities:       []string{"Bristol", "Leeds", "Norwich", "York", "Bath"},
+		Streets:      []string{"High Street", "Station Road", "Church Lane", "Victoria Road", "Mill Lane"},
+		NumberFirst:  true,
+		PhoneFormat:  "+44 #### ######",
+		PostalFormat: "??# #??",
+	},
+	"DE": {
+		Country:      "DE",
+		FirstNames:   []string{"Lukas", "Anna", "Felix", "Lea", "Jonas", "Marie", "Paul", "Sophie"},
+		LastNames:    []string{"Müller", "Schmidt", "Schneider", "Fischer", "Weber", "Meyer", "Wagner", "Becker"},
+		Cities:       []string{"Hamburg", "Köln", "Leipzig", "Dresden", "Bremen"},
+		Streets:      []string{"Hauptstraße", "Schulstraße", "Gartenweg", "Bahnhofstraße", "Lindenallee"},
+		PhoneFormat:  "+49 ### #######",
+		PostalFormat: "#####",
+	},
+	"FR": {
+		Country:      "FR",
+		FirstNames:   []string{"Gabriel", "Louise", "Raphaël", "Jade", "Léo", "Emma", "Louis", "Alice"},
+		LastNames:    []string{"Martin", "Bernard", "Dubois", "Thomas", "Robert", "Richard", "Petit", "Durand"},
+		Cities:       []string{"Lyon", "Nantes", "Lille", "Rennes", "Dijon"},
+		Streets:      []string{"rue de la Paix", "avenue Victor Hugo", "rue Pasteur", "boulevard Voltaire", "rue du Moulin"},
+		NumberFirst:  true,
+		PhoneFormat:  "+33 # ## ## ## ##",
+		PostalFormat: "#####",
+	},
+	"ES": {
+		Country:      "ES",
+		FirstNames:   []string{"Hugo", "Lucía", "Martín", "Sofía", "Daniel", "Martina", "Pablo", "Paula"},
+		LastNames:    []string{"García", "Fernández", "González", "Rodríguez", "López", "Martínez", "Sánchez", "Pérez"},
+		Cities:       []string{"Sevilla", "Valencia", "Bilbao", "Málaga", "Zaragoza"},
+		Streets:      []string{"Calle Mayor", "Calle Real", "Avenida de la Constitución", "Calle del Sol", "Plaza de España"},
+		PhoneFormat:  "+34 ### ### ###",
+		PostalFormat: "#####",
+	},
+	"JP": {
+		Country:      "JP",
+		FirstNames:   []string{"Haruto", "Yui", "Sota", "Hina", "Yuto", "Mio", "Riku", "Sakura"},
+		LastNames:    []string{"Sato", "Suzuki", "Takahashi", "Tanaka", "Watanabe", "Ito", "Yamamoto", "Nakamura"},
+		Cities:       []string{"Osaka", "Nagoya", "Sapporo", "Fukuoka", "Kobe"},
+		Streets:      []string{"Chuo-dori", "Sakura-dori", "Meiji-dori", "Kita-dori", "Minami-dori"},
+		NumberFirst:  true,
+		PhoneFormat:  "+81 ##-####-####",
+		PostalFormat: "###-####",
+	},
+}
@@ -0,0 +1,105 @@
+package ccopy
+
+import (
+	"reflect"
+	"time"
+)
+
+// fastCopy copies the value src of a field to the field dst, without the general engine.
+type fastCopy func(dst, src reflect.Value)
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	stringPtrType = reflect.TypeOf((*string)(nil))
+	stringsType   = reflect.TypeOf([]string(nil))
+	stringMapType = reflect.TypeOf(map[string]string(nil))
+)
+
+// fastPath returns how to copy the fields of type t directly, for the most common field types:
+// the basic kinds, arrays of them, time.Time, *string, []string and map[string]string.
+// Values of these types cannot have tags or warnings of their own, and rules can only match them
+// from the struct having the field, so the fast path is taken when no rule is active in the struct.
+// Types with a registered handler are not copied directly.
+func fastPath(t reflect.Type) fastCopy {
+	if fn, ok := typeHandler(t); ok {
+		if t == timeType && !fn.IsValid() {
+			return setField
+		}
+		return nil
+	}
+	switch t {
+	case stringPtrType:
+		return copyStringPtr
+	case stringsType:
+		return copyStrings
+	case stringMapType:
+		return copyStringMap
+	}
+	switch t.Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		return setField
+	case reflect.Array:
+		if plainType(t) {
+			return setField
+		}
+	}
+	return nil
+}
+
+// plainType reports whether the values of type t are copied by assignment, unless the copy is canonical:
+// values of basic kinds and arrays of them, down to any number of dimensions, without registered handlers.
+func plainType(t reflect.Type) bool {
+	for {
+		if _, ok := typeHandler(t); ok {
+			return false
+		}
+		switch t.Kind() {
+		case reflect.Bool, reflect.String,
+			reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+			return true
+		case reflect.Array:
+			t = t.Elem()
+		default:
+			return false
+		}
+	}
+}
+
+func setField(dst, src reflect.Value) {
+	dst.Set(src)
+}
+
+func copyStringPtr(dst, src reflect.Value) {
+	if src.IsNil() {
+		return
+	}
+	p := new(string)
+	*p = src.Elem().String()
+	dst.Set(reflect.ValueOf(p))
+}
+
+func copyStrings(dst, src reflect.Value) {
+	if src.IsNil() {
+		return
+	}
+	s := make([]string, src.Len())
+	reflect.Copy(reflect.ValueOf(s), src)
+	dst.Set(reflect.ValueOf(s))
+}
+
+func copyStringMap(dst, src reflect.Value) {
+	if src.IsNil() {
+		return
+	}
+	m := src.Interface().(map[string]string)
+	c := make(map[string]string, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	dst.Set(reflect.ValueOf(c))
+}
@@ -0,0 +1,33 @@
+// Package fibercopy renders JSON responses of github.com/gofiber/fiber/v2 handlers customized by ccopy Copiers,
+// so the values are redacted when they are rendered, whatever the handler.
+package fibercopy
+
+import (
+	"github.com/gadumitrachioaiei/ccopy"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Error is returned when a value fails to be customized. Its message tells nothing of the value,
+// as the default error handler of fiber writes it in the response, with status 500.
+type Error struct {
+	Err error
+}
+
+func (e *Error) Error() string {
+	return "fibercopy: response not customized"
+}
+
+// Unwrap returns the error of the copy.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// JSON renders the copy of obj made by profile as JSON, with status code, like c.Status(code).JSON(obj).
+// If the copy fails, obj is not rendered, and an *Error is returned.
+func JSON(c *fiber.Ctx, code int, obj interface{}, profile *ccopy.Copier) error {
+	v, err := profile.Copy(obj)
+	if err != nil {
+		return &Error{Err: err}
+	}
+	return c.Status(code).JSON(v)
+}
@@ -0,0 +1,43 @@
+package fibercopy
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gadumitrachioaiei/ccopy"
+	"github.com/gofiber/fiber/v2"
+)
+
+type user struct {
+	Name  string
+	Email string `ccopy:"email"`
+}
+
+func TestJSON(t *testing.T) {
+	profile, err := ccopy.NewCopier(ccopy.Config{"email": func(string) string { return "redacted" }}, ccopy.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	obj := user{Name: "Ann", Email: "ann@example.com"}
+	app := fiber.New()
+	app.Get("/", func(c *fiber.Ctx) error { return JSON(c, http.StatusCreated, obj, profile) })
+	app.Get("/failing", func(c *fiber.Ctx) error { return JSON(c, http.StatusOK, obj, &ccopy.Copier{}) })
+	for path, expected := range map[string]struct {
+		code int
+		body string
+	}{
+		"/":        {http.StatusCreated, `{"Name":"Ann","Email":"redacted"}`},
+		"/failing": {http.StatusInternalServerError, "fibercopy: response not customized"},
+	} {
+		res, err := app.Test(httptest.NewRequest(http.MethodGet, path, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(res.Body)
+		if res.StatusCode != expected.code || string(body) != expected.body {
+			t.Fatalf("%s: got: %d %s", path, res.StatusCode, body)
+		}
+	}
+}
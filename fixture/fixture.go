@@ -0,0 +1,169 @@
+// Package fixture turns live objects into test fixtures: an object is deep copied and anonymized
+// by a ccopy.Copier, stripped of the values that cannot be serialized, like functions, channels and contexts,
+// and written as JSON or as a Go composite literal, so that production bugs become reproducible test inputs.
+package fixture
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gadumitrachioaiei/ccopy"
+)
+
+// Policy is what is done with the values that cannot be part of a fixture:
+// functions, channels, unsafe pointers and contexts.
+type Policy int
+
+const (
+	// Strip leaves them out of the fixture.
+	Strip Policy = iota
+	// Fail fails making the fixture.
+	Fail
+)
+
+var contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+
+// Make returns the copy of obj made by c, without the values that cannot be part of a fixture, handled by policy.
+func Make(c *ccopy.Copier, obj interface{}, policy Policy) (interface{}, error) {
+	v, err := c.Copy(obj)
+	if err != nil || v == nil {
+		return v, err
+	}
+	p := reflect.New(reflect.TypeOf(v))
+	p.Elem().Set(reflect.ValueOf(v))
+	if err := strip(p.Elem(), policy, typeName(p.Elem().Type()), 0); err != nil {
+		return nil, err
+	}
+	return p.Elem().Interface(), nil
+}
+
+func typeName(t reflect.Type) string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Name() != "" {
+		return t.Name()
+	}
+	return t.String()
+}
+
+// strip strips v, an addressable value found at path.
+func strip(v reflect.Value, policy Policy, path string, depth int) error {
+	if depth > 64 {
+		return fmt.Errorf("fixture: value too deep, or cyclic, at: %s", path)
+	}
+	unserializable := false
+	switch v.Kind() {
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		unserializable = !v.IsNil()
+	case reflect.Interface:
+		unserializable = !v.IsNil() && v.Elem().Type().Implements(contextType)
+	}
+	if unserializable {
+		if policy == Fail {
+			return fmt.Errorf("fixture: value of type %s cannot be part of a fixture, at: %s", v.Type(), path)
+		}
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !v.IsNil() {
+			return strip(v.Elem(), policy, path, depth+1)
+		}
+	case reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		e := reflect.New(v.Elem().Type()).Elem()
+		e.Set(v.Elem())
+		if err := strip(e, policy, path, depth+1); err != nil {
+			return err
+		}
+		v.Set(e)
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if f := v.Type().Field(i); f.PkgPath == "" {
+				if err := strip(v.Field(i), policy, path+"."+f.Name, depth+1); err != nil {
+					return err
+				}
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := strip(v.Index(i), policy, path+"["+strconv.Itoa(i)+"]", depth+1); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			e := reflect.New(v.Type().Elem()).Elem()
+			e.Set(iter.Value())
+			if err := strip(e, policy, fmt.Sprintf("%s[%v]", path, iter.Key()), depth+1); err != nil {
+				return err
+			}
+			v.SetMapIndex(iter.Key(), e)
+		}
+	}
+	return nil
+}
+
+// WriteJSON writes the fixture of obj, made like Make, as indented JSON.
+func WriteJSON(w io.Writer, c *ccopy.Copier, obj interface{}, policy Policy) error {
+	v, err := Make(c, obj, policy)
+	if err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(v, "", "\t")
+	if err != nil {
+		return fmt.Errorf("fixture: %w", err)
+	}
+	_, err = w.Write(append(b, '\n'))
+	return err
+}
+
+// GoFile describes the Go source file of a fixture.
+type GoFile struct {
+	// Package is the name of the package of the file.
+	Package string
+	// PackagePath is the import path of the package, whose types are not qualified.
+	PackagePath string
+	// Var is the name of the variable holding the fixture.
+	Var string
+}
+
+// WriteGo writes the fixture of obj, made like Make, as a Go source file declaring a variable
+// initialized with a composite literal of the fixture.
+func WriteGo(w io.Writer, c *ccopy.Copier, obj interface{}, policy Policy, file GoFile) error {
+	v, err := Make(c, obj, policy)
+	if err != nil {
+		return err
+	}
+	lit, imports, err := ccopy.GoLiteral(v, file.PackagePath)
+	if err != nil {
+		return fmt.Errorf("fixture: %w", err)
+	}
+	var src bytes.Buffer
+	fmt.Fprintf(&src, "package %s\n\n", file.Package)
+	if len(imports) > 0 {
+		for i, p := range imports {
+			imports[i] = strconv.Quote(p)
+		}
+		fmt.Fprintf(&src, "import (\n%s\n)\n\n", strings.Join(imports, "\n"))
+	}
+	fmt.Fprintf(&src, "var %s = %s\n", file.Var, lit)
+	b, err := format.Source(src.Bytes())
+	if err != nil {
+		return fmt.Errorf("fixture: format: %w", err)
+	}
+	_, err = w.Write(b)
+	return err
+}
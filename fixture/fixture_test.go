@@ -0,0 +1,103 @@
+package fixture
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gadumitrachioaiei/ccopy"
+	"github.com/google/go-cmp/cmp"
+)
+
+type Address struct {
+	City string
+	Zip  *string
+}
+
+type Order struct {
+	ID       int64
+	Email    string `ccopy:"email"`
+	Created  time.Time
+	Address  *Address
+	Tags     []string
+	Totals   map[string]float64
+	Extra    interface{}
+	Callback func()          `json:"-"`
+	Done     chan bool       `json:"-"`
+	Ctx      context.Context `json:"-"`
+}
+
+func order() Order {
+	zip := "12345"
+	return Order{
+		ID:       7,
+		Email:    "bob@example.com",
+		Created:  time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC),
+		Address:  &Address{City: "Lyon", Zip: &zip},
+		Tags:     []string{"vip"},
+		Totals:   map[string]float64{"eur": 10, "usd": 11.5},
+		Extra:    int32(3),
+		Callback: func() {},
+		Done:     make(chan bool),
+		Ctx:      context.Background(),
+	}
+}
+
+func copier(t *testing.T) *ccopy.Copier {
+	c, err := ccopy.NewCopier(ccopy.Config{"email": func(string) string { return "user@example.com" }}, ccopy.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestMake(t *testing.T) {
+	v, err := Make(copier(t), order(), Strip)
+	if err != nil {
+		t.Fatal(err)
+	}
+	o := v.(Order)
+	if o.Callback != nil || o.Done != nil || o.Ctx != nil || o.Email != "user@example.com" || *o.Address.Zip != "12345" {
+		t.Fatalf("got %+v, expected a stripped and anonymized copy", o)
+	}
+	_, err = Make(copier(t), []Order{{}, order()}, Fail)
+	if err == nil || !strings.Contains(err.Error(), "at: []fixture.Order[1].Callback") {
+		t.Fatalf("got %v, expected an error for the callback", err)
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, copier(t), order(), Strip); err != nil {
+		t.Fatal(err)
+	}
+	var o Order
+	if err := json.Unmarshal(buf.Bytes(), &o); err != nil {
+		t.Fatal(err)
+	}
+	if o.Email != "user@example.com" || o.Address.City != "Lyon" {
+		t.Fatalf("got %+v, expected the fixture", o)
+	}
+}
+
+func TestWriteGo(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteGo(&buf, copier(t), order(), Strip, GoFile{Package: "fixture", PackagePath: "github.com/gadumitrachioaiei/ccopy/fixture", Var: "order1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `package fixture
+
+import (
+	"time"
+)
+
+var order1 = Order{ID: 7, Email: "user@example.com", Created: time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC), Address: &Address{City: "Lyon", Zip: func() *string { v := "12345"; return &v }()}, Tags: []string{"vip"}, Totals: map[string]float64{"eur": 10.0, "usd": 11.5}, Extra: int32(3)}
+`
+	if diff := cmp.Diff(buf.String(), expected); diff != "" {
+		t.Fatal(diff)
+	}
+}
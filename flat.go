@@ -0,0 +1,43 @@
+package ccopy
+
+import "reflect"
+
+// flatType reports whether the values of type t can be copied by assignment, with all their fields,
+// exported or not: t holds no pointers, no tags, no types with registered handlers or customizers,
+// no unions, no collections and no types that rules start from.
+func (p *plans) flatType(t reflect.Type) bool {
+	if _, ok := typeHandler(t); ok || unionOf(t) != nil || collectionOf(t) != nil {
+		return false
+	}
+	if _, ok := p.typeCustomizers()[t]; ok {
+		return false
+	}
+	if t.PkgPath() != "" && t.Name() != "" {
+		registered, _ := registeredRules.v.Load().([]*rule)
+		for _, rules := range [][]*rule{p.rules, registered} {
+			for _, r := range rules {
+				if r.anchors(t) {
+					return false
+				}
+			}
+		}
+	}
+	switch t.Kind() {
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		return true
+	case reflect.Array:
+		return p.flatType(t.Elem())
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if _, ok := f.Tag.Lookup(tagCcopy); ok || !p.flatType(f.Type) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+package ccopy
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type flatPoint struct {
+	X, y int
+}
+
+type flatShape struct {
+	Corners [2]flatPoint
+	id      uint32
+	Visible bool
+}
+
+type flatLabeled struct {
+	Point flatPoint
+	Name  string
+}
+
+type flatTagged struct {
+	Score int `ccopy:"double"`
+	rank  int
+}
+
+func TestFlatStructs(t *testing.T) {
+	type T struct {
+		Shape   flatShape
+		Labeled flatLabeled
+		Tagged  flatTagged
+		Shapes  []flatShape
+	}
+	obj := T{
+		Shape:   flatShape{Corners: [2]flatPoint{{1, 2}, {3, 4}}, id: 7, Visible: true},
+		Labeled: flatLabeled{Point: flatPoint{5, 6}, Name: "a"},
+		Tagged:  flatTagged{Score: 1, rank: 2},
+		Shapes:  []flatShape{{id: 8}},
+	}
+	config := Config{"double": func(i int) int { return 2 * i }}
+	c, err := NewCopier(config, Options{FlatStructs: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, warnings, err := c.CopyWarnings(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := T{
+		Shape:   obj.Shape,
+		Labeled: flatLabeled{Point: flatPoint{5, 6}, Name: "a"},
+		Tagged:  flatTagged{Score: 2},
+		Shapes:  []flatShape{{id: 8}},
+	}
+	if diff := cmp.Diff(res, expected, cmp.AllowUnexported(flatPoint{}, flatShape{}, flatTagged{})); diff != "" {
+		t.Fatal(diff)
+	}
+	if len(warnings) != 1 || warnings[0].Field != "rank" {
+		t.Fatalf("got %v, expected a warning for the tagged struct only", warnings)
+	}
+
+	// rules starting from a type disable its flat copy
+	c, err = NewCopier(config, Options{FlatStructs: true, Rules: Rules{"flatPoint.X": "double"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err = c.Copy(obj.Shape)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := res.(flatShape); s.Corners[1].X != 6 || s.id != 0 {
+		t.Fatalf("got %v, expected the rule to apply", s)
+	}
+}
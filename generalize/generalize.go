@@ -0,0 +1,110 @@
+// Package generalize provides ccopy customizers that coarsen numbers and durations,
+// for exports used in aggregate analytics: values are rounded to buckets or clamped to ranges.
+//
+// The customizers are generic, so they work on any numeric type, including time.Duration:
+// generalize.Floor(time.Hour) truncates durations to whole hours.
+package generalize
+
+import (
+	"math"
+	"sort"
+)
+
+// Number is the constraint of the types of customized values.
+type Number interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr |
+		~float32 | ~float64
+}
+
+func isFloat[T Number]() bool {
+	var one T = 1
+	return one/2 != 0
+}
+
+func isSigned[T Number]() bool {
+	var zero T
+	return zero-1 < zero
+}
+
+// floor returns the largest multiple of size lower than or equal to v.
+func floor[T Number](v, size T) T {
+	switch {
+	case isFloat[T]():
+		return T(math.Floor(float64(v)/float64(size)) * float64(size))
+	case isSigned[T]():
+		q := int64(v) / int64(size)
+		if int64(v)%int64(size) < 0 {
+			q--
+		}
+		return T(q * int64(size))
+	}
+	return T(uint64(v) / uint64(size) * uint64(size))
+}
+
+func mustPositive[T Number](size T) {
+	if size <= 0 {
+		panic("generalize: bucket size must be positive")
+	}
+}
+
+// Floor returns a customizer replacing a value with the lower bound of its bucket of the given size,
+// for example with size 10 both 31 and 39 become 30.
+// It panics if size is not positive.
+func Floor[T Number](size T) func(T) T {
+	mustPositive(size)
+	return func(v T) T {
+		return floor(v, size)
+	}
+}
+
+// Round returns a customizer replacing a value with the nearest multiple of size, with halves rounded up,
+// for example with size 10, 34 becomes 30 and 35 becomes 40.
+// It panics if size is not positive.
+func Round[T Number](size T) func(T) T {
+	mustPositive(size)
+	return func(v T) T {
+		low := floor(v, size)
+		if v-low >= size-(v-low) {
+			return low + size
+		}
+		return low
+	}
+}
+
+// Clamp returns a customizer replacing the values lower than min with min, and the values greater than max with max,
+// so that outliers cannot single out anyone.
+// It panics if min is greater than max.
+func Clamp[T Number](min, max T) func(T) T {
+	if min > max {
+		panic("generalize: clamp min greater than max")
+	}
+	return func(v T) T {
+		switch {
+		case v < min:
+			return min
+		case v > max:
+			return max
+		}
+		return v
+	}
+}
+
+// Ranges returns a customizer replacing a value with the lower bound of the range it falls in,
+// the ranges being delimited by the given bounds, for example the age ranges 0, 18, 25, 35, 50 and 65.
+// Values lower than the first bound become the first bound.
+// It panics if no bound is given.
+func Ranges[T Number](bounds ...T) func(T) T {
+	if len(bounds) == 0 {
+		panic("generalize: no range bounds")
+	}
+	bounds = append([]T(nil), bounds...)
+	sort.Slice(bounds, func(i, j int) bool { return bounds[i] < bounds[j] })
+	return func(v T) T {
+		i := sort.Search(len(bounds), func(i int) bool { return bounds[i] > v })
+		if i == 0 {
+			return bounds[0]
+		}
+		return bounds[i-1]
+	}
+}
@@ -0,0 +1,74 @@
+package generalize
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gadumitrachioaiei/ccopy"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFloor(t *testing.T) {
+	for _, test := range []struct{ v, expected int }{{31, 30}, {39, 30}, {40, 40}, {-1, -10}, {-10, -10}, {0, 0}} {
+		if got := Floor(10)(test.v); got != test.expected {
+			t.Fatalf("got %d, expected %d for %d", got, test.expected, test.v)
+		}
+	}
+	if got := Floor[uint8](50)(249); got != 200 {
+		t.Fatalf("got %d, expected 200", got)
+	}
+	if got := Floor(0.5)(1.7); got != 1.5 {
+		t.Fatalf("got %v, expected 1.5", got)
+	}
+	if got := Floor(time.Hour)(90 * time.Minute); got != time.Hour {
+		t.Fatalf("got %v, expected 1h", got)
+	}
+}
+
+func TestRound(t *testing.T) {
+	for _, test := range []struct{ v, expected int64 }{{34, 30}, {35, 40}, {-34, -30}, {-36, -40}} {
+		if got := Round[int64](10)(test.v); got != test.expected {
+			t.Fatalf("got %d, expected %d for %d", got, test.expected, test.v)
+		}
+	}
+	if got := Round(15 * time.Minute)(38 * time.Minute); got != 45*time.Minute {
+		t.Fatalf("got %v, expected 45m", got)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected a panic for a bucket of size 0")
+		}
+	}()
+	Round(0)
+}
+
+func TestClampRanges(t *testing.T) {
+	clamp := Clamp(18, 90)
+	for _, test := range []struct{ v, expected int }{{5, 18}, {40, 40}, {101, 90}} {
+		if got := clamp(test.v); got != test.expected {
+			t.Fatalf("got %d, expected %d for %d", got, test.expected, test.v)
+		}
+	}
+	ages := Ranges(65, 0, 18, 25, 35, 50)
+	for _, test := range []struct{ v, expected int }{{-1, 0}, {17, 0}, {18, 18}, {40, 35}, {99, 65}} {
+		if got := ages(test.v); got != test.expected {
+			t.Fatalf("got %d, expected %d for %d", got, test.expected, test.v)
+		}
+	}
+}
+
+func TestCopy(t *testing.T) {
+	type Session struct {
+		Age      int           `ccopy:"age"`
+		Duration time.Duration `ccopy:"duration"`
+		Amount   float64       `ccopy:"amount"`
+	}
+	config := ccopy.Config{"age": Ranges(0, 18, 30, 50), "duration": Round(time.Minute), "amount": Clamp(0.0, 1000)}
+	res, err := config.Copy(Session{Age: 42, Duration: 95 * time.Second, Amount: 25000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(res, Session{Age: 30, Duration: 2 * time.Minute, Amount: 1000}); diff != "" {
+		t.Fatal(diff)
+	}
+}
@@ -0,0 +1,117 @@
+// Package geo provides ccopy customizers fuzzing geographic coordinates,
+// by snapping them to a grid or moving them by a bounded random distance.
+//
+// A Transform works on latitude and longitude pairs. It customizes structs holding both,
+// with Fields, or single coordinate fields, with Lat and Lng.
+// The results are always valid coordinates: latitudes are clamped to [-90, 90]
+// and longitudes wrapped to [-180, 180).
+package geo
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"reflect"
+)
+
+// metersPerDegree is the length of a degree of latitude, and of longitude at the equator.
+const metersPerDegree = 111320
+
+// Transform fuzzes a latitude and longitude pair, in degrees.
+type Transform func(lat, lng float64) (float64, float64)
+
+// Grid snaps coordinates to the center of their cell in a grid of cells of the given size in degrees,
+// for example 0.01 for cells of about a kilometer.
+// It panics if cell is not positive.
+func Grid(cell float64) Transform {
+	if cell <= 0 {
+		panic("geo: grid cell must be positive")
+	}
+	snap := func(v float64) float64 { return (math.Floor(v/cell) + 0.5) * cell }
+	return func(lat, lng float64) (float64, float64) {
+		return bounded(snap(lat), snap(lng))
+	}
+}
+
+// Jitter moves coordinates in a random direction, by a random distance up to maxMeters, using r,
+// like the generator of a ccopy.Session.
+func Jitter(r *rand.Rand, maxMeters float64) Transform {
+	return func(lat, lng float64) (float64, float64) {
+		// uniform in the disc
+		d := maxMeters * math.Sqrt(r.Float64())
+		angle := 2 * math.Pi * r.Float64()
+		dLat := d * math.Cos(angle) / metersPerDegree
+		dLng := 0.0
+		if c := math.Cos(lat * math.Pi / 180); c > 1e-9 {
+			dLng = d * math.Sin(angle) / (metersPerDegree * c)
+		}
+		return bounded(lat+dLat, lng+dLng)
+	}
+}
+
+// bounded clamps lat and wraps lng to valid coordinates.
+func bounded(lat, lng float64) (float64, float64) {
+	lat = math.Max(-90, math.Min(90, lat))
+	lng = math.Mod(lng+180, 360)
+	if lng < 0 {
+		lng += 360
+	}
+	return lat, lng - 180
+}
+
+// Lat returns a customizer of latitudes alone, fuzzed as if their longitude were 0.
+func (t Transform) Lat() func(float64) float64 {
+	return func(lat float64) float64 {
+		lat, _ = t(lat, 0)
+		return lat
+	}
+}
+
+// Lng returns a customizer of longitudes alone, fuzzed as if they were at the equator,
+// which for Jitter is the largest move in degrees.
+func (t Transform) Lng() func(float64) float64 {
+	return func(lng float64) float64 {
+		_, lng = t(0, lng)
+		return lng
+	}
+}
+
+// Fields returns a customizer of structs of type T, or pointers to them, fuzzing together
+// their float fields named lat and lng, like Fields[Location](t, "Latitude", "Longitude").
+// The other fields are kept, without being deep copied.
+// It panics if T does not have such fields.
+func Fields[T any](t Transform, lat, lng string) func(T) T {
+	rt := reflect.TypeOf((*T)(nil)).Elem()
+	st := rt
+	if st.Kind() == reflect.Ptr {
+		st = st.Elem()
+	}
+	index := func(name string) []int {
+		f, ok := st.FieldByName(name)
+		if st.Kind() != reflect.Struct || !ok || f.Type.Kind() != reflect.Float64 && f.Type.Kind() != reflect.Float32 {
+			panic(fmt.Sprintf("geo: %s has no float field %s", rt, name))
+		}
+		return f.Index
+	}
+	latIndex, lngIndex := index(lat), index(lng)
+	return func(v T) T {
+		rv := reflect.ValueOf(&v).Elem()
+		if rt.Kind() == reflect.Ptr {
+			if rv.IsNil() {
+				return v
+			}
+			p := reflect.New(st)
+			p.Elem().Set(rv.Elem())
+			rv.Set(p)
+		}
+		s := rv
+		if rt.Kind() == reflect.Ptr {
+			s = rv.Elem()
+		}
+		la, ln := s.FieldByIndex(latIndex), s.FieldByIndex(lngIndex)
+		newLat, newLng := t(la.Float(), ln.Float())
+		la.SetFloat(newLat)
+		ln.SetFloat(newLng)
+		return v
+	}
+}
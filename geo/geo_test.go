@@ -0,0 +1,84 @@
+package geo
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/gadumitrachioaiei/ccopy"
+)
+
+type Location struct {
+	Name      string
+	Latitude  float64
+	Longitude float64
+}
+
+func TestGrid(t *testing.T) {
+	lat, lng := Grid(0.1)(48.8584, 2.2945)
+	if math.Abs(lat-48.85) > 1e-9 || math.Abs(lng-2.25) > 1e-9 {
+		t.Fatalf("got %v, %v, expected 48.85, 2.25", lat, lng)
+	}
+	a, b := Grid(0.1)(48.81, 2.29)
+	if a != lat || b != lng {
+		t.Fatalf("got %v, %v, expected the same cell as %v, %v", a, b, lat, lng)
+	}
+	lat, lng = Grid(1)(89.9, 179.9)
+	if lat != 89.5 || lng != 179.5 {
+		t.Fatalf("got %v, %v, expected 89.5, 179.5", lat, lng)
+	}
+}
+
+func TestJitter(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	j := Jitter(r, 500)
+	for _, p := range [][2]float64{{48.8584, 2.2945}, {89.9999, 179.9999}, {-89.9999, -180}} {
+		for i := 0; i < 100; i++ {
+			lat, lng := j(p[0], p[1])
+			if lat < -90 || lat > 90 || lng < -180 || lng >= 180 {
+				t.Fatalf("got %v, %v, expected valid coordinates", lat, lng)
+			}
+			if math.Abs(p[0]) < 80 && distance(p[0], p[1], lat, lng) > 500.001 {
+				t.Fatalf("got %v, %v, expected at most 500m from %v", lat, lng, p)
+			}
+		}
+	}
+}
+
+// distance returns the distance in meters between two close points, on the equirectangular projection.
+func distance(lat1, lng1, lat2, lng2 float64) float64 {
+	x := (lng2 - lng1) * math.Cos((lat1+lat2)/2*math.Pi/180)
+	y := lat2 - lat1
+	return math.Sqrt(x*x+y*y) * metersPerDegree
+}
+
+func TestCopy(t *testing.T) {
+	type Visit struct {
+		Place    Location  `ccopy:"location"`
+		Previous *Location `ccopy:"previous"`
+		Lat      float64   `ccopy:"lat"`
+		Lng      float64   `ccopy:"lng"`
+	}
+	grid := Grid(0.1)
+	config := ccopy.Config{
+		"location": Fields[Location](grid, "Latitude", "Longitude"),
+		"previous": Fields[*Location](grid, "Latitude", "Longitude"),
+		"lat":      grid.Lat(),
+		"lng":      grid.Lng(),
+	}
+	previous := &Location{Name: "b", Latitude: 1.01, Longitude: 1.01}
+	res, err := config.Copy(Visit{Place: Location{"a", 48.8584, 2.2945}, Previous: previous, Lat: 10.01, Lng: -10.01})
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := res.(Visit)
+	if v.Place.Name != "a" || math.Abs(v.Place.Latitude-48.85) > 1e-9 || math.Abs(v.Place.Longitude-2.25) > 1e-9 {
+		t.Fatalf("got %v, expected the place to be snapped", v.Place)
+	}
+	if v.Previous == previous || math.Abs(v.Previous.Latitude-1.05) > 1e-9 || previous.Latitude != 1.01 {
+		t.Fatalf("got %v, expected a snapped copy of the previous location", v.Previous)
+	}
+	if math.Abs(v.Lat-10.05) > 1e-9 || math.Abs(v.Lng+10.05) > 1e-9 {
+		t.Fatalf("got %v, %v, expected 10.05, -10.05", v.Lat, v.Lng)
+	}
+}
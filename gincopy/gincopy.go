@@ -0,0 +1,22 @@
+// Package gincopy renders JSON responses of github.com/gin-gonic/gin handlers customized by ccopy Copiers,
+// so the values are redacted when they are rendered, whatever the handler.
+package gincopy
+
+import (
+	"net/http"
+
+	"github.com/gadumitrachioaiei/ccopy"
+	"github.com/gin-gonic/gin"
+)
+
+// JSON renders the copy of obj made by profile as JSON, like c.JSON.
+// If the copy fails, obj is not rendered: the error is added to c and the request aborted with status 500.
+func JSON(c *gin.Context, code int, obj interface{}, profile *ccopy.Copier) {
+	v, err := profile.Copy(obj)
+	if err != nil {
+		c.Error(err)
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	c.JSON(code, v)
+}
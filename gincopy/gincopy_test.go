@@ -0,0 +1,36 @@
+package gincopy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gadumitrachioaiei/ccopy"
+	"github.com/gin-gonic/gin"
+)
+
+type user struct {
+	Name  string
+	Email string `ccopy:"email"`
+}
+
+func TestJSON(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	profile, err := ccopy.NewCopier(ccopy.Config{"email": func(string) string { return "redacted" }}, ccopy.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	JSON(c, http.StatusCreated, user{Name: "Ann", Email: "ann@example.com"}, profile)
+	if rec.Code != http.StatusCreated || rec.Body.String() != `{"Name":"Ann","Email":"redacted"}` {
+		t.Fatalf("got: %d %s", rec.Code, rec.Body)
+	}
+
+	rec = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(rec)
+	JSON(c, http.StatusOK, user{Email: "ann@example.com"}, &ccopy.Copier{})
+	if rec.Code != http.StatusInternalServerError || rec.Body.Len() != 0 || len(c.Errors) != 1 {
+		t.Fatalf("got: %d %s %v", rec.Code, rec.Body, c.Errors)
+	}
+}
@@ -0,0 +1,272 @@
+package ccopy
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GoString returns a Go expression evaluating to the copy of obj made with c, like a composite literal.
+// Unlike the %#v format, it writes the values of pointers instead of their addresses, sorts map keys,
+// writes time.Time values as calls of time.Date and leaves zero fields out, so the result compiles
+// and can be pasted in table driven tests, as the input of a test case made from real data.
+// Named types are qualified by the names of their packages, and unexported fields are left out.
+func GoString(obj interface{}, c Config) (string, error) {
+	return c.copier().GoString(obj)
+}
+
+// GoString returns a Go expression evaluating to the copy of obj, like GoString.
+func (c *Copier) GoString(obj interface{}) (string, error) {
+	v, err := c.Copy(obj)
+	if err != nil {
+		return "", err
+	}
+	s, _, err := GoLiteral(v, "")
+	return s, err
+}
+
+// GoLiteral returns a Go expression evaluating to v, written like GoString but without copying v first,
+// and the import paths of the packages it uses.
+// The types of the package of path pkgPath are not qualified, and their unexported fields are written,
+// for the expression to be part of a source file of that package.
+func GoLiteral(v interface{}, pkgPath string) (string, []string, error) {
+	l := &literal{local: pkgPath}
+	if v == nil {
+		return "nil", nil, nil
+	}
+	if err := l.value(reflect.ValueOf(v), untyped, 0); err != nil {
+		return "", nil, err
+	}
+	var imports []string
+	for p := range l.imports {
+		imports = append(imports, p)
+	}
+	sort.Strings(imports)
+	return l.b.String(), imports, nil
+}
+
+// literal writes Go composite literals, qualifying the named types by their package names,
+// except the types of the package of path local.
+type literal struct {
+	b     strings.Builder
+	local string
+	// imports are the paths of the packages used by the literal
+	imports map[string]bool
+}
+
+func (l *literal) use(path string) {
+	if l.imports == nil {
+		l.imports = make(map[string]bool)
+	}
+	l.imports[path] = true
+}
+
+func (l *literal) typeName(t reflect.Type) string {
+	if t.Name() != "" {
+		if t.PkgPath() == "" || t.PkgPath() == l.local {
+			return t.Name()
+		}
+		l.use(t.PkgPath())
+		return t.String()
+	}
+	switch t.Kind() {
+	case reflect.Ptr:
+		return "*" + l.typeName(t.Elem())
+	case reflect.Slice:
+		return "[]" + l.typeName(t.Elem())
+	case reflect.Array:
+		return fmt.Sprintf("[%d]%s", t.Len(), l.typeName(t.Elem()))
+	case reflect.Map:
+		return "map[" + l.typeName(t.Key()) + "]" + l.typeName(t.Elem())
+	case reflect.Interface:
+		if t.NumMethod() == 0 {
+			return "interface{}"
+		}
+	}
+	return t.String()
+}
+
+// position is where a literal is written.
+type position int
+
+const (
+	// untyped is for literals whose type is not known from the context, like the values of interfaces.
+	untyped position = iota
+	// typed is for literals of a known type, like struct fields, where constants need no conversion.
+	typed
+	// elided is for the elements of composite literals, which can also omit the types of composite literals.
+	elided
+)
+
+// value writes the literal of v, at position ctx.
+func (l *literal) value(v reflect.Value, ctx position, depth int) error {
+	if depth > 64 {
+		return fmt.Errorf("ccopy: value too deep, or cyclic, at: %s", v.Type())
+	}
+	t := v.Type()
+	if t == timeType {
+		tm := v.Interface().(time.Time)
+		l.use("time")
+		loc := "time.UTC"
+		if tm.Location() != time.UTC {
+			loc = "time.Local"
+		}
+		fmt.Fprintf(&l.b, "time.Date(%d, %d, %d, %d, %d, %d, %d, %s)", tm.Year(), tm.Month(), tm.Day(), tm.Hour(), tm.Minute(), tm.Second(), tm.Nanosecond(), loc)
+		return nil
+	}
+	switch t.Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128, reflect.String:
+		return l.basic(v, ctx)
+	case reflect.Ptr:
+		if v.IsNil() {
+			l.b.WriteString("nil")
+			return nil
+		}
+		switch t.Elem().Kind() {
+		case reflect.Struct, reflect.Slice, reflect.Array, reflect.Map:
+			if t.Elem() != timeType {
+				l.b.WriteString("&")
+				return l.value(v.Elem(), untyped, depth+1)
+			}
+		}
+		// pointers to other values need a variable
+		fmt.Fprintf(&l.b, "func() %s { v := ", l.typeName(t))
+		if err := l.value(v.Elem(), untyped, depth+1); err != nil {
+			return err
+		}
+		l.b.WriteString("; return &v }()")
+		return nil
+	case reflect.Interface:
+		if v.IsNil() {
+			l.b.WriteString("nil")
+			return nil
+		}
+		return l.value(v.Elem(), untyped, depth+1)
+	case reflect.Slice:
+		if v.IsNil() {
+			l.b.WriteString("nil")
+			return nil
+		}
+		fallthrough
+	case reflect.Array:
+		if ctx != elided {
+			l.b.WriteString(l.typeName(t))
+		}
+		l.b.WriteString("{")
+		for i := 0; i < v.Len(); i++ {
+			if i > 0 {
+				l.b.WriteString(", ")
+			}
+			if err := l.value(v.Index(i), elided, depth+1); err != nil {
+				return err
+			}
+		}
+		l.b.WriteString("}")
+		return nil
+	case reflect.Map:
+		if v.IsNil() {
+			l.b.WriteString("nil")
+			return nil
+		}
+		if ctx != elided {
+			l.b.WriteString(l.typeName(t))
+		}
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface()) })
+		l.b.WriteString("{")
+		for i, k := range keys {
+			if i > 0 {
+				l.b.WriteString(", ")
+			}
+			if err := l.value(k, elided, depth+1); err != nil {
+				return err
+			}
+			l.b.WriteString(": ")
+			if err := l.value(v.MapIndex(k), elided, depth+1); err != nil {
+				return err
+			}
+		}
+		l.b.WriteString("}")
+		return nil
+	case reflect.Struct:
+		if ctx != elided {
+			l.b.WriteString(l.typeName(t))
+		}
+		l.b.WriteString("{")
+		first := true
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if f.PkgPath != "" && l.local != t.PkgPath() || v.Field(i).IsZero() {
+				continue
+			}
+			if !first {
+				l.b.WriteString(", ")
+			}
+			first = false
+			l.b.WriteString(f.Name + ": ")
+			ctx := typed
+			if f.Type.Kind() == reflect.Interface {
+				ctx = untyped
+			}
+			if err := l.value(v.Field(i), ctx, depth+1); err != nil {
+				return err
+			}
+		}
+		l.b.WriteString("}")
+		return nil
+	}
+	return fmt.Errorf("ccopy: no literal for values of kind %s, of type %s", t.Kind(), t)
+}
+
+// basic writes the literal of v, of a basic kind, converted to its type unless it is the default type of the literal.
+func (l *literal) basic(v reflect.Value, ctx position) error {
+	var s string
+	// constant is set for literals that are untyped constants
+	constant := true
+	switch v.Kind() {
+	case reflect.Bool:
+		s = strconv.FormatBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		s = strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		s = strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		switch {
+		case math.IsNaN(f):
+			l.use("math")
+			s, constant = "math.NaN()", false
+		case math.IsInf(f, 0):
+			l.use("math")
+			s, constant = fmt.Sprintf("math.Inf(%d)", int(math.Copysign(1, f))), false
+		default:
+			s = strconv.FormatFloat(f, 'g', -1, v.Type().Bits())
+			if !strings.ContainsAny(s, ".eEn") {
+				s += ".0"
+			}
+		}
+	case reflect.Complex64, reflect.Complex128:
+		c := v.Complex()
+		s = fmt.Sprintf("complex(%s, %s)", strconv.FormatFloat(real(c), 'g', -1, 64), strconv.FormatFloat(imag(c), 'g', -1, 64))
+	case reflect.String:
+		s = strconv.Quote(v.String())
+	}
+	t := v.Type()
+	if ctx != untyped && constant || t.PkgPath() == "" && t.Name() == defaultBasic[t.Kind()] {
+		l.b.WriteString(s)
+		return nil
+	}
+	l.b.WriteString(l.typeName(t) + "(" + s + ")")
+	return nil
+}
+
+// defaultBasic are the default types of the literals of basic kinds.
+var defaultBasic = map[reflect.Kind]string{
+	reflect.Bool: "bool", reflect.Int: "int", reflect.Float64: "float64", reflect.String: "string", reflect.Complex128: "complex128",
+}
@@ -0,0 +1,54 @@
+package ccopy
+
+import (
+	"testing"
+	"time"
+)
+
+type golitEvent struct {
+	Name    string `ccopy:"redact"`
+	At      time.Time
+	Count   *int
+	Labels  map[string]uint8
+	Parent  *golitEvent
+	Payload interface{}
+	seq     int
+}
+
+func TestGoString(t *testing.T) {
+	n := 3
+	obj := &golitEvent{
+		Name:    "login",
+		At:      time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Count:   &n,
+		Labels:  map[string]uint8{"b": 2, "a": 1},
+		Parent:  &golitEvent{Name: "session"},
+		Payload: []float32{1.5},
+		seq:     4,
+	}
+	s, err := GoString(obj, Config{"redact": func(string) string { return "x" }})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `&ccopy.golitEvent{Name: "x", At: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), Count: func() *int { v := 3; return &v }(), ` +
+		`Labels: map[string]uint8{"a": 1, "b": 2}, Parent: &ccopy.golitEvent{Name: "x"}, Payload: []float32{1.5}}`
+	if s != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, s)
+	}
+}
+
+func TestGoLiteral(t *testing.T) {
+	s, imports, err := GoLiteral(golitEvent{At: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), seq: 4}, "github.com/gadumitrachioaiei/ccopy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `golitEvent{At: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), seq: 4}`; s != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, s)
+	}
+	if len(imports) != 1 || imports[0] != "time" {
+		t.Fatalf("expected the time import, got: %v", imports)
+	}
+	if _, _, err := GoLiteral(make(chan int), ""); err == nil {
+		t.Fatal("expected an error for a channel")
+	}
+}
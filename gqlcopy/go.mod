@@ -0,0 +1,14 @@
+module github.com/gadumitrachioaiei/ccopy/gqlcopy
+
+go 1.22.0
+
+require (
+	github.com/99designs/gqlgen v0.17.49
+	github.com/gadumitrachioaiei/ccopy v0.0.0-20261015025656-45a8b2362908
+)
+
+require (
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/sosodev/duration v1.3.1 // indirect
+	github.com/vektah/gqlparser/v2 v2.5.16 // indirect
+)
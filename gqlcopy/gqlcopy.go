@@ -0,0 +1,56 @@
+// Package gqlcopy customizes the results of GraphQL resolvers with ccopy Copiers, picked by the role of
+// the requester, as a field middleware of github.com/99designs/gqlgen, so GraphQL APIs redact the same data
+// as REST APIs, with the same configs.
+package gqlcopy
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/gadumitrachioaiei/ccopy"
+)
+
+// Middleware customizes the results of resolvers with the Copier of the role of the requester.
+// The Copiers are shared with the rest of the service, so are their plans.
+type Middleware struct {
+	// Role returns the role of the requester of a request, from its context.
+	Role func(ctx context.Context) string
+	// Profiles are the Copiers of the roles.
+	Profiles map[string]*ccopy.Copier
+	// Default is the Copier of the roles without profile. If nil, their requests fail.
+	Default *ccopy.Copier
+}
+
+// copier returns the copier of the requester of ctx.
+func (m *Middleware) copier(ctx context.Context) (*ccopy.Copier, string) {
+	var role string
+	if m.Role != nil {
+		role = m.Role(ctx)
+	}
+	if c, ok := m.Profiles[role]; ok {
+		return c, role
+	}
+	return m.Default, role
+}
+
+// Field is a graphql.FieldMiddleware, to be set with the AroundFields option of the gqlgen server.
+// It customizes the results of the fields that are resolved by resolvers or methods, which are the copies
+// of the values the resolvers return; the other fields are read from these copies, already customized,
+// so they are not copied again. Nil results are returned as they are.
+func (m *Middleware) Field(ctx context.Context, next graphql.Resolver) (interface{}, error) {
+	res, err := next(ctx)
+	if err != nil || res == nil {
+		return res, err
+	}
+	if fc := graphql.GetFieldContext(ctx); fc != nil && !fc.IsResolver && !fc.IsMethod {
+		return res, nil
+	}
+	c, role := m.copier(ctx)
+	if c == nil {
+		return nil, fmt.Errorf("gqlcopy: no profile for role: %q", role)
+	}
+	return c.Copy(res)
+}
+
+var _ graphql.FieldMiddleware = (&Middleware{}).Field
@@ -0,0 +1,60 @@
+package gqlcopy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/gadumitrachioaiei/ccopy"
+)
+
+type user struct {
+	Name  string
+	Email string `ccopy:"email"`
+}
+
+type roleKey struct{}
+
+func TestMiddleware(t *testing.T) {
+	admin, err := ccopy.NewCopier(ccopy.Config{"email": func(s string) string { return s }}, ccopy.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	public, err := ccopy.NewCopier(ccopy.Config{"email": func(string) string { return "redacted" }}, ccopy.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := &Middleware{
+		Role:     func(ctx context.Context) string { role, _ := ctx.Value(roleKey{}).(string); return role },
+		Profiles: map[string]*ccopy.Copier{"admin": admin},
+		Default:  public,
+	}
+	u := &user{Name: "Ann", Email: "ann@example.com"}
+	resolver := func(context.Context) (interface{}, error) { return u, nil }
+	field := func(ctx context.Context, isResolver bool) context.Context {
+		return graphql.WithFieldContext(ctx, &graphql.FieldContext{IsResolver: isResolver})
+	}
+	for _, test := range []struct {
+		ctx      context.Context
+		expected string
+	}{
+		{field(context.WithValue(context.Background(), roleKey{}, "admin"), true), "ann@example.com"},
+		{field(context.WithValue(context.Background(), roleKey{}, "guest"), true), "redacted"},
+		{field(context.Background(), false), "ann@example.com"},
+	} {
+		res, err := m.Field(test.ctx, resolver)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := res.(*user); got.Email != test.expected || got.Name != "Ann" {
+			t.Fatalf("expected email: %s, got: %+v", test.expected, got)
+		}
+	}
+	if u.Email != "ann@example.com" {
+		t.Fatalf("the result of the resolver was modified: %+v", u)
+	}
+	m.Default = nil
+	if _, err := m.Field(field(context.Background(), true), resolver); err == nil {
+		t.Fatal("expected an error without profile")
+	}
+}
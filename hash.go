@@ -0,0 +1,124 @@
+package ccopy
+
+import (
+	"encoding/binary"
+	"hash"
+	"hash/fnv"
+	"math"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// Hash returns a structural hash of the copy of obj made with c, so snapshots can be deduplicated,
+// or compared with earlier ones, without serializing them.
+// The hash depends only on the copied values: maps hash the same whatever their iteration order,
+// all NaN values hash the same, and time.Time values hash by their instant, whatever their location.
+// Pointers hash by the values they point to, and functions and channels by whether they are nil.
+// The hash is stable across processes for the same types and values, but not across type renames.
+func Hash(obj interface{}, c Config) (uint64, error) {
+	return c.copier().Hash(obj)
+}
+
+// Hash returns a structural hash of the copy of obj, like Hash.
+func (c *Copier) Hash(obj interface{}) (uint64, error) {
+	v, err := c.Copy(obj)
+	if err != nil {
+		return 0, err
+	}
+	h := &hasher{Hash64: fnv.New64a()}
+	h.value(reflect.ValueOf(v))
+	return h.Sum64(), nil
+}
+
+type hasher struct {
+	hash.Hash64
+	buf [8]byte
+}
+
+func (h *hasher) uint(u uint64) {
+	binary.LittleEndian.PutUint64(h.buf[:], u)
+	h.Write(h.buf[:])
+}
+
+func (h *hasher) string(s string) {
+	h.uint(uint64(len(s)))
+	h.Write([]byte(s))
+}
+
+func (h *hasher) float(f float64) {
+	if math.IsNaN(f) {
+		f = math.NaN()
+	}
+	h.uint(math.Float64bits(f))
+}
+
+func (h *hasher) value(v reflect.Value) {
+	if !v.IsValid() {
+		h.uint(0)
+		return
+	}
+	h.uint(uint64(v.Kind()))
+	if v.Type() == timeType {
+		t := v.Interface().(time.Time)
+		h.uint(uint64(t.Unix()))
+		h.uint(uint64(t.Nanosecond()))
+		return
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		h.uint(uint64(boolInt(v.Bool())))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		h.uint(uint64(v.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		h.uint(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		h.float(v.Float())
+	case reflect.Complex64, reflect.Complex128:
+		h.float(real(v.Complex()))
+		h.float(imag(v.Complex()))
+	case reflect.String:
+		h.string(v.String())
+	case reflect.Ptr, reflect.Interface:
+		h.uint(uint64(boolInt(v.IsNil())))
+		if !v.IsNil() {
+			if v.Kind() == reflect.Interface {
+				h.string(v.Elem().Type().String())
+			}
+			h.value(v.Elem())
+		}
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		h.uint(uint64(boolInt(v.IsNil())))
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice {
+			h.uint(uint64(boolInt(v.IsNil())))
+		}
+		h.uint(uint64(v.Len()))
+		for i := 0; i < v.Len(); i++ {
+			h.value(v.Index(i))
+		}
+	case reflect.Map:
+		h.uint(uint64(boolInt(v.IsNil())))
+		// the entries are hashed on their own, and their hashes in order
+		sums := make([]uint64, 0, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			e := &hasher{Hash64: fnv.New64a()}
+			e.value(iter.Key())
+			e.value(iter.Value())
+			sums = append(sums, e.Sum64())
+		}
+		sort.Slice(sums, func(i, j int) bool { return sums[i] < sums[j] })
+		h.uint(uint64(len(sums)))
+		for _, s := range sums {
+			h.uint(s)
+		}
+	case reflect.Struct:
+		t := v.Type()
+		h.uint(uint64(t.NumField()))
+		for i := 0; i < t.NumField(); i++ {
+			h.string(t.Field(i).Name)
+			h.value(v.Field(i))
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package ccopy
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+type hashSnapshot struct {
+	Owner  string `ccopy:"owner"`
+	Taken  time.Time
+	Counts map[string]float64
+	Parent *hashSnapshot
+}
+
+func TestHash(t *testing.T) {
+	config := Config{"owner": func(string) string { return "x" }}
+	taken := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	obj := hashSnapshot{Owner: "alice", Taken: taken, Counts: map[string]float64{"a": 1, "b": math.NaN()}, Parent: &hashSnapshot{}}
+	h1, err := Hash(obj, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// same copy: customized field, other location, other map, other NaN
+	same := hashSnapshot{Owner: "bob", Taken: taken.In(time.FixedZone("X", 3600)), Counts: map[string]float64{"b": -math.NaN(), "a": 1}, Parent: &hashSnapshot{}}
+	if h2, err := Hash(same, config); err != nil || h2 != h1 {
+		t.Fatalf("expected the same hash %d, got: %d, %v", h1, h2, err)
+	}
+	for _, other := range []hashSnapshot{
+		{Taken: taken, Counts: map[string]float64{"a": 2, "b": math.NaN()}, Parent: &hashSnapshot{}},
+		{Owner: "bob", Taken: taken.Add(time.Nanosecond), Counts: map[string]float64{"a": 1, "b": math.NaN()}, Parent: &hashSnapshot{}},
+		{Owner: "bob", Taken: taken, Counts: map[string]float64{"a": 1, "c": math.NaN()}, Parent: &hashSnapshot{}},
+		{Owner: "bob", Taken: taken, Counts: map[string]float64{"a": 1, "b": math.NaN()}},
+	} {
+		if h, err := Hash(other, config); err != nil || h == h1 {
+			t.Fatalf("expected another hash than %d for %+v, got: %d, %v", h1, other, h, err)
+		}
+	}
+	if _, err := Hash(obj, Config{}); err == nil {
+		t.Fatal("expected the error of the copy")
+	}
+}
@@ -0,0 +1,142 @@
+package httpcopy
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/gadumitrachioaiei/ccopy"
+)
+
+// Request holds the parts of an http.Request worth logging, as plain data that copies correctly,
+// unlike an http.Request whose body is a stream and whose context and connection state cannot be copied.
+type Request struct {
+	Method        string
+	URL           *url.URL
+	Proto         string
+	Header        http.Header
+	Body          []byte
+	BodyTruncated bool
+	ContentLength int64
+	Host          string
+	RemoteAddr    string
+	RequestURI    string
+	Trailer       http.Header
+}
+
+// Response holds the parts of an http.Response worth logging, like Request.
+type Response struct {
+	Status        string
+	StatusCode    int
+	Proto         string
+	Header        http.Header
+	Body          []byte
+	BodyTruncated bool
+	ContentLength int64
+	Trailer       http.Header
+}
+
+// readBody reads up to limit bytes of body, and replaces it with a body reading the same bytes again,
+// so the reader of the original is not affected.
+func readBody(body *io.ReadCloser, limit int64) ([]byte, bool, error) {
+	if *body == nil || *body == http.NoBody {
+		return nil, false, nil
+	}
+	if limit < 0 {
+		return nil, false, errors.New("httpcopy: negative body limit")
+	}
+	b, err := io.ReadAll(io.LimitReader(*body, limit+1))
+	if err != nil {
+		return nil, false, err
+	}
+	*body = readCloser{Reader: io.MultiReader(bytes.NewReader(b), *body), Closer: *body}
+	if int64(len(b)) > limit {
+		return b[:limit], true, nil
+	}
+	return b, false, nil
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// NewRequest returns the copy with c of the Request of r, with at most limit bytes of its body.
+// The body of r is read, and replaced with a body returning the same data, for the handlers of r.
+// The preset of this package customizes it with:
+//
+//	c, err := ccopy.NewCopier(httpcopy.Config(), ccopy.Options{Rules: httpcopy.Rules()})
+func NewRequest(c *ccopy.Copier, r *http.Request, limit int64) (Request, error) {
+	body, truncated, err := readBody(&r.Body, limit)
+	if err != nil {
+		return Request{}, err
+	}
+	req := Request{
+		Method: r.Method, URL: r.URL, Proto: r.Proto, Header: r.Header, Body: body, BodyTruncated: truncated,
+		ContentLength: r.ContentLength, Host: r.Host, RemoteAddr: r.RemoteAddr, RequestURI: r.RequestURI, Trailer: r.Trailer,
+	}
+	v, err := c.Copy(req)
+	if err != nil {
+		return Request{}, err
+	}
+	return v.(Request), nil
+}
+
+// NewResponse returns the copy with c of the Response of resp, with at most limit bytes of its body, like NewRequest.
+func NewResponse(c *ccopy.Copier, resp *http.Response, limit int64) (Response, error) {
+	body, truncated, err := readBody(&resp.Body, limit)
+	if err != nil {
+		return Response{}, err
+	}
+	res := Response{
+		Status: resp.Status, StatusCode: resp.StatusCode, Proto: resp.Proto, Header: resp.Header, Body: body,
+		BodyTruncated: truncated, ContentLength: resp.ContentLength, Trailer: resp.Trailer,
+	}
+	v, err := c.Copy(res)
+	if err != nil {
+		return Response{}, err
+	}
+	return v.(Response), nil
+}
+
+// CloneRequest returns a clone of r customized by c, like NewRequest,
+// with the context of r and a body holding at most limit bytes of the body of r.
+func CloneRequest(c *ccopy.Copier, r *http.Request, limit int64) (*http.Request, error) {
+	req, err := NewRequest(c, r, limit)
+	if err != nil {
+		return nil, err
+	}
+	clone := r.Clone(r.Context())
+	clone.Method, clone.URL, clone.Proto, clone.Header = req.Method, req.URL, req.Proto, req.Header
+	clone.Host, clone.RemoteAddr, clone.RequestURI, clone.Trailer = req.Host, req.RemoteAddr, req.RequestURI, req.Trailer
+	clone.Body, clone.GetBody, clone.ContentLength = body(req.Body), nil, int64(len(req.Body))
+	clone.Form, clone.PostForm, clone.MultipartForm = nil, nil, nil
+	if clone.URL == nil {
+		clone.URL = &url.URL{}
+	}
+	return clone, nil
+}
+
+// CloneResponse returns a clone of resp customized by c, like NewResponse,
+// with a body holding at most limit bytes of the body of resp.
+// The Request and TLS state of the clone are nil, as they are not customized.
+func CloneResponse(c *ccopy.Copier, resp *http.Response, limit int64) (*http.Response, error) {
+	res, err := NewResponse(c, resp, limit)
+	if err != nil {
+		return nil, err
+	}
+	clone := *resp
+	clone.Status, clone.StatusCode, clone.Proto, clone.Header, clone.Trailer = res.Status, res.StatusCode, res.Proto, res.Header, res.Trailer
+	clone.Body, clone.ContentLength = body(res.Body), int64(len(res.Body))
+	clone.Request, clone.TLS = nil, nil
+	return &clone, nil
+}
+
+func body(b []byte) io.ReadCloser {
+	if b == nil {
+		return http.NoBody
+	}
+	return io.NopCloser(bytes.NewReader(b))
+}
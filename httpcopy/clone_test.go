@@ -0,0 +1,73 @@
+package httpcopy
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gadumitrachioaiei/ccopy"
+)
+
+func preset(t *testing.T) *ccopy.Copier {
+	c, err := ccopy.NewCopier(Config(), ccopy.Options{Rules: Rules()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestCloneRequest(t *testing.T) {
+	r := httptest.NewRequest("POST", "/login?user=bob", strings.NewReader(`{"password":"secret"}`))
+	r.Header.Set("Authorization", "Basic Ym9iOnNlY3JldA==")
+	r.Header.Set("Content-Type", "application/json")
+	clone, err := CloneRequest(preset(t), r, 12)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if clone.Header.Get("Authorization") != "" || clone.Header.Get("Content-Type") != "application/json" {
+		t.Fatalf("got %v, expected the authorization to be scrubbed", clone.Header)
+	}
+	if clone.URL.RawQuery != "user=REDACTED" || clone.RequestURI != "/login?user=REDACTED" {
+		t.Fatalf("got %v and %v, expected the query to be redacted", clone.URL, clone.RequestURI)
+	}
+	b, _ := io.ReadAll(clone.Body)
+	if string(b) != `{"password":` || clone.ContentLength != 12 {
+		t.Fatalf("got %q, expected the body to be truncated", b)
+	}
+	// the original is kept
+	if r.Header.Get("Authorization") == "" || r.URL.RawQuery != "user=bob" {
+		t.Fatalf("got %v, expected the original request to be kept", r)
+	}
+	b, _ = io.ReadAll(r.Body)
+	if string(b) != `{"password":"secret"}` {
+		t.Fatalf("got %q, expected the original body to be readable", b)
+	}
+}
+
+func TestNewResponse(t *testing.T) {
+	rec := httptest.NewRecorder()
+	http.SetCookie(rec, &http.Cookie{Name: "session", Value: "abc"})
+	rec.WriteString("hello")
+	resp := rec.Result()
+	res, err := NewResponse(preset(t), resp, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.StatusCode != 200 || res.Header.Get("Set-Cookie") != "" || string(res.Body) != "hello" || res.BodyTruncated {
+		t.Fatalf("got %+v, expected the cookie to be scrubbed", res)
+	}
+	clone, err := CloneResponse(preset(t), resp, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := io.ReadAll(clone.Body)
+	if string(b) != "he" || clone.Request != nil {
+		t.Fatalf("got %q, expected the body to be truncated", b)
+	}
+	b, _ = io.ReadAll(resp.Body)
+	if string(b) != "hello" {
+		t.Fatalf("got %q, expected the original body to be readable", b)
+	}
+}
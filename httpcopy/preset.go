@@ -0,0 +1,127 @@
+// Package httpcopy provides ccopy customizers and rules for HTTP requests and responses,
+// for logging them without credentials and personal data.
+package httpcopy
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gadumitrachioaiei/ccopy"
+)
+
+// SensitiveHeaders are the headers removed by ScrubHeader.
+var SensitiveHeaders = []string{
+	"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie",
+	"X-Api-Key", "X-Auth-Token", "X-Csrf-Token", "X-Forwarded-For", "X-Real-Ip",
+}
+
+// Redacted replaces the redacted values.
+const Redacted = "REDACTED"
+
+// ScrubHeader is a customizer of headers removing the SensitiveHeaders, truncating the User-Agent
+// to its first product, like "Mozilla/5.0", and redacting the query string of the Referer.
+func ScrubHeader(h http.Header) http.Header {
+	if h == nil {
+		return nil
+	}
+	c := h.Clone()
+	for _, name := range SensitiveHeaders {
+		c.Del(name)
+	}
+	for i, ua := range c.Values("User-Agent") {
+		c["User-Agent"][i] = UserAgent(ua)
+	}
+	for i, ref := range c.Values("Referer") {
+		c["Referer"][i] = URI(ref)
+	}
+	return c
+}
+
+// UserAgent is a customizer truncating a user agent to its first product, like "Mozilla/5.0",
+// as the details of user agents help fingerprinting users.
+func UserAgent(ua string) string {
+	if i := strings.IndexAny(ua, " ("); i >= 0 {
+		return ua[:i]
+	}
+	return ua
+}
+
+// URL is a customizer of URLs redacting the values of the query string, the user info and the fragment.
+func URL(u *url.URL) *url.URL {
+	if u == nil {
+		return nil
+	}
+	c := *u
+	c.User = nil
+	c.Fragment, c.RawFragment = "", ""
+	c.RawQuery = redactQuery(u.RawQuery)
+	return &c
+}
+
+// URI is a customizer of URLs in textual form, like a request URI or a referer, that redacts them like URL.
+// Strings that are not URLs are redacted.
+func URI(s string) string {
+	if s == "" {
+		return s
+	}
+	u, err := url.Parse(s)
+	if err != nil {
+		return Redacted
+	}
+	return URL(u).String()
+}
+
+// RemoteAddr is a customizer redacting the network address of a client, like the RemoteAddr of requests,
+// as the headers forwarding the addresses of clients are removed by ScrubHeader.
+func RemoteAddr(addr string) string {
+	if addr == "" {
+		return addr
+	}
+	return Redacted
+}
+
+// redactQuery redacts the values of the parameters of a query string, keeping their names.
+// The parameters without values, like "?token", are redacted as a whole, as they can be values themselves.
+func redactQuery(raw string) string {
+	if raw == "" {
+		return raw
+	}
+	params := strings.Split(raw, "&")
+	for i, p := range params {
+		if name, _, ok := strings.Cut(p, "="); ok {
+			params[i] = name + "=" + Redacted
+		} else if p != "" {
+			params[i] = Redacted
+		}
+	}
+	return strings.Join(params, "&")
+}
+
+// Config returns the customizers of the preset, named "http.header", "http.url", "http.uri", "http.useragent"
+// and "http.remoteaddr",
+// to be merged with the Config of a Copier using Rules.
+func Config() ccopy.Config {
+	return ccopy.Config{
+		"http.header":     ScrubHeader,
+		"http.url":        URL,
+		"http.uri":        URI,
+		"http.useragent":  UserAgent,
+		"http.remoteaddr": RemoteAddr,
+	}
+}
+
+// Rules returns the rules of the preset, customizing the headers, trailers, URLs and client addresses of the values
+// of types named Request or Response, like the Request and Response of this package,
+// with the customizers of Config.
+func Rules() ccopy.Rules {
+	return ccopy.Rules{
+		"Request.Header":     "http.header",
+		"Request.Trailer":    "http.header",
+		"Request.URL":        "http.url",
+		"Request.RequestURI": "http.uri",
+		"Request.RemoteAddr": "http.remoteaddr",
+		"Response.Header":    "http.header",
+		"Response.Trailer":   "http.header",
+	}
+}
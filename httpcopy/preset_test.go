@@ -0,0 +1,64 @@
+package httpcopy
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/gadumitrachioaiei/ccopy"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestScrubHeader(t *testing.T) {
+	h := http.Header{
+		"Authorization": {"Bearer token"},
+		"Cookie":        {"session=abc"},
+		"User-Agent":    {"Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36"},
+		"Referer":       {"https://example.com/search?q=john+doe&page=2"},
+		"Accept":        {"text/html"},
+	}
+	expected := http.Header{
+		"User-Agent": {"Mozilla/5.0"},
+		"Referer":    {"https://example.com/search?q=REDACTED&page=REDACTED"},
+		"Accept":     {"text/html"},
+	}
+	if diff := cmp.Diff(ScrubHeader(h), expected); diff != "" {
+		t.Fatal(diff)
+	}
+	if len(h) != 5 {
+		t.Fatalf("got %v, expected the original headers to be kept", h)
+	}
+}
+
+func TestRules(t *testing.T) {
+	type Request struct {
+		Method     string
+		URL        *url.URL
+		Header     http.Header
+		RequestURI string
+		RemoteAddr string
+	}
+	type Entry struct {
+		Request Request
+		Status  int
+	}
+	c, err := ccopy.NewCopier(Config(), ccopy.Options{Rules: Rules()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, _ := url.Parse("https://bob:pw@example.com/users?email=bob@example.com#top")
+	res, err := c.Copy(Entry{
+		Request: Request{Method: "GET", URL: u, Header: http.Header{"Cookie": {"a=b"}}, RequestURI: "/users?email=bob@example.com&bob", RemoteAddr: "203.0.113.7:52100"},
+		Status:  200,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := res.(Entry).Request
+	if r.Method != "GET" || r.URL.String() != "https://example.com/users?email=REDACTED" || len(r.Header) != 0 || r.RequestURI != "/users?email=REDACTED&REDACTED" || r.RemoteAddr != Redacted {
+		t.Fatalf("got %+v, expected a redacted request", r)
+	}
+	if u.User == nil {
+		t.Fatal("expected the original URL to be kept")
+	}
+}
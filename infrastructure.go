@@ -0,0 +1,72 @@
+package ccopy
+
+import (
+	"context"
+	"log"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// infrastructureTypes holds the types of the infrastructure of programs, registered with RegisterInfrastructure,
+// as a map[reflect.Type]bool replaced on every registration, as it is read for every copied value.
+var infrastructureTypes struct {
+	sync.Mutex
+	v atomic.Value
+}
+
+func init() {
+	for _, p := range []interface{}{
+		(*context.Context)(nil),
+		(*log.Logger)(nil), (*slog.Logger)(nil), (*slog.Handler)(nil),
+		(*http.Client)(nil), (*http.Transport)(nil), (*http.RoundTripper)(nil),
+	} {
+		registerInfrastructure(reflect.TypeOf(p).Elem())
+	}
+}
+
+// RegisterInfrastructure registers the type of v as a type of the infrastructure of programs, like loggers,
+// metrics or HTTP clients, whose values are shared by the copies rather than deep copied:
+// the values of the type, and the pointers to them, are copied by assignment.
+// The types context.Context, log.Logger, slog.Logger, slog.Handler, http.Client, http.Transport and http.RoundTripper
+// are registered already. Interface types are registered by a pointer to them, like (*slog.Handler)(nil),
+// and their values are shared whatever the type of the value they hold.
+// The types registered with RegisterAtomic, RegisterConverter or RegisterHandler are copied by them instead.
+// It panics if v is nil.
+func RegisterInfrastructure(v interface{}) {
+	if v == nil {
+		panic("ccopy: register infrastructure nil")
+	}
+	t := reflect.TypeOf(v)
+	if t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Interface {
+		t = t.Elem()
+	}
+	registerInfrastructure(t)
+}
+
+func registerInfrastructure(t reflect.Type) {
+	infrastructureTypes.Lock()
+	defer infrastructureTypes.Unlock()
+	old, _ := infrastructureTypes.v.Load().(map[reflect.Type]bool)
+	m := make(map[reflect.Type]bool, len(old)+1)
+	for k, v := range old {
+		m[k] = v
+	}
+	m[t] = true
+	infrastructureTypes.v.Store(m)
+}
+
+// isInfrastructure reports whether t is an infrastructure type, registered or of the Infrastructure option,
+// or a pointer to one, without a registered handler.
+func (c *Copier) isInfrastructure(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if m, _ := infrastructureTypes.v.Load().(map[reflect.Type]bool); !m[t] && !c.infrastructure[t] {
+		return false
+	}
+	_, ok := typeHandler(t)
+	return !ok
+}
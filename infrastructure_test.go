@@ -0,0 +1,85 @@
+package ccopy
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+type metricsClient struct {
+	Endpoint string
+	sent     []string
+}
+
+func TestCopyInfrastructure(t *testing.T) {
+	type Handler struct {
+		Name    string
+		Ctx     context.Context
+		Logger  *slog.Logger
+		Client  http.Client
+		Metrics *metricsClient
+		Deps    map[string]interface{}
+	}
+	type requestKey struct{}
+	ctx := context.WithValue(context.Background(), requestKey{}, "acme")
+	logger := slog.Default()
+	metrics := &metricsClient{Endpoint: "localhost"}
+	h := Handler{Name: "a", Ctx: ctx, Logger: logger, Client: http.Client{Timeout: 1}, Metrics: metrics, Deps: map[string]interface{}{"logger": logger}}
+
+	v, err := (Config{}).Copy(h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	copied := v.(Handler)
+	if copied.Ctx != ctx || copied.Logger != logger || copied.Deps["logger"] != logger || copied.Client.Timeout != 1 {
+		t.Fatalf("expected the registered infrastructure to be shared, got: %+v", copied)
+	}
+	if copied.Metrics == metrics {
+		t.Fatal("expected the metrics client to be copied")
+	}
+
+	c, err := NewCopier(Config{}, Options{Infrastructure: []reflect.Type{reflect.TypeOf(metricsClient{})}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, err = c.Copy(h); err != nil {
+		t.Fatal(err)
+	}
+	if v.(Handler).Metrics != metrics {
+		t.Fatal("expected the metrics client of the option to be shared")
+	}
+	a, err := c.ResolveAction(reflect.TypeOf(h), "Handler.Metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Kind != ActionInfrastructure {
+		t.Fatalf("got action: %v, expected infrastructure", a.Kind)
+	}
+}
+
+type auditor interface {
+	Audit(string)
+}
+
+type fileAuditor struct {
+	Path string
+}
+
+func (fileAuditor) Audit(string) {}
+
+func TestRegisterInfrastructure(t *testing.T) {
+	RegisterInfrastructure((*auditor)(nil))
+	type Service struct {
+		Auditor auditor
+	}
+	a := &fileAuditor{Path: "audit.log"}
+	v, err := (Config{}).Copy(Service{Auditor: a})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.(Service).Auditor != auditor(a) {
+		t.Fatal("expected the values of the registered interface to be shared")
+	}
+}
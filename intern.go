@@ -0,0 +1,31 @@
+package ccopy
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// validateIntern returns an error if a name of the Intern option is not in the config.
+func validateIntern(c Config, intern map[string]bool) error {
+	for name := range intern {
+		if _, ok := c[name]; !ok {
+			return fmt.Errorf("invalid intern option for %s: not a customizer of the config", name)
+		}
+	}
+	return nil
+}
+
+// internKey identifies the result of the customizer of the tag, with its arguments, for the value v, within a copy.
+type internKey struct {
+	tag string
+	v   interface{}
+}
+
+// internKeyOf returns the key of the result of the customizer named name, of the tag, for ov,
+// false if the results of the customizer are not interned, or ov cannot be a key, like the values of incomparable types.
+func (c *state) internKeyOf(name, tag string, ov reflect.Value) (internKey, bool) {
+	if !c.intern[name] || !ov.CanInterface() || !ov.Comparable() {
+		return internKey{}, false
+	}
+	return internKey{tag: tag, v: ov.Interface()}, true
+}
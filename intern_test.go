@@ -0,0 +1,56 @@
+package ccopy
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIntern(t *testing.T) {
+	type Record struct {
+		Email  string `ccopy:"hash"`
+		Backup string `ccopy:"hash"`
+		Tags   []string
+		Name   string `ccopy:"name"`
+		Alias  string `ccopy:"name"`
+	}
+	calls := map[string]int{}
+	config := Config{
+		"hash": func(s string) string { calls["hash"]++; return "h(" + s + ")" },
+		"name": func(s string) string { calls["name"]++; return "n(" + s + ")" },
+	}
+	c, err := NewCopier(config, Options{Intern: map[string]bool{"hash": true}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	records := []Record{
+		{Email: "a@b.c", Backup: "a@b.c", Name: "ann", Alias: "ann"},
+		{Email: "a@b.c", Backup: "d@e.f", Name: "ann", Alias: "bob"},
+	}
+	v, err := c.Copy(records)
+	if err != nil {
+		t.Fatal(err)
+	}
+	copied := v.([]Record)
+	if copied[1].Email != "h(a@b.c)" || copied[1].Backup != "h(d@e.f)" || copied[0].Alias != "n(ann)" {
+		t.Fatalf("unexpected copy: %+v", copied)
+	}
+	if calls["hash"] != 2 {
+		t.Fatalf("expected hash to be called once per distinct value, got %d calls", calls["hash"])
+	}
+	if calls["name"] != 4 {
+		t.Fatalf("expected name to be called for every value, got %d calls", calls["name"])
+	}
+
+	// the results are interned within a copy only
+	if _, err := c.Copy(records); err != nil {
+		t.Fatal(err)
+	}
+	if calls["hash"] != 4 {
+		t.Fatalf("expected hash to be called again by a new copy, got %d calls", calls["hash"])
+	}
+
+	_, err = NewCopier(config, Options{Intern: map[string]bool{"missing": true}})
+	if err == nil || !strings.Contains(err.Error(), "missing") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,67 @@
+package ccopy
+
+import "reflect"
+
+// CopyInto deep copies src like Copy and writes the copy into the value dst points to,
+// like a destination allocated once and reused for every copy.
+// See Copier.CopyInto for the destinations that can receive the copy.
+func (c Config) CopyInto(dst, src interface{}) error {
+	return c.copier().CopyInto(dst, src)
+}
+
+// CopyInto deep copies src like Copy and writes the copy into the value dst points to.
+// The destination can be of the type of src, or of a type the copy converts to, like a struct type
+// with the same fields and other tags, or of a struct type with some fields of the same names:
+// these are set from the fields of the copy, by assignment or conversion, and the other fields are zeroed.
+// It returns an *ErrDestination error if dst is not a non nil pointer, or if the copy cannot be written into it.
+func (c *Copier) CopyInto(dst, src interface{}) error {
+	d := reflect.ValueOf(dst)
+	if d.Kind() != reflect.Ptr || d.IsNil() {
+		return &ErrDestination{Type: reflect.TypeOf(src), Destination: reflect.TypeOf(dst)}
+	}
+	v, err := c.Copy(src)
+	if err != nil {
+		return err
+	}
+	return setInto(d.Elem(), reflect.ValueOf(v))
+}
+
+// setInto sets dst to v, converting it, or setting the fields of dst from the fields of v of the same names.
+// Conversions change the type of values, not their kind, so numbers are never converted to strings.
+// Nothing is set if some field cannot be.
+func setInto(dst, v reflect.Value) error {
+	t := dst.Type()
+	switch {
+	case v.Type().AssignableTo(t):
+		dst.Set(v)
+		return nil
+	case v.Type().ConvertibleTo(t) && v.Kind() == t.Kind():
+		dst.Set(v.Convert(t))
+		return nil
+	case v.Kind() != reflect.Struct || t.Kind() != reflect.Struct:
+		return &ErrDestination{Type: v.Type(), Destination: reflect.PtrTo(t)}
+	}
+	// sources holds the indexes of the fields of v setting the fields of dst, -1 for the fields zeroed
+	sources := make([]int, t.NumField())
+	for i := range sources {
+		sources[i] = -1
+		f := t.Field(i)
+		sf, ok := v.Type().FieldByName(f.Name)
+		if !f.IsExported() || !ok || !sf.IsExported() || len(sf.Index) != 1 {
+			continue
+		}
+		if !sf.Type.AssignableTo(f.Type) && !(sf.Type.ConvertibleTo(f.Type) && sf.Type.Kind() == f.Type.Kind()) {
+			return &ErrDestination{Type: v.Type(), Destination: reflect.PtrTo(t), Field: f.Name}
+		}
+		sources[i] = sf.Index[0]
+	}
+	for i, j := range sources {
+		switch {
+		case j >= 0:
+			dst.Field(i).Set(v.Field(j).Convert(t.Field(i).Type))
+		case t.Field(i).IsExported():
+			dst.Field(i).SetZero()
+		}
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package ccopy
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type userView struct {
+	Email     string
+	Addresses []Address
+	Extra     int
+	internal  string
+}
+
+func TestCopyInto(t *testing.T) {
+	c := Config{"redact": func(string) string { return "redacted" }}
+	type tagged struct {
+		Email string `ccopy:"redact"`
+	}
+	type untagged struct {
+		Email string `json:"email"`
+	}
+	var dst tagged
+	if err := c.CopyInto(&dst, tagged{Email: "john@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	if dst.Email != "redacted" {
+		t.Fatalf("got email: %s, expected: redacted", dst.Email)
+	}
+	var converted untagged
+	if err := c.CopyInto(&converted, tagged{Email: "john@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	if converted.Email != "redacted" {
+		t.Fatalf("got email: %s, expected: redacted", converted.Email)
+	}
+
+	u := User{Email: "john@example.com", Addresses: []Address{{Street: "Main"}}}
+	view := userView{Email: "old", Extra: 1, internal: "kept"}
+	if err := c.CopyInto(&view, u); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(userView{Email: u.Email, Addresses: u.Addresses, internal: "kept"}, view, cmp.AllowUnexported(userView{})); diff != "" {
+		t.Fatal(diff)
+	}
+	if &view.Addresses[0] == &u.Addresses[0] {
+		t.Fatal("expected a copy of the addresses")
+	}
+
+	var e *ErrDestination
+	for _, dst := range []interface{}{view, (*userView)(nil), new(int), new(struct{ Email int })} {
+		if err := c.CopyInto(dst, u); !errors.As(err, &e) {
+			t.Fatalf("%T: got error: %v, expected *ErrDestination", dst, err)
+		}
+	}
+	var s string
+	if err := c.CopyInto(&s, 65); !errors.As(err, &e) {
+		t.Fatalf("got error: %v, expected *ErrDestination", err)
+	}
+}
@@ -0,0 +1,132 @@
+// Package ipanon provides ccopy customizers anonymizing IP addresses, held in strings,
+// net.IP or netip.Addr fields, with the common schemes: truncation of the host part
+// and crypto-PAn style prefix-preserving encryption.
+package ipanon
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"net"
+	"net/netip"
+)
+
+// Scheme anonymizes IP addresses.
+type Scheme func(netip.Addr) netip.Addr
+
+// Truncate returns a scheme keeping the first v4Bits bits of IPv4 addresses and the first v6Bits bits
+// of IPv6 addresses, and zeroing the others.
+// It panics if the bits are not valid prefix lengths.
+func Truncate(v4Bits, v6Bits int) Scheme {
+	if v4Bits < 0 || v4Bits > 32 || v6Bits < 0 || v6Bits > 128 {
+		panic("ipanon: invalid prefix length")
+	}
+	return func(a netip.Addr) netip.Addr {
+		bits := v6Bits
+		if a.Is4() {
+			bits = v4Bits
+		}
+		p, err := a.Prefix(bits)
+		if err != nil {
+			return netip.Addr{}
+		}
+		return p.Addr().WithZone(a.Zone())
+	}
+}
+
+// Default zeroes the last octet of IPv4 addresses and keeps the /48 prefix of IPv6 addresses,
+// the usual setting for analytics.
+var Default = Truncate(24, 48)
+
+// PrefixPreserving returns a scheme encrypting addresses so that two addresses sharing a prefix of n bits
+// are mapped to addresses sharing a prefix of n bits as well, like crypto-PAn does.
+// The mapping is deterministic for the key, a secret of 32 bytes,
+// and it maps IPv4 addresses to IPv4 addresses and IPv6 addresses to IPv6 addresses.
+// It panics if the key is not 32 bytes long.
+func PrefixPreserving(key []byte) Scheme {
+	if len(key) != 32 {
+		panic("ipanon: prefix preserving key must be 32 bytes")
+	}
+	block, err := aes.NewCipher(key[:16])
+	if err != nil {
+		panic("ipanon: " + err.Error())
+	}
+	var pad [16]byte
+	block.Encrypt(pad[:], key[16:])
+	return func(a netip.Addr) netip.Addr {
+		if !a.IsValid() {
+			return a
+		}
+		if a.Is4() {
+			b := a.As4()
+			encrypt(block, pad, b[:])
+			return netip.AddrFrom4(b)
+		}
+		b := a.As16()
+		encrypt(block, pad, b[:])
+		return netip.AddrFrom16(b).WithZone(a.Zone())
+	}
+}
+
+// encrypt flips every bit of addr with the first bit of the encryption of the bits before it,
+// completed with pad.
+func encrypt(block cipher.Block, pad [16]byte, addr []byte) {
+	orig := append([]byte(nil), addr...)
+	var in, out [16]byte
+	for i := 0; i < 8*len(orig); i++ {
+		in = pad
+		// the first i bits of the address, then the pad
+		for j := 0; j < i/8; j++ {
+			in[j] = orig[j]
+		}
+		if r := i % 8; r != 0 {
+			mask := byte(0xff << (8 - r))
+			in[i/8] = orig[i/8]&mask | pad[i/8]&^mask
+		}
+		block.Encrypt(out[:], in[:])
+		addr[i/8] ^= (out[0] >> 7) << (7 - i%8)
+	}
+}
+
+// Addr returns a customizer of netip.Addr values.
+func (s Scheme) Addr() func(netip.Addr) netip.Addr {
+	return func(a netip.Addr) netip.Addr {
+		if !a.IsValid() {
+			return a
+		}
+		if a.Is4In6() {
+			return netip.AddrFrom16(s(a.Unmap()).As16())
+		}
+		return s(a)
+	}
+}
+
+// IP returns a customizer of net.IP values, keeping the length of their representation.
+// Values that are not IP addresses are left out of the copy.
+func (s Scheme) IP() func(net.IP) net.IP {
+	addr := s.Addr()
+	return func(ip net.IP) net.IP {
+		if ip == nil {
+			return nil
+		}
+		a, ok := netip.AddrFromSlice(ip)
+		if !ok {
+			return nil
+		}
+		return net.IP(addr(a).AsSlice())
+	}
+}
+
+// String returns a customizer of IP addresses in textual form.
+// Strings that are not IP addresses are left out of the copy, empty strings excepted.
+func (s Scheme) String() func(string) string {
+	return func(str string) string {
+		if str == "" {
+			return str
+		}
+		a, err := netip.ParseAddr(str)
+		if err != nil {
+			return ""
+		}
+		return s.Addr()(a).String()
+	}
+}
@@ -0,0 +1,82 @@
+package ipanon
+
+import (
+	"net"
+	"net/netip"
+	"testing"
+
+	"github.com/gadumitrachioaiei/ccopy"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTruncate(t *testing.T) {
+	for _, test := range []struct{ in, expected string }{
+		{"192.168.1.42", "192.168.1.0"},
+		{"2001:db8:abcd:12::1", "2001:db8:abcd::"},
+		{"fe80::1%eth0", "fe80::%eth0"},
+		{"::ffff:10.1.2.3", "::ffff:10.1.2.0"},
+	} {
+		if got := Default.String()(test.in); got != test.expected {
+			t.Fatalf("got %s, expected %s for %s", got, test.expected, test.in)
+		}
+	}
+	if got := Default.String()("not an ip"); got != "" {
+		t.Fatalf("got %q, expected invalid addresses to be left out", got)
+	}
+}
+
+func commonPrefix(a, b netip.Addr) int {
+	x, y := a.AsSlice(), b.AsSlice()
+	for i := 0; i < 8*len(x); i++ {
+		if x[i/8]>>(7-i%8)&1 != y[i/8]>>(7-i%8)&1 {
+			return i
+		}
+	}
+	return 8 * len(x)
+}
+
+func TestPrefixPreserving(t *testing.T) {
+	s := PrefixPreserving([]byte("0123456789abcdef0123456789abcdef"))
+	addrs := []netip.Addr{
+		netip.MustParseAddr("10.1.2.3"), netip.MustParseAddr("10.1.2.4"), netip.MustParseAddr("10.1.200.3"),
+		netip.MustParseAddr("192.168.0.1"),
+	}
+	for _, a := range addrs {
+		if e := s(a); e == a || !e.Is4() || s(a) != e {
+			t.Fatalf("got %s, expected a deterministic IPv4 encryption of %s", e, a)
+		}
+		for _, b := range addrs {
+			if commonPrefix(a, b) != commonPrefix(s(a), s(b)) {
+				t.Fatalf("got %s and %s, expected the prefix of %s and %s to be preserved", s(a), s(b), a, b)
+			}
+		}
+	}
+	a, b := netip.MustParseAddr("2001:db8::1"), netip.MustParseAddr("2001:db8::2")
+	if !s(a).Is6() || commonPrefix(a, b) != commonPrefix(s(a), s(b)) {
+		t.Fatalf("got %s and %s, expected the prefix of IPv6 addresses to be preserved", s(a), s(b))
+	}
+	other := PrefixPreserving([]byte("fedcba9876543210fedcba9876543210"))
+	if other(a) == s(a) {
+		t.Fatal("expected other keys to give other addresses")
+	}
+}
+
+func TestCopy(t *testing.T) {
+	type Request struct {
+		Remote string     `ccopy:"ip"`
+		Client net.IP     `ccopy:"net"`
+		Peer   netip.Addr `ccopy:"addr"`
+	}
+	config := ccopy.Config{"ip": Default.String(), "net": Default.IP(), "addr": Default.Addr()}
+	res, err := config.Copy(Request{Remote: "1.2.3.4", Client: net.ParseIP("5.6.7.8"), Peer: netip.MustParseAddr("2001:db8:1:2::3")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := Request{Remote: "1.2.3.0", Client: net.ParseIP("5.6.7.0"), Peer: netip.MustParseAddr("2001:db8:1::")}
+	if diff := cmp.Diff(res, expected, cmp.Comparer(func(a, b netip.Addr) bool { return a == b })); diff != "" {
+		t.Fatal(diff)
+	}
+	if len(res.(Request).Client) != net.IPv6len {
+		t.Fatalf("got %v, expected the 16 bytes form to be kept", res.(Request).Client)
+	}
+}
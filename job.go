@@ -0,0 +1,291 @@
+package ccopy
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// Job anonymizes a dataset: it copies the records of a source with a Copier, with a pool of workers,
+// in a session shared by all the copies, so the same values are anonymized the same way in all the records,
+// and writes the copies to a sink, in the order of the source.
+//
+// The records whose copy fails are quarantined, when Quarantine is set, so that a few bad records
+// do not fail the whole dataset; otherwise the job fails with the error of the copy.
+//
+// Long jobs save checkpoints, when Checkpoint is set, and resume from the last of them after a crash:
+// the records done before it are skipped, and the session is restored, so the pseudonyms stay consistent
+// with the records already written.
+type Job[T any] struct {
+	// Copier copies the records.
+	Copier *Copier
+	// Session is the session of the copies, the session of Resume or a new session if nil.
+	Session *Session
+	// Source yields the records, like an iter.Seq[T]. FromChannel makes a source of a channel.
+	// Run returns once Source returns, so sources waiting for records must stop when the context of Run is done.
+	Source func(yield func(T) bool)
+	// Sink writes the copies. It is called by one goroutine at a time, and the job fails with its errors.
+	Sink func(copy T) error
+	// Workers is the number of records copied concurrently, runtime.GOMAXPROCS(0) if less than 1.
+	Workers int
+	// Quarantine, if set, is called with the records whose copy failed, instead of failing the job,
+	// like to write them to a dead letter queue. The job fails with its errors.
+	// It is called by the goroutine calling Sink, in the order of the source.
+	Quarantine func(record T, err error) error
+	// Progress, if set, is called with the metrics of the job after every record is written or quarantined,
+	// by the goroutine calling Sink.
+	Progress func(JobMetrics)
+	// Checkpoint, if set, saves the checkpoints of the job: every CheckpointEvery records,
+	// and when the job ends, unless it ends because Checkpoint failed. The job fails with its errors.
+	// It is called by the goroutine calling Sink, after the records of the checkpoint are written,
+	// so it must make sure they are durable before saving it, like by flushing the output of the sink.
+	Checkpoint func(*Checkpoint) error
+	// CheckpointEvery is the number of records between checkpoints, 1000 if less than 1.
+	CheckpointEvery int
+	// Resume, if set, is the checkpoint to resume from.
+	Resume *Checkpoint
+}
+
+// Checkpoint is the state of a job, to resume it from. It can be encoded as JSON.
+type Checkpoint struct {
+	// Records is the number of records of the source done, written or quarantined, since the start of the first run.
+	Records int
+	// Session is the state of the session of the copies.
+	Session *SessionState
+}
+
+// JobMetrics are the metrics of a job.
+type JobMetrics struct {
+	// Records is the number of records done: written or quarantined.
+	Records int
+	// Written is the number of copies written to the sink.
+	Written int
+	// Quarantined is the number of records whose copy failed.
+	Quarantined int
+	// Resumed is the number of records skipped, as they were done before the checkpoint resumed from.
+	Resumed int
+	// Checkpoints is the number of checkpoints saved.
+	Checkpoints int
+	// Elapsed is the time since the start of the job.
+	Elapsed time.Duration
+}
+
+// FromChannel returns a source yielding the values received from ch, until it is closed.
+func FromChannel[T any](ch <-chan T) func(yield func(T) bool) {
+	return func(yield func(T) bool) {
+		for v := range ch {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// jobResult is the result of the copy of a record.
+type jobResult[T any] struct {
+	record T
+	copy   T
+	err    error
+}
+
+// jobTask is a record to copy, and where to send the result, waited for by the sink in the order of the source.
+type jobTask[T any] struct {
+	record T
+	done   chan jobResult[T]
+}
+
+// Run runs the job, until the source has no more records, the job fails or the context is done.
+// It returns the metrics of the job, and the error it failed with, or the error of the context.
+func (j *Job[T]) Run(ctx context.Context) (JobMetrics, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	session, resumed, err := j.start()
+	if err != nil {
+		return JobMetrics{}, err
+	}
+	workers := j.Workers
+	if workers < 1 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	tasks := make(chan jobTask[T])
+	// pending holds the results to wait for, in the order of the source, bounding the records in flight
+	pending := make(chan chan jobResult[T], workers)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for t := range tasks {
+				v, err := j.Copier.CopySession(session, t.record)
+				copied, _ := v.(T)
+				t.done <- jobResult[T]{record: t.record, copy: copied, err: err}
+			}
+		}()
+	}
+	go func() {
+		defer close(pending)
+		defer close(tasks)
+		skip := resumed
+		j.Source(func(record T) bool {
+			if skip > 0 {
+				skip--
+				return true
+			}
+			done := make(chan jobResult[T], 1)
+			select {
+			case pending <- done:
+			case <-ctx.Done():
+				return false
+			}
+			select {
+			case tasks <- jobTask[T]{record: record, done: done}:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		})
+	}()
+
+	start := time.Now()
+	m := JobMetrics{Resumed: resumed}
+	// saved is the number of records done at the last checkpoint, or -1 if saving it failed
+	saved := 0
+	save := func() error {
+		st, err := session.State()
+		if err == nil {
+			err = j.Checkpoint(&Checkpoint{Records: resumed + m.Records, Session: st})
+		}
+		if err != nil {
+			saved = -1
+			return fmt.Errorf("checkpoint: %w", err)
+		}
+		saved = m.Records
+		m.Checkpoints++
+		return nil
+	}
+	err = j.sink(ctx, pending, &m, start, save)
+	cancel()
+	for range pending {
+	}
+	wg.Wait()
+	if j.Checkpoint != nil && saved >= 0 && saved != m.Records {
+		if serr := save(); err == nil {
+			err = serr
+		}
+	}
+	m.Elapsed = time.Since(start)
+	return m, err
+}
+
+// start returns the session of the copies and the number of records to skip, resuming from j.Resume.
+func (j *Job[T]) start() (*Session, int, error) {
+	session := j.Session
+	if j.Resume == nil {
+		if session == nil {
+			session = NewSession()
+		}
+		return session, 0, nil
+	}
+	if session == nil && j.Resume.Session != nil {
+		var err error
+		if session, err = RestoreSession(j.Resume.Session); err != nil {
+			return nil, 0, fmt.Errorf("resume: %w", err)
+		}
+	}
+	if session == nil {
+		session = NewSession()
+	}
+	return session, j.Resume.Records, nil
+}
+
+// sink writes or quarantines the results, in the order of pending, updating the metrics m
+// and saving checkpoints with save.
+func (j *Job[T]) sink(ctx context.Context, pending <-chan chan jobResult[T], m *JobMetrics, start time.Time, save func() error) error {
+	every := j.CheckpointEvery
+	if every < 1 {
+		every = 1000
+	}
+	for {
+		var done chan jobResult[T]
+		var ok bool
+		select {
+		case done, ok = <-pending:
+			if !ok {
+				return ctx.Err()
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		var r jobResult[T]
+		select {
+		case r = <-done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		if r.err != nil {
+			if j.Quarantine == nil {
+				return r.err
+			}
+			if err := j.Quarantine(r.record, r.err); err != nil {
+				return fmt.Errorf("quarantine: %w", err)
+			}
+			m.Quarantined++
+		} else {
+			if err := j.Sink(r.copy); err != nil {
+				return fmt.Errorf("sink: %w", err)
+			}
+			m.Written++
+		}
+		m.Records++
+		if j.Progress != nil {
+			m.Elapsed = time.Since(start)
+			j.Progress(*m)
+		}
+		if j.Checkpoint != nil && m.Records%every == 0 {
+			if err := save(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// CopyStream copies the records received from in with c, with the given number of workers as in Job,
+// and sends the copies to out, in the order of in, until in is closed, a copy fails or the context is done.
+// It is meant to be a stage of a pipeline of channels: it stops receiving from in while out is not ready,
+// with no more than workers records in flight, and it closes out when it returns.
+// It returns the error of the failed copy, or the error of the context.
+func CopyStream[T any](ctx context.Context, c *Copier, in <-chan T, out chan<- T, workers int) error {
+	defer close(out)
+	source := func(yield func(T) bool) {
+		for {
+			select {
+			case record, ok := <-in:
+				if !ok || !yield(record) {
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+	job := Job[T]{
+		Copier:  c,
+		Source:  source,
+		Workers: workers,
+		Sink: func(copy T) error {
+			select {
+			case out <- copy:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		},
+	}
+	if _, err := job.Run(ctx); ctx.Err() == nil {
+		return err
+	}
+	return ctx.Err()
+}
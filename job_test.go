@@ -0,0 +1,235 @@
+package ccopy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"unsafe"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type jobRecord struct {
+	ID    int
+	Email string `ccopy:"idmap=email"`
+	Bad   interface{}
+}
+
+func TestJob(t *testing.T) {
+	var n atomic.Int64
+	c, err := NewCopier(Config{"email": func(string) string { return "user" + strconv.Itoa(int(n.Add(1))) + "@example.com" }}, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ch := make(chan jobRecord)
+	go func() {
+		defer close(ch)
+		for i := 0; i < 100; i++ {
+			r := jobRecord{ID: i, Email: "john@example.com"}
+			if i%10 == 3 {
+				r.Bad = unsafe.Pointer(&r)
+			}
+			ch <- r
+		}
+	}()
+	var written []int
+	var quarantined []int
+	var emails = make(map[string]bool)
+	var progress JobMetrics
+	job := Job[jobRecord]{
+		Copier:  c,
+		Source:  FromChannel(ch),
+		Workers: 4,
+		Sink: func(r jobRecord) error {
+			written = append(written, r.ID)
+			emails[r.Email] = true
+			return nil
+		},
+		Quarantine: func(r jobRecord, err error) error {
+			var e *ErrUnsupportedKind
+			if !errors.As(err, &e) {
+				t.Errorf("got error: %v, expected *ErrUnsupportedKind", err)
+			}
+			quarantined = append(quarantined, r.ID)
+			return nil
+		},
+		Progress: func(m JobMetrics) { progress = m },
+	}
+	m, err := job.Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Records != 100 || m.Written != 90 || m.Quarantined != 10 || progress.Records != 100 {
+		t.Fatalf("got metrics: %+v, progress: %+v", m, progress)
+	}
+	for i := 1; i < len(written); i++ {
+		if written[i] < written[i-1] {
+			t.Fatalf("records written out of order: %v", written)
+		}
+	}
+	if diff := cmp.Diff([]int{3, 13, 23, 33, 43, 53, 63, 73, 83, 93}, quarantined); diff != "" {
+		t.Fatal(diff)
+	}
+	if len(emails) != 1 || emails["john@example.com"] {
+		t.Fatalf("got emails: %v, expected one anonymized email", emails)
+	}
+}
+
+func TestJobFails(t *testing.T) {
+	c, err := NewCopier(Config{"email": func(s string) string { return s }}, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	source := func(yield func(jobRecord) bool) {
+		for i := 0; yield(jobRecord{ID: i}); i++ {
+		}
+	}
+	errSink := errors.New("full")
+	m, err := (&Job[jobRecord]{Copier: c, Source: source, Sink: func(r jobRecord) error {
+		if r.ID == 5 {
+			return errSink
+		}
+		return nil
+	}}).Run(context.Background())
+	if !errors.Is(err, errSink) || m.Written != 5 {
+		t.Fatalf("got error: %v, metrics: %+v", err, m)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = (&Job[jobRecord]{Copier: c, Source: source, Sink: func(jobRecord) error { return nil }}).Run(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("got error: %v, expected context.Canceled", err)
+	}
+
+	_, err = (&Job[jobRecord]{Copier: c, Source: func(yield func(jobRecord) bool) {
+		yield(jobRecord{ID: 1})
+		yield(jobRecord{ID: 2, Bad: unsafe.Pointer(&m)})
+	}, Sink: func(jobRecord) error { return nil }}).Run(context.Background())
+	var e *ErrUnsupportedKind
+	if !errors.As(err, &e) {
+		t.Fatalf("got error: %v, expected *ErrUnsupportedKind", err)
+	}
+}
+
+func TestJobCheckpoint(t *testing.T) {
+	var n atomic.Int64
+	c, err := NewCopier(Config{"email": func(string) string { return "user" + strconv.Itoa(int(n.Add(1))) + "@example.com" }}, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	source := func(yield func(jobRecord) bool) {
+		for i := 0; i < 100; i++ {
+			if !yield(jobRecord{ID: i, Email: strconv.Itoa(i%5) + "@example.com"}) {
+				return
+			}
+		}
+	}
+	written := make(map[int]string)
+	var saved []byte
+	var checkpoints []int
+	errCrash := errors.New("crash")
+	job := Job[jobRecord]{
+		Copier: c,
+		Source: source,
+		Sink: func(r jobRecord) error {
+			if r.ID == 25 {
+				return errCrash
+			}
+			written[r.ID] = r.Email
+			return nil
+		},
+		Checkpoint: func(cp *Checkpoint) error {
+			checkpoints = append(checkpoints, cp.Records)
+			var err error
+			saved, err = json.Marshal(cp)
+			return err
+		},
+		CheckpointEvery: 10,
+	}
+	m, err := job.Run(context.Background())
+	if !errors.Is(err, errCrash) || m.Checkpoints != 3 {
+		t.Fatalf("got error: %v, metrics: %+v", err, m)
+	}
+	if diff := cmp.Diff([]int{10, 20, 25}, checkpoints); diff != "" {
+		t.Fatal(diff)
+	}
+
+	var resume Checkpoint
+	if err := json.Unmarshal(saved, &resume); err != nil {
+		t.Fatal(err)
+	}
+	job.Resume = &resume
+	job.Sink = func(r jobRecord) error {
+		if _, ok := written[r.ID]; ok {
+			t.Errorf("record %d written again", r.ID)
+		}
+		written[r.ID] = r.Email
+		return nil
+	}
+	m, err = job.Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Resumed != 25 || m.Records != 75 || len(written) != 100 || checkpoints[len(checkpoints)-1] != 100 {
+		t.Fatalf("got metrics: %+v, written: %d, checkpoints: %v", m, len(written), checkpoints)
+	}
+	for i := 5; i < 100; i++ {
+		if written[i] != written[i%5] {
+			t.Fatalf("record %d: got email: %s, expected: %s", i, written[i], written[i%5])
+		}
+	}
+
+	job.Checkpoint = func(*Checkpoint) error { return errCrash }
+	job.Resume = nil
+	job.Sink = func(jobRecord) error { return nil }
+	if _, err := job.Run(context.Background()); !errors.Is(err, errCrash) {
+		t.Fatalf("got error: %v, expected the error of the checkpoint", err)
+	}
+}
+
+func TestCopyStream(t *testing.T) {
+	c, err := NewCopier(Config{"email": func(string) string { return "redacted" }}, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	in, out := make(chan jobRecord), make(chan jobRecord)
+	go func() {
+		defer close(in)
+		for i := 0; i < 50; i++ {
+			in <- jobRecord{ID: i, Email: "john@example.com"}
+		}
+	}()
+	errc := make(chan error, 1)
+	go func() { errc <- CopyStream(context.Background(), c, in, out, 4) }()
+	i := 0
+	for r := range out {
+		if r.ID != i || r.Email != "redacted" {
+			t.Fatalf("got record: %+v, expected record %d redacted", r, i)
+		}
+		i++
+	}
+	if err := <-errc; err != nil || i != 50 {
+		t.Fatalf("got error: %v, records: %d", err, i)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	in, out = make(chan jobRecord, 10), make(chan jobRecord)
+	for i := 0; i < 10; i++ {
+		in <- jobRecord{ID: i}
+	}
+	go func() { errc <- CopyStream(ctx, c, in, out, 2) }()
+	if r := <-out; r.ID != 0 {
+		t.Fatalf("got record: %+v, expected record 0", r)
+	}
+	cancel()
+	if err := <-errc; !errors.Is(err, context.Canceled) {
+		t.Fatalf("got error: %v, expected context.Canceled", err)
+	}
+	if len(in) == 0 {
+		t.Fatal("expected records left in the input, as the output was not ready")
+	}
+}
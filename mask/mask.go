@@ -0,0 +1,168 @@
+// Package mask provides format-preserving ccopy customizers: the masked values keep the length,
+// the character classes and the separators of the original values, and the checksums of card numbers and IBANs,
+// so validators and user interfaces keep working on the copies.
+//
+// The maskers take the random generator they use, like the generator of a ccopy.Session.
+// Keyed makes them deterministic instead, for a secret key.
+package mask
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"math/big"
+	"math/rand"
+	"strconv"
+	"strings"
+)
+
+// Chars returns a customizer replacing every digit with a random digit and every ASCII letter
+// with a random letter of the same case, keeping the other characters, like separators, as they are.
+func Chars(r *rand.Rand) func(string) string {
+	return func(s string) string {
+		b := []byte(s)
+		for i, c := range b {
+			b[i] = randomLike(r, c)
+		}
+		return string(b)
+	}
+}
+
+func randomLike(r *rand.Rand, c byte) byte {
+	switch {
+	case '0' <= c && c <= '9':
+		return '0' + byte(r.Intn(10))
+	case 'a' <= c && c <= 'z':
+		return 'a' + byte(r.Intn(26))
+	case 'A' <= c && c <= 'Z':
+		return 'A' + byte(r.Intn(26))
+	}
+	return c
+}
+
+// Card returns a customizer replacing the digits of a card number with random digits,
+// except the first one that identifies the card network, and the last one that is set to the Luhn check digit.
+// Separators are kept, so "4111 1111 1111 1111" becomes something like "4735 0912 6648 3020".
+// Values with less than 2 digits are kept as they are.
+func Card(r *rand.Rand) func(string) string {
+	return func(s string) string {
+		b := []byte(s)
+		var digits []int
+		for i, c := range b {
+			if '0' <= c && c <= '9' {
+				digits = append(digits, i)
+			}
+		}
+		if len(digits) < 2 {
+			return s
+		}
+		for _, i := range digits[1 : len(digits)-1] {
+			b[i] = '0' + byte(r.Intn(10))
+		}
+		b[digits[len(digits)-1]] = '0'
+		b[digits[len(digits)-1]] = '0' + byte((10-luhnSum(b)%10)%10)
+		return string(b)
+	}
+}
+
+// luhnSum returns the Luhn sum of the digits of b, the last digit being the check digit.
+func luhnSum(b []byte) int {
+	sum, double := 0, false
+	for i := len(b) - 1; i >= 0; i-- {
+		c := b[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum
+}
+
+// ValidLuhn reports whether the digits of s, ignoring other characters, pass the Luhn check.
+func ValidLuhn(s string) bool {
+	return strings.ContainsAny(s, "0123456789") && luhnSum([]byte(s))%10 == 0
+}
+
+// IBAN returns a customizer replacing the account part of an IBAN with random characters of the same classes,
+// and setting its check digits, so that it stays a valid IBAN of the same country and length.
+// Separators are kept. Values that do not start with a country code and two digits are kept as they are.
+func IBAN(r *rand.Rand) func(string) string {
+	chars := Chars(r)
+	return func(s string) string {
+		b := []byte(s)
+		pos := alnumPositions(b)
+		if len(pos) < 5 || !isUpper(b[pos[0]]) || !isUpper(b[pos[1]]) || !isDigit(b[pos[2]]) || !isDigit(b[pos[3]]) {
+			return s
+		}
+		bban := chars(string(b[pos[4]:]))
+		b = append(b[:pos[4]], bban...)
+		b[pos[2]], b[pos[3]] = '0', '0'
+		check := 98 - ibanMod(b)
+		b[pos[2]], b[pos[3]] = '0'+byte(check/10), '0'+byte(check%10)
+		return string(b)
+	}
+}
+
+// ValidIBAN reports whether s, ignoring separators, is an IBAN with valid check digits.
+func ValidIBAN(s string) bool {
+	b := []byte(s)
+	pos := alnumPositions(b)
+	if len(pos) < 5 || !isUpper(b[pos[0]]) || !isUpper(b[pos[1]]) || !isDigit(b[pos[2]]) || !isDigit(b[pos[3]]) {
+		return false
+	}
+	return ibanMod(b) == 1
+}
+
+// ibanMod returns the IBAN of b, without separators, modulo 97, as defined by ISO 13616.
+func ibanMod(b []byte) int {
+	pos := alnumPositions(b)
+	var digits strings.Builder
+	for _, i := range append(pos[4:], pos[:4]...) {
+		c := b[i]
+		switch {
+		case isDigit(c):
+			digits.WriteByte(c)
+		case isUpper(c):
+			digits.WriteString(strconv.Itoa(int(c-'A') + 10))
+		case 'a' <= c && c <= 'z':
+			digits.WriteString(strconv.Itoa(int(c-'a') + 10))
+		}
+	}
+	n, _ := new(big.Int).SetString(digits.String(), 10)
+	return int(n.Mod(n, big.NewInt(97)).Int64())
+}
+
+func alnumPositions(b []byte) []int {
+	var pos []int
+	for i, c := range b {
+		if isDigit(c) || isUpper(c) || 'a' <= c && c <= 'z' {
+			pos = append(pos, i)
+		}
+	}
+	return pos
+}
+
+func isDigit(c byte) bool { return '0' <= c && c <= '9' }
+
+func isUpper(c byte) bool { return 'A' <= c && c <= 'Z' }
+
+// Keyed returns a deterministic version of a masker: every value is masked with a generator
+// seeded by the HMAC-SHA256 of the value with key, so the same value is always masked the same way,
+// across unrelated copies, and the masked values cannot be linked to the original ones without the key.
+// For example Keyed(key, mask.Card) masks card numbers deterministically.
+func Keyed(key []byte, masker func(*rand.Rand) func(string) string) func(string) string {
+	return func(s string) string {
+		h := hmac.New(sha256.New, key)
+		h.Write([]byte(s))
+		seed := int64(binary.LittleEndian.Uint64(h.Sum(nil)))
+		return masker(rand.New(rand.NewSource(seed)))(s)
+	}
+}
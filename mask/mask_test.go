@@ -0,0 +1,103 @@
+package mask
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/gadumitrachioaiei/ccopy"
+)
+
+func TestChars(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	s := "AB-12 cd/3"
+	m := Chars(r)(s)
+	if len(m) != len(s) || m == s {
+		t.Fatalf("got %q, expected a masked %q", m, s)
+	}
+	for i := range s {
+		if class(s[i]) != class(m[i]) || class(s[i]) == 0 && s[i] != m[i] {
+			t.Fatalf("got %q, expected the character classes of %q", m, s)
+		}
+	}
+}
+
+func class(c byte) int {
+	switch {
+	case isDigit(c):
+		return 1
+	case isUpper(c):
+		return 2
+	case 'a' <= c && c <= 'z':
+		return 3
+	}
+	return 0
+}
+
+func TestCard(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, s := range []string{"4111 1111 1111 1111", "5500-0000-0000-0004", "378282246310005", "7"} {
+		m := Card(r)(s)
+		if len(m) != len(s) || m[0] != s[0] {
+			t.Fatalf("got %q, expected the length and first digit of %q", m, s)
+		}
+		if len(s) > 1 && (m == s || !ValidLuhn(m)) {
+			t.Fatalf("got %q, expected a masked valid card number", m)
+		}
+		for i := range s {
+			if class(s[i]) != class(m[i]) {
+				t.Fatalf("got %q, expected the separators of %q", m, s)
+			}
+		}
+	}
+	if ValidLuhn("4111 1111 1111 1112") || !ValidLuhn("4111 1111 1111 1111") {
+		t.Fatal("unexpected Luhn check")
+	}
+}
+
+func TestIBAN(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, s := range []string{"GB82 WEST 1234 5698 7654 32", "DE89370400440532013000", "NO9386011117947"} {
+		if !ValidIBAN(s) {
+			t.Fatalf("expected %q to be valid", s)
+		}
+		m := IBAN(r)(s)
+		if len(m) != len(s) || m[:2] != s[:2] || m == s || !ValidIBAN(m) {
+			t.Fatalf("got %q, expected a masked valid IBAN of %q", m, s)
+		}
+	}
+	if s := IBAN(r)("not an iban"); s != "not an iban" {
+		t.Fatalf("got %q, expected the invalid IBAN to be kept", s)
+	}
+	if ValidIBAN("GB83 WEST 1234 5698 7654 32") {
+		t.Fatal("expected an invalid IBAN")
+	}
+}
+
+func TestKeyed(t *testing.T) {
+	type Payment struct {
+		Card string `ccopy:"card"`
+		IBAN string `ccopy:"iban"`
+	}
+	key := []byte("secret")
+	config := ccopy.Config{"card": Keyed(key, Card), "iban": Keyed(key, IBAN)}
+	obj := Payment{Card: "4111 1111 1111 1111", IBAN: "DE89370400440532013000"}
+	a, err := config.Copy(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := config.Copy(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := a.(Payment)
+	if a != b || p == obj || !ValidLuhn(p.Card) || !ValidIBAN(p.IBAN) {
+		t.Fatalf("got %v and %v, expected the same valid masked values", a, b)
+	}
+	c, err := ccopy.Config{"card": Keyed([]byte("other"), Card), "iban": Keyed([]byte("other"), IBAN)}.Copy(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c == a {
+		t.Fatalf("got %v, expected other masked values for another key", c)
+	}
+}
@@ -0,0 +1,178 @@
+package ccopy
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+)
+
+// MessageKey identifies the kind of an error or warning of a copy, for looking up its translations.
+type MessageKey string
+
+// The keys of the errors and warnings of copies.
+const (
+	MessageInvalidValue      MessageKey = "invalid_value"
+	MessageMissingCustomizer MessageKey = "missing_customizer"
+	MessageUnsupportedKind   MessageKey = "unsupported_kind"
+	MessageBadCustomizer     MessageKey = "bad_customizer"
+	MessageNaNKey            MessageKey = "nan_key"
+	MessageKeyCollision      MessageKey = "key_collision"
+	MessageNotUnique         MessageKey = "not_unique"
+	MessageHandler           MessageKey = "handler"
+	MessageCustomizer        MessageKey = "customizer"
+	MessageTimeout           MessageKey = "timeout"
+	MessageCanceled          MessageKey = "canceled"
+	MessageAliasedResult     MessageKey = "aliased_result"
+	MessageResource          MessageKey = "resource"
+	MessageIncomparable      MessageKey = "incomparable"
+	MessageCannotDive        MessageKey = "cannot_dive"
+	MessageTooBig            MessageKey = "too_big"
+	MessageRuleConflict      MessageKey = "rule_conflict"
+	// MessageError is the key of the other errors.
+	MessageError MessageKey = "error"
+
+	MessageUnexportedField      MessageKey = "warning_unexported_field"
+	MessageAliased              MessageKey = "warning_aliased"
+	MessageNaNKeyWarning        MessageKey = "warning_nan_key"
+	MessageKeyCollisionWarning  MessageKey = "warning_key_collision"
+	MessageCostlyWarning        MessageKey = "warning_costly"
+	MessageAliasedResultWarning MessageKey = "warning_aliased_result"
+)
+
+// Message describes an error or a warning of a copy by its parts, instead of by a sentence,
+// so that services showing them to end users can phrase them in their own words and languages,
+// like "field Email could not be exported", without leaking type names.
+type Message struct {
+	Key MessageKey
+	// Path is the path of the value the message is about, like Order.Items[3].Buyer.Email.
+	Path string
+	// Field is the name of the last field of Path, like Email, if any.
+	Field string
+	// Tag is the name of the customizer, for the errors of customizers.
+	Tag string
+	// Type is the type of the value, if known.
+	Type reflect.Type
+	// Err is the error described, nil for warnings.
+	Err error
+}
+
+// Describe returns the message describing err, an error returned by a copy.
+func Describe(err error) Message {
+	m := Message{Key: MessageError, Err: err}
+	var (
+		missing      *ErrMissingCustomizer
+		unsupported  *ErrUnsupportedKind
+		signature    *ErrBadCustomizerSignature
+		nanKey       *ErrNaNKey
+		collision    *ErrKeyCollision
+		notUnique    *ErrNotUnique
+		handler      *ErrHandler
+		customizer   *ErrCustomizer
+		timeout      *ErrTimeout
+		canceled     *ErrCanceled
+		aliased      *ErrAliasedResult
+		resource     *ErrResource
+		incomparable *ErrIncomparable
+		dive         *ErrCannotDive
+		tooBig       *ErrTooBig
+		conflict     *ErrRuleConflict
+	)
+	switch {
+	case errors.Is(err, ErrInvalidValue):
+		m.Key = MessageInvalidValue
+	case errors.As(err, &missing):
+		m.Key, m.Path, m.Tag = MessageMissingCustomizer, missing.Path, missing.Tag
+	case errors.As(err, &unsupported):
+		m.Key, m.Path = MessageUnsupportedKind, unsupported.Path
+	case errors.As(err, &signature):
+		m.Key, m.Path, m.Tag, m.Type = MessageBadCustomizer, signature.Path, signature.Tag, signature.Type
+	case errors.As(err, &nanKey):
+		m.Key, m.Path = MessageNaNKey, nanKey.Path
+	case errors.As(err, &collision):
+		m.Key, m.Path = MessageKeyCollision, collision.Path
+	case errors.As(err, &notUnique):
+		m.Key, m.Path, m.Tag = MessageNotUnique, notUnique.Path, notUnique.Tag
+	case errors.As(err, &handler):
+		m.Key, m.Path, m.Type = MessageHandler, handler.Path, handler.Type
+	case errors.As(err, &customizer):
+		m.Key, m.Path, m.Tag = MessageCustomizer, customizer.Path, customizer.Tag
+	case errors.As(err, &timeout):
+		m.Key, m.Path = MessageTimeout, timeout.Path
+	case errors.As(err, &canceled):
+		m.Key, m.Path = MessageCanceled, canceled.Path
+	case errors.As(err, &aliased):
+		m.Key, m.Path, m.Tag = MessageAliasedResult, aliased.Path, aliased.Tag
+	case errors.As(err, &resource):
+		m.Key, m.Path, m.Type = MessageResource, resource.Path, resource.Type
+	case errors.As(err, &incomparable):
+		m.Key, m.Path, m.Tag, m.Type = MessageIncomparable, incomparable.Path, incomparable.Tag, incomparable.Type
+	case errors.As(err, &dive):
+		m.Key, m.Path, m.Tag, m.Type = MessageCannotDive, dive.Path, dive.Tag, dive.Type
+	case errors.As(err, &tooBig):
+		m.Key, m.Path = MessageTooBig, tooBig.Path
+	case errors.As(err, &conflict):
+		m.Key, m.Path = MessageRuleConflict, conflict.Path
+	}
+	m.Field = lastField(m.Path)
+	return m
+}
+
+// DescribeWarning returns the message describing w.
+func DescribeWarning(w Warning) Message {
+	m := Message{Key: MessageError, Path: w.Path, Field: lastField(w.Path), Type: w.Type}
+	switch w.Kind {
+	case WarningUnexportedField:
+		m.Key = MessageUnexportedField
+	case WarningAliased:
+		m.Key = MessageAliased
+	case WarningNaNKey:
+		m.Key = MessageNaNKeyWarning
+	case WarningKeyCollision:
+		m.Key = MessageKeyCollisionWarning
+	case WarningCostly:
+		m.Key = MessageCostlyWarning
+	case WarningAliasedResult:
+		m.Key = MessageAliasedResultWarning
+	}
+	return m
+}
+
+// lastField returns the name of the last field of path, ignoring the indexes and keys after it.
+func lastField(path string) string {
+	for strings.HasSuffix(path, "]") {
+		i := strings.LastIndex(path, "[")
+		if i < 0 {
+			return ""
+		}
+		path = path[:i]
+	}
+	i := strings.LastIndex(path, ".")
+	if i < 0 {
+		return ""
+	}
+	return path[i+1:]
+}
+
+// ErrMessage is returned instead of the errors of the copies of a Copier with the Messages option,
+// with the text formatted by the option.
+type ErrMessage struct {
+	Text string
+	Err  error
+}
+
+func (e *ErrMessage) Error() string {
+	return e.Text
+}
+
+// Unwrap returns the error of the copy.
+func (e *ErrMessage) Unwrap() error {
+	return e.Err
+}
+
+// message returns err, as a *FieldError if it is about a value, formatted by the Messages option, if any.
+func (c *Copier) message(err error) error {
+	if c.messages == nil || err == nil {
+		return fieldError(err)
+	}
+	return &ErrMessage{Text: c.messages(Describe(err)), Err: fieldError(err)}
+}
@@ -0,0 +1,48 @@
+package ccopy
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMessages(t *testing.T) {
+	type Buyer struct {
+		Email string `ccopy:"email"`
+	}
+	type Order struct {
+		Buyers []Buyer
+	}
+	var described Message
+	c, err := NewCopier(Config{}, Options{Messages: func(m Message) string {
+		described = m
+		return "le champ " + m.Field + " n'a pas pu être exporté"
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.Copy(Order{Buyers: []Buyer{{Email: "a@example.com"}}})
+	if err == nil || err.Error() != "le champ Email n'a pas pu être exporté" {
+		t.Fatalf("expected the formatted message, got: %v", err)
+	}
+	var missing *ErrMissingCustomizer
+	if !errors.As(err, &missing) {
+		t.Fatalf("expected the error to wrap the missing customizer, got: %T", err)
+	}
+	expected := Message{Key: MessageMissingCustomizer, Path: "Order.Buyers[0].Email", Field: "Email", Tag: "email", Err: missing}
+	if described != expected {
+		t.Fatalf("expected: %+v, got: %+v", expected, described)
+	}
+	if _, errs := c.CopyPartial(Order{Buyers: []Buyer{{}}}); len(errs) != 1 || errs[0].Error() != "le champ Email n'a pas pu être exporté" {
+		t.Fatalf("expected the formatted message of the partial copy, got: %v", errs)
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	if m := Describe(errors.New("other")); m.Key != MessageError {
+		t.Fatalf("expected the key of other errors, got: %+v", m)
+	}
+	m := DescribeWarning(Warning{Kind: WarningAliased, Path: "Order.Callbacks[\"x\"]"})
+	if m.Key != MessageAliased || m.Field != "Callbacks" {
+		t.Fatalf("expected an aliased warning on Callbacks, got: %+v", m)
+	}
+}
@@ -0,0 +1,71 @@
+package ccopy
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+var models = struct {
+	sync.RWMutex
+	types map[string]reflect.Type
+}{types: make(map[string]reflect.Type)}
+
+// RegisterModel registers the type of model under a name, so payloads of that type can be copied by name.
+// It panics if the name is already registered, or if model is nil.
+func RegisterModel(name string, model interface{}) {
+	if model == nil {
+		panic("ccopy: register nil model: " + name)
+	}
+	models.Lock()
+	defer models.Unlock()
+	if _, ok := models.types[name]; ok {
+		panic("ccopy: model already registered: " + name)
+	}
+	models.types[name] = reflect.TypeOf(model)
+}
+
+// Models returns the sorted names of the registered models.
+func Models() []string {
+	models.RLock()
+	defer models.RUnlock()
+	names := make([]string, 0, len(models.types))
+	for name := range models.types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ModelType returns the type registered under name, if any.
+func ModelType(name string) (reflect.Type, bool) {
+	models.RLock()
+	defer models.RUnlock()
+	t, ok := models.types[name]
+	return t, ok
+}
+
+// CopyNamed decodes a json payload into the model registered under name,
+// copies it respecting the customizations provided in the config, and encodes the copy back to json.
+func (c Config) CopyNamed(name string, data []byte) ([]byte, error) {
+	return c.copier().CopyNamed(name, data)
+}
+
+// CopyNamed decodes a json payload into the model registered under name, copies it, and encodes the copy back to json.
+func (c *Copier) CopyNamed(name string, data []byte) ([]byte, error) {
+	t, ok := ModelType(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown model: %s", name)
+	}
+	obj := reflect.New(t)
+	if err := json.Unmarshal(data, obj.Interface()); err != nil {
+		return nil, fmt.Errorf("decode model %s: %w", name, err)
+	}
+	oc, err := c.Copy(obj.Elem().Interface())
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(oc)
+}
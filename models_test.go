@@ -0,0 +1,65 @@
+package ccopy
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCopyNamed(t *testing.T) {
+	type Account struct {
+		ID    int    `json:"id"`
+		Email string `json:"email" ccopy:"redact"`
+	}
+	RegisterModel("account", Account{})
+	c := Config{"redact": func(string) string { return "redacted" }}
+	data, err := c.CopyNamed("account", []byte(`{"id": 1, "email": "john@example.com"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `{"id":1,"email":"redacted"}`; string(data) != expected {
+		t.Fatalf("got: %s, expected: %s", data, expected)
+	}
+	if _, err := c.CopyNamed("unknown", data); err == nil {
+		t.Fatal("expected error for unknown model")
+	}
+	if _, err := c.CopyNamed("account", []byte(`{`)); err == nil {
+		t.Fatal("expected error for invalid payload")
+	}
+}
+
+func TestRegisterModelTwice(t *testing.T) {
+	RegisterModel("twice", 1)
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic")
+		}
+	}()
+	RegisterModel("twice", 1)
+}
+
+func TestWarmModels(t *testing.T) {
+	type Item struct {
+		SKU string `ccopy:"redact"`
+	}
+	type Cart struct {
+		Items []Item
+	}
+	RegisterModel("cart", Cart{})
+	c, err := NewCopier(Config{"redact": func(string) string { return "" }}, Options{WarmModels: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []interface{}{Cart{}, Item{}} {
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			if _, ok := c.plans.m.Load(reflect.TypeOf(v)); ok {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("expected the plan of %T to be compiled in the background", v)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+}
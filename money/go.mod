@@ -0,0 +1,8 @@
+module github.com/gadumitrachioaiei/ccopy/money
+
+go 1.22.0
+
+require (
+	github.com/gadumitrachioaiei/ccopy v0.0.0-20261015030205-5f8663826b31
+	github.com/shopspring/decimal v1.3.1
+)
@@ -0,0 +1,94 @@
+// Package money provides ccopy customizers that coarsen monetary amounts,
+// held either in shopspring decimals or in integer minor units, like cents.
+//
+// Importing it also registers decimal.Decimal with ccopy as atomic,
+// because its state is unexported and a decimal is immutable.
+package money
+
+import (
+	"github.com/gadumitrachioaiei/ccopy"
+	"github.com/shopspring/decimal"
+)
+
+func init() {
+	ccopy.RegisterAtomic(decimal.Decimal{})
+}
+
+// Round returns a customizer rounding a decimal to the given number of decimal places, half away from zero.
+func Round(places int32) func(decimal.Decimal) decimal.Decimal {
+	return func(d decimal.Decimal) decimal.Decimal {
+		return d.Round(places)
+	}
+}
+
+// ZeroCents drops the fractional part of a decimal, keeping whole units.
+func ZeroCents(d decimal.Decimal) decimal.Decimal {
+	return d.Truncate(0)
+}
+
+// Bucket returns a customizer replacing a decimal with the lower bound of its bucket of the given size,
+// for example with size 100 the amount 1234.56 becomes 1200.
+// It panics if size is not positive.
+func Bucket(size decimal.Decimal) func(decimal.Decimal) decimal.Decimal {
+	if !size.IsPositive() {
+		panic("money: bucket size must be positive")
+	}
+	return func(d decimal.Decimal) decimal.Decimal {
+		return d.Div(size).Floor().Mul(size)
+	}
+}
+
+// Zero replaces a decimal with zero.
+func Zero(decimal.Decimal) decimal.Decimal {
+	return decimal.Zero
+}
+
+// NullRound is Round for nullable decimals, keeping null values.
+func NullRound(places int32) func(decimal.NullDecimal) decimal.NullDecimal {
+	round := Round(places)
+	return func(d decimal.NullDecimal) decimal.NullDecimal {
+		if d.Valid {
+			d.Decimal = round(d.Decimal)
+		}
+		return d
+	}
+}
+
+// RoundMinor returns a customizer rounding an amount in minor units to a multiple of unit, half away from zero,
+// for example with unit 100 the amount of 1250 cents becomes 1300.
+// It panics if unit is not positive.
+func RoundMinor(unit int64) func(int64) int64 {
+	if unit <= 0 {
+		panic("money: unit must be positive")
+	}
+	return func(amount int64) int64 {
+		r := amount % unit
+		amount -= r
+		if r >= (unit+1)/2 {
+			amount += unit
+		} else if -r >= (unit+1)/2 {
+			amount -= unit
+		}
+		return amount
+	}
+}
+
+// BucketMinor returns a customizer replacing an amount in minor units with the lower bound of its bucket of the given size.
+// It panics if size is not positive.
+func BucketMinor(size int64) func(int64) int64 {
+	if size <= 0 {
+		panic("money: bucket size must be positive")
+	}
+	return func(amount int64) int64 {
+		b := amount / size * size
+		if b > amount {
+			b -= size
+		}
+		return b
+	}
+}
+
+// ZeroCentsMinor drops the cents of an amount in cents, keeping whole units.
+func ZeroCentsMinor(amount int64) int64 {
+	return amount / 100 * 100
+}
@@ -0,0 +1,74 @@
+package money
+
+import (
+	"testing"
+
+	"github.com/gadumitrachioaiei/ccopy"
+	"github.com/shopspring/decimal"
+)
+
+func TestDecimal(t *testing.T) {
+	d := decimal.RequireFromString("1234.567")
+	tests := []struct {
+		name     string
+		fn       func(decimal.Decimal) decimal.Decimal
+		expected string
+	}{
+		{"round", Round(2), "1234.57"},
+		{"zero cents", ZeroCents, "1234"},
+		{"bucket", Bucket(decimal.NewFromInt(100)), "1200"},
+		{"negative bucket", func(d decimal.Decimal) decimal.Decimal { return Bucket(decimal.NewFromInt(100))(d.Neg()) }, "-1300"},
+		{"zero", Zero, "0"},
+	}
+	for _, test := range tests {
+		if got := test.fn(d); !got.Equal(decimal.RequireFromString(test.expected)) {
+			t.Fatalf("%s: got: %s, expected: %s", test.name, got, test.expected)
+		}
+	}
+}
+
+func TestMinor(t *testing.T) {
+	tests := []struct {
+		name     string
+		fn       func(int64) int64
+		amount   int64
+		expected int64
+	}{
+		{"round up", RoundMinor(100), 1250, 1300},
+		{"round down", RoundMinor(100), 1249, 1200},
+		{"round negative", RoundMinor(100), -1250, -1300},
+		{"bucket", BucketMinor(1000), 1999, 1000},
+		{"bucket negative", BucketMinor(1000), -1, -1000},
+		{"zero cents", ZeroCentsMinor, 1999, 1900},
+	}
+	for _, test := range tests {
+		if got := test.fn(test.amount); got != test.expected {
+			t.Fatalf("%s: got: %d, expected: %d", test.name, got, test.expected)
+		}
+	}
+}
+
+func TestCopy(t *testing.T) {
+	type Invoice struct {
+		Total    decimal.Decimal `ccopy:"round"`
+		Discount *decimal.Decimal
+		Tax      decimal.NullDecimal `ccopy:"nullRound"`
+	}
+	discount := decimal.RequireFromString("0.5")
+	obj := Invoice{Total: decimal.RequireFromString("10.129"), Discount: &discount, Tax: decimal.NewNullDecimal(decimal.RequireFromString("1.555"))}
+	c := ccopy.Config{"round": Round(2), "nullRound": NullRound(1)}
+	vi, err := c.Copy(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := vi.(Invoice)
+	if !v.Total.Equal(decimal.RequireFromString("10.13")) {
+		t.Fatalf("got total: %s, expected: 10.13", v.Total)
+	}
+	if v.Discount == obj.Discount || !v.Discount.Equal(discount) {
+		t.Fatalf("got discount: %s, expected a copy of: %s", v.Discount, discount)
+	}
+	if !v.Tax.Valid || !v.Tax.Decimal.Equal(decimal.RequireFromString("1.6")) {
+		t.Fatalf("got tax: %v, expected: 1.6", v.Tax)
+	}
+}
@@ -0,0 +1,99 @@
+// Package nationalid provides ccopy detectors of national identification numbers, for scans of values
+// that must meet regional requirements: US social security numbers, UK national insurance numbers
+// and Brazilian CPF numbers.
+//
+// The detectors match whole values, formatted or not, and check the numbers are valid,
+// not only that they look like ones, which keeps other numbers of the same length from being reported.
+package nationalid
+
+import (
+	"strings"
+
+	"github.com/gadumitrachioaiei/ccopy"
+)
+
+var (
+	// SSN detects US social security numbers, like "123-45-6789", of kind "ssn".
+	SSN = ccopy.StringDetector("ssn", ValidSSN)
+	// NINO detects UK national insurance numbers, like "AB 12 34 56 C", of kind "nino".
+	NINO = ccopy.StringDetector("nino", ValidNINO)
+	// CPF detects Brazilian individual taxpayer numbers, like "123.456.789-09", of kind "cpf".
+	CPF = ccopy.StringDetector("cpf", ValidCPF)
+)
+
+// Register registers the detectors of the package with ccopy.RegisterDetector.
+func Register() {
+	for _, d := range []ccopy.Detector{SSN, NINO, CPF} {
+		ccopy.RegisterDetector(d)
+	}
+}
+
+// ValidSSN reports whether s is a social security number, with or without dashes or spaces between its groups:
+// 9 digits, with an area other than 000, 666 and 900 to 999, a group other than 00 and a serial other than 0000.
+func ValidSSN(s string) bool {
+	if len(s) == 11 && (s[3] == '-' && s[6] == '-' || s[3] == ' ' && s[6] == ' ') {
+		s = s[:3] + s[4:6] + s[7:]
+	}
+	if len(s) != 9 || !digits(s) {
+		return false
+	}
+	area, group, serial := s[:3], s[3:5], s[5:]
+	return area != "000" && area != "666" && area[0] != '9' && group != "00" && serial != "0000"
+}
+
+// ValidNINO reports whether s is a national insurance number, with or without spaces:
+// two prefix letters, six digits and a suffix letter from A to D, the prefixes that are never allocated excepted.
+// Lower case letters are accepted.
+func ValidNINO(s string) bool {
+	s = strings.ToUpper(strings.ReplaceAll(s, " ", ""))
+	if len(s) != 9 || !digits(s[2:8]) || s[8] < 'A' || s[8] > 'D' {
+		return false
+	}
+	first, second := s[0], s[1]
+	if first < 'A' || first > 'Z' || second < 'A' || second > 'Z' {
+		return false
+	}
+	if strings.IndexByte("DFIQUV", first) >= 0 || strings.IndexByte("DFIOQUV", second) >= 0 {
+		return false
+	}
+	switch s[:2] {
+	case "BG", "GB", "KN", "NK", "NT", "TN", "ZZ":
+		return false
+	}
+	return true
+}
+
+// ValidCPF reports whether s is a CPF number, formatted like "123.456.789-09" or not:
+// 11 digits, not all the same, ending with the two check digits of the first 9.
+func ValidCPF(s string) bool {
+	if len(s) == 14 && s[3] == '.' && s[7] == '.' && s[11] == '-' {
+		s = s[:3] + s[4:7] + s[8:11] + s[12:]
+	}
+	if len(s) != 11 || !digits(s) || strings.Count(s, s[:1]) == len(s) {
+		return false
+	}
+	return cpfDigit(s[:9]) == s[9] && cpfDigit(s[:10]) == s[10]
+}
+
+// cpfDigit returns the check digit of the digits of s.
+func cpfDigit(s string) byte {
+	sum := 0
+	for i := 0; i < len(s); i++ {
+		sum += int(s[i]-'0') * (len(s) + 1 - i)
+	}
+	d := sum * 10 % 11
+	if d == 10 {
+		d = 0
+	}
+	return byte('0' + d)
+}
+
+// digits reports whether s is made of ASCII digits only.
+func digits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
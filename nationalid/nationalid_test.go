@@ -0,0 +1,66 @@
+package nationalid
+
+import (
+	"testing"
+
+	"github.com/gadumitrachioaiei/ccopy"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestValid(t *testing.T) {
+	tests := []struct {
+		valid func(string) bool
+		s     string
+		ok    bool
+	}{
+		{ValidSSN, "123-45-6789", true},
+		{ValidSSN, "123 45 6789", true},
+		{ValidSSN, "123456789", true},
+		{ValidSSN, "000-45-6789", false},
+		{ValidSSN, "666-45-6789", false},
+		{ValidSSN, "912-45-6789", false},
+		{ValidSSN, "123-00-6789", false},
+		{ValidSSN, "123-45-0000", false},
+		{ValidSSN, "123-456-789", false},
+		{ValidNINO, "AB 12 34 56 C", true},
+		{ValidNINO, "ab123456d", true},
+		{ValidNINO, "AB123456E", false},
+		{ValidNINO, "DA123456A", false},
+		{ValidNINO, "AO123456A", false},
+		{ValidNINO, "GB123456A", false},
+		{ValidNINO, "AB12345C", false},
+		{ValidCPF, "111.444.777-35", true},
+		{ValidCPF, "11144477735", true},
+		{ValidCPF, "111.444.777-36", false},
+		{ValidCPF, "111.111.111-11", false},
+		{ValidCPF, "111.444.77735", false},
+	}
+	for _, test := range tests {
+		if ok := test.valid(test.s); ok != test.ok {
+			t.Errorf("%s: got: %t, expected: %t", test.s, ok, test.ok)
+		}
+	}
+}
+
+func TestRegister(t *testing.T) {
+	type Person struct {
+		Name   string
+		SSN    string
+		IDs    map[string]string
+		Phones []string
+	}
+	Register()
+	p := Person{Name: "John", SSN: " 123-45-6789 ", IDs: map[string]string{"uk": "AB 12 34 56 C", "br": "111.444.777-35"}, Phones: []string{"555-123-4567"}}
+	expected := []ccopy.Finding{
+		{Path: "Person.SSN", Kind: "ssn"},
+		{Path: `Person.IDs["br"]`, Kind: "cpf"},
+		{Path: `Person.IDs["uk"]`, Kind: "nino"},
+	}
+	c, err := ccopy.NewCopier(ccopy.Config{}, ccopy.Options{KeysInPaths: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(expected, c.Scan(&p)); diff != "" {
+		t.Fatal(diff)
+	}
+}
@@ -0,0 +1,144 @@
+package ccopy
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+)
+
+// sortedEntries returns the keys and values of map m, sorted by key with compareValues.
+// The values are not looked up by key, as keys with NaN values cannot be.
+func sortedEntries(m reflect.Value) [][2]reflect.Value {
+	entries := make([][2]reflect.Value, 0, m.Len())
+	iter := m.MapRange()
+	for iter.Next() {
+		entries = append(entries, [2]reflect.Value{iter.Key(), iter.Value()})
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return compareValues(entries[i][0], entries[j][0]) < 0 })
+	return entries
+}
+
+// containsNaN reports whether v is or contains a NaN value, making it unequal to itself.
+func containsNaN(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return math.IsNaN(v.Float())
+	case reflect.Complex64, reflect.Complex128:
+		return math.IsNaN(real(v.Complex())) || math.IsNaN(imag(v.Complex()))
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if containsNaN(v.Index(i)) {
+				return true
+			}
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if containsNaN(v.Field(i)) {
+				return true
+			}
+		}
+	case reflect.Interface:
+		return !v.IsNil() && containsNaN(v.Elem())
+	}
+	return false
+}
+
+// compareValues compares values of the same type, returning -1, 0 or +1.
+// Values of kinds without a natural order, like pointers and channels, are compared by address,
+// which orders them deterministically only within a process.
+func compareValues(a, b reflect.Value) int {
+	switch a.Kind() {
+	case reflect.Bool:
+		return compareInts(boolInt(a.Bool()), boolInt(b.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return compareInts(a.Int(), b.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return compareUints(a.Uint(), b.Uint())
+	case reflect.Float32, reflect.Float64:
+		return compareFloats(a.Float(), b.Float())
+	case reflect.Complex64, reflect.Complex128:
+		if c := compareFloats(real(a.Complex()), real(b.Complex())); c != 0 {
+			return c
+		}
+		return compareFloats(imag(a.Complex()), imag(b.Complex()))
+	case reflect.String:
+		return compareStrings(a.String(), b.String())
+	case reflect.Ptr, reflect.Chan, reflect.UnsafePointer:
+		return compareUints(uint64(a.Pointer()), uint64(b.Pointer()))
+	case reflect.Array:
+		for i := 0; i < a.Len(); i++ {
+			if c := compareValues(a.Index(i), b.Index(i)); c != 0 {
+				return c
+			}
+		}
+		return 0
+	case reflect.Struct:
+		for i := 0; i < a.NumField(); i++ {
+			if c := compareValues(a.Field(i), b.Field(i)); c != 0 {
+				return c
+			}
+		}
+		return 0
+	case reflect.Interface:
+		if a.IsNil() || b.IsNil() {
+			return compareInts(boolInt(!a.IsNil()), boolInt(!b.IsNil()))
+		}
+		ea, eb := a.Elem(), b.Elem()
+		if ea.Type() != eb.Type() {
+			return compareStrings(ea.Type().String(), eb.Type().String())
+		}
+		return compareValues(ea, eb)
+	}
+	return compareStrings(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+func boolInt(b bool) int64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func compareInts(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func compareUints(a, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// compareFloats orders NaN before every other value.
+func compareFloats(a, b float64) int {
+	switch {
+	case math.IsNaN(a) || math.IsNaN(b):
+		return compareInts(boolInt(!math.IsNaN(a)), boolInt(!math.IsNaN(b)))
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func compareStrings(a, b string) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,197 @@
+package ccopy
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSortMapKeys(t *testing.T) {
+	type Key struct {
+		A string
+		B int
+	}
+	type T struct {
+		Names  map[string]string      `json:"-"`
+		Scores map[Key][]string       `json:"-"`
+		Any    map[interface{}]string `json:"-"`
+		Nested map[int]map[string][]float64
+		Words  []string
+	}
+	obj := T{
+		Names:  map[string]string{"c": "3", "a": "1", "b": "2", "d": "4"},
+		Scores: map[Key][]string{{"b", 1}: {"x"}, {"a", 2}: {"y"}, {"a", 1}: {"z"}},
+		Any:    map[interface{}]string{2: "int", "s": "string", 1: "int"},
+		Nested: map[int]map[string][]float64{2: {"b": {1}, "a": {2}}, 1: {"c": {3}}},
+		Words:  []string{"q", "r"},
+	}
+	run := func() ([]string, []byte) {
+		var calls []string
+		r := rand.New(rand.NewSource(1))
+		record := func(s string) string {
+			calls = append(calls, s)
+			return s + string(rune('a'+r.Intn(26)))
+		}
+		c, err := NewCopier(Config{"record": record}, Options{SortMapKeys: true, Rules: Rules{
+			"T.Names{}":    "record",
+			"T.Scores{}[]": "record",
+			"T.Any{}":      "record",
+			"T.Words[]":    "record",
+		}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		v, err := c.Copy(obj)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return calls, data
+	}
+	calls, data := run()
+	expected := []string{"1", "2", "3", "4", "z", "y", "x", "int", "int", "string", "q", "r"}
+	if diff := cmp.Diff(calls, expected); diff != "" {
+		t.Fatal(diff)
+	}
+	for i := 0; i < 20; i++ {
+		c, d := run()
+		if diff := cmp.Diff(c, calls); diff != "" {
+			t.Fatal(diff)
+		}
+		if string(d) != string(data) {
+			t.Fatalf("got output: %s, expected: %s", d, data)
+		}
+	}
+}
+
+func TestNaNKeys(t *testing.T) {
+	type Point struct {
+		X, Y float64
+	}
+	type T struct {
+		Scores map[float64]int
+		Points map[Point]string
+		Any    map[interface{}]string
+	}
+	nan := math.NaN()
+	obj := T{
+		Scores: map[float64]int{nan: 1, 1: 2},
+		Points: map[Point]string{{1, nan}: "a", {1, 2}: "b"},
+		Any:    map[interface{}]string{[2]float64{nan, 0}: "a", complex(nan, 0): "b", "c": "c"},
+	}
+	obj.Scores[nan] = 3
+
+	for _, sort := range []bool{false, true} {
+		c, err := NewCopier(Config{}, Options{SortMapKeys: sort})
+		if err != nil {
+			t.Fatal(err)
+		}
+		res, warnings, err := c.CopyWarnings(obj)
+		if err != nil {
+			t.Fatal(err)
+		}
+		copied := res.(T)
+		if len(copied.Scores) != 3 || len(copied.Points) != 2 || len(copied.Any) != 3 {
+			t.Fatalf("got %v, expected all entries to be kept", copied)
+		}
+		if len(warnings) != 3 {
+			t.Fatalf("got %v, expected a warning per map type", warnings)
+		}
+		for _, w := range warnings {
+			if w.Kind != WarningNaNKey {
+				t.Fatalf("got %v, expected %v", w.Kind, WarningNaNKey)
+			}
+		}
+
+		c, err = NewCopier(Config{}, Options{SortMapKeys: sort, NaNKeys: NaNKeysDrop})
+		if err != nil {
+			t.Fatal(err)
+		}
+		res, err = c.Copy(obj)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := T{Scores: map[float64]int{1: 2}, Points: map[Point]string{{1, 2}: "b"}, Any: map[interface{}]string{"c": "c"}}
+		if diff := cmp.Diff(res, expected); diff != "" {
+			t.Fatal(diff)
+		}
+
+		c, err = NewCopier(Config{}, Options{SortMapKeys: sort, NaNKeys: NaNKeysError, KeysInPaths: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = c.Copy(T{Points: obj.Points})
+		var nanErr *ErrNaNKey
+		if !errors.As(err, &nanErr) || nanErr.Path != "T.Points[{1 NaN}]" {
+			t.Fatalf("got %v, expected an ErrNaNKey at T.Points[{1 NaN}]", err)
+		}
+	}
+}
+
+func TestStructKeys(t *testing.T) {
+	type Key struct {
+		Email string `ccopy:"email"`
+		Rank  *int
+	}
+	type T struct {
+		ByKey map[Key]string
+	}
+	one, two := 1, 2
+	obj := T{ByKey: map[Key]string{{"a@x.com", &one}: "a", {"b@x.com", &one}: "b", {"c@x.com", &two}: "c"}}
+	config := Config{"email": func(s string) string { return "user@example.com" }}
+
+	// the entries whose keys collide are kept once by default
+	res, err := config.Copy(T{ByKey: map[Key]string{{"a@x.com", nil}: "a", {"b@x.com", nil}: "b"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.(T).ByKey) != 1 {
+		t.Fatalf("got %v, expected 1 entry", res)
+	}
+
+	c, err := NewCopier(config, Options{KeyCollisions: KeyCollisionsError})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.Copy(T{ByKey: map[Key]string{{"a@x.com", nil}: "a", {"b@x.com", nil}: "b"}})
+	var collision *ErrKeyCollision
+	if !errors.As(err, &collision) || !strings.HasPrefix(collision.Path, "T.ByKey[") {
+		t.Fatalf("got %v, expected an ErrKeyCollision in T.ByKey", err)
+	}
+
+	// copied pointers make distinct keys
+	res, err = c.Copy(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.(T).ByKey) != 3 {
+		t.Fatalf("got %v, expected 3 entries", res)
+	}
+	for k := range res.(T).ByKey {
+		if k.Rank == &one || k.Rank == &two {
+			t.Fatalf("got key %v, expected its pointer to be copied", k)
+		}
+	}
+
+	for policy, expected := range map[KeyCollisionPolicy]string{KeyCollisionsKeepFirst: "a", KeyCollisionsKeepLast: "c"} {
+		c, _ := NewCopier(Config{"email": func(s string) string { return "user@example.com" }}, Options{SortMapKeys: true, KeyCollisions: policy})
+		res, warnings, err := c.CopyWarnings(T{ByKey: map[Key]string{{"a@x.com", nil}: "a", {"b@x.com", nil}: "b", {"c@x.com", nil}: "c"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(res, T{ByKey: map[Key]string{{"user@example.com", nil}: expected}}); diff != "" {
+			t.Fatal(diff)
+		}
+		if len(warnings) != 1 || warnings[0].Kind != WarningKeyCollision {
+			t.Fatalf("got %v, expected a key collision warning", warnings)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package ccopy
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// pathPrefix starts the keys of the config of the customizers registered with RegisterPath,
+// followed by the path of the values they customize, like "path:User.Addresses[].Street".
+const pathPrefix = "path:"
+
+// RegisterPath adds fn to the config, as the customizer of the values at path, written like the paths of Rules,
+// like "User.Addresses[].Street", for the fields of types that cannot be tagged, like the types of other packages.
+// It is added under the key "path:" followed by path, and applied like a rule of the Rules option naming that key:
+// the copies of the config match path wherever the type it starts from is found.
+// It must be called before the config is used by copies, and it panics if path is not valid or fn is not a function.
+func (c Config) RegisterPath(path string, fn interface{}) {
+	if _, err := (Rules{path: pathPrefix + path}).parse(); err != nil {
+		panic(fmt.Sprintf("ccopy: register path: %v", err))
+	}
+	if reflect.TypeOf(fn) == nil || reflect.TypeOf(fn).Kind() != reflect.Func {
+		panic(fmt.Sprintf("ccopy: register path: expected a customizer, got: %T", fn))
+	}
+	c[pathPrefix+path] = fn
+}
+
+// pathRules returns the rules of the customizers of c registered with RegisterPath, in lexical order of their paths.
+func (c Config) pathRules() []*rule {
+	paths := make(Rules)
+	for name := range c {
+		if path, ok := strings.CutPrefix(name, pathPrefix); ok {
+			paths[path] = name
+		}
+	}
+	if len(paths) == 0 {
+		return nil
+	}
+	// the paths are checked by RegisterPath
+	rules, _ := paths.parse()
+	return rules
+}
@@ -0,0 +1,47 @@
+package ccopy
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRegisterPath(t *testing.T) {
+	c := Config{}
+	c.RegisterPath("User.Addresses[].Street", func(string) string { return "street" })
+	c.RegisterPath("User.Tags{}", func(string) string { return "tag" })
+	if _, ok := c["path:User.Addresses[].Street"]; !ok {
+		t.Fatalf("expected the customizer to be registered under its path, got: %v", c)
+	}
+	u := User{Email: "a@b.c", Addresses: []Address{{Street: "Main", City: "X"}}, Tags: map[string]string{"k": "v"}}
+	expected := User{Email: "a@b.c", Addresses: []Address{{Street: "street", City: "X"}}, Tags: map[string]string{"k": "tag"}}
+	v, err := c.Copy(map[string]*User{"u": &u})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(map[string]*User{"u": &expected}, v); diff != "" {
+		t.Fatal(diff)
+	}
+
+	// the rules of the options come first
+	config := Config{"address": func(Address) Address { return Address{City: "rule"} }}
+	config.RegisterPath("User.Addresses[].Street", func(string) string { return "street" })
+	cp, err := NewCopier(config, Options{Rules: Rules{"User.Addresses[]": "address"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err = cp.Copy(u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]Address{{City: "rule"}}, v.(User).Addresses); diff != "" {
+		t.Fatal(diff)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic for an invalid path")
+		}
+	}()
+	c.RegisterPath("User..Email", func(string) string { return "" })
+}
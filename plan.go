@@ -0,0 +1,305 @@
+package ccopy
+
+import (
+	"expvar"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// structPlan is what a copy needs to know about a struct type, computed once per type.
+type structPlan struct {
+	fields []fieldPlan
+	// unexported are the names of the fields that are not copied
+	unexported []string
+	// exposed is set for the types whose unexported fields are copied, with the Unexported option
+	exposed bool
+	// flat is set for the types copied by assignment, with the FlatStructs option
+	flat bool
+	// costly is set for the types whose cost exceeds the CostLimits option
+	costly bool
+	// union is set for the types registered with RegisterUnion
+	union *union
+}
+
+type fieldPlan struct {
+	index int
+	tag   string
+	step  step
+	// fast is the direct copy of the field, if its type has one and it is not tagged
+	fast fastCopy
+	// customizer is the customizer of the tag, of signature sig, when the tag is the name
+	// of a customizer of the config that can customize the field, so copies do not look it up
+	customizer reflect.Value
+	sig        signature
+	// unexported is set for the unexported fields, copied with the Unexported option
+	unexported bool
+}
+
+// field returns the plan of the field of index i, nil for the fields that are not copied.
+func (sp *structPlan) field(i int) *fieldPlan {
+	for j := range sp.fields {
+		if sp.fields[j].index == i {
+			return &sp.fields[j]
+		}
+	}
+	return nil
+}
+
+// plans caches the plans of a Copier, keyed by type.
+type plans struct {
+	// counters first, for 64 bit alignment
+	hits        uint64
+	misses      uint64
+	compileTime int64
+	size        int64
+	// handlerCalls counts the calls of the handlers registered with RegisterHandler
+	handlerCalls uint64
+
+	m sync.Map
+
+	// config is the config of the Copier, whose customizers are resolved by the plans
+	config Config
+	// order is the FieldOrder option
+	order func(t reflect.Type, fields []reflect.StructField) []reflect.StructField
+	// flatStructs is the FlatStructs option, and rules the rules of the options
+	flatStructs bool
+	rules       []*rule
+	// canonical is the Canonical option, which has no fast paths
+	canonical bool
+	// costLimits is the CostLimits option
+	costLimits CostLimits
+	// unexported is the Unexported option
+	unexported bool
+	// cloneStrings is set by the StringsClone policy of the Strings option, which has no fast paths for strings
+	cloneStrings bool
+	// uncustomized is set for the full copies of CopyBoth, which ignore the tags and the registered rules
+	uncustomized bool
+
+	// types caches the customizers of the config registered with RegisterType, by type
+	types atomic.Value
+
+	// transitions caches the transitions of the matches of the rules, by transition,
+	// and anchors the matches starting at the values of a type, by type
+	transitions sync.Map
+	anchors     sync.Map
+}
+
+// Stats represents counters of the plan cache of a Copier.
+type Stats struct {
+	// Hits counts the plans found in the cache.
+	Hits uint64
+	// Misses counts the plans that had to be compiled.
+	Misses uint64
+	// CompileTime is the total time spent compiling plans.
+	CompileTime time.Duration
+	// Plans is the number of cached plans, one per distinct struct type.
+	Plans int
+	// HandlerCalls counts the values copied by handlers registered with RegisterHandler.
+	HandlerCalls uint64
+}
+
+func (p *plans) structPlan(t reflect.Type) *structPlan {
+	if sp, ok := p.m.Load(t); ok {
+		atomic.AddUint64(&p.hits, 1)
+		return sp.(*structPlan)
+	}
+	atomic.AddUint64(&p.misses, 1)
+	start := time.Now()
+	sp := compileStruct(t, p.order, p.unexported, !p.uncustomized)
+	if p.flatStructs {
+		sp.flat = p.flatType(t)
+	}
+	sp.union = unionOf(t)
+	p.resolve(t, sp)
+	if p.costLimits.set() {
+		sp.costly = p.costLimits.exceeded(EstimateCost(t))
+	}
+	for i := range sp.fields {
+		if f := &sp.fields[i]; p.canonical || f.fast != nil && (p.typeCustomized(t.Field(f.index).Type) || p.cloneStrings && holdsString(t.Field(f.index).Type)) {
+			f.fast = nil
+		}
+	}
+	atomic.AddInt64(&p.compileTime, int64(time.Since(start)))
+	if actual, loaded := p.m.LoadOrStore(t, sp); loaded {
+		return actual.(*structPlan)
+	}
+	atomic.AddInt64(&p.size, 1)
+	return sp
+}
+
+// resolve resolves the customizers of the fields of sp, of struct type t, whose tags are names of customizers.
+func (p *plans) resolve(t reflect.Type, sp *structPlan) {
+	for i := range sp.fields {
+		f := &sp.fields[i]
+		fn, ok := p.config[f.tag]
+		if name, _ := splitTag(f.tag); !ok && name != f.tag && !strings.HasPrefix(f.tag, divePrefix) &&
+			!strings.HasPrefix(f.tag, idmapPrefix) && !strings.HasSuffix(f.tag, uniqueOption) && len(p.config.chain(f.tag)) == 1 {
+			// tags with arguments, but not chains, which are resolved by every copy
+			fn, ok = p.config[name]
+		}
+		if f.tag == "" || f.tag == tagSkip || f.tag == tagShallow || !ok || fn == nil {
+			continue
+		}
+		fv := reflect.ValueOf(fn)
+		if sig, ok := signatureOf(fv.Type(), t.Field(f.index).Type); ok {
+			f.customizer, f.sig = fv, sig
+		}
+	}
+}
+
+// Compile returns a Copier for the config, like NewCopier without options, having compiled the plans
+// of the struct types reachable from the type of prototype: which fields are copied, how, and with which customizers.
+// So the copies of values of that type neither parse tags nor look up customizers.
+// The types held by interfaces are not known without values: their plans are compiled by their first copy.
+func (c Config) Compile(prototype interface{}) (*Copier, error) {
+	t := reflect.TypeOf(prototype)
+	if t == nil {
+		return nil, ErrInvalidValue
+	}
+	cp, err := NewCopier(c, Options{})
+	if err != nil {
+		return nil, err
+	}
+	cp.plans.compile(t, make(map[reflect.Type]bool))
+	return cp, nil
+}
+
+// Warm compiles the plans of the struct types reachable from the types of the given values, like Config.Compile,
+// so the first copies of values of those types do not compile them, like at the startup of a service.
+// The values can be reflect.Type values as well, like the types returned by ModelType.
+func (c *Copier) Warm(types ...interface{}) {
+	p := &c.live().plans
+	seen := make(map[reflect.Type]bool)
+	for _, v := range types {
+		t, ok := v.(reflect.Type)
+		if !ok {
+			t = reflect.TypeOf(v)
+		}
+		if t != nil {
+			p.compile(t, seen)
+		}
+	}
+}
+
+// warmModels warms the plans of the registered models.
+func (c *Copier) warmModels() {
+	var types []interface{}
+	for _, name := range Models() {
+		if t, ok := ModelType(name); ok {
+			types = append(types, t)
+		}
+	}
+	c.Warm(types...)
+}
+
+// compile compiles the plans of the struct types reachable from type t, except the types in seen.
+func (p *plans) compile(t reflect.Type, seen map[reflect.Type]bool) {
+	if seen[t] {
+		return
+	}
+	seen[t] = true
+	if _, ok := typeHandler(t); ok {
+		return
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		for _, f := range p.structPlan(t).fields {
+			if f.fast == nil {
+				p.compile(t.Field(f.index).Type, seen)
+			}
+		}
+	case reflect.Ptr, reflect.Slice, reflect.Array:
+		p.compile(t.Elem(), seen)
+	case reflect.Map:
+		p.compile(t.Key(), seen)
+		p.compile(t.Elem(), seen)
+	}
+}
+
+const (
+	// tagSkip is the tag of the fields left out of the copies, as the zero values of their types.
+	tagSkip = "-"
+	// tagShallow is the tag of the fields copied by assignment, sharing what they point to with the copied values.
+	tagShallow = "shallow"
+)
+
+func compileStruct(t reflect.Type, order func(reflect.Type, []reflect.StructField) []reflect.StructField, unexported, tagged bool) *structPlan {
+	sp := &structPlan{}
+	var fields []reflect.StructField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		// skip unexported fields, unless they are copied
+		if f.PkgPath != "" && !unexported {
+			sp.unexported = append(sp.unexported, f.Name)
+			continue
+		}
+		fields = append(fields, f)
+	}
+	if order != nil {
+		fields = reorder(t, fields, order)
+	}
+	for _, f := range fields {
+		fp := fieldPlan{index: f.Index[0], step: fieldStep(f), unexported: f.PkgPath != ""}
+		if tagged {
+			fp.tag = f.Tag.Get(tagCcopy)
+		}
+		sp.exposed = sp.exposed || fp.unexported
+		if fp.tag == "" {
+			fp.fast = fastPath(f.Type)
+		}
+		sp.fields = append(sp.fields, fp)
+	}
+	return sp
+}
+
+// reorder orders fields with order, panicking if it does not return a permutation of them.
+func reorder(t reflect.Type, fields []reflect.StructField, order func(reflect.Type, []reflect.StructField) []reflect.StructField) []reflect.StructField {
+	ordered := order(t, append([]reflect.StructField(nil), fields...))
+	seen := make(map[int]bool)
+	for _, f := range ordered {
+		seen[f.Index[0]] = true
+	}
+	if len(ordered) != len(fields) || len(seen) != len(fields) {
+		panic(fmt.Sprintf("ccopy: field order of %s: expected a permutation of %d fields, got %d fields", t, len(fields), len(ordered)))
+	}
+	for _, f := range fields {
+		if !seen[f.Index[0]] {
+			panic(fmt.Sprintf("ccopy: field order of %s: missing field %s", t, f.Name))
+		}
+	}
+	return ordered
+}
+
+// IDMapFirst is a FieldOrder that copies the fields tagged with "idmap=name" first,
+// so the identifiers of an object are mapped in the session before the customizers of its other fields run.
+func IDMapFirst(t reflect.Type, fields []reflect.StructField) []reflect.StructField {
+	sort.SliceStable(fields, func(i, j int) bool {
+		return strings.HasPrefix(fields[i].Tag.Get(tagCcopy), idmapPrefix) && !strings.HasPrefix(fields[j].Tag.Get(tagCcopy), idmapPrefix)
+	})
+	return fields
+}
+
+// Stats returns the counters of the plan cache.
+// After a Swap, they are the counters of the plans of the new config.
+func (c *Copier) Stats() Stats {
+	p := &c.live().plans
+	return Stats{
+		Hits:        atomic.LoadUint64(&p.hits),
+		Misses:      atomic.LoadUint64(&p.misses),
+		CompileTime: time.Duration(atomic.LoadInt64(&p.compileTime)),
+		Plans:       int(atomic.LoadInt64(&p.size)),
+
+		HandlerCalls: atomic.LoadUint64(&p.handlerCalls),
+	}
+}
+
+// Var returns the stats as an expvar variable, to be published by the caller,
+// for example with expvar.Publish("ccopy", c.Var()).
+func (c *Copier) Var() expvar.Var {
+	return expvar.Func(func() interface{} { return c.Stats() })
+}
@@ -0,0 +1,188 @@
+package ccopy
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCopierStats(t *testing.T) {
+	c, err := NewCopier(Config{}, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := c.Copy(User{Addresses: []Address{{}, {}}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s := c.Stats()
+	if s.Misses != 2 || s.Hits != 7 || s.Plans != 2 {
+		t.Fatalf("got stats: %+v, expected 2 misses, 7 hits and 2 plans", s)
+	}
+	var published Stats
+	if err := json.Unmarshal([]byte(c.Var().String()), &published); err != nil {
+		t.Fatal(err)
+	}
+	if published != s {
+		t.Fatalf("got published stats: %+v, expected: %+v", published, s)
+	}
+}
+
+func TestCompile(t *testing.T) {
+	type Doc struct {
+		Owner   *User
+		Readers map[string][]User
+		Email   string `ccopy:"redact"`
+	}
+	type Bad struct {
+		Wrong int `ccopy:"redact"`
+	}
+	config := Config{"redact": func(string) string { return "redacted" }}
+	c, err := config.Compile(&Doc{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := c.Stats(); s.Plans != 3 || s.Misses != 3 {
+		t.Fatalf("got stats: %+v, expected the plans of Doc, User and Address", s)
+	}
+	vi, err := c.Copy(Doc{Owner: &User{Email: "john@example.com"}, Email: "doc@example.com"})
+	if err != nil {
+		t.Fatalf("got error: %v", err)
+	}
+	if v := vi.(Doc); v.Email != "redacted" || v.Owner.Email != "john@example.com" {
+		t.Fatalf("got: %+v", v)
+	}
+	if s := c.Stats(); s.Misses != 3 {
+		t.Fatalf("got stats: %+v, expected no other plan compiled", s)
+	}
+	// the customizers that cannot customize a field are not resolved, and fail the copies as usual
+	if c, err = config.Compile(Bad{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Copy(Bad{}); err == nil {
+		t.Fatal("expected error for the bad signature of the customizer of Wrong")
+	}
+	if _, err := (Config{}).Compile(nil); err != ErrInvalidValue {
+		t.Fatalf("got error: %v, expected ErrInvalidValue", err)
+	}
+}
+
+func TestWarm(t *testing.T) {
+	type Address struct {
+		Street string `ccopy:"redact"`
+	}
+	type User struct {
+		Name    string `ccopy:"redact"`
+		Home    *Address
+		Friends map[string][]User
+	}
+	type Order struct {
+		Buyer User
+	}
+	c, err := NewCopier(Config{"redact": func(string) string { return "" }}, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Warm(User{}, reflect.TypeOf(Order{}), nil)
+	warm := c.Stats()
+	if warm.Plans != 3 {
+		t.Fatalf("got %d plans, expected 3", warm.Plans)
+	}
+	u := User{Name: "name", Home: &Address{Street: "street"}, Friends: map[string][]User{"a": {{Name: "friend"}}}}
+	if _, err := CopyWith(c, Order{Buyer: u}); err != nil {
+		t.Fatal(err)
+	}
+	if s := c.Stats(); s.Misses != warm.Misses {
+		t.Fatalf("got %d plan misses, expected %d: no plan compiled by the copy", s.Misses, warm.Misses)
+	}
+}
+
+func TestFieldOrder(t *testing.T) {
+	type T struct {
+		Name    string `ccopy:"name"`
+		OwnerID int    `ccopy:"idmap=id"`
+		ID      int    `ccopy:"idmap=id"`
+	}
+	var order []interface{}
+	cfg := Config{
+		"name": func(v string) string {
+			order = append(order, v)
+			return v
+		},
+		"id": func(v int) int {
+			order = append(order, v)
+			return v
+		},
+	}
+	c, err := NewCopier(cfg, Options{FieldOrder: IDMapFirst})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Copy(T{Name: "a", OwnerID: 1, ID: 2}); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(order, []interface{}{1, 2, "a"}); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestFieldOrderInvalid(t *testing.T) {
+	c, err := NewCopier(Config{}, Options{FieldOrder: func(_ reflect.Type, fields []reflect.StructField) []reflect.StructField {
+		return fields[:1]
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic")
+		}
+	}()
+	c.Copy(Address{})
+}
+
+func TestFastPath(t *testing.T) {
+	type T struct {
+		Name    string
+		Created time.Time
+		Nick    *string
+		Tags    []string
+		Labels  map[string]string
+		Empty   []string
+	}
+	nick := "bob"
+	obj := T{Name: "a", Created: time.Now(), Nick: &nick, Tags: []string{"x"}, Labels: map[string]string{"k": "v"}}
+	c, err := NewCopier(Config{"upper": strings.ToUpper}, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := c.Copy(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(res, obj); diff != "" {
+		t.Fatal(diff)
+	}
+	copied := res.(T)
+	if copied.Nick == obj.Nick || &copied.Tags[0] == &obj.Tags[0] || reflect.ValueOf(copied.Labels).Pointer() == reflect.ValueOf(obj.Labels).Pointer() {
+		t.Fatal("expected the pointer, slice and map to be copied")
+	}
+
+	// rules reaching into the fields take the general path
+	c, err = NewCopier(Config{"upper": strings.ToUpper}, Options{Rules: Rules{"T.Tags[]": "upper", "T.Labels{}": "upper", "T.Name": "upper"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err = c.Copy(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if copied := res.(T); copied.Name != "A" || copied.Tags[0] != "X" || copied.Labels["k"] != "V" {
+		t.Fatalf("got %v, expected the rules to apply", copied)
+	}
+}
@@ -0,0 +1,157 @@
+package ccopy
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// PointerPolicy is what a copy does with the pointers of the original pointing to the same value,
+// like the values of a map pointing to the same object.
+type PointerPolicy int
+
+const (
+	// PointersDuplicate copies the value pointed to for every pointer, so the pointers of the copy
+	// point to distinct values, even when the pointers of the original point to the same one.
+	// Cycles are the exception, as they cannot be copied as trees: a pointer to a value being copied,
+	// that is to a value the pointer is in, like the parent of a child, points to the copy of that value,
+	// so the cycles of the original are cycles of the copy.
+	PointersDuplicate PointerPolicy = iota
+	// PointersShare copies the values pointed to by several pointers once, so the pointers of the copy
+	// share them like the pointers of the original: a copy of map[string]*User whose values point
+	// to the same User has values pointing to the same copy of the User.
+	// The values are shared by the pointers of the same type, reaching them where the same rules match,
+	// as the values the rules customize differently cannot be shared.
+	// Slices sharing a backing array in the original do not share it in the copy.
+	PointersShare
+)
+
+// sharedPointer identifies the values pointed to that are copied once, with PointersShare:
+// by their address and the type of the pointer, and the set of the matches of the rules reaching them.
+type sharedPointer struct {
+	p    uintptr
+	t    reflect.Type
+	from *match
+	n    int
+}
+
+// cyclePointer identifies the values pointed to that are being copied, by their address and the type of the pointer only:
+// the matches of the rules reaching a value again through a cycle may differ from the matches it was reached with first,
+// like with a ** rule, and the cycle must be closed anyway.
+type cyclePointer struct {
+	p uintptr
+	t reflect.Type
+}
+
+// copying returns the copy of the value pointed to by ov if it is being copied, that is if ov closes a cycle.
+func (c *state) copying(ov reflect.Value) (reflect.Value, bool) {
+	v, ok := c.inProgress[cyclePointer{p: ov.Pointer(), t: ov.Type()}]
+	return v, ok
+}
+
+// enter records oc as the copy of the value pointed to by ov while it is copied,
+// and returns the function to call when it is copied.
+func (c *state) enter(ov, oc reflect.Value) func() {
+	if c.inProgress == nil {
+		c.inProgress = make(map[cyclePointer]reflect.Value)
+	}
+	key := cyclePointer{p: ov.Pointer(), t: ov.Type()}
+	c.inProgress[key] = oc
+	return func() { delete(c.inProgress, key) }
+}
+
+// sharedCopy returns the copy of the value pointed to by ov, reached with the matches active, if it was copied already.
+func (c *state) sharedCopy(ov reflect.Value, active []match) (reflect.Value, bool) {
+	v, ok := c.shared[sharedPointer{p: ov.Pointer(), t: ov.Type(), from: first(active), n: len(active)}]
+	return v, ok
+}
+
+// share records oc as the copy of the value pointed to by ov, reached with the matches active.
+// It is recorded before the value is copied, so the pointers to it in the value itself share it too.
+func (c *state) share(ov, oc reflect.Value, active []match) {
+	if c.shared == nil {
+		c.shared = make(map[sharedPointer]reflect.Value)
+	}
+	c.shared[sharedPointer{p: ov.Pointer(), t: ov.Type(), from: first(active), n: len(active)}] = oc
+}
+
+// registeredPointer identifies a pointer registered with RegisterShared.
+type registeredPointer struct {
+	p uintptr
+	t reflect.Type
+}
+
+// sharedValues holds the pointers registered with RegisterShared, as a map[registeredPointer]interface{}
+// replaced on every registration, holding the registered pointers.
+var sharedValues struct {
+	sync.Mutex
+	v atomic.Value
+}
+
+// RegisterShared registers the pointer p as shared: the copies hold p itself, instead of a copy of the value
+// it points to, wherever p is found, like a global configuration or a client referenced all over the objects,
+// which are read-only infrastructure that must not be copied. Other pointers to the same value, of other types,
+// like pointers to its first field, are copied as usual.
+// The pointers are identified by their address, so the values they point to are kept alive while registered,
+// as their addresses could be reused otherwise; UnregisterShared releases them.
+// Tags and rules matching p still customize it.
+// It panics if p is not a non nil pointer.
+func RegisterShared(p interface{}) {
+	key := sharedKeyOf(p, "register")
+	sharedValues.Lock()
+	defer sharedValues.Unlock()
+	old, _ := sharedValues.v.Load().(map[registeredPointer]interface{})
+	m := make(map[registeredPointer]interface{}, len(old)+1)
+	for k, v := range old {
+		m[k] = v
+	}
+	m[key] = p
+	sharedValues.v.Store(m)
+}
+
+// UnregisterShared unregisters the pointer p registered with RegisterShared, if it is.
+// It panics if p is not a non nil pointer.
+func UnregisterShared(p interface{}) {
+	key := sharedKeyOf(p, "unregister")
+	sharedValues.Lock()
+	defer sharedValues.Unlock()
+	old, _ := sharedValues.v.Load().(map[registeredPointer]interface{})
+	if _, ok := old[key]; !ok {
+		return
+	}
+	m := make(map[registeredPointer]interface{}, len(old))
+	for k, v := range old {
+		if k != key {
+			m[k] = v
+		}
+	}
+	sharedValues.v.Store(m)
+}
+
+func sharedKeyOf(p interface{}, op string) registeredPointer {
+	v := reflect.ValueOf(p)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		panic(fmt.Sprintf("ccopy: %s shared: expected a non nil pointer, got: %T", op, p))
+	}
+	return registeredPointer{p: v.Pointer(), t: v.Type()}
+}
+
+// registeredShared returns the pointers registered with RegisterShared, nil if there are none.
+func registeredShared() map[registeredPointer]interface{} {
+	m, _ := sharedValues.v.Load().(map[registeredPointer]interface{})
+	if len(m) == 0 {
+		return nil
+	}
+	return m
+}
+
+// isShared reports whether the pointer ov is registered with RegisterShared.
+func isShared(ov reflect.Value) bool {
+	m := registeredShared()
+	if m == nil {
+		return false
+	}
+	_, ok := m[registeredPointer{p: ov.Pointer(), t: ov.Type()}]
+	return ok
+}
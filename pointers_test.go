@@ -0,0 +1,180 @@
+package ccopy
+
+import (
+	"testing"
+)
+
+type sharedNode struct {
+	Name string
+	Next *sharedNode
+}
+
+func TestCopyPointersShare(t *testing.T) {
+	u := &User{Email: "john@example.com"}
+	name := "john"
+	type Index struct {
+		ByEmail map[string]*User
+		ByName  map[string]*User
+		First   *string
+		Last    *string
+	}
+	obj := Index{ByEmail: map[string]*User{"a": u, "b": u}, ByName: map[string]*User{"john": u}, First: &name, Last: &name}
+
+	vi, err := Config{}.Copy(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := vi.(Index)
+	if v.ByEmail["a"] == v.ByEmail["b"] || v.First == v.Last {
+		t.Fatal("expected the pointers to be duplicated by default")
+	}
+
+	c, err := NewCopier(Config{}, Options{Pointers: PointersShare})
+	if err != nil {
+		t.Fatal(err)
+	}
+	vi, err = c.Copy(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v = vi.(Index)
+	if v.ByEmail["a"] != v.ByEmail["b"] || v.ByEmail["a"] != v.ByName["john"] || v.First != v.Last {
+		t.Fatal("expected the pointers to share their copies")
+	}
+	if v.ByEmail["a"] == u || v.ByEmail["a"].Email != u.Email || v.First == &name || *v.First != name {
+		t.Fatal("expected copies of the values pointed to")
+	}
+
+	// the copies are not shared between copies
+	wi, err := c.Copy(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wi.(Index).ByEmail["a"] == v.ByEmail["a"] {
+		t.Fatal("expected distinct copies for every copy")
+	}
+}
+
+func TestCopyPointersShareCycles(t *testing.T) {
+	a := &sharedNode{Name: "a"}
+	a.Next = &sharedNode{Name: "b", Next: a}
+	c, err := NewCopier(Config{}, Options{Pointers: PointersShare})
+	if err != nil {
+		t.Fatal(err)
+	}
+	vi, err := c.Copy(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := vi.(*sharedNode)
+	if v == a || v.Name != "a" || v.Next.Name != "b" || v.Next.Next != v {
+		t.Fatalf("got: %+v, expected a copy of the cycle", v)
+	}
+}
+
+func TestCopyPointersShareRules(t *testing.T) {
+	type Pair struct {
+		A, B *User
+	}
+	c, err := NewCopier(Config{"redact": func(string) string { return "redacted" }},
+		Options{Pointers: PointersShare, Rules: Rules{"Pair.A.email": "redact"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	u := &User{Email: "john@example.com"}
+	vi, err := c.Copy(Pair{A: u, B: u})
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := vi.(Pair)
+	if v.A == v.B || v.A.Email != "redacted" || v.B.Email != u.Email {
+		t.Fatalf("got: %+v and %+v, expected distinct copies customized by the rules", v.A, v.B)
+	}
+}
+
+func TestCopyPointerCycles(t *testing.T) {
+	a := &sharedNode{Name: "a"}
+	a.Next = &sharedNode{Name: "b", Next: a}
+	vi, err := Config{}.Copy(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := vi.(*sharedNode)
+	if v == a || v.Next == a.Next || v.Next.Next != v {
+		t.Fatalf("got: %+v, expected a copy of the cycle", v)
+	}
+
+	self := &sharedNode{Name: "self"}
+	self.Next = self
+	vi, err = Config{}.Copy([]*sharedNode{self, self})
+	if err != nil {
+		t.Fatal(err)
+	}
+	nodes := vi.([]*sharedNode)
+	if nodes[0].Next != nodes[0] || nodes[1].Next != nodes[1] || nodes[0] == nodes[1] || nodes[0] == self {
+		t.Fatalf("got: %p and %p, expected distinct copies of the cycle", nodes[0], nodes[1])
+	}
+}
+
+func TestCopyPointerCyclesDeepRules(t *testing.T) {
+	type RV4Node struct {
+		Email string
+		Next  *RV4Node
+	}
+	a := &RV4Node{Email: "john@example.com"}
+	a.Next = a
+	for _, pointers := range []PointerPolicy{PointersDuplicate, PointersShare} {
+		c, err := NewCopier(Config{"redact": func(string) string { return "redacted" }},
+			Options{Pointers: pointers, Rules: Rules{"RV4Node.**.Email": "redact"}, MaxElements: 1000})
+		if err != nil {
+			t.Fatal(err)
+		}
+		vi, err := c.Copy(a)
+		if err != nil {
+			t.Fatal(err)
+		}
+		v := vi.(*RV4Node)
+		if v == a || v.Next != v || v.Email != "redacted" {
+			t.Fatalf("pointers %d: got: %+v, expected a redacted copy of the cycle", pointers, v)
+		}
+	}
+}
+
+type sharedConfig struct {
+	Name string
+}
+
+func TestRegisterShared(t *testing.T) {
+	type Service struct {
+		Config *sharedConfig
+		Name   *string
+		Any    interface{}
+	}
+	global := &sharedConfig{Name: "global"}
+	name := "service"
+	RegisterShared(global)
+	RegisterShared(&name)
+	defer UnregisterShared(global)
+	defer UnregisterShared(&name)
+	other := &sharedConfig{Name: "other"}
+	vi, err := Config{}.Copy([]Service{{Config: global, Name: &name, Any: global}, {Config: other}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := vi.([]Service)
+	if v[0].Config != global || v[0].Name != &name || v[0].Any != global {
+		t.Fatal("expected the registered pointers to be shared")
+	}
+	if v[1].Config == other || v[1].Config.Name != "other" {
+		t.Fatal("expected the other pointers to be copied")
+	}
+
+	UnregisterShared(global)
+	vi, err = Config{}.Copy(Service{Config: global})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vi.(Service).Config == global {
+		t.Fatal("expected the unregistered pointer to be copied")
+	}
+}
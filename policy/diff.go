@@ -0,0 +1,86 @@
+package policy
+
+import (
+	"go/types"
+	"sort"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// Change is a path whose action differs between two policies.
+type Change struct {
+	Path string
+	// Old is the action under the old policy, nil if the path is not explained under it,
+	// like the paths of the fields of a struct the old policy customizes as a whole.
+	Old *FieldAction
+	// New is the action under the new policy, nil if the path is not explained under it.
+	New *FieldAction
+}
+
+// StructTypes returns the named struct types declared in pkgs, not in their dependencies,
+// exported or not, sorted by package path and name.
+func StructTypes(pkgs []*packages.Package, exported bool) []*types.Named {
+	var named []*types.Named
+	for _, p := range pkgs {
+		if p.Types == nil {
+			continue
+		}
+		scope := p.Types.Scope()
+		for _, name := range scope.Names() {
+			tn, ok := scope.Lookup(name).(*types.TypeName)
+			if !ok || tn.IsAlias() || exported && !tn.Exported() {
+				continue
+			}
+			if t, ok := tn.Type().(*types.Named); ok {
+				if _, ok := t.Underlying().(*types.Struct); ok {
+					named = append(named, t)
+				}
+			}
+		}
+	}
+	sort.SliceStable(named, func(i, j int) bool {
+		return named[i].Obj().Pkg().Path() < named[j].Obj().Pkg().Path()
+	})
+	return named
+}
+
+// Diff returns the changes of the actions of the values of types ts, from policy old to policy new,
+// sorted by type, in the order of ts, then by path, in the order of Explain.
+func Diff(old, new *Policy, ts []*types.Named) ([]Change, error) {
+	var changes []Change
+	for _, t := range ts {
+		oldActions, err := old.Explain(t)
+		if err != nil {
+			return nil, err
+		}
+		newActions, err := new.Explain(t)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, diffActions(oldActions, newActions)...)
+	}
+	return changes, nil
+}
+
+func diffActions(old, new []FieldAction) []Change {
+	byPath := make(map[string]*FieldAction, len(old))
+	for i := range old {
+		byPath[old[i].Path] = &old[i]
+	}
+	var changes []Change
+	seen := make(map[string]bool, len(new))
+	for i := range new {
+		n := &new[i]
+		seen[n.Path] = true
+		o := byPath[n.Path]
+		if o == nil || o.Action != n.Action || o.Customizer != n.Customizer || o.Source != n.Source {
+			changes = append(changes, Change{Path: n.Path, Old: o, New: n})
+		}
+	}
+	for i := range old {
+		if !seen[old[i].Path] {
+			changes = append(changes, Change{Path: old[i].Path, Old: &old[i]})
+		}
+	}
+	return changes
+}
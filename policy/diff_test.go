@@ -0,0 +1,59 @@
+package policy
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDiff(t *testing.T) {
+	old, err := Read("testdata/old.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	new, err := Read("testdata/new.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkgs, err := Load(".", "./testdata/app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := StructTypes(pkgs, true)
+	var names []string
+	for _, t := range ts {
+		names = append(names, t.Obj().Name())
+	}
+	if diff := cmp.Diff([]string{"Address", "Contact", "Invoice", "User"}, names); diff != "" {
+		t.Fatalf("unexpected types (-expected +got):\n%s", diff)
+	}
+	changes, err := Diff(old, new, ts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	type change struct{ Path, Old, New string }
+	format := func(a *FieldAction) string {
+		if a == nil {
+			return ""
+		}
+		return a.Action + ":" + a.Customizer
+	}
+	var got []change
+	for _, c := range changes {
+		got = append(got, change{c.Path, format(c.Old), format(c.New)})
+	}
+	expected := []change{
+		{"Invoice.Buyer.Name", "rule:name", "rule:fullname"},
+		{"Invoice.Buyer.Addresses", "", "rule:redact"},
+		{"Invoice.Notes", "copy:", "rule:redact"},
+		{"Invoice.Buyer.Addresses[].Street", "rule:redact", ""},
+		{"Invoice.Buyer.Addresses[].City", "copy:", ""},
+		{"User.Name", "rule:name", "rule:fullname"},
+		{"User.Addresses", "", "rule:redact"},
+		{"User.Addresses[].Street", "rule:redact", ""},
+		{"User.Addresses[].City", "copy:", ""},
+	}
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Fatalf("unexpected changes (-expected +got):\n%s", diff)
+	}
+}
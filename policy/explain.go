@@ -0,0 +1,199 @@
+package policy
+
+import (
+	"fmt"
+	"go/types"
+	"reflect"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// Source is what decides the action of a field.
+type Source string
+
+// The sources of the actions.
+const (
+	// SourceTag is the ccopy tag of the field.
+	SourceTag Source = "tag"
+	// SourceRule is a rule of the policy.
+	SourceRule Source = "rule"
+	// SourceScope is a rule of a scope of the policy.
+	SourceScope Source = "scope"
+	// SourceType is the handler registered for the type of the field.
+	SourceType Source = "type"
+	// SourceDefault is the default copy of the kind of the field.
+	SourceDefault Source = "default"
+)
+
+// FieldAction is how the values at a path are copied.
+type FieldAction struct {
+	// Path is the path of the values, written like the paths of rules, like User.Addresses[].Street.
+	Path string
+	// Type is the type of the values.
+	Type string
+	// Action is the action, named like ccopy.ActionKind values: "tag", "rule", "atomic", "blob", "copy", "skip"...
+	Action string
+	// Customizer is the name of the customizer, for tags and rules.
+	Customizer string
+	// Source is what decides the action.
+	Source Source
+	// Rule is the path of the rule, for rules.
+	Rule string
+}
+
+func (a FieldAction) String() string {
+	s := fmt.Sprintf("%s\t%s\t%s", a.Path, a.Action, a.Source)
+	if a.Customizer != "" {
+		s += "\t" + a.Customizer
+	}
+	if a.Rule != "" {
+		s += "\t" + a.Rule
+	}
+	return s
+}
+
+// Load loads the packages matching patterns, like ./..., from dir.
+func Load(dir string, patterns ...string) ([]*packages.Package, error) {
+	cfg := &packages.Config{Mode: packages.NeedName | packages.NeedTypes | packages.NeedImports | packages.NeedDeps, Dir: dir}
+	pkgs, err := packages.Load(cfg, patterns...)
+	if err != nil {
+		return nil, err
+	}
+	var errs []string
+	packages.Visit(pkgs, nil, func(p *packages.Package) {
+		for _, e := range p.Errors {
+			errs = append(errs, e.Error())
+		}
+	})
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("load packages: %s", strings.Join(errs, "; "))
+	}
+	return pkgs, nil
+}
+
+// FindType returns the named type of pkgs, or of their dependencies, of name pkgpath.Name,
+// like example.com/app/users.User, of name pkgname.Name, like users.User, or of name Name, if it is unique.
+func FindType(pkgs []*packages.Package, name string) (*types.Named, error) {
+	pkgPath, typeName := "", name
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		pkgPath, typeName = name[:i], name[i+1:]
+	}
+	var found []*types.Named
+	packages.Visit(pkgs, nil, func(p *packages.Package) {
+		if p.Types == nil || pkgPath != "" && p.PkgPath != pkgPath && p.Name != pkgPath {
+			return
+		}
+		if tn, ok := p.Types.Scope().Lookup(typeName).(*types.TypeName); ok {
+			if named, ok := tn.Type().(*types.Named); ok {
+				found = append(found, named)
+			}
+		}
+	})
+	switch len(found) {
+	case 0:
+		return nil, fmt.Errorf("type not found: %s", name)
+	case 1:
+		return found[0], nil
+	}
+	return nil, fmt.Errorf("ambiguous type: %s, qualify it with its package path", name)
+}
+
+// Explain returns the actions of the values of type t and of the values they hold, by path:
+// one action per value that is handled as a whole, or that holds no other value.
+func (p *Policy) Explain(t *types.Named) ([]FieldAction, error) {
+	rules, err := p.rules()
+	if err != nil {
+		return nil, err
+	}
+	e := &explainer{rules: rules, handled: make(map[string]bool), visiting: make(map[types.Type]bool)}
+	e.handled["time.Time"] = true
+	for _, t := range p.Types {
+		e.handled[t] = true
+	}
+	e.explain(t, t.Obj().Name(), nil, "")
+	return e.actions, nil
+}
+
+type explainer struct {
+	rules   []*rule
+	handled map[string]bool
+	// visiting are the named types being explained, to stop at recursive types
+	visiting map[types.Type]bool
+	actions  []FieldAction
+}
+
+func (e *explainer) add(path string, t types.Type, action string, source Source) *FieldAction {
+	e.actions = append(e.actions, FieldAction{Path: path, Type: t.String(), Action: action, Source: source})
+	return &e.actions[len(e.actions)-1]
+}
+
+// explain explains the values at path, of type t, tagged with tag if they are fields.
+func (e *explainer) explain(t types.Type, path string, active []match, tag string) {
+	if tag != "" {
+		e.add(path, t, "tag", SourceTag).Customizer = tag
+		return
+	}
+	name := ""
+	if named, ok := t.(*types.Named); ok {
+		name = named.Obj().Name()
+	}
+	active = reach(active, t, name)
+	if r := matched(active); r != nil {
+		source := SourceRule
+		if r.packages != "" {
+			source = SourceScope
+		}
+		a := e.add(path, t, "rule", source)
+		a.Customizer, a.Rule = r.name, r.path
+		return
+	}
+	if named, ok := t.(*types.Named); ok && named.Obj().Pkg() != nil {
+		pkg, name := named.Obj().Pkg().Path(), named.Obj().Name()
+		for _, r := range e.rules {
+			if r.anchors(pkg, name) {
+				active = append(active[:len(active):len(active)], match{rule: r})
+			}
+		}
+		if e.handled[pkg+"."+name] {
+			e.add(path, t, "atomic", SourceType)
+			return
+		}
+		if e.visiting[t] {
+			e.add(path, t, "copy", SourceDefault)
+			return
+		}
+		e.visiting[t] = true
+		defer delete(e.visiting, t)
+	}
+	switch u := t.Underlying().(type) {
+	case *types.Pointer:
+		e.explain(u.Elem(), path, active, "")
+	case *types.Slice:
+		if b, ok := u.Elem().Underlying().(*types.Basic); ok && b.Kind() == types.Byte && len(advance(active, step{kind: segIndex})) == 0 {
+			e.add(path, t, "blob", SourceDefault)
+			return
+		}
+		e.explain(u.Elem(), path+"[]", advance(active, step{kind: segIndex}), "")
+	case *types.Array:
+		e.explain(u.Elem(), path+"[]", advance(active, step{kind: segIndex}), "")
+	case *types.Map:
+		e.explain(u.Elem(), path+"{}", advance(active, step{kind: segKey}), "")
+	case *types.Struct:
+		for i := 0; i < u.NumFields(); i++ {
+			f := u.Field(i)
+			if !f.Exported() {
+				e.add(path+"."+f.Name(), f.Type(), "skip", SourceDefault)
+				continue
+			}
+			st := reflect.StructTag(u.Tag(i))
+			s := step{kind: segField, name: f.Name()}
+			if name := strings.Split(st.Get("json"), ",")[0]; name != "-" {
+				s.jsonName = name
+			}
+			e.explain(f.Type(), path+"."+f.Name(), advance(active, s), st.Get("ccopy"))
+		}
+	default:
+		e.add(path, t, "copy", SourceDefault)
+	}
+}
@@ -0,0 +1,15 @@
+module github.com/gadumitrachioaiei/ccopy/policy
+
+go 1.22.0
+
+require (
+	github.com/gadumitrachioaiei/ccopy v0.0.0-20261015025656-45a8b2362908
+	github.com/google/go-cmp v0.6.0
+	golang.org/x/tools v0.29.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require (
+	golang.org/x/mod v0.22.0 // indirect
+	golang.org/x/sync v0.10.0 // indirect
+)
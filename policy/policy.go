@@ -0,0 +1,282 @@
+// Package policy resolves how ccopy copies the fields of struct types, from the source of the types
+// and a policy file holding rules, so policies can be reviewed without writing or running Go code.
+// The resolution follows the precedence of ccopy.Copier.ResolveAction, with the types known from the source:
+// the handlers registered at run time are only known from the Types of the policy.
+package policy
+
+import (
+	"fmt"
+	"go/types"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/gadumitrachioaiei/ccopy"
+	"gopkg.in/yaml.v3"
+)
+
+// Policy is the content of a policy file, in YAML or JSON:
+//
+//	rules:
+//	  User.Email: redact
+//	scopes:
+//	  - packages: example.com/billing/*
+//	    rules:
+//	      "*.Email": strict
+//	types:
+//	  - example.com/pii.SSN
+type Policy struct {
+	// Rules are the rules of the Rules option of the Copier.
+	Rules ccopy.Rules `yaml:"rules"`
+	// Scopes are the scopes of the Scopes option of the Copier.
+	Scopes []Scope `yaml:"scopes"`
+	// Types are the types with handlers registered with ccopy.RegisterAtomic, RegisterConverter or RegisterHandler,
+	// by package path and name, like example.com/pii.SSN. time.Time is always registered.
+	Types []string `yaml:"types"`
+	// Customizers are the names of the customizers of the Config, if known, to report the unknown tags.
+	Customizers []string `yaml:"customizers"`
+}
+
+// Scope is a ccopy.Scope, in a policy file.
+type Scope struct {
+	Packages string      `yaml:"packages"`
+	Rules    ccopy.Rules `yaml:"rules"`
+}
+
+// Read reads the policy file at path.
+func Read(path string) (*Policy, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var p Policy
+	if err := yaml.Unmarshal(b, &p); err != nil {
+		return nil, fmt.Errorf("policy %s: %w", path, err)
+	}
+	if _, err := p.rules(); err != nil {
+		return nil, fmt.Errorf("policy %s: %w", path, err)
+	}
+	return &p, nil
+}
+
+// Options returns the options of a Copier applying the rules and scopes of the policy.
+func (p *Policy) Options() ccopy.Options {
+	o := ccopy.Options{Rules: p.Rules}
+	for _, s := range p.Scopes {
+		o.Scopes = append(o.Scopes, ccopy.Scope{Packages: s.Packages, Rules: s.Rules})
+	}
+	return o
+}
+
+type segKind int
+
+const (
+	segField segKind = iota
+	segIndex
+	segKey
+	segAny
+	segType
+)
+
+type segment struct {
+	kind segKind
+	name string
+}
+
+// rule is a rule of the policy, parsed like the rules of ccopy.
+type rule struct {
+	path     string
+	typeName string
+	segs     []segment
+	name     string
+	// packages is the Packages of the scope of the rule, if any
+	packages string
+}
+
+// rules returns the rules of the policy, in the order ccopy tries them: the rules, then the scopes.
+func (p *Policy) rules() ([]*rule, error) {
+	var rules []*rule
+	add := func(r ccopy.Rules, packages string) error {
+		var group []*rule
+		for path, name := range r {
+			typeName, segs, err := parsePath(path)
+			if err != nil {
+				return err
+			}
+			if typeName == "*" && packages == "" {
+				return fmt.Errorf("invalid path: %s: any type outside of a scope", path)
+			}
+			group = append(group, &rule{path: path, typeName: typeName, segs: segs, name: name, packages: packages})
+		}
+		sort.Slice(group, func(i, j int) bool { return group[i].path < group[j].path })
+		rules = append(rules, group...)
+		return nil
+	}
+	if err := add(p.Rules, ""); err != nil {
+		return nil, err
+	}
+	for _, s := range p.Scopes {
+		if pkg := strings.TrimSuffix(s.Packages, "/*"); pkg == "" || strings.Contains(pkg, "*") {
+			return nil, fmt.Errorf("invalid scope: %q", s.Packages)
+		}
+		if err := add(s.Rules, s.Packages); err != nil {
+			return nil, err
+		}
+	}
+	return rules, nil
+}
+
+func parsePath(path string) (string, []segment, error) {
+	typeName, rest := splitIdent(path)
+	if typeName == "" {
+		return "", nil, fmt.Errorf("invalid path: %s: missing type name", path)
+	}
+	var segs []segment
+	for rest != "" {
+		switch {
+		case strings.HasPrefix(rest, ".**"):
+			if len(segs) > 0 && segs[len(segs)-1].kind == segAny {
+				return "", nil, fmt.Errorf("invalid path: %s: consecutive wildcards", path)
+			}
+			segs = append(segs, segment{kind: segAny})
+			rest = rest[3:]
+		case rest[0] == ':':
+			var name string
+			name, rest = splitIdent(rest[1:])
+			if name == "" || len(segs) == 0 || segs[len(segs)-1].kind == segType {
+				return "", nil, fmt.Errorf("invalid path: %s: type filter not following a step", path)
+			}
+			segs = append(segs, segment{kind: segType, name: name})
+		case strings.HasPrefix(rest, "[]"):
+			segs = append(segs, segment{kind: segIndex})
+			rest = rest[2:]
+		case strings.HasPrefix(rest, "{}"):
+			segs = append(segs, segment{kind: segKey})
+			rest = rest[2:]
+		case rest[0] == '.':
+			var name string
+			name, rest = splitIdent(rest[1:])
+			if name == "" {
+				return "", nil, fmt.Errorf("invalid path: %s: missing field name", path)
+			}
+			if strings.ContainsAny(name, " \t\n*") {
+				return "", nil, fmt.Errorf("invalid path: %s: invalid field name %q", path, name)
+			}
+			segs = append(segs, segment{kind: segField, name: name})
+		default:
+			return "", nil, fmt.Errorf("invalid path: %s: unexpected %q", path, rest)
+		}
+	}
+	if len(segs) == 0 {
+		return "", nil, fmt.Errorf("invalid path: %s: no field selected", path)
+	}
+	if segs[len(segs)-1].kind == segAny {
+		return "", nil, fmt.Errorf("invalid path: %s: ending with a wildcard", path)
+	}
+	return typeName, segs, nil
+}
+
+func splitIdent(s string) (string, string) {
+	i := strings.IndexAny(s, ".[]{}:")
+	if i < 0 {
+		return s, ""
+	}
+	return s[:i], s[i:]
+}
+
+// anchors reports whether the paths of r start from the values of the named type name of package pkg.
+func (r *rule) anchors(pkg, name string) bool {
+	if r.typeName != name && r.typeName != "*" {
+		return false
+	}
+	if r.packages == "" {
+		return true
+	}
+	if prefix, ok := strings.CutSuffix(r.packages, "/*"); ok {
+		return pkg == prefix || strings.HasPrefix(pkg, prefix+"/")
+	}
+	return pkg == r.packages
+}
+
+type match struct {
+	rule *rule
+	pos  int
+}
+
+type step struct {
+	kind     segKind
+	name     string
+	jsonName string
+}
+
+func advance(active []match, s step) []match {
+	var next []match
+	for _, m := range active {
+		if m.pos == len(m.rule.segs) {
+			continue
+		}
+		seg := m.rule.segs[m.pos]
+		if seg.kind == segAny {
+			next = appendMatch(next, m)
+			if seg = m.rule.segs[m.pos+1]; seg.kind != segType && seg.matches(s) {
+				next = appendMatch(next, match{rule: m.rule, pos: m.pos + 2})
+			}
+			continue
+		}
+		if seg.matches(s) {
+			next = appendMatch(next, match{rule: m.rule, pos: m.pos + 1})
+		}
+	}
+	return next
+}
+
+func (seg segment) matches(s step) bool {
+	if seg.kind != s.kind {
+		return false
+	}
+	return seg.kind != segField || seg.name == s.name || seg.name == s.jsonName
+}
+
+func appendMatch(matches []match, m match) []match {
+	for _, o := range matches {
+		if o == m {
+			return matches
+		}
+	}
+	return append(matches, m)
+}
+
+// reach applies the type filters of active to a value of type t, named name, or unnamed if name is empty.
+func reach(active []match, t types.Type, name string) []match {
+	switch t.Underlying().(type) {
+	case *types.Pointer, *types.Interface:
+		return active
+	}
+	var next []match
+	for _, m := range active {
+		p := m.pos
+		switch {
+		case p < len(m.rule.segs) && m.rule.segs[p].kind == segType:
+			if m.rule.segs[p].name == name {
+				next = appendMatch(next, match{rule: m.rule, pos: p + 1})
+			}
+		case p < len(m.rule.segs) && m.rule.segs[p].kind == segAny && m.rule.segs[p+1].kind == segType:
+			next = appendMatch(next, m)
+			if m.rule.segs[p+1].name == name {
+				next = appendMatch(next, match{rule: m.rule, pos: p + 2})
+			}
+		default:
+			next = appendMatch(next, m)
+		}
+	}
+	return next
+}
+
+func matched(active []match) *rule {
+	for _, m := range active {
+		if m.pos == len(m.rule.segs) {
+			return m.rule
+		}
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package policy
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestExplain(t *testing.T) {
+	p, err := Read("testdata/old.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkgs, err := Load(".", "./testdata/app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	user, err := FindType(pkgs, "github.com/gadumitrachioaiei/ccopy/policy/testdata/app.User")
+	if err != nil {
+		t.Fatal(err)
+	}
+	actions, err := p.Explain(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []FieldAction{
+		{Path: "User.Email", Action: "tag", Customizer: "email", Source: SourceTag},
+		{Path: "User.Name", Action: "rule", Customizer: "name", Source: SourceScope, Rule: "*.Name"},
+		{Path: "User.SSN", Action: "atomic", Source: SourceType},
+		{Path: "User.Addresses[].Street", Action: "rule", Customizer: "redact", Source: SourceRule, Rule: "User.Addresses[].Street"},
+		{Path: "User.Addresses[].City", Action: "copy", Source: SourceDefault},
+		{Path: "User.Tags{}", Action: "copy", Source: SourceDefault},
+		{Path: "User.Avatar", Action: "blob", Source: SourceDefault},
+		{Path: "User.Created", Action: "atomic", Source: SourceType},
+		{Path: "User.Manager", Action: "copy", Source: SourceDefault},
+		{Path: "User.password", Action: "skip", Source: SourceDefault},
+	}
+	if diff := cmp.Diff(expected, actions, cmpopts.IgnoreFields(FieldAction{}, "Type")); diff != "" {
+		t.Fatalf("unexpected actions (-expected +got):\n%s", diff)
+	}
+}
+
+func TestFindType(t *testing.T) {
+	pkgs, err := Load(".", "./testdata/app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := FindType(pkgs, "Invoice"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := FindType(pkgs, "app.Missing"); err == nil {
+		t.Fatal("expected error for missing type")
+	}
+}
+
+func TestReadInvalid(t *testing.T) {
+	p := &Policy{Rules: map[string]string{"User..Email": "email"}}
+	if _, err := p.rules(); err == nil {
+		t.Fatal("expected error for invalid rule")
+	}
+}
@@ -0,0 +1,129 @@
+package policy
+
+import (
+	"go/types"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/gadumitrachioaiei/ccopy"
+	"golang.org/x/tools/go/packages"
+)
+
+// TypeScan is what a scan found in the fields of a struct type.
+type TypeScan struct {
+	// Type is the name of the type, qualified by its package path.
+	Type string
+	// Fields is the number of exported fields.
+	Fields int
+	// Tagged are the exported fields with a ccopy tag.
+	Tagged []TaggedField
+	// Unknown are the fields whose tag names a customizer that is not known.
+	Unknown []TaggedField
+	// Suspect are the fields that are not tagged, but whose name suggests they hold personal data.
+	Suspect []string
+}
+
+// TaggedField is a field with a ccopy tag.
+type TaggedField struct {
+	Field string
+	// Tag is the customizer of the tag, without the dive, and idmap= prefixes, the ,unique suffix and the arguments.
+	Tag string
+}
+
+// Scan is what a scan found in the struct types of packages.
+type Scan struct {
+	Types []TypeScan
+}
+
+// Fields returns the number of exported fields of the types.
+func (s *Scan) Fields() int {
+	n := 0
+	for _, t := range s.Types {
+		n += t.Fields
+	}
+	return n
+}
+
+// Tagged returns the number of exported fields with a ccopy tag.
+func (s *Scan) Tagged() int {
+	n := 0
+	for _, t := range s.Types {
+		n += len(t.Tagged)
+	}
+	return n
+}
+
+// Tags returns the customizers named by the tags, with the number of fields tagged with each of them.
+func (s *Scan) Tags() map[string]int {
+	tags := make(map[string]int)
+	for _, t := range s.Types {
+		for _, f := range t.Tagged {
+			tags[f.Tag]++
+		}
+	}
+	return tags
+}
+
+// ScanTypes scans the exported fields of the exported struct types declared in pkgs,
+// for the customizers named by their tags, reported as unknown if they are not in known,
+// unless known is nil, and for the untagged fields that may hold personal data.
+// Fields holding structs are not suspect: the fields of these structs are.
+func ScanTypes(pkgs []*packages.Package, known []string) *Scan {
+	isKnown := make(map[string]bool, len(known))
+	for _, k := range known {
+		isKnown[k] = true
+	}
+	s := &Scan{}
+	for _, t := range StructTypes(pkgs, true) {
+		st := t.Underlying().(*types.Struct)
+		ts := TypeScan{Type: t.Obj().Pkg().Path() + "." + t.Obj().Name()}
+		for i := 0; i < st.NumFields(); i++ {
+			f := st.Field(i)
+			if !f.Exported() {
+				continue
+			}
+			ts.Fields++
+			if tag := reflect.StructTag(st.Tag(i)).Get("ccopy"); tag != "" {
+				for strings.HasPrefix(tag, "dive,") {
+					tag = tag[len("dive,"):]
+				}
+				tag, _, _ = strings.Cut(strings.TrimSuffix(strings.TrimPrefix(tag, "idmap="), ",unique"), ",")
+				tf := TaggedField{Field: f.Name(), Tag: tag}
+				ts.Tagged = append(ts.Tagged, tf)
+				if known != nil && !isKnown[tf.Tag] {
+					ts.Unknown = append(ts.Unknown, tf)
+				}
+				continue
+			}
+			if ccopy.SuspectField(f.Name()) && !holdsStructs(f.Type()) {
+				ts.Suspect = append(ts.Suspect, f.Name())
+			}
+		}
+		s.Types = append(s.Types, ts)
+	}
+	sort.SliceStable(s.Types, func(i, j int) bool { return s.Types[i].Type < s.Types[j].Type })
+	return s
+}
+
+// holdsStructs reports whether the values of type t are structs, or pointers or containers of structs,
+// other than time.Time.
+func holdsStructs(t types.Type) bool {
+	for {
+		switch u := t.Underlying().(type) {
+		case *types.Pointer:
+			t = u.Elem()
+		case *types.Slice:
+			t = u.Elem()
+		case *types.Array:
+			t = u.Elem()
+		case *types.Map:
+			t = u.Elem()
+		case *types.Struct:
+			named, ok := t.(*types.Named)
+			return !ok || named.Obj().Pkg() == nil || named.Obj().Pkg().Path()+"."+named.Obj().Name() != "time.Time"
+		default:
+			return false
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package policy
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestScanTypes(t *testing.T) {
+	pkgs, err := Load(".", "./testdata/app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := ScanTypes(pkgs, []string{"email", "phone"})
+	const pkg = "github.com/gadumitrachioaiei/ccopy/policy/testdata/app."
+	expected := []TypeScan{
+		{Type: pkg + "Address", Fields: 2, Suspect: []string{"Street", "City"}},
+		{
+			Type: pkg + "Contact", Fields: 5,
+			Tagged:  []TaggedField{{Field: "Phone", Tag: "phone"}, {Field: "Nickname", Tag: "nickname"}},
+			Unknown: []TaggedField{{Field: "Nickname", Tag: "nickname"}},
+			Suspect: []string{"Birth"},
+		},
+		{Type: pkg + "Invoice", Fields: 3},
+		{
+			Type: pkg + "User", Fields: 8,
+			Tagged:  []TaggedField{{Field: "Email", Tag: "email"}},
+			Suspect: []string{"Name", "SSN"},
+		},
+	}
+	if diff := cmp.Diff(expected, s.Types); diff != "" {
+		t.Fatalf("unexpected scan (-expected +got):\n%s", diff)
+	}
+	if s.Fields() != 18 || s.Tagged() != 3 {
+		t.Fatalf("unexpected counts: %d fields, %d tagged", s.Fields(), s.Tagged())
+	}
+	if diff := cmp.Diff(map[string]int{"email": 1, "phone": 1, "nickname": 1}, s.Tags()); diff != "" {
+		t.Fatalf("unexpected tags (-expected +got):\n%s", diff)
+	}
+	if s := ScanTypes(pkgs, nil); len(s.Types[1].Unknown) != 0 {
+		t.Fatalf("unexpected unknown tags without known customizers: %v", s.Types[1].Unknown)
+	}
+}
@@ -0,0 +1,36 @@
+package app
+
+import "time"
+
+type SSN string
+
+type Address struct {
+	Street string
+	City   string
+}
+
+type User struct {
+	Email     string `json:"email" ccopy:"email"`
+	Name      string
+	SSN       SSN
+	Addresses []*Address
+	Tags      map[string]string
+	Avatar    []byte
+	Created   time.Time
+	Manager   *User
+	password  string
+}
+
+type Invoice struct {
+	Buyer  User
+	Amount int
+	Notes  string
+}
+
+type Contact struct {
+	Phone    string `ccopy:"phone"`
+	Nickname string `ccopy:"idmap=nickname,unique"`
+	Home     Address
+	Birth    time.Time
+	Note     string
+}
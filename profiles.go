@@ -0,0 +1,71 @@
+package ccopy
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+var profiles = struct {
+	sync.RWMutex
+	copiers map[string]*Copier
+}{copiers: make(map[string]*Copier)}
+
+// RegisterProfile registers the Copier c under a name, as a view of the objects, like "internal" or "public",
+// so objects can be copied for several views at once with CopyProfiles.
+// It panics if the name is already registered, or if c is nil.
+func RegisterProfile(name string, c *Copier) {
+	if c == nil {
+		panic("ccopy: register nil profile: " + name)
+	}
+	profiles.Lock()
+	defer profiles.Unlock()
+	if _, ok := profiles.copiers[name]; ok {
+		panic("ccopy: profile already registered: " + name)
+	}
+	profiles.copiers[name] = c
+}
+
+// Profiles returns the sorted names of the registered profiles.
+func Profiles() []string {
+	profiles.RLock()
+	defer profiles.RUnlock()
+	names := make([]string, 0, len(profiles.copiers))
+	for name := range profiles.copiers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ProfileCopier returns the Copier registered under name, if any.
+func ProfileCopier(name string) (*Copier, bool) {
+	profiles.RLock()
+	defer profiles.RUnlock()
+	c, ok := profiles.copiers[name]
+	return c, ok
+}
+
+// CopyProfiles deep copies obj with the Copiers of the named profiles, returning a copy per profile, in the order of the names.
+// The copies share no memory, so each view can be changed or encoded on its own.
+// They are made one after the other, each profile reusing the plans it compiled for the types of obj.
+// It returns an error for an unknown profile, before copying, or the error of the first copy that fails.
+func CopyProfiles(obj interface{}, names ...string) ([]interface{}, error) {
+	copiers := make([]*Copier, len(names))
+	for i, name := range names {
+		c, ok := ProfileCopier(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown profile: %s", name)
+		}
+		copiers[i] = c
+	}
+	copies := make([]interface{}, len(names))
+	for i, c := range copiers {
+		v, err := c.Copy(obj)
+		if err != nil {
+			return nil, fmt.Errorf("profile %s: %w", names[i], err)
+		}
+		copies[i] = v
+	}
+	return copies, nil
+}
@@ -0,0 +1,51 @@
+package ccopy
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCopyProfiles(t *testing.T) {
+	type User struct {
+		Name  string `ccopy:"name"`
+		Email string `ccopy:"email"`
+	}
+	keep := func(s string) string { return s }
+	redact := func(string) string { return "" }
+	for name, config := range map[string]Config{
+		"test-internal": {"name": keep, "email": keep},
+		"test-partner":  {"name": keep, "email": redact},
+		"test-public":   {"name": redact, "email": redact},
+	} {
+		c, err := NewCopier(config, Options{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		RegisterProfile(name, c)
+	}
+	copies, err := CopyProfiles(&User{Name: "Ann", Email: "ann@example.com"}, "test-internal", "test-partner", "test-public")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []interface{}{&User{Name: "Ann", Email: "ann@example.com"}, &User{Name: "Ann"}, &User{}}
+	if diff := cmp.Diff(expected, copies); diff != "" {
+		t.Fatal(diff)
+	}
+	if copies[0] == copies[1] {
+		t.Fatal("expected the copies not to share memory")
+	}
+	if _, err := CopyProfiles(User{}, "test-public", "unknown"); err == nil {
+		t.Fatal("expected error for unknown profile")
+	}
+	if c, ok := ProfileCopier("test-public"); !ok || c == nil {
+		t.Fatal("expected the registered profile")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic for a profile registered twice")
+		}
+	}()
+	RegisterProfile("test-public", &Copier{})
+}
@@ -0,0 +1,108 @@
+package ccopy
+
+import (
+	crand "crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"math/rand"
+	"sync"
+)
+
+// lockedSource makes a rand.Source safe for concurrent use.
+type lockedSource struct {
+	mu  sync.Mutex
+	src rand.Source64
+}
+
+func (s *lockedSource) Int63() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.src.Int63()
+}
+
+func (s *lockedSource) Uint64() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.src.Uint64()
+}
+
+func (s *lockedSource) Seed(seed int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.src.Seed(seed)
+}
+
+// NewRand returns a random generator seeded with seed, safe for concurrent use except for its Read method.
+// Randomized customizers accept such a generator, so their outputs can be reproduced by using the same seed.
+func NewRand(seed int64) *rand.Rand {
+	return rand.New(&lockedSource{src: rand.NewSource(seed).(rand.Source64)})
+}
+
+// randomSeed returns a seed read from crypto/rand.
+func randomSeed() int64 {
+	var b [8]byte
+	if _, err := crand.Read(b[:]); err != nil {
+		panic("ccopy: read random seed: " + err.Error())
+	}
+	return int64(binary.LittleEndian.Uint64(b[:]))
+}
+
+// Randomness is the source of the random generator passed to the customizers taking one: func(*rand.Rand, T) T.
+// Datasets often mix requirements: reproducible outputs for some fields, like test fixtures regenerated from a seed,
+// and irreversible ones for others, like secrets that must not be recovered by whoever knows the seed.
+type Randomness int
+
+const (
+	// RandSession is the generator of the session of the copy, seeded with NewSeededSession,
+	// so the outputs can be reproduced with the same seed.
+	RandSession Randomness = iota
+	// RandCrypto is a generator reading crypto/rand, so the outputs can be neither reproduced nor predicted.
+	RandCrypto
+)
+
+// cryptoRand is the generator of RandCrypto, shared by all the copies.
+var cryptoRand = CryptoRand()
+
+// CryptoRand returns a random generator reading crypto/rand, safe for concurrent use except for its Read method.
+// It is meant for randomized customizers whose outputs must not be reproducible, like mask.Chars(ccopy.CryptoRand()).
+func CryptoRand() *rand.Rand {
+	return rand.New(cryptoSource{})
+}
+
+// cryptoSource is a rand.Source reading crypto/rand, which cannot be seeded.
+type cryptoSource struct{}
+
+func (cryptoSource) Int63() int64 {
+	return int64(cryptoSource{}.Uint64() >> 1)
+}
+
+func (cryptoSource) Uint64() uint64 {
+	var b [8]byte
+	if _, err := crand.Read(b[:]); err != nil {
+		panic("ccopy: read random: " + err.Error())
+	}
+	return binary.LittleEndian.Uint64(b[:])
+}
+
+func (cryptoSource) Seed(int64) {}
+
+// rand returns the generator passed to the customizer named name.
+func (c *state) rand(name string) *rand.Rand {
+	if c.randomness[name] == RandCrypto {
+		return cryptoRand
+	}
+	return c.session.Rand()
+}
+
+// validateRandomness returns an error if a name of the Randomness option is not in the config.
+func validateRandomness(c Config, randomness map[string]Randomness) error {
+	for name, r := range randomness {
+		if _, ok := c[name]; !ok {
+			return fmt.Errorf("invalid randomness option for %s: not a customizer of the config", name)
+		}
+		if r != RandSession && r != RandCrypto {
+			return fmt.Errorf("invalid randomness option for %s: %d", name, r)
+		}
+	}
+	return nil
+}
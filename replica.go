@@ -0,0 +1,56 @@
+package ccopy
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Replica holds a primary value of type T, mutated by writers, and a deep copy of it, read by readers.
+// Readers load the copy without locking, while writers update the primary under a lock
+// and publish a fresh copy of it once they are done.
+// The loaded copies must not be modified, as they are shared by all the readers.
+type Replica[T any] struct {
+	copier *Copier
+
+	mu      sync.Mutex
+	primary T
+
+	snapshot atomic.Pointer[T]
+}
+
+// NewReplica returns a replica of primary, whose copies are made by c.
+func NewReplica[T any](c *Copier, primary T) (*Replica[T], error) {
+	r := &Replica[T]{copier: c, primary: primary}
+	if err := r.publish(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Load returns the latest published copy.
+func (r *Replica[T]) Load() T {
+	return *r.snapshot.Load()
+}
+
+// Update calls fn with the primary value, for it to be modified, and publishes a copy of it if fn succeeds.
+// If fn fails, or the copy fails, the published copy is unchanged and the error is returned,
+// though the primary value keeps the modifications made by fn.
+func (r *Replica[T]) Update(fn func(primary *T) error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := fn(&r.primary); err != nil {
+		return err
+	}
+	return r.publish()
+}
+
+// publish copies the primary value and publishes the copy. It must be called holding the lock, or before sharing r.
+func (r *Replica[T]) publish() error {
+	v, err := r.copier.Copy(r.primary)
+	if err != nil {
+		return err
+	}
+	snapshot, _ := v.(T)
+	r.snapshot.Store(&snapshot)
+	return nil
+}
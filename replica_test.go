@@ -0,0 +1,55 @@
+package ccopy
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestReplica(t *testing.T) {
+	c, err := NewCopier(Config{}, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := NewReplica(c, map[string][]string{"a": {"1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if v := r.Load(); len(v["a"]) == 0 {
+					t.Error("got empty replica")
+					return
+				}
+			}
+		}()
+	}
+	for i := 0; i < 100; i++ {
+		if err := r.Update(func(m *map[string][]string) error {
+			(*m)["a"] = append((*m)["a"], "x")
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	wg.Wait()
+	if l := len(r.Load()["a"]); l != 101 {
+		t.Fatalf("got len: %d, expected 101", l)
+	}
+
+	failure := errors.New("failure")
+	err = r.Update(func(m *map[string][]string) error {
+		(*m)["b"] = nil
+		return failure
+	})
+	if err != failure {
+		t.Fatalf("got error: %v, expected: %v", err, failure)
+	}
+	if _, ok := r.Load()["b"]; ok {
+		t.Fatal("expected the failed update not to be published")
+	}
+}
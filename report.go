@@ -0,0 +1,80 @@
+package ccopy
+
+import (
+	"reflect"
+	"sort"
+)
+
+// Report measures the memory allocated by a copy, for capacity planning based on the measured cost of copies.
+// The sizes are estimates of the memory of the values created: the pointed values of pointers,
+// the elements of slices, the keys and values of maps and the strings and blobs created by customizers,
+// without the overhead of the allocator and of the internal structures of maps.
+// The memory is held until the copy is not referenced anymore, so Bytes is also its high-water mark.
+type Report struct {
+	// Bytes is the memory allocated by the copy.
+	Bytes int64
+	// Allocations counts the values allocated by the copy.
+	Allocations int64
+	// Types is the memory allocated for the values of each type: pointer, slice or map types, blobs and strings.
+	Types map[reflect.Type]int64
+}
+
+// TypeCost is the memory allocated for the values of a type.
+type TypeCost struct {
+	Type  reflect.Type
+	Bytes int64
+}
+
+// Costliest returns the types of the report by decreasing memory allocated for their values.
+func (r *Report) Costliest() []TypeCost {
+	costs := make([]TypeCost, 0, len(r.Types))
+	for t, b := range r.Types {
+		costs = append(costs, TypeCost{Type: t, Bytes: b})
+	}
+	sort.Slice(costs, func(i, j int) bool {
+		if costs[i].Bytes != costs[j].Bytes {
+			return costs[i].Bytes > costs[j].Bytes
+		}
+		return costs[i].Type.String() < costs[j].Type.String()
+	})
+	return costs
+}
+
+// CopyReport deep copies an object, like Copy, and returns the report of the memory allocated by the copy.
+func (c *Copier) CopyReport(obj interface{}) (interface{}, *Report, error) {
+	st := &state{Copier: c.live(), onWarning: c.onWarning, report: &Report{Types: make(map[reflect.Type]int64)}}
+	v, err := c.copyRoot(st, obj)
+	return v, st.report, err
+}
+
+// account records the allocation of n bytes for a value of type t, when reporting.
+func (c *state) account(t reflect.Type, n int) {
+	c.bytes += int64(n)
+	if c.report == nil {
+		return
+	}
+	c.report.Bytes += int64(n)
+	c.report.Allocations++
+	c.report.Types[t] += int64(n)
+}
+
+// accountFast records the allocations of the fast path of a field, dst.
+func (c *state) accountFast(dst reflect.Value) {
+	if c.report == nil && c.maxBytes == 0 {
+		return
+	}
+	switch t := dst.Type(); t {
+	case stringPtrType:
+		if !dst.IsNil() {
+			c.account(t, int(t.Elem().Size()))
+		}
+	case stringsType:
+		if !dst.IsNil() {
+			c.account(t, dst.Len()*int(t.Elem().Size()))
+		}
+	case stringMapType:
+		if !dst.IsNil() {
+			c.account(t, dst.Len()*int(t.Key().Size()+t.Elem().Size()))
+		}
+	}
+}
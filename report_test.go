@@ -0,0 +1,49 @@
+package ccopy
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCopyReport(t *testing.T) {
+	type Line struct {
+		SKU   string `ccopy:"sku"`
+		Notes []string
+	}
+	type Cart struct {
+		Lines []Line
+		Attrs map[string]int64
+		Owner *string
+	}
+	owner := "alice"
+	obj := Cart{
+		Lines: []Line{{SKU: "a", Notes: []string{"x", "y"}}, {SKU: "b"}},
+		Attrs: map[string]int64{"k": 1},
+		Owner: &owner,
+	}
+	c, err := NewCopier(Config{"sku": func(s string) string { return s + "-0" }}, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, report, err := c.CopyReport(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lineSize := int64(reflect.TypeOf(Line{}).Size())
+	expected := map[reflect.Type]int64{
+		reflect.TypeOf([]Line(nil)):           2 * lineSize,
+		reflect.TypeOf([]string(nil)):         32,
+		reflect.TypeOf(map[string]int64(nil)): 24,
+		reflect.TypeOf((*string)(nil)):        16,
+		reflect.TypeOf(""):                    6,
+	}
+	if !reflect.DeepEqual(report.Types, expected) {
+		t.Fatalf("expected: %v, got: %v", expected, report.Types)
+	}
+	if report.Bytes != 2*lineSize+78 || report.Allocations != 6 {
+		t.Fatalf("got %d bytes in %d allocations", report.Bytes, report.Allocations)
+	}
+	if top := report.Costliest()[0]; top.Type != reflect.TypeOf([]Line(nil)) {
+		t.Fatalf("expected the lines to cost the most, got: %v", top.Type)
+	}
+}
@@ -0,0 +1,249 @@
+package ccopy
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ActionKind is how a copy handles a value.
+type ActionKind int
+
+const (
+	// ActionCopy is the deep copy of the value by its kind.
+	ActionCopy ActionKind = iota
+	// ActionTag is the customization of a field by its tag.
+	ActionTag
+	// ActionRule is the customization by a rule, of the Rules or Scopes options or registered with RegisterRules.
+	ActionRule
+	// ActionAtomic is the assignment of a value of a type registered with RegisterAtomic, like time.Time.
+	ActionAtomic
+	// ActionConverter is the call of the converter of the type, registered with RegisterConverter.
+	ActionConverter
+	// ActionHandler is the call of the handler of the type, registered with RegisterHandler.
+	ActionHandler
+	// ActionBlob is the copy of a blob by the Blobs or BlobTypes options.
+	ActionBlob
+	// ActionFactory is the call of a factory of the Factories option, for all the values of an interface type.
+	ActionFactory
+	// ActionSkip is leaving a field out of the copy: an unexported field, without the Unexported option,
+	// or a field tagged with "-".
+	ActionSkip
+	// ActionShallow is the assignment of a field tagged with "shallow".
+	ActionShallow
+	// ActionResource is the copy of the handle of a resource, like an *os.File, by the Resources option.
+	ActionResource
+	// ActionInfrastructure is the assignment of a value of an infrastructure type, like *slog.Logger,
+	// registered with RegisterInfrastructure or of the Infrastructure option.
+	ActionInfrastructure
+	// ActionType is the customization of a value by the customizer of its type, registered with Config.RegisterType.
+	ActionType
+	// ActionCollection is the copy of a value of a collection type, registered with RegisterCollection, by its adapter.
+	ActionCollection
+)
+
+func (k ActionKind) String() string {
+	switch k {
+	case ActionCopy:
+		return "copy"
+	case ActionTag:
+		return "tag"
+	case ActionRule:
+		return "rule"
+	case ActionAtomic:
+		return "atomic"
+	case ActionConverter:
+		return "converter"
+	case ActionHandler:
+		return "handler"
+	case ActionBlob:
+		return "blob"
+	case ActionFactory:
+		return "factory"
+	case ActionSkip:
+		return "skip"
+	case ActionShallow:
+		return "shallow"
+	case ActionResource:
+		return "resource"
+	case ActionInfrastructure:
+		return "infrastructure"
+	case ActionType:
+		return "type"
+	case ActionCollection:
+		return "collection"
+	}
+	return fmt.Sprintf("ActionKind(%d)", int(k))
+}
+
+// Action is how a copy handles the values at a path.
+type Action struct {
+	Kind ActionKind
+	// At is the path of the value the action applies to, which is a prefix of the resolved path
+	// when a value holding the resolved one, like a tagged struct field, is handled as a whole.
+	At string
+	// Type is the type of the value at At.
+	Type reflect.Type
+	// Customizer is the name of the customizer of tags, rules, types and blobs.
+	Customizer string
+	// Rule is the path of the rule, for rules.
+	Rule string
+}
+
+func (a Action) String() string {
+	s := fmt.Sprintf("%s of %s, at: %s", a.Kind, a.Type, a.At)
+	if a.Customizer != "" {
+		s += ", by: " + a.Customizer
+	}
+	if a.Rule != "" {
+		s += ", for rule: " + a.Rule
+	}
+	return s
+}
+
+// ResolveAction returns how the copies handle the values at path in values of type t,
+// for finding out which customization wins for a field. The path is written like the paths of Rules,
+// starting with the name of t, like "User.Addresses[].Street"; pointers are followed implicitly.
+// It returns an error if path does not select values of t, or goes through an interface.
+//
+// A value is handled by the first of these that applies to it:
+//  1. the tag of the struct field holding it, even if some rule matches it as well;
+//  2. the rules matching it, the ones starting from its outermost enclosing value first, and
+//     for the same value, the rules of the Rules option, then the ones of the Scopes option, then the registered rules,
+//     every group in the order of their paths;
+//  3. the handler of its type, registered with RegisterAtomic, RegisterConverter or RegisterHandler;
+//  4. the Blobs and BlobTypes options, for blobs whose bytes are not matched by rules;
+//  5. the Factories option, for interface values;
+//  6. the deep copy of its kind, which handles the values it holds the same way.
+//
+// A value that is handled by 1 to 5 is handled as a whole, with the values it holds.
+// The factories of the types held by interface values depend on the values, so only the factories
+// of all the values of an interface type are resolved.
+func (c *Copier) ResolveAction(t reflect.Type, path string) (Action, error) {
+	// the values are handled by the Copier the copies use, after Swap
+	return c.live().resolveAction(t, path)
+}
+
+func (c *Copier) resolveAction(t reflect.Type, path string) (Action, error) {
+	typeName, segs, err := parsePath(path)
+	if err != nil {
+		return Action{}, err
+	}
+	base := t
+	for base.Kind() == reflect.Ptr {
+		base = base.Elem()
+	}
+	if typeName != base.Name() {
+		return Action{}, fmt.Errorf("invalid path: %s: not a path of %s", path, t)
+	}
+	at := typeName
+	var active []match
+	var tag string
+	for {
+		switch tag {
+		case "":
+		case tagSkip:
+			return Action{Kind: ActionSkip, At: at, Type: t}, nil
+		case tagShallow:
+			return Action{Kind: ActionShallow, At: at, Type: t}, nil
+		default:
+			return Action{Kind: ActionTag, At: at, Type: t, Customizer: tag}, nil
+		}
+		active = c.plans.reach(active, t)
+		if r := matchedRule(active); r != nil {
+			return Action{Kind: ActionRule, At: at, Type: t, Customizer: r.name, Rule: r.path}, nil
+		}
+		if name, ok := c.plans.typeCustomizers()[t]; ok {
+			return Action{Kind: ActionType, At: at, Type: t, Customizer: name}, nil
+		}
+		active = c.anchor(active, t)
+		if fn, ok := typeHandler(t); ok {
+			kind := ActionConverter
+			switch {
+			case !fn.IsValid():
+				kind = ActionAtomic
+			case isRawHandler(fn):
+				kind = ActionHandler
+			}
+			return Action{Kind: kind, At: at, Type: t}, nil
+		}
+		if c.isInfrastructure(t) {
+			return Action{Kind: ActionInfrastructure, At: at, Type: t}, nil
+		}
+		if collectionOf(t) != nil {
+			return Action{Kind: ActionCollection, At: at, Type: t}, nil
+		}
+		if isResource(t) {
+			return Action{Kind: ActionResource, At: at, Type: t}, nil
+		}
+		if isBlob(t) && len(c.plans.advance(active, &indexStep)) == 0 {
+			return Action{Kind: ActionBlob, At: at, Type: t, Customizer: c.blobPolicy(t).Customizer}, nil
+		}
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
+			continue
+		}
+		if t.Kind() == reflect.Interface {
+			if _, ok := c.factories[factoryKey{iface: t, impl: t}]; ok {
+				return Action{Kind: ActionFactory, At: at, Type: t}, nil
+			}
+		}
+		if len(segs) == 0 {
+			return Action{Kind: ActionCopy, At: at, Type: t}, nil
+		}
+		seg := segs[0]
+		segs = segs[1:]
+		switch {
+		case seg.kind == segField && t.Kind() == reflect.Struct:
+			f, ok := lookupField(t, seg.name)
+			if !ok {
+				return Action{}, fmt.Errorf("invalid path: %s: no field %s in %s", path, seg.name, t)
+			}
+			at += "." + seg.name
+			if f.PkgPath != "" && !c.plans.unexported {
+				return Action{Kind: ActionSkip, At: at, Type: f.Type}, nil
+			}
+			// the steps of the plans are cached transitions, unlike new steps
+			active = c.plans.advance(active, &c.plans.structPlan(t).field(f.Index[0]).step)
+			tag = f.Tag.Get(tagCcopy)
+			t = f.Type
+		case seg.kind == segIndex && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array):
+			at += "[]"
+			active = c.plans.advance(active, &indexStep)
+			t = t.Elem()
+		case seg.kind == segKey && t.Kind() == reflect.Map:
+			at += "{}"
+			active = c.plans.advance(active, &keyStep)
+			t = t.Elem()
+		default:
+			return Action{}, fmt.Errorf("invalid path: %s: cannot select %s of %s, at: %s", path, segName(seg), t, at)
+		}
+	}
+}
+
+// lookupField returns the field of struct type t named name, or having the json name name.
+func lookupField(t reflect.Type, name string) (reflect.StructField, bool) {
+	if f, ok := t.FieldByName(name); ok && len(f.Index) == 1 {
+		return f, true
+	}
+	for i := 0; i < t.NumField(); i++ {
+		if f := t.Field(i); strings.Split(f.Tag.Get("json"), ",")[0] == name {
+			return f, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+func segName(s segment) string {
+	switch s.kind {
+	case segIndex:
+		return "elements"
+	case segKey:
+		return "values"
+	case segAny:
+		return "values at any depth"
+	case segType:
+		return "values of type " + s.name
+	}
+	return "field " + s.name
+}
@@ -0,0 +1,60 @@
+package ccopy
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type resolveAccount struct {
+	Owner   *User
+	Email   string `ccopy:"email"`
+	Created time.Time
+	Raw     json.RawMessage
+	Doc     document
+	Audit   []string          `ccopy:"-"`
+	Cache   map[string]string `ccopy:"shallow"`
+	Log     *os.File
+	secret  string
+}
+
+func TestResolveAction(t *testing.T) {
+	RegisterHandler(document.Clone)
+	c, err := NewCopier(Config{}, Options{
+		Rules:  Rules{"resolveAccount.Email": "rule", "User.Addresses[].City": "city", "resolveAccount.Owner.Tags": "tags"},
+		Scopes: []Scope{{Packages: "github.com/gadumitrachioaiei/ccopy", Rules: Rules{"*.Addresses[].Street": "street", "*.Addresses[].City": "scoped"}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	typ := reflect.TypeOf(&resolveAccount{})
+	for path, expected := range map[string]Action{
+		"resolveAccount.Email":                    {Kind: ActionTag, At: "resolveAccount.Email", Type: reflect.TypeOf(""), Customizer: "email"},
+		"resolveAccount.Owner.email":              {Kind: ActionCopy, At: "resolveAccount.Owner.email", Type: reflect.TypeOf("")},
+		"resolveAccount.Owner.Tags{}":             {Kind: ActionRule, At: "resolveAccount.Owner.Tags", Type: reflect.TypeOf(map[string]string(nil)), Customizer: "tags", Rule: "resolveAccount.Owner.Tags"},
+		"resolveAccount.Owner.Addresses[].City":   {Kind: ActionRule, At: "resolveAccount.Owner.Addresses[].City", Type: reflect.TypeOf(""), Customizer: "city", Rule: "User.Addresses[].City"},
+		"resolveAccount.Owner.Addresses[].Street": {Kind: ActionRule, At: "resolveAccount.Owner.Addresses[].Street", Type: reflect.TypeOf(""), Customizer: "street", Rule: "*.Addresses[].Street"},
+		"resolveAccount.Created":                  {Kind: ActionAtomic, At: "resolveAccount.Created", Type: timeType},
+		"resolveAccount.Raw[]":                    {Kind: ActionBlob, At: "resolveAccount.Raw", Type: reflect.TypeOf(json.RawMessage(nil))},
+		"resolveAccount.Doc.Title":                {Kind: ActionHandler, At: "resolveAccount.Doc", Type: reflect.TypeOf(document{})},
+		"resolveAccount.secret":                   {Kind: ActionSkip, At: "resolveAccount.secret", Type: reflect.TypeOf("")},
+		"resolveAccount.Audit[]":                  {Kind: ActionSkip, At: "resolveAccount.Audit", Type: reflect.TypeOf([]string(nil))},
+		"resolveAccount.Cache{}":                  {Kind: ActionShallow, At: "resolveAccount.Cache", Type: reflect.TypeOf(map[string]string(nil))},
+		"resolveAccount.Log":                      {Kind: ActionResource, At: "resolveAccount.Log", Type: reflect.TypeOf((*os.File)(nil))},
+	} {
+		a, err := c.ResolveAction(typ, path)
+		if err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		if a != expected {
+			t.Fatalf("%s: expected: %s, got: %s", path, expected, a)
+		}
+	}
+	for _, path := range []string{"User.Email", "resolveAccount.Missing", "resolveAccount.Owner.email[]", "resolveAccount"} {
+		if _, err := c.ResolveAction(typ, path); err == nil {
+			t.Fatalf("expected an error for: %s", path)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package ccopy
+
+import (
+	"database/sql"
+	"net"
+	"os"
+	"reflect"
+	"sync"
+)
+
+// ResourcePolicy is what a copy does with the handles of resources, like open files, connections and pools,
+// whose deep copies are broken: their unexported state is left out, and the resources are not duplicated anyway.
+type ResourcePolicy int
+
+const (
+	// ResourcesError fails the copies of the values of the resource types, and of the pointers to them,
+	// with an *ErrResource error. Nil pointers are copied.
+	ResourcesError ResourcePolicy = iota
+	// ResourcesShare copies the values of the resource types, and the pointers to them, by assignment:
+	// the copies share the resources with the original.
+	ResourcesShare
+)
+
+// resourceTypes holds the types of the handles of resources, registered with RegisterResource.
+var resourceTypes = struct {
+	sync.RWMutex
+	m map[reflect.Type]bool
+}{m: make(map[reflect.Type]bool)}
+
+func init() {
+	for _, p := range []interface{}{
+		(*os.File)(nil), (*os.Process)(nil),
+		(*net.TCPConn)(nil), (*net.UDPConn)(nil), (*net.UnixConn)(nil), (*net.IPConn)(nil),
+		(*net.TCPListener)(nil), (*net.UnixListener)(nil),
+		(*sql.DB)(nil), (*sql.Conn)(nil), (*sql.Tx)(nil), (*sql.Stmt)(nil), (*sql.Rows)(nil),
+	} {
+		registerResource(reflect.TypeOf(p).Elem())
+	}
+}
+
+// RegisterResource registers the type of v as the type of the handles of resources, like os.File or sql.DB,
+// which are registered already: the copies of its values, and of the pointers to them, follow the Resources option.
+// Registering the type with RegisterAtomic, RegisterConverter or RegisterHandler overrides the option.
+// It panics if v is nil.
+func RegisterResource(v interface{}) {
+	if v == nil {
+		panic("ccopy: register resource nil")
+	}
+	registerResource(reflect.TypeOf(v))
+}
+
+func registerResource(t reflect.Type) {
+	resourceTypes.Lock()
+	defer resourceTypes.Unlock()
+	resourceTypes.m[t] = true
+}
+
+// isResource reports whether t is a resource type, or a pointer to one, without a registered handler.
+func isResource(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if _, ok := typeHandler(t); ok {
+		return false
+	}
+	resourceTypes.RLock()
+	defer resourceTypes.RUnlock()
+	return resourceTypes.m[t]
+}
+
+// copyResource copies ov, a value of a resource type or a pointer to one, by the Resources option.
+func (c *state) copyResource(ov reflect.Value) (reflect.Value, error) {
+	if ov.Kind() == reflect.Ptr && ov.IsNil() || c.resources == ResourcesShare {
+		return ov, nil
+	}
+	return reflect.Zero(ov.Type()), &ErrResource{Type: ov.Type(), Path: c.pathString()}
+}
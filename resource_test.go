@@ -0,0 +1,75 @@
+package ccopy
+
+import (
+	"errors"
+	"io"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestCopyResources(t *testing.T) {
+	type Upload struct {
+		Name   string
+		File   *os.File
+		Reader io.Reader
+	}
+	f, err := os.CreateTemp(t.TempDir(), "upload")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var resource *ErrResource
+	_, err = (Config{}).Copy(Upload{Name: "a", File: f})
+	if !errors.As(err, &resource) || resource.Path != "Upload.File" || resource.Type != reflect.TypeOf(f) {
+		t.Fatalf("got error: %v, expected *ErrResource", err)
+	}
+	if m := Describe(err); m.Key != MessageResource || m.Field != "File" {
+		t.Fatalf("unexpected message: %+v", m)
+	}
+	if _, err := (Config{}).Copy(Upload{Name: "a", Reader: f}); !errors.As(err, &resource) || resource.Path != "Upload.Reader" {
+		t.Fatalf("got error: %v, expected *ErrResource for the resource held by an interface", err)
+	}
+	// nil handles are copied
+	if _, err := (Config{}).Copy(Upload{Name: "a"}); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := NewCopier(Config{}, Options{Resources: ResourcesShare})
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := c.Copy(Upload{Name: "a", File: f, Reader: f})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u := v.(Upload); u.File != f || u.Reader != io.Reader(f) {
+		t.Fatalf("expected the files to be shared, got: %+v", u)
+	}
+
+	// the fields tagged "shallow" are copied as they are, whatever the option
+	v, err = (Config{}).Copy(struct {
+		File *os.File `ccopy:"shallow"`
+	}{File: f})
+	if err != nil || v.(struct {
+		File *os.File `ccopy:"shallow"`
+	}).File != f {
+		t.Fatalf("got: %v, %v, expected the file to be shared", v, err)
+	}
+}
+
+type resourceHandle struct {
+	fd int
+}
+
+func TestRegisterResource(t *testing.T) {
+	RegisterResource(resourceHandle{})
+	type Worker struct {
+		Handle *resourceHandle
+	}
+	var resource *ErrResource
+	if _, err := (Config{}).Copy(Worker{Handle: &resourceHandle{fd: 3}}); !errors.As(err, &resource) || resource.Path != "Worker.Handle" {
+		t.Fatalf("got error: %v, expected *ErrResource", err)
+	}
+}
@@ -0,0 +1,76 @@
+package ccopy
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// validateCopyResults returns an error if a name of the CopyResults option is not in the config.
+func validateCopyResults(c Config, results map[string]bool) error {
+	for name := range results {
+		if _, ok := c[name]; !ok {
+			return fmt.Errorf("invalid copy results option for %s: not a customizer of the config", name)
+		}
+	}
+	return nil
+}
+
+// clone deep copies v, as it is: the values returned by customizers are not customized again.
+// The unexported fields of structs are copied by assignment, and the pointers shared in v are shared in the copy.
+func clone(v reflect.Value, seen map[uintptr]reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		if p, ok := seen[v.Pointer()]; ok && p.Type() == v.Type() {
+			return p
+		}
+		p := reflect.New(v.Type().Elem())
+		seen[v.Pointer()] = p
+		p.Elem().Set(clone(v.Elem(), seen))
+		return p
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		i := reflect.New(v.Type()).Elem()
+		i.Set(clone(v.Elem(), seen))
+		return i
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		s := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			s.Index(i).Set(clone(v.Index(i), seen))
+		}
+		return s
+	case reflect.Array:
+		a := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			a.Index(i).Set(clone(v.Index(i), seen))
+		}
+		return a
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		m := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			m.SetMapIndex(clone(iter.Key(), seen), clone(iter.Value(), seen))
+		}
+		return m
+	case reflect.Struct:
+		s := reflect.New(v.Type()).Elem()
+		s.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if f := s.Field(i); f.CanSet() {
+				f.Set(clone(v.Field(i), seen))
+			}
+		}
+		return s
+	}
+	return v
+}
@@ -0,0 +1,49 @@
+package ccopy
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCopyResults(t *testing.T) {
+	type Avatar struct {
+		Pixels []byte
+		Tags   map[string]*string
+	}
+	type Profile struct {
+		Avatar  *Avatar `ccopy:"avatar"`
+		Default *Avatar `ccopy:"default"`
+	}
+	label := "default"
+	cached := &Avatar{Pixels: []byte{1, 2}, Tags: map[string]*string{"a": &label, "b": &label}}
+	config := Config{
+		"avatar":  func(*Avatar) *Avatar { return cached },
+		"default": func(*Avatar) *Avatar { return cached },
+	}
+	c, err := NewCopier(config, Options{CopyResults: map[string]bool{"avatar": true, "default": false}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := c.Copy(Profile{Avatar: &Avatar{}, Default: &Avatar{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := v.(Profile)
+	if p.Default != cached {
+		t.Fatal("expected the result of default to be used as it is")
+	}
+	if p.Avatar == cached || &p.Avatar.Pixels[0] == &cached.Pixels[0] || p.Avatar.Tags["a"] == &label {
+		t.Fatal("expected the result of avatar to be deep copied")
+	}
+	if p.Avatar.Pixels[1] != 2 || *p.Avatar.Tags["a"] != "default" {
+		t.Fatalf("unexpected copy: %+v", p.Avatar)
+	}
+	if p.Avatar.Tags["a"] != p.Avatar.Tags["b"] {
+		t.Fatal("expected the pointers shared in the result to be shared in its copy")
+	}
+
+	_, err = NewCopier(config, Options{CopyResults: map[string]bool{"missing": true}})
+	if err == nil || !strings.Contains(err.Error(), "missing") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
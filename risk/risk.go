@@ -0,0 +1,158 @@
+// Package risk estimates the risk of re-identification of anonymized records: records whose quasi-identifiers,
+// like zip code, birth year and gender, are shared by few other records can be linked to the people they describe,
+// even without names. It reports the combinations of quasi-identifiers shared by fewer than k records,
+// the records that are not k-anonymous, and suggests the fields to generalize further.
+package risk
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/gadumitrachioaiei/ccopy"
+)
+
+// Report is the re-identification risk of records.
+type Report struct {
+	// Records counts the records.
+	Records int
+	// K is the minimum number of records that must share the quasi-identifiers of a record.
+	K int
+	// Fields are the quasi-identifiers.
+	Fields []string
+	// Classes counts the distinct combinations of quasi-identifiers.
+	Classes int
+	// Risky are the combinations of quasi-identifiers shared by fewer than K records, the rarest first.
+	Risky []Class
+	// Suggestions are the fields to generalize, the most effective first.
+	Suggestions []Suggestion
+}
+
+// RiskyRecords counts the records that are not K-anonymous.
+func (r *Report) RiskyRecords() int {
+	n := 0
+	for _, c := range r.Risky {
+		n += len(c.Records)
+	}
+	return n
+}
+
+// Class is a combination of values of quasi-identifiers, and the records having it.
+type Class struct {
+	// Values are the values of the quasi-identifiers, formatted with fmt.Sprint, in the order of the fields.
+	Values []string
+	// Records are the indexes of the records.
+	Records []int
+}
+
+// Suggestion is the effect of generalizing a field completely, like dropping it.
+type Suggestion struct {
+	Field string
+	// RiskyRecords counts the records that would still not be K-anonymous.
+	RiskyRecords int
+}
+
+// Analyze returns the re-identification risk of records, a slice of structs or of pointers to structs,
+// for the quasi-identifiers fields, which are paths of fields like "Address.ZipCode".
+// It returns an error if records is not a slice, or if some field is not found in its elements.
+func Analyze(records interface{}, fields []string, k int) (*Report, error) {
+	rv := reflect.ValueOf(records)
+	if rv.Kind() != reflect.Slice {
+		return nil, fmt.Errorf("risk: expected a slice of records, got: %T", records)
+	}
+	if k < 1 {
+		return nil, fmt.Errorf("risk: invalid k: %d", k)
+	}
+	values := make([][]string, rv.Len())
+	for i := range values {
+		values[i] = make([]string, len(fields))
+		for j, f := range fields {
+			v, err := field(rv.Index(i), f)
+			if err != nil {
+				return nil, fmt.Errorf("risk: record %d: %w", i, err)
+			}
+			values[i][j] = v
+		}
+	}
+	report := &Report{Records: rv.Len(), K: k, Fields: fields}
+	classes := classify(values, -1)
+	report.Classes = len(classes)
+	for _, c := range classes {
+		if len(c.Records) < k {
+			report.Risky = append(report.Risky, c)
+		}
+	}
+	sort.SliceStable(report.Risky, func(i, j int) bool { return len(report.Risky[i].Records) < len(report.Risky[j].Records) })
+	if len(report.Risky) == 0 {
+		return report, nil
+	}
+	for j, f := range fields {
+		s := Suggestion{Field: f}
+		for _, c := range classify(values, j) {
+			if len(c.Records) < k {
+				s.RiskyRecords += len(c.Records)
+			}
+		}
+		report.Suggestions = append(report.Suggestions, s)
+	}
+	sort.SliceStable(report.Suggestions, func(i, j int) bool {
+		return report.Suggestions[i].RiskyRecords < report.Suggestions[j].RiskyRecords
+	})
+	return report, nil
+}
+
+// AnalyzeCopies returns the re-identification risk of the copies of records made by c, like Analyze.
+func AnalyzeCopies(c *ccopy.Copier, records interface{}, fields []string, k int) (*Report, error) {
+	copies, err := c.Copy(records)
+	if err != nil {
+		return nil, err
+	}
+	return Analyze(copies, fields, k)
+}
+
+// classify returns the classes of the records of values, ignoring the field of index ignored.
+func classify(values [][]string, ignored int) []Class {
+	var classes []Class
+	index := make(map[string]int)
+	for i, v := range values {
+		key := make([]string, 0, len(v))
+		for j, s := range v {
+			if j != ignored {
+				key = append(key, s)
+			}
+		}
+		// the values are quoted, so that no value contains the separator
+		k := fmt.Sprintf("%q", key)
+		n, ok := index[k]
+		if !ok {
+			n = len(classes)
+			index[k] = n
+			classes = append(classes, Class{Values: key})
+		}
+		classes[n].Records = append(classes[n].Records, i)
+	}
+	return classes
+}
+
+// field returns the value at path in v, formatted with fmt.Sprint, following pointers.
+// A nil pointer on the path is the value "<nil>".
+func field(v reflect.Value, path string) (string, error) {
+	for _, name := range strings.Split(path, ".") {
+		for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+			if v.IsNil() {
+				return "<nil>", nil
+			}
+			v = v.Elem()
+		}
+		if v.Kind() != reflect.Struct {
+			return "", fmt.Errorf("no field %s in %s, for: %s", name, v.Type(), path)
+		}
+		f, ok := v.Type().FieldByName(name)
+		if !ok || f.PkgPath != "" {
+			return "", fmt.Errorf("no field %s in %s, for: %s", name, v.Type(), path)
+		}
+		v = v.FieldByIndex(f.Index)
+	}
+	return fmt.Sprint(v.Interface()), nil
+}
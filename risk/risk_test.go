@@ -0,0 +1,76 @@
+package risk
+
+import (
+	"testing"
+
+	"github.com/gadumitrachioaiei/ccopy"
+	"github.com/google/go-cmp/cmp"
+)
+
+type address struct {
+	Zip string `ccopy:"zip"`
+}
+
+type patient struct {
+	Name      string `ccopy:"redact"`
+	BirthYear int
+	Gender    string
+	Address   *address
+}
+
+func TestAnalyzeCopies(t *testing.T) {
+	records := []patient{
+		{Name: "a", BirthYear: 1980, Gender: "F", Address: &address{Zip: "75011"}},
+		{Name: "b", BirthYear: 1980, Gender: "F", Address: &address{Zip: "75012"}},
+		{Name: "c", BirthYear: 1980, Gender: "M", Address: &address{Zip: "75013"}},
+		{Name: "d", BirthYear: 1980, Gender: "M", Address: &address{Zip: "75014"}},
+		{Name: "e", BirthYear: 1991, Gender: "F", Address: &address{Zip: "69001"}},
+	}
+	c, err := ccopy.NewCopier(ccopy.Config{
+		"redact": func(string) string { return "" },
+		// generalizes zip codes to their first 2 digits
+		"zip": func(s string) string { return s[:2] + "***" },
+	}, ccopy.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := AnalyzeCopies(c, records, []string{"BirthYear", "Gender", "Address.Zip"}, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := &Report{
+		Records: 5,
+		K:       2,
+		Fields:  []string{"BirthYear", "Gender", "Address.Zip"},
+		Classes: 3,
+		Risky:   []Class{{Values: []string{"1991", "F", "69***"}, Records: []int{4}}},
+		Suggestions: []Suggestion{
+			{Field: "BirthYear", RiskyRecords: 1},
+			{Field: "Gender", RiskyRecords: 1},
+			{Field: "Address.Zip", RiskyRecords: 1},
+		},
+	}
+	if diff := cmp.Diff(expected, report); diff != "" {
+		t.Fatal(diff)
+	}
+
+	report, err = Analyze(records, []string{"Gender", "Address.Zip"}, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.RiskyRecords() != 5 || report.Suggestions[0].Field != "Address.Zip" || report.Suggestions[0].RiskyRecords != 0 {
+		t.Fatalf("expected all records at risk, and the zip code to generalize first, got: %+v", report)
+	}
+}
+
+func TestAnalyzeInvalid(t *testing.T) {
+	if _, err := Analyze(patient{}, []string{"Gender"}, 2); err == nil {
+		t.Fatal("expected an error for a record that is not a slice")
+	}
+	if _, err := Analyze([]patient{{}}, []string{"Missing"}, 2); err == nil {
+		t.Fatal("expected an error for a missing field")
+	}
+	if _, err := Analyze([]patient{{}}, []string{"Address.Zip"}, 2); err != nil {
+		t.Fatalf("expected nil pointers to be values, got: %v", err)
+	}
+}
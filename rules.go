@@ -0,0 +1,530 @@
+package ccopy
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Rules maps paths to names of customizers in a Config.
+// They customize fields of types that cannot be tagged, or whose tags are decided elsewhere.
+//
+// A path starts with the name of a type, followed by steps into values of that type:
+// ".Name" selects a struct field, by its name or its json name,
+// "[]" selects any element of a slice or array and "{}" selects any value of a map.
+// For example "User.Addresses[].Street" matches the Street field of every address of every User,
+// wherever a User is found in the copied object.
+// So rules apply the same way whether a User is the copied object, is nested in a struct,
+// or is an element of a slice or a map passed to the copy, like []User or map[string]*User.
+//
+// There is a step per level of nesting of containers: the path of the strings of a field
+// Index map[string][]map[int][]*Entry of type Doc, in the Term field of the entries, is "Doc.Index{}[]{}[].Term",
+// and "Doc.Index{}[]" selects the maps of the slices, as a whole. Pointers and interfaces take no step:
+// "Doc.Index{}[]{}[]" selects the *Entry values, customized as a whole, and the paths going on from them
+// step into the entries they point to. Multi-dimensional arrays
+// take a step per dimension, like "Image.Pixels[][]". The keys of maps cannot be selected.
+//
+// The step ".**" selects the values reached by any number of steps, none included, so "Order.**.Email"
+// matches the Email fields of all the structs found in an Order, at any depth, and "Order.**[]" any element
+// of any slice in it. It cannot end a path. A step can be followed by a type filter ":TypeName",
+// restricting it to the values of the named type, or of a pointer or an interface holding it:
+// the values these pointers and interfaces hold are the ones customized. A filter names a type by its name,
+// without its package. So "Order.**:Email" matches every Email value of an Order,
+// and "Order.Contacts[]:Email" the elements of the Contacts field of type Email only.
+//
+// Rules are matched step by step while copying, and the transitions from a set of partial matches
+// to the next are cached by the Copier, so matching costs a lookup per value, whatever the number of rules.
+type Rules map[string]string
+
+// Scope applies rules to the types of some packages only, like stricter defaults for the types of sensitive domains.
+// The type name of the paths of scoped rules can be "*", matching every type of the packages:
+// for example the rule "*.Email" of the scope "github.com/acme/billing/*" matches the Email field
+// of every type of the billing package and of the packages under it.
+type Scope struct {
+	// Packages is the import path of the package of the types, or a path ending with "/*",
+	// matching the package and the packages under it.
+	Packages string
+	Rules    Rules
+}
+
+type segKind int
+
+const (
+	segField segKind = iota
+	segIndex
+	segKey
+	// segAny is the ** step, matching any number of steps
+	segAny
+	// segType is a type filter, matching the values of the named type, with no step
+	segType
+)
+
+// segment is a step of a path, as written in a rule, or a type filter, with the name of the type.
+type segment struct {
+	kind segKind
+	name string
+}
+
+type rule struct {
+	path     string
+	typeName string
+	segs     []segment
+	name     string
+	// packages is the Packages of the scope of the rule, if any
+	packages string
+}
+
+// step is a step taken while copying, to be matched against rule segments.
+type step struct {
+	kind     segKind
+	name     string
+	jsonName string
+}
+
+// match is a rule that matched the steps taken so far, up to segment pos.
+type match struct {
+	rule *rule
+	pos  int
+}
+
+func (r Rules) parse() ([]*rule, error) {
+	var rules []*rule
+	for path, name := range r {
+		typeName, segs, err := parsePath(path)
+		if err != nil {
+			return nil, err
+		}
+		if typeName == anyType {
+			return nil, fmt.Errorf("invalid path: %s: any type outside of a scope", path)
+		}
+		rules = append(rules, &rule{path: path, typeName: typeName, segs: segs, name: name})
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].path < rules[j].path })
+	return rules, nil
+}
+
+// anyType is the type name of the scoped rules matching every type of their packages.
+const anyType = "*"
+
+func (s Scope) parse() ([]*rule, error) {
+	pkg := strings.TrimSuffix(s.Packages, "/*")
+	if pkg == "" || strings.Contains(pkg, "*") {
+		return nil, fmt.Errorf("invalid scope: %q", s.Packages)
+	}
+	var rules []*rule
+	for path, name := range s.Rules {
+		typeName, segs, err := parsePath(path)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, &rule{path: path, typeName: typeName, segs: segs, name: name, packages: s.Packages})
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].path < rules[j].path })
+	return rules, nil
+}
+
+// anchors reports whether the paths of r start from values of type t, a named type.
+func (r *rule) anchors(t reflect.Type) bool {
+	if r.typeName != t.Name() && r.typeName != anyType {
+		return false
+	}
+	if r.packages == "" {
+		return true
+	}
+	if pkg, ok := strings.CutSuffix(r.packages, "/*"); ok {
+		return t.PkgPath() == pkg || strings.HasPrefix(t.PkgPath(), pkg+"/")
+	}
+	return t.PkgPath() == r.packages
+}
+
+func parsePath(path string) (string, []segment, error) {
+	typeName, rest := splitIdent(path)
+	if typeName == "" {
+		return "", nil, fmt.Errorf("invalid path: %s: missing type name", path)
+	}
+	var segs []segment
+	for rest != "" {
+		switch {
+		case strings.HasPrefix(rest, ".**"):
+			if len(segs) > 0 && segs[len(segs)-1].kind == segAny {
+				return "", nil, fmt.Errorf("invalid path: %s: consecutive wildcards", path)
+			}
+			segs = append(segs, segment{kind: segAny})
+			rest = rest[3:]
+		case rest[0] == ':':
+			var name string
+			name, rest = splitIdent(rest[1:])
+			if name == "" || len(segs) == 0 || segs[len(segs)-1].kind == segType {
+				return "", nil, fmt.Errorf("invalid path: %s: type filter not following a step", path)
+			}
+			segs = append(segs, segment{kind: segType, name: name})
+		case strings.HasPrefix(rest, "[]"):
+			segs = append(segs, segment{kind: segIndex})
+			rest = rest[2:]
+		case strings.HasPrefix(rest, "{}"):
+			segs = append(segs, segment{kind: segKey})
+			rest = rest[2:]
+		case rest[0] == '.':
+			var name string
+			name, rest = splitIdent(rest[1:])
+			if name == "" {
+				return "", nil, fmt.Errorf("invalid path: %s: missing field name", path)
+			}
+			if strings.ContainsAny(name, " \t\n*") {
+				return "", nil, fmt.Errorf("invalid path: %s: invalid field name %q", path, name)
+			}
+			segs = append(segs, segment{kind: segField, name: name})
+		default:
+			return "", nil, fmt.Errorf("invalid path: %s: unexpected %q", path, rest)
+		}
+	}
+	if len(segs) == 0 {
+		return "", nil, fmt.Errorf("invalid path: %s: no field selected", path)
+	}
+	if segs[len(segs)-1].kind == segAny {
+		return "", nil, fmt.Errorf("invalid path: %s: ending with a wildcard", path)
+	}
+	return typeName, segs, nil
+}
+
+// splitIdent splits s after its leading identifier.
+func splitIdent(s string) (string, string) {
+	i := strings.IndexAny(s, ".[]{}:")
+	if i < 0 {
+		return s, ""
+	}
+	return s[:i], s[i:]
+}
+
+// registeredRules holds the rules registered with RegisterRules, as a []*rule replaced on every registration.
+var registeredRules struct {
+	sync.Mutex
+	v atomic.Value
+}
+
+// RegisterRules registers rules that apply to every copy, after the rules and scopes of the options of a Copier.
+// It is meant to be called by generated code, from the package that defines the types of the rules,
+// for rules that are part of the definition of the types, like their tags.
+// The rules start from the types of the package with import path pkg only, like the rules of a Scope,
+// so they do not apply to the types of the same name of other packages.
+// It panics if pkg is empty or some path is not valid.
+func RegisterRules(pkg string, r Rules) {
+	if pkg == "" || strings.Contains(pkg, "*") {
+		panic(fmt.Sprintf("ccopy: register rules: invalid package: %q", pkg))
+	}
+	rules, err := r.parse()
+	if err != nil {
+		panic("ccopy: register rules: " + err.Error())
+	}
+	for _, r := range rules {
+		r.packages = pkg
+	}
+	registeredRules.Lock()
+	defer registeredRules.Unlock()
+	old, _ := registeredRules.v.Load().([]*rule)
+	registeredRules.v.Store(append(old[:len(old):len(old)], rules...))
+}
+
+// anchor starts matching the rules whose path starts with the name of type t.
+func (c *Copier) anchor(active []match, t reflect.Type) []match {
+	if t.PkgPath() == "" || c.plans.uncustomized {
+		return active
+	}
+	registered, _ := registeredRules.v.Load().([]*rule)
+	if len(active) == 0 {
+		// the anchors of the types, the most common transitions, are cached by type only
+		if a, ok := c.plans.anchors.Load(t); ok && a.(*anchors).registered == len(registered) {
+			return a.(*anchors).matches
+		}
+		next := anchorRules(nil, t, c.rules, registered)
+		c.plans.anchors.Store(t, &anchors{matches: next, registered: len(registered)})
+		return next
+	}
+	key := transition{from: first(active), n: len(active), anchor: t, registered: len(registered)}
+	if next, ok := c.plans.transitions.Load(key); ok {
+		return next.([]match)
+	}
+	next := anchorRules(active, t, c.rules, registered)
+	c.plans.transitions.Store(key, next)
+	return next
+}
+
+// anchors are the matches starting at the values of a type, when registered rules were registered.
+type anchors struct {
+	matches    []match
+	registered int
+}
+
+// anchorRules appends to active the matches of the rules whose path starts with the name of type t.
+func anchorRules(active []match, t reflect.Type, rules, registered []*rule) []match {
+	for _, rules := range [][]*rule{rules, registered} {
+		for _, r := range rules {
+			if r.anchors(t) {
+				active = append(active[:len(active):len(active)], match{rule: r})
+			}
+		}
+	}
+	return active
+}
+
+// transition is a change of a set of matches, cached by the plans: taking a step, which is not copied,
+// reaching a value of type reached, or anchoring the rules at a value of type anchor, when registered rules were registered.
+// The sets of matches are identified by their first match and their length, as the sets returned
+// by the plans are never modified, and the sets the engine matches with are all returned by the plans.
+type transition struct {
+	from       *match
+	n          int
+	step       *step
+	reached    reflect.Type
+	anchor     reflect.Type
+	registered int
+}
+
+// indexStep and keyStep are the steps into the elements of slices and arrays and into the values of maps,
+// identified by their address in the transitions, like the steps into fields in the plans of their structs.
+var (
+	indexStep = step{kind: segIndex}
+	keyStep   = step{kind: segKey}
+)
+
+// first returns the first match of a set, identifying it.
+func first(active []match) *match {
+	if len(active) == 0 {
+		return nil
+	}
+	return &active[0]
+}
+
+// advance is the package advance, cached.
+func (p *plans) advance(active []match, s *step) []match {
+	if len(active) == 0 {
+		return nil
+	}
+	key := transition{from: first(active), n: len(active), step: s}
+	if next, ok := p.transitions.Load(key); ok {
+		return next.([]match)
+	}
+	next := advance(active, *s)
+	p.transitions.Store(key, next)
+	return next
+}
+
+// advance returns the matches that still match after taking step s.
+func advance(active []match, s step) []match {
+	var next []match
+	for _, m := range active {
+		// the matches that are complete select the values they reached, not the values in them
+		if m.pos == len(m.rule.segs) {
+			continue
+		}
+		seg := m.rule.segs[m.pos]
+		if seg.kind == segAny {
+			// the wildcard takes the step, or matches no step, letting the next segment take it
+			next = appendMatch(next, m)
+			if seg = m.rule.segs[m.pos+1]; seg.kind != segType && seg.matches(s) {
+				next = appendMatch(next, match{rule: m.rule, pos: m.pos + 2})
+			}
+			continue
+		}
+		if seg.matches(s) {
+			next = appendMatch(next, match{rule: m.rule, pos: m.pos + 1})
+		}
+	}
+	return next
+}
+
+// matches reports whether the step segment seg matches step s.
+func (seg segment) matches(s step) bool {
+	if seg.kind != s.kind {
+		return false
+	}
+	return seg.kind != segField || seg.name == s.name || seg.name == s.jsonName
+}
+
+// appendMatch appends m to matches, unless it is in them already, as wildcards can match the same steps many ways.
+func appendMatch(matches []match, m match) []match {
+	for _, o := range matches {
+		if o == m {
+			return matches
+		}
+	}
+	return append(matches, m)
+}
+
+// reach is the package reach, cached, so the sets of matches it filters are returned by the plans too.
+func (p *plans) reach(active []match, t reflect.Type) []match {
+	if t.Kind() == reflect.Ptr || t.Kind() == reflect.Interface || !filtered(active) {
+		return active
+	}
+	key := transition{from: first(active), n: len(active), reached: t}
+	if next, ok := p.transitions.Load(key); ok {
+		return next.([]match)
+	}
+	next := reach(active, t)
+	p.transitions.Store(key, next)
+	return next
+}
+
+// reach returns the matches that still match after reaching a value of type t, applying the type filters:
+// the filters of the wildcards matching no step as well. Pointers and interfaces pass the filters on to the values they hold.
+func reach(active []match, t reflect.Type) []match {
+	if t.Kind() == reflect.Ptr || t.Kind() == reflect.Interface || !filtered(active) {
+		return active
+	}
+	var next []match
+	for _, m := range active {
+		p := m.pos
+		switch {
+		case p < len(m.rule.segs) && m.rule.segs[p].kind == segType:
+			if m.rule.segs[p].name == t.Name() {
+				next = appendMatch(next, match{rule: m.rule, pos: p + 1})
+			}
+		case p < len(m.rule.segs) && m.rule.segs[p].kind == segAny && m.rule.segs[p+1].kind == segType:
+			next = appendMatch(next, m)
+			if m.rule.segs[p+1].name == t.Name() {
+				next = appendMatch(next, match{rule: m.rule, pos: p + 2})
+			}
+		default:
+			next = appendMatch(next, m)
+		}
+	}
+	return next
+}
+
+// filtered reports whether some of the matches filter the types of the values they reach.
+func filtered(active []match) bool {
+	for _, m := range active {
+		if p := m.pos; p < len(m.rule.segs) && (m.rule.segs[p].kind == segType || m.rule.segs[p].kind == segAny && m.rule.segs[p+1].kind == segType) {
+			return true
+		}
+	}
+	return false
+}
+
+// matched returns the name of the customizer of a rule fully matched by the steps taken so far.
+func matched(active []match) (string, bool) {
+	if r := matchedRule(active); r != nil {
+		return r.name, true
+	}
+	return "", false
+}
+
+// matchedRule returns the first rule fully matched by the steps taken so far, if any.
+func matchedRule(active []match) *rule {
+	for _, m := range active {
+		if m.pos == len(m.rule.segs) {
+			return m.rule
+		}
+	}
+	return nil
+}
+
+func fieldStep(f reflect.StructField) step {
+	s := step{kind: segField, name: f.Name}
+	if name := strings.Split(f.Tag.Get("json"), ",")[0]; name != "-" {
+		s.jsonName = name
+	}
+	return s
+}
+
+// RuleConflictPolicy is what to do with the rules that can match the same values with different customizers.
+//
+// The rule that applies to a value is the first of the rules matching it, in this order: the rules anchored
+// at the outermost value, that is the ones starting from the type of the value found first, while copying;
+// then the Rules option, the paths of the config registered with Config.RegisterPath, the Scopes option,
+// in their order, and the rules registered with RegisterRules;
+// then the paths of the rules in lexical order. Tags always win over rules.
+type RuleConflictPolicy int
+
+const (
+	// RuleConflictsPriority applies the first rule, in the order above.
+	RuleConflictsPriority RuleConflictPolicy = iota
+	// RuleConflictsError makes NewCopier fail with an *ErrRuleConflict error for the rules whose paths
+	// can match the same values, starting from the same types, and the copies fail with an *ErrRuleConflict error
+	// for the values that rules starting from different types match.
+	RuleConflictsError
+)
+
+// conflict returns the first pair of rules with different customizers whose paths can match the same values,
+// starting from the same type, if any.
+func conflict(rules []*rule) (*rule, *rule) {
+	for i, a := range rules {
+		for _, b := range rules[i+1:] {
+			if a.name != b.name && a.sameAnchors(b) && overlap(a.segs, b.segs) {
+				return a, b
+			}
+		}
+	}
+	return nil, nil
+}
+
+// sameAnchors reports whether some type can anchor both r and o.
+func (r *rule) sameAnchors(o *rule) bool {
+	if r.typeName != o.typeName && r.typeName != anyType && o.typeName != anyType {
+		return false
+	}
+	if r.packages == "" || o.packages == "" {
+		return true
+	}
+	return packagesOverlap(r.packages, o.packages) || packagesOverlap(o.packages, r.packages)
+}
+
+// packagesOverlap reports whether the packages of scope a include some of the packages of scope b.
+func packagesOverlap(a, b string) bool {
+	if prefix, ok := strings.CutSuffix(a, "/*"); ok {
+		b = strings.TrimSuffix(b, "/*")
+		return b == prefix || strings.HasPrefix(b, prefix+"/")
+	}
+	return a == b
+}
+
+// overlap reports whether some steps can match both paths a and b, made of segments.
+// Field names are compared as they are written, as a field and its json name cannot be told apart without its type,
+// and type filters are assumed to accept the values, unless they are at the same value and differ,
+// so some paths reported to overlap may match no value together, but no paths that do are missed.
+func overlap(a, b []segment) bool {
+	memo := make(map[[2]int]bool)
+	var can func(i, j int) bool
+	can = func(i, j int) (ok bool) {
+		key := [2]int{i, j}
+		if v, seen := memo[key]; seen {
+			return v
+		}
+		memo[key] = false
+		defer func() { memo[key] = ok }()
+		switch {
+		case i < len(a) && j < len(b) && a[i].kind == segType && b[j].kind == segType:
+			return a[i].name == b[j].name && can(i+1, j+1)
+		case i < len(a) && a[i].kind == segType:
+			return can(i+1, j)
+		case j < len(b) && b[j].kind == segType:
+			return can(i, j+1)
+		case i < len(a) && a[i].kind == segAny:
+			// the wildcard matches no more steps, or takes the next step of b, wildcards included
+			return can(i+1, j) || j < len(b) && can(i, j+1)
+		case j < len(b) && b[j].kind == segAny:
+			return can(i, j+1) || i < len(a) && can(i+1, j)
+		case i == len(a) || j == len(b):
+			return i == len(a) && j == len(b)
+		}
+		if a[i].kind != b[j].kind || a[i].kind == segField && a[i].name != b[j].name {
+			return false
+		}
+		return can(i+1, j+1)
+	}
+	return can(0, 0)
+}
+
+// conflicting returns the first rule fully matched by the steps taken so far whose customizer differs
+// from the one of the rule r that applies, if any.
+func conflicting(active []match, r *rule) *rule {
+	for _, m := range active {
+		if m.pos == len(m.rule.segs) && m.rule.name != r.name {
+			return m.rule
+		}
+	}
+	return nil
+}
@@ -0,0 +1,415 @@
+package ccopy
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type Address struct {
+	Street string
+	City   string
+}
+
+type User struct {
+	Email     string `json:"email"`
+	Addresses []Address
+	Tags      map[string]string
+}
+
+func TestCopierRules(t *testing.T) {
+	redact := func(s string) string { return "redacted" }
+	c, err := NewCopier(Config{"redact": redact}, Options{Rules: Rules{
+		"User.email":              "redact",
+		"User.Addresses[].Street": "redact",
+		"User.Tags{}":             "redact",
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	u := User{
+		Email:     "john@example.com",
+		Addresses: []Address{{Street: "Main", City: "Paris"}},
+		Tags:      map[string]string{"a": "b"},
+	}
+	vi, err := c.Copy([]*User{&u})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []*User{{
+		Email:     "redacted",
+		Addresses: []Address{{Street: "redacted", City: "Paris"}},
+		Tags:      map[string]string{"a": "redacted"},
+	}}
+	if diff := cmp.Diff(vi, expected); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestCopierRulesTagWins(t *testing.T) {
+	type T struct {
+		Name string `ccopy:"fn"`
+	}
+	c, err := NewCopier(Config{
+		"fn":    func(string) string { return "tag" },
+		"other": func(string) string { return "rule" },
+	}, Options{Rules: Rules{"T.Name": "other"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	vi, err := c.Copy(T{Name: "x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := vi.(T); v.Name != "tag" {
+		t.Fatalf("got name: %s, expected: tag", v.Name)
+	}
+}
+
+func TestNewCopierInvalidRules(t *testing.T) {
+	for _, path := range []string{"", "User", ".Email", "User..Email", "User[", "User.Email]", "User.Email ", "User.*"} {
+		if _, err := NewCopier(Config{}, Options{Rules: Rules{path: "fn"}}); err == nil {
+			t.Fatalf("expected error for path: %q", path)
+		}
+	}
+}
+
+type registeredUser struct {
+	Email, Name string
+}
+
+func TestRegisterRules(t *testing.T) {
+	RegisterRules(reflect.TypeOf(registeredUser{}).PkgPath(), Rules{"registeredUser.Email": "redact"})
+	RegisterRules("example.com/other", Rules{"registeredUser.Name": "redact"})
+	vi, err := Config{"redact": func(string) string { return "redacted" }}.Copy(registeredUser{Email: "john@example.com", Name: "John"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := vi.(registeredUser); v.Email != "redacted" || v.Name != "John" {
+		t.Fatalf("got: %+v, expected the email only to be redacted", v)
+	}
+}
+
+func TestCopierRulesTopLevel(t *testing.T) {
+	type Users []User
+	redact := func(s string) string { return "redacted" }
+	c, err := NewCopier(Config{"redact": redact}, Options{Rules: Rules{"User.email": "redact"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	u := User{Email: "john@example.com"}
+	expected := User{Email: "redacted"}
+	tests := []struct {
+		name     string
+		obj      interface{}
+		expected interface{}
+	}{
+		{"struct", u, expected},
+		{"pointer", &u, &expected},
+		{"slice", []User{u}, []User{expected}},
+		{"named slice", Users{u}, Users{expected}},
+		{"array", [1]User{u}, [1]User{expected}},
+		{"map", map[string]User{"a": u}, map[string]User{"a": expected}},
+		{"map of slices", map[int][]*User{1: {&u}}, map[int][]*User{1: {&expected}}},
+		{"interfaces", []interface{}{u, "x"}, []interface{}{expected, "x"}},
+	}
+	for _, test := range tests {
+		v, err := c.Copy(test.obj)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if diff := cmp.Diff(v, test.expected); diff != "" {
+			t.Fatalf("%s: %s", test.name, diff)
+		}
+	}
+}
+
+func TestCopierScopes(t *testing.T) {
+	config := Config{
+		"redact": func(string) string { return "redacted" },
+		"strict": func(string) string { return "strict" },
+	}
+	for _, test := range []struct {
+		scope    Scope
+		rules    Rules
+		expected string
+	}{
+		{Scope{Packages: "github.com/gadumitrachioaiei/ccopy", Rules: Rules{"*.Email": "strict"}}, nil, "strict"},
+		{Scope{Packages: "github.com/gadumitrachioaiei/*", Rules: Rules{"User.email": "strict"}}, nil, "strict"},
+		{Scope{Packages: "github.com/gadumitrachioaiei/ccopy/*", Rules: Rules{"*.Email": "strict"}}, nil, "strict"},
+		{Scope{Packages: "github.com/acme/billing/*", Rules: Rules{"*.Email": "strict"}}, nil, "john@example.com"},
+		{Scope{Packages: "github.com/gadumitrachioaiei/ccopy", Rules: Rules{"*.Email": "strict"}}, Rules{"User.Email": "redact"}, "redacted"},
+	} {
+		c, err := NewCopier(config, Options{Rules: test.rules, Scopes: []Scope{test.scope}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		vi, err := c.Copy(&User{Email: "john@example.com"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v := vi.(*User); v.Email != test.expected {
+			t.Fatalf("scope %+v: got email: %s, expected: %s", test.scope, v.Email, test.expected)
+		}
+	}
+}
+
+func TestCopierScopesInvalid(t *testing.T) {
+	if _, err := NewCopier(Config{}, Options{Rules: Rules{"*.Email": "fn"}}); err == nil {
+		t.Fatal("expected error for any type outside of a scope")
+	}
+	for _, pkg := range []string{"", "/*", "github.com/*/billing"} {
+		if _, err := NewCopier(Config{}, Options{Scopes: []Scope{{Packages: pkg, Rules: Rules{"*.Email": "fn"}}}}); err == nil {
+			t.Fatalf("expected error for scope: %q", pkg)
+		}
+	}
+}
+
+type nestedEntry struct {
+	Term  string
+	Count int
+}
+
+type nestedDoc struct {
+	Index  map[string][]map[int][]*nestedEntry
+	Grid   [2][3]string
+	Shapes [][]interface{}
+}
+
+func TestCopierRulesNested(t *testing.T) {
+	c, err := NewCopier(Config{
+		"redact": func(s string) string { return "redacted" },
+		"drop":   func(m map[int][]*nestedEntry) map[int][]*nestedEntry { return nil },
+		"count":  func(n int) int { return -1 },
+		"shape":  func(v interface{}) interface{} { return -1 },
+	}, Options{Rules: Rules{
+		"nestedDoc.Index{}[]{}[].Term": "redact",
+		"nestedDoc.Index{}[]":          "drop",
+		"nestedDoc.Grid[][]":           "redact",
+		"nestedDoc.Shapes[][]":         "shape",
+		"nestedEntry.Count":            "count",
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry := &nestedEntry{Term: "secret", Count: 2}
+	obj := nestedDoc{
+		Index:  map[string][]map[int][]*nestedEntry{"a": {{1: {entry, nil}}}},
+		Grid:   [2][3]string{{"a", "b", "c"}, {"d"}},
+		Shapes: [][]interface{}{{1, 2}, nil},
+	}
+	v, err := c.Copy(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the rule selecting the maps wins over the rule selecting their entries, being complete first
+	expected := nestedDoc{
+		Index:  map[string][]map[int][]*nestedEntry{"a": {nil}},
+		Grid:   [2][3]string{{"redacted", "redacted", "redacted"}, {"redacted", "redacted", "redacted"}},
+		Shapes: [][]interface{}{{-1, -1}, nil},
+	}
+	if diff := cmp.Diff(expected, v); diff != "" {
+		t.Fatal(diff)
+	}
+
+	c, err = NewCopier(Config{"redact": func(s string) string { return "redacted" }}, Options{Rules: Rules{
+		"nestedDoc.Index{}[]{}[].Term": "redact",
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err = c.Copy(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := v.(nestedDoc).Index["a"][0][1]
+	if got[0].Term != "redacted" || got[0].Count != 2 || got[1] != nil || got[0] == entry {
+		t.Fatalf("unexpected entries: %+v", got)
+	}
+	if entry.Term != "secret" {
+		t.Fatal("expected the original to be unchanged")
+	}
+}
+
+type Email string
+
+type wildContact struct {
+	Email    string
+	Personal Email
+	Other    interface{}
+}
+
+type wildOrder struct {
+	Email    string
+	Buyer    *wildContact
+	Contacts []wildContact
+	Notes    map[string][]interface{}
+}
+
+func TestCopierRulesWildcards(t *testing.T) {
+	config := Config{
+		"redact": func(s string) string { return "redacted" },
+		"email":  func(e Email) Email { return "hidden@example.com" },
+	}
+	obj := wildOrder{
+		Email:    "order@example.com",
+		Buyer:    &wildContact{Email: "buyer@example.com", Personal: "me@example.com", Other: Email("other@example.com")},
+		Contacts: []wildContact{{Email: "c@example.com", Other: "kept"}},
+		Notes:    map[string][]interface{}{"a": {Email("note@example.com"), "kept"}},
+	}
+	c, err := NewCopier(config, Options{Rules: Rules{
+		"wildOrder.**.Email": "redact",
+		"wildOrder.**:Email": "email",
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		v, err := c.Copy(obj)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := wildOrder{
+			Email:    "redacted",
+			Buyer:    &wildContact{Email: "redacted", Personal: "hidden@example.com", Other: Email("hidden@example.com")},
+			Contacts: []wildContact{{Email: "redacted", Personal: "hidden@example.com", Other: "kept"}},
+			Notes:    map[string][]interface{}{"a": {Email("hidden@example.com"), "kept"}},
+		}
+		if diff := cmp.Diff(expected, v); diff != "" {
+			t.Fatal(diff)
+		}
+	}
+
+	c, err = NewCopier(config, Options{Rules: Rules{"wildOrder.Contacts[].Other:Email": "email"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := c.Copy(wildOrder{Contacts: []wildContact{{Other: Email("a@example.com")}, {Other: "b"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if others := v.(wildOrder).Contacts; others[0].Other != Email("hidden@example.com") || others[1].Other != "b" {
+		t.Fatalf("unexpected contacts: %+v", others)
+	}
+
+	for _, path := range []string{"wildOrder.**", "wildOrder.**.**.Email", "wildOrder:Email", "wildOrder.Email:", "wildOrder.Buyer:Email:Email"} {
+		if _, err := NewCopier(config, Options{Rules: Rules{path: "redact"}}); err == nil {
+			t.Errorf("expected error for path: %q", path)
+		}
+	}
+}
+
+func TestCopierRulesWildcardsCache(t *testing.T) {
+	c, err := NewCopier(Config{"email": func(e Email) Email { return "hidden@example.com" }},
+		Options{Rules: Rules{"wildOrder.**:Email": "email"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	obj := wildOrder{
+		Buyer:    &wildContact{Personal: "me@example.com", Other: Email("other@example.com")},
+		Contacts: []wildContact{{Personal: "c@example.com"}, {Other: "kept"}},
+		Notes:    map[string][]interface{}{"a": {Email("note@example.com"), "kept"}},
+	}
+	transitions := func() int {
+		n := 0
+		c.plans.transitions.Range(func(_, _ interface{}) bool {
+			n++
+			return true
+		})
+		return n
+	}
+	if _, err := c.Copy(obj); err != nil {
+		t.Fatal(err)
+	}
+	n := transitions()
+	for i := 0; i < 10; i++ {
+		if _, err := c.Copy(obj); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := transitions(); got != n {
+		t.Fatalf("got: %d transitions, expected: %d, the same as after the first copy", got, n)
+	}
+	// resolving the actions, the coverage and the shadow diffs take the cached transitions as well
+	walk := func() {
+		if _, err := c.ResolveAction(reflect.TypeOf(obj), "wildOrder.Contacts[].Other"); err != nil {
+			t.Fatal(err)
+		}
+		c.Coverage(obj)
+		NewShadow(c, c).Diff(obj)
+	}
+	walk()
+	n = transitions()
+	for i := 0; i < 10; i++ {
+		walk()
+	}
+	if got := transitions(); got != n {
+		t.Fatalf("got: %d transitions, expected: %d, the same as after the first walk", got, n)
+	}
+}
+
+func TestCopierRuleConflicts(t *testing.T) {
+	config := Config{
+		"redact": func(string) string { return "redacted" },
+		"strict": func(string) string { return "strict" },
+	}
+	u := User{Email: "john@example.com", Addresses: []Address{{Street: "Main"}}}
+
+	c, err := NewCopier(config, Options{Rules: Rules{
+		"User.Addresses[].Street": "redact",
+		"Address.Street":          "strict",
+		"User.**.Street":          "strict",
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	vi, err := c.Copy(u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if street := vi.(User).Addresses[0].Street; street != "strict" {
+		t.Fatalf("got street: %s, expected: strict", street)
+	}
+
+	_, err = NewCopier(config, Options{RuleConflicts: RuleConflictsError, Rules: Rules{
+		"User.Addresses[].Street": "redact",
+		"User.**.Street":          "strict",
+	}})
+	var conflict *ErrRuleConflict
+	if !errors.As(err, &conflict) {
+		t.Fatalf("got error: %v, expected a rule conflict", err)
+	}
+	if diff := cmp.Diff([]string{"User.**.Street", "User.Addresses[].Street"}, conflict.Rules); diff != "" {
+		t.Fatal(diff)
+	}
+
+	for _, rules := range []Rules{
+		{"User.Addresses[].Street": "redact", "User.**.Street": "redact"},
+		{"User.Addresses[].Street": "redact", "User.Addresses[].City": "strict", "User.email": "strict"},
+		{"User.Addresses[]:Email.Street": "redact", "User.Addresses[]:Address.Street": "strict"},
+	} {
+		if _, err := NewCopier(config, Options{RuleConflicts: RuleConflictsError, Rules: rules}); err != nil {
+			t.Fatalf("%v: %v", rules, err)
+		}
+	}
+
+	c, err = NewCopier(config, Options{RuleConflicts: RuleConflictsError, Rules: Rules{
+		"User.Addresses[].Street": "redact",
+		"Address.Street":          "strict",
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Copy(Address{Street: "Main"}); err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.Copy(u)
+	if !errors.As(err, &conflict) {
+		t.Fatalf("got error: %v, expected a rule conflict", err)
+	}
+	if conflict.Path == "" {
+		t.Fatalf("expected the path of the conflict: %v", err)
+	}
+}
@@ -0,0 +1,136 @@
+package ccopy
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+)
+
+// Finding is personal data found by a detector while scanning a value.
+type Finding struct {
+	// Path is the path of the value holding the data, like Order.Items[3].Buyer.Email.
+	Path string
+	// Kind is what the value holds, like "ssn".
+	Kind string
+}
+
+// Detector finds personal data in the value v, found at path by Scan.
+// It is called for every value found, from the scanned value down to its basic values,
+// except for pointers and interfaces, whose values are passed instead.
+// The values of unexported fields are passed as well, so v.Interface() cannot be called on every value.
+type Detector func(path string, v reflect.Value) []Finding
+
+// StringDetector returns a detector of the values of kind string for which detect returns true,
+// reporting them with the given kind. Leading and trailing spaces are trimmed before calling detect.
+func StringDetector(kind string, detect func(s string) bool) Detector {
+	return func(path string, v reflect.Value) []Finding {
+		if v.Kind() != reflect.String || !detect(strings.TrimSpace(v.String())) {
+			return nil
+		}
+		return []Finding{{Path: path, Kind: kind}}
+	}
+}
+
+// registeredDetectors holds the detectors registered with RegisterDetector, as a []Detector replaced on every registration.
+var registeredDetectors struct {
+	sync.Mutex
+	v atomic.Value
+}
+
+// RegisterDetector registers a detector used by every scan, after the detectors registered before it.
+// Detectors for the formats of some regions, like national identification numbers, are in package nationalid.
+func RegisterDetector(d Detector) {
+	registeredDetectors.Lock()
+	defer registeredDetectors.Unlock()
+	old, _ := registeredDetectors.v.Load().([]Detector)
+	registeredDetectors.v.Store(append(old[:len(old):len(old)], d))
+}
+
+// Scan returns what the registered detectors find in v, like in a copy that should hold no personal data anymore.
+// The findings are in the order of the values, the entries of maps sorted by key, and of the detectors.
+// Values reached through several pointers are scanned once.
+// The keys of maps that are not integers are written as a hash of their value in the paths,
+// like in the errors of copies; Copier.Scan writes them as they are with the KeysInPaths option.
+func Scan(v interface{}) []Finding {
+	return scan(v, false)
+}
+
+// Scan returns what the registered detectors find in v, like the function Scan,
+// writing the keys of maps in the paths like the errors of the copies of c.
+func (c *Copier) Scan(v interface{}) []Finding {
+	return scan(v, c.live().keysInPaths)
+}
+
+// scan scans v, writing the keys of maps as they are in the paths if keys is set.
+func scan(v interface{}, keys bool) []Finding {
+	detectors, _ := registeredDetectors.v.Load().([]Detector)
+	if len(detectors) == 0 || v == nil {
+		return nil
+	}
+	s := &scanner{detectors: detectors, root: reflect.TypeOf(v), keys: keys, seen: make(map[scanned]bool)}
+	s.scan(reflect.ValueOf(v))
+	return s.findings
+}
+
+// scanned identifies a value reached through a pointer.
+type scanned struct {
+	p unsafe.Pointer
+	t reflect.Type
+}
+
+type scanner struct {
+	detectors []Detector
+	root      reflect.Type
+	path      []pathElem
+	keys      bool
+	seen      map[scanned]bool
+	findings  []Finding
+}
+
+func (s *scanner) scan(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Invalid:
+		return
+	case reflect.Ptr:
+		if v.IsNil() {
+			return
+		}
+		key := scanned{p: unsafe.Pointer(v.Pointer()), t: v.Type()}
+		if s.seen[key] {
+			return
+		}
+		s.seen[key] = true
+		s.scan(v.Elem())
+		return
+	case reflect.Interface:
+		s.scan(v.Elem())
+		return
+	}
+	path := formatPath(s.root, s.path, s.keys)
+	for _, d := range s.detectors {
+		s.findings = append(s.findings, d(path, v)...)
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < v.NumField(); i++ {
+			s.path = append(s.path, pathElem{kind: segField, field: t.Field(i).Name})
+			s.scan(v.Field(i))
+			s.path = s.path[:len(s.path)-1]
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			s.path = append(s.path, pathElem{kind: segIndex, index: i})
+			s.scan(v.Index(i))
+			s.path = s.path[:len(s.path)-1]
+		}
+	case reflect.Map:
+		for _, e := range sortedEntries(v) {
+			s.path = append(s.path, pathElem{kind: segKey, key: e[0]})
+			s.scan(e[1])
+			s.path = s.path[:len(s.path)-1]
+		}
+	}
+}
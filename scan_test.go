@@ -0,0 +1,53 @@
+package ccopy
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type scanNode struct {
+	Email string
+	note  string
+	Next  *scanNode
+	Any   interface{}
+}
+
+func TestScan(t *testing.T) {
+	if findings := Scan(scanNode{Email: "john@example.com"}); len(findings) != 0 {
+		t.Fatalf("got findings: %v, expected none without detectors", findings)
+	}
+	RegisterDetector(StringDetector("email", func(s string) bool { return strings.Contains(s, "@") }))
+	RegisterDetector(func(path string, v reflect.Value) []Finding {
+		if v.Kind() == reflect.Struct && v.Type() == reflect.TypeOf(scanNode{}) && v.FieldByName("note").String() != "" {
+			return []Finding{{Path: path, Kind: "note"}}
+		}
+		return nil
+	})
+	n := &scanNode{Email: "kept", note: "x", Any: []string{"a", " jane@example.com "}}
+	n.Next = &scanNode{Email: "john@example.com", Next: n, Any: map[int]interface{}{2: "b@example.com", 1: "a@example.com"}}
+	expected := []Finding{
+		{Path: "scanNode", Kind: "note"},
+		{Path: "scanNode.Next.Email", Kind: "email"},
+		{Path: "scanNode.Next.Any[1]", Kind: "email"},
+		{Path: "scanNode.Next.Any[2]", Kind: "email"},
+		{Path: "scanNode.Any[1]", Kind: "email"},
+	}
+	if diff := cmp.Diff(expected, Scan(n)); diff != "" {
+		t.Fatal(diff)
+	}
+	// the keys of maps, which can be personal data, are hashed unless the Copier writes them as they are
+	contacts := map[string]string{"john@example.com": "jane@example.com"}
+	if f := Scan(contacts); len(f) != 1 || strings.Contains(f[0].Path, "john") || !strings.HasPrefix(f[0].Path, "map[string]string[#") {
+		t.Fatalf("got findings: %v, expected the key hashed in the path", f)
+	}
+	c, err := NewCopier(Config{}, Options{KeysInPaths: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f := c.Scan(contacts); len(f) != 1 || f[0].Path != `map[string]string["john@example.com"]` {
+		t.Fatalf("got findings: %v, expected the key in the path", f)
+	}
+}
@@ -0,0 +1,231 @@
+// Package schema infers ccopy rules from Avro and JSON schemas,
+// so that fields annotated in a schema registry are customized without tagging the Go types.
+package schema
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gadumitrachioaiei/ccopy"
+)
+
+// Inference maps schema annotations to names of customizers of a ccopy.Config.
+// When several annotations of a field apply, the explicit Property wins over Properties,
+// which win over LogicalTypes and Formats.
+type Inference struct {
+	// Property is the custom property whose string value names the customizer of a field, "ccopy" if empty.
+	Property string
+	// Properties maps custom boolean properties, like "pii", to the customizer of fields having them true.
+	Properties map[string]string
+	// LogicalTypes maps Avro logical types, like "uuid", to customizers.
+	LogicalTypes map[string]string
+	// Formats maps JSON schema formats, like "email", to customizers.
+	Formats map[string]string
+}
+
+// customizer returns the customizer for a schema node with the given properties, if any.
+func (in Inference) customizer(props map[string]interface{}, typeProps map[string]interface{}) string {
+	property := in.Property
+	if property == "" {
+		property = "ccopy"
+	}
+	if name, ok := props[property].(string); ok && name != "" {
+		return name
+	}
+	var names []string
+	for prop := range in.Properties {
+		names = append(names, prop)
+	}
+	sort.Strings(names)
+	for _, prop := range names {
+		if v, ok := props[prop].(bool); ok && v {
+			return in.Properties[prop]
+		}
+	}
+	for _, p := range []map[string]interface{}{props, typeProps} {
+		if lt, ok := p["logicalType"].(string); ok && in.LogicalTypes[lt] != "" {
+			return in.LogicalTypes[lt]
+		}
+		if f, ok := p["format"].(string); ok && in.Formats[f] != "" {
+			return in.Formats[f]
+		}
+	}
+	return ""
+}
+
+// FromAvro returns the rules for the records defined in an Avro schema.
+// The paths of the rules start with the simple names of the records,
+// so every record must be copied into a Go type of the same name.
+func (in Inference) FromAvro(data []byte) (ccopy.Rules, error) {
+	var s interface{}
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("invalid avro schema: %w", err)
+	}
+	rules := ccopy.Rules{}
+	if err := in.avroType(rules, "", s); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// avroType adds the rules for the avro type found at path s.
+func (in Inference) avroType(rules ccopy.Rules, path string, s interface{}) error {
+	switch s := s.(type) {
+	case string:
+		// primitive type, or reference to a named type defined elsewhere
+		return nil
+	case []interface{}:
+		// union
+		for _, t := range s {
+			if err := in.avroType(rules, path, t); err != nil {
+				return err
+			}
+		}
+		return nil
+	case map[string]interface{}:
+		switch s["type"] {
+		case "record", "error":
+			return in.avroRecord(rules, s)
+		case "array":
+			return in.avroItems(rules, path+"[]", s["items"])
+		case "map":
+			return in.avroItems(rules, path+"{}", s["values"])
+		default:
+			// a primitive with properties, like a logical type, or an enum or fixed
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid avro schema: unexpected type: %v", s)
+}
+
+func (in Inference) avroRecord(rules ccopy.Rules, s map[string]interface{}) error {
+	name, _ := s["name"].(string)
+	if name == "" {
+		return errors.New("invalid avro schema: record without name")
+	}
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+	fields, _ := s["fields"].([]interface{})
+	for _, f := range fields {
+		f, ok := f.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("invalid avro schema: record %s: unexpected field: %v", name, f)
+		}
+		fieldName, _ := f["name"].(string)
+		if fieldName == "" {
+			return fmt.Errorf("invalid avro schema: record %s: field without name", name)
+		}
+		path := name + "." + fieldName
+		if c := in.customizer(f, avroProps(f["type"])); c != "" {
+			rules[path] = c
+			continue
+		}
+		if err := in.avroType(rules, path, f["type"]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// avroProps returns the properties of the avro type s, looking through the unions of a type with null,
+// like ["null", {"type": "string", "logicalType": "uuid"}], the nullable fields; nil if it has none.
+func avroProps(s interface{}) map[string]interface{} {
+	switch s := s.(type) {
+	case map[string]interface{}:
+		return s
+	case []interface{}:
+		var props map[string]interface{}
+		for _, t := range s {
+			if t == "null" {
+				continue
+			}
+			if props != nil {
+				// the other unions have no properties of their own
+				return nil
+			}
+			if props, _ = t.(map[string]interface{}); props == nil {
+				return nil
+			}
+		}
+		return props
+	}
+	return nil
+}
+
+// avroItems adds the rules for the items of an array, or the values of a map, found at path.
+func (in Inference) avroItems(rules ccopy.Rules, path string, s interface{}) error {
+	// the items of a top level array or map are not found in any record
+	if props := avroProps(s); props != nil && path[0] != '[' && path[0] != '{' {
+		if c := in.customizer(props, nil); c != "" {
+			rules[path] = c
+			return nil
+		}
+	}
+	return in.avroType(rules, path, s)
+}
+
+// FromJSONSchema returns the rules for a JSON schema describing objects of the Go type named root.
+// Definitions referenced with "$ref" start their own paths, using the name of the definition.
+func (in Inference) FromJSONSchema(root string, data []byte) (ccopy.Rules, error) {
+	var s map[string]interface{}
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("invalid json schema: %w", err)
+	}
+	rules := ccopy.Rules{}
+	if err := in.jsonSchema(rules, root, s); err != nil {
+		return nil, err
+	}
+	for _, key := range []string{"definitions", "$defs"} {
+		defs, _ := s[key].(map[string]interface{})
+		for name, def := range defs {
+			def, ok := def.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("invalid json schema: definition %s: unexpected schema: %v", name, def)
+			}
+			if err := in.jsonSchema(rules, name, def); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return rules, nil
+}
+
+func (in Inference) jsonSchema(rules ccopy.Rules, path string, s map[string]interface{}) error {
+	props, _ := s["properties"].(map[string]interface{})
+	for name, p := range props {
+		p, ok := p.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("invalid json schema: property %s: unexpected schema: %v", name, p)
+		}
+		if err := in.jsonProperty(rules, path+"."+name, p); err != nil {
+			return err
+		}
+	}
+	if items, ok := s["items"].(map[string]interface{}); ok {
+		if err := in.jsonProperty(rules, path+"[]", items); err != nil {
+			return err
+		}
+	}
+	if values, ok := s["additionalProperties"].(map[string]interface{}); ok {
+		if err := in.jsonProperty(rules, path+"{}", values); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (in Inference) jsonProperty(rules ccopy.Rules, path string, s map[string]interface{}) error {
+	if c := in.customizer(s, nil); c != "" {
+		rules[path] = c
+		return nil
+	}
+	if _, ok := s["$ref"]; ok {
+		// the referenced definition has rules of its own
+		return nil
+	}
+	return in.jsonSchema(rules, path, s)
+}
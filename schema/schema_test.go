@@ -0,0 +1,85 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/gadumitrachioaiei/ccopy"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFromAvro(t *testing.T) {
+	s := `{
+		"type": "record", "name": "com.acme.User",
+		"fields": [
+			{"name": "email", "type": "string", "pii": true},
+			{"name": "id", "type": {"type": "string", "logicalType": "uuid"}},
+			{"name": "nick", "type": ["null", "string"], "ccopy": "mask"},
+			{"name": "ref", "type": ["null", {"type": "string", "logicalType": "uuid"}]},
+			{"name": "refs", "type": {"type": "array", "items": ["null", {"type": "string", "logicalType": "uuid"}]}},
+			{"name": "alias", "type": ["null", "string", {"type": "string", "logicalType": "uuid"}]},
+			{"name": "age", "type": "int"},
+			{"name": "phones", "type": {"type": "array", "items": {"type": "string", "logicalType": "uuid"}}},
+			{"name": "address", "type": {"type": "record", "name": "Address", "fields": [
+				{"name": "street", "type": "string", "pii": true}
+			]}},
+			{"name": "previous", "type": {"type": "array", "items": "Address"}}
+		]
+	}`
+	in := Inference{Properties: map[string]string{"pii": "redact"}, LogicalTypes: map[string]string{"uuid": "uuid"}}
+	rules, err := in.FromAvro([]byte(s))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := ccopy.Rules{
+		"User.email":     "redact",
+		"User.id":        "uuid",
+		"User.nick":      "mask",
+		"User.ref":       "uuid",
+		"User.refs[]":    "uuid",
+		"User.phones[]":  "uuid",
+		"Address.street": "redact",
+	}
+	if diff := cmp.Diff(rules, expected); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestFromJSONSchema(t *testing.T) {
+	s := `{
+		"type": "object",
+		"properties": {
+			"email": {"type": "string", "format": "email"},
+			"name": {"type": "string", "pii": true},
+			"address": {"type": "object", "properties": {"street": {"type": "string", "x-ccopy": "mask"}}},
+			"labels": {"type": "object", "additionalProperties": {"type": "string", "pii": true}},
+			"orders": {"type": "array", "items": {"$ref": "#/definitions/Order"}}
+		},
+		"definitions": {
+			"Order": {"type": "object", "properties": {"card": {"type": "string", "pii": true}}}
+		}
+	}`
+	in := Inference{Property: "x-ccopy", Properties: map[string]string{"pii": "redact"}, Formats: map[string]string{"email": "email"}}
+	rules, err := in.FromJSONSchema("User", []byte(s))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := ccopy.Rules{
+		"User.email":          "email",
+		"User.name":           "redact",
+		"User.address.street": "mask",
+		"User.labels{}":       "redact",
+		"Order.card":          "redact",
+	}
+	if diff := cmp.Diff(rules, expected); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestFromAvroInvalid(t *testing.T) {
+	in := Inference{}
+	for _, s := range []string{`{`, `{"type": "record"}`, `{"type": "record", "name": "A", "fields": [{}]}`, `1`} {
+		if _, err := in.FromAvro([]byte(s)); err == nil {
+			t.Fatalf("expected error for schema: %s", s)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+// Package scrub provides a ccopy customizer redacting personal data embedded in free text,
+// like notes, comments or chat messages: emails, phone numbers and person names.
+//
+// What is redacted is decided by detectors: regular expressions by default,
+// and any other implementation of Detector, like a dictionary of names or a named entity recognizer.
+package scrub
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Span is a part of a text found by a detector, from byte Start to byte End.
+type Span struct {
+	Start, End int
+	// Kind is what the part holds, like "email".
+	Kind string
+}
+
+// Detector finds the parts of texts to redact.
+type Detector interface {
+	Detect(text string) []Span
+}
+
+// DetectorFunc is a function used as a Detector.
+type DetectorFunc func(text string) []Span
+
+// Detect calls f.
+func (f DetectorFunc) Detect(text string) []Span {
+	return f(text)
+}
+
+// Regexp returns a detector of the matches of re, of the given kind.
+// If re has a group named "redact", only the text matched by that group is redacted.
+func Regexp(kind string, re *regexp.Regexp) Detector {
+	group := re.SubexpIndex("redact")
+	return DetectorFunc(func(text string) []Span {
+		var spans []Span
+		for _, m := range re.FindAllStringSubmatchIndex(text, -1) {
+			start, end := m[0], m[1]
+			if group > 0 {
+				start, end = m[2*group], m[2*group+1]
+			}
+			if start >= 0 && start < end {
+				spans = append(spans, Span{Start: start, End: end, Kind: kind})
+			}
+		}
+		return spans
+	})
+}
+
+var (
+	// Emails detects email addresses, also with non ASCII letters.
+	Emails = Regexp("email", regexp.MustCompile(`[\p{L}\p{N}._%+-]+@[\p{L}\p{N}.-]+\.\p{L}{2,}`))
+	// Phones detects phone numbers of at least 7 digits, possibly international and with separators,
+	// like "+44 20 7946 0958" or "(555) 123-4567".
+	Phones = Regexp("phone", regexp.MustCompile(`(?:\+|\b00)?\(?\d[\d ().-]{5,}\d\b`))
+	// Titles detects the capitalized names following a title, in several languages,
+	// like "Mr Smith", "Dr. Jane Doe", "Frau Müller", "Mme Dubois" or "Sra. García".
+	Titles = Regexp("name", regexp.MustCompile(
+		`\b(?:Mr|Mrs|Ms|Miss|Dr|Prof|Sir|Herr|Frau|M|Mme|Mlle|Sr|Sra|Srta|Sig|Sig\.ra|Dott|Pan|Pani)\.?\s+`+
+			`(?P<redact>\p{Lu}[\p{Ll}'-]+(?:\s+\p{Lu}[\p{Ll}'-]+)*)`))
+)
+
+// Names returns a detector of the given names, as whole words, like the names known to be in a dataset.
+func Names(names ...string) Detector {
+	var sorted, quoted []string
+	for _, n := range names {
+		if n != "" {
+			sorted = append(sorted, n)
+		}
+	}
+	if len(sorted) == 0 {
+		return DetectorFunc(func(string) []Span { return nil })
+	}
+	// longer names first, so that full names win over their parts
+	sort.Slice(sorted, func(i, j int) bool { return len(sorted[i]) > len(sorted[j]) })
+	for _, n := range sorted {
+		quoted = append(quoted, regexp.QuoteMeta(n))
+	}
+	re := regexp.MustCompile(strings.Join(quoted, "|"))
+	// the boundaries of the words are checked around the matches, not matched,
+	// so that the separator between two names does not hide the second one
+	return DetectorFunc(func(text string) []Span {
+		var spans []Span
+		for i := 0; i < len(text); {
+			m := re.FindStringIndex(text[i:])
+			if m == nil {
+				break
+			}
+			start := i + m[0]
+			end := wordAt(text, start, sorted)
+			if end < 0 {
+				_, size := utf8.DecodeRuneInString(text[start:])
+				i = start + size
+				continue
+			}
+			spans = append(spans, Span{Start: start, End: end, Kind: "name"})
+			i = end
+		}
+		return spans
+	})
+}
+
+// wordAt returns the end of the longest of the names found as a whole word at byte start of text, -1 if there is none.
+func wordAt(text string, start int, names []string) int {
+	if r, _ := utf8.DecodeLastRuneInString(text[:start]); start > 0 && isWordRune(r) {
+		return -1
+	}
+	for _, n := range names {
+		end := start + len(n)
+		if !strings.HasPrefix(text[start:], n) {
+			continue
+		}
+		if r, _ := utf8.DecodeRuneInString(text[end:]); end < len(text) && isWordRune(r) {
+			continue
+		}
+		return end
+	}
+	return -1
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsNumber(r)
+}
+
+// Scrubber redacts the parts of texts found by its detectors.
+type Scrubber struct {
+	detectors []Detector
+	// Replace returns the replacement of a part of text of the given kind, "[EMAIL]" for emails by default.
+	Replace func(kind, text string) string
+}
+
+// New returns a scrubber using the given detectors, or Emails, Phones and Titles if none is given.
+func New(detectors ...Detector) *Scrubber {
+	if len(detectors) == 0 {
+		detectors = []Detector{Emails, Phones, Titles}
+	}
+	return &Scrubber{detectors: detectors}
+}
+
+// Scrub is a customizer redacting the parts of text found by the detectors.
+// When parts overlap, the one starting first is redacted, and the longest one if they start together.
+func (s *Scrubber) Scrub(text string) string {
+	var spans []Span
+	for _, d := range s.detectors {
+		spans = append(spans, d.Detect(text)...)
+	}
+	if len(spans) == 0 {
+		return text
+	}
+	sort.Slice(spans, func(i, j int) bool {
+		if spans[i].Start != spans[j].Start {
+			return spans[i].Start < spans[j].Start
+		}
+		return spans[i].End > spans[j].End
+	})
+	var b strings.Builder
+	end := 0
+	for _, sp := range spans {
+		if sp.Start < end {
+			continue
+		}
+		b.WriteString(text[end:sp.Start])
+		b.WriteString(s.replace(sp.Kind, text[sp.Start:sp.End]))
+		end = sp.End
+	}
+	b.WriteString(text[end:])
+	return b.String()
+}
+
+func (s *Scrubber) replace(kind, text string) string {
+	if s.Replace != nil {
+		return s.Replace(kind, text)
+	}
+	return "[" + strings.ToUpper(kind) + "]"
+}
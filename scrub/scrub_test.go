@@ -0,0 +1,66 @@
+package scrub
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gadumitrachioaiei/ccopy"
+)
+
+func TestScrub(t *testing.T) {
+	s := New()
+	tests := []struct {
+		text, expected string
+	}{
+		{"Call Dr. Jane Doe at +44 20 7946 0958 or jane.doe@example.co.uk", "Call Dr. [NAME] at [PHONE] or [EMAIL]"},
+		{"Bitte Frau Müller unter 030 1234567 anrufen", "Bitte Frau [NAME] unter [PHONE] anrufen"},
+		{"Mme Dubois a écrit à zoé@exemple.fr", "Mme [NAME] a écrit à [EMAIL]"},
+		{"La Sra. García llamó al (555) 123-4567", "La Sra. [NAME] llamó al [PHONE]"},
+		{"order 1234 shipped in 3 days", "order 1234 shipped in 3 days"},
+	}
+	for _, test := range tests {
+		if got := s.Scrub(test.text); got != test.expected {
+			t.Fatalf("got %q, expected %q", got, test.expected)
+		}
+	}
+}
+
+func TestNames(t *testing.T) {
+	s := New(Names("Ann", "Ann Lee", "Zoë"), Emails)
+	s.Replace = func(kind, text string) string { return strings.Repeat("*", len([]rune(text))) }
+	got := s.Scrub("Ann Lee met Zoë and Annabel, mail ann@lee.com")
+	if expected := "******* met *** and Annabel, mail ***********"; got != expected {
+		t.Fatalf("got %q, expected %q", got, expected)
+	}
+
+	s = New(Names("Ann", "Ann Lee", "Bob"))
+	for text, expected := range map[string]string{
+		"Ann Bob met":   "[NAME] [NAME] met",
+		"Ann,Bob":       "[NAME],[NAME]",
+		"Ann Leer, Bob": "[NAME] Leer, [NAME]",
+		"Bobby Ann":     "Bobby [NAME]",
+	} {
+		if got := s.Scrub(text); got != expected {
+			t.Errorf("got %q, expected %q", got, expected)
+		}
+	}
+}
+
+func TestCopy(t *testing.T) {
+	type Ticket struct {
+		Subject string `ccopy:"scrub"`
+		Notes   []string
+	}
+	c, err := ccopy.NewCopier(ccopy.Config{"scrub": New().Scrub}, ccopy.Options{Rules: ccopy.Rules{"Ticket.Notes[]": "scrub"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := c.Copy(Ticket{Subject: "refund for bob@example.com", Notes: []string{"asked Mr Brown", "ok"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := Ticket{Subject: "refund for [EMAIL]", Notes: []string{"asked Mr [NAME]", "ok"}}
+	if got := res.(Ticket); got.Subject != expected.Subject || strings.Join(got.Notes, "|") != strings.Join(expected.Notes, "|") {
+		t.Fatalf("got %v, expected %v", got, expected)
+	}
+}
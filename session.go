@@ -0,0 +1,214 @@
+package ccopy
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+)
+
+// Session holds state shared by the copies of related objects, like mappings of identifiers,
+// so that the same source value is anonymized to the same value across all the copies made in the session.
+// It is safe for concurrent use.
+type Session struct {
+	// the random generator is created on first use, as sessions are created for every copy
+	seed     int64
+	seeded   bool
+	randOnce sync.Once
+	rand     *rand.Rand
+
+	mu     sync.Mutex
+	tables map[string]map[interface{}]interface{}
+	// claimed maps the outputs of the customizers of unique fields to their inputs, per customizer
+	claimed map[string]map[interface{}]interface{}
+
+	// store is the store of the tables of stored sessions, which tables and claimed cache
+	store SessionStore
+}
+
+// SessionStore is a store of the mappings of sessions, shared by the sessions of several processes,
+// like horizontally scaled anonymization workers, so they map the same source values to the same values.
+// The keys and values are encodings of the values of the sessions, in tables named by the sessions.
+// Mappings never change once stored, so a store only needs to compare them with the absent mapping.
+// The stores must be safe for concurrent use, and apply their own timeouts.
+type SessionStore interface {
+	// GetMapping returns the value mapped to key in table, if any.
+	GetMapping(ctx context.Context, table string, key []byte) (value []byte, ok bool, err error)
+	// PutMapping maps key to value in table, unless key is already mapped,
+	// and returns the value mapped to key after the call, whichever it is.
+	PutMapping(ctx context.Context, table string, key, value []byte) (actual []byte, err error)
+}
+
+// NewSession returns an empty session, with a randomly seeded random generator.
+func NewSession() *Session {
+	return &Session{}
+}
+
+// NewStoredSession returns a session whose mappings, of Map, of the fields tagged with "idmap=name"
+// and of the fields tagged with "name,unique", are stored in store, and cached by the session.
+// The mapped values must be of the types registered with RegisterSessionType, or of basic types.
+// The copies fail with the errors of the store, as Map panics with them.
+// Len and State only know about the mappings cached by the session.
+func NewStoredSession(store SessionStore) *Session {
+	return &Session{store: store}
+}
+
+// NewSeededSession returns an empty session, whose random generator is seeded with seed.
+// Randomized customizers using the generator of the session then produce the same outputs for the same inputs,
+// as long as they are called in the same order.
+func NewSeededSession(seed int64) *Session {
+	return &Session{seed: seed, seeded: true}
+}
+
+// Rand returns the random generator of the session, safe for concurrent use except for its Read method.
+// It is meant to be passed to randomized customizers, like uuids.RandomFrom.
+func (s *Session) Rand() *rand.Rand {
+	s.randOnce.Do(func() {
+		if !s.seeded {
+			s.seed = randomSeed()
+		}
+		s.rand = NewRand(s.seed)
+	})
+	return s.rand
+}
+
+// Map returns the value mapped to v in the named table.
+// The first time v is seen in the table, the mapped value is created by calling fn with v.
+// The values v must be comparable.
+// If fn is called concurrently for the same value, only one of the results is kept and returned to all callers.
+func (s *Session) Map(table string, v interface{}, fn func(v interface{}) interface{}) interface{} {
+	m, err := s.mapValue(table, v, func(v interface{}) (interface{}, error) { return fn(v), nil })
+	if err != nil {
+		panic(fmt.Sprintf("ccopy: session map: %v", err))
+	}
+	return m
+}
+
+// mapValue is like Map, but nothing is mapped when fn fails.
+func (s *Session) mapValue(table string, v interface{}, fn func(v interface{}) (interface{}, error)) (interface{}, error) {
+	s.mu.Lock()
+	m, ok := s.tables[table][v]
+	s.mu.Unlock()
+	if ok {
+		return m, nil
+	}
+	if s.store != nil {
+		return s.mapStored(table, v, fn)
+	}
+	// fn is called without holding the lock, as it may use the session itself
+	m, err := fn(v)
+	if err != nil {
+		return nil, err
+	}
+	return s.cache(&s.tables, table, v, m), nil
+}
+
+// cache maps v to m in the named table of tables, unless v is already mapped, and returns the value mapped to v.
+func (s *Session) cache(tables *map[string]map[interface{}]interface{}, table string, v, m interface{}) interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if *tables == nil {
+		*tables = make(map[string]map[interface{}]interface{})
+	}
+	t := (*tables)[table]
+	if t == nil {
+		t = make(map[interface{}]interface{})
+		(*tables)[table] = t
+	}
+	if existing, ok := t[v]; ok {
+		return existing
+	}
+	t[v] = m
+	return m
+}
+
+// mapStored is mapValue for stored sessions.
+func (s *Session) mapStored(table string, v interface{}, fn func(v interface{}) (interface{}, error)) (interface{}, error) {
+	ctx := context.Background()
+	key, err := encodeStored(v)
+	if err != nil {
+		return nil, err
+	}
+	stored, ok, err := s.store.GetMapping(ctx, idmapTable+table, key)
+	if err != nil {
+		return nil, fmt.Errorf("session store: %w", err)
+	}
+	if !ok {
+		m, err := fn(v)
+		if err != nil {
+			return nil, err
+		}
+		value, err := encodeStored(m)
+		if err != nil {
+			return nil, err
+		}
+		if stored, err = s.store.PutMapping(ctx, idmapTable+table, key, value); err != nil {
+			return nil, fmt.Errorf("session store: %w", err)
+		}
+	}
+	m, err := decodeStored(stored)
+	if err != nil {
+		return nil, err
+	}
+	return s.cache(&s.tables, table, v, m), nil
+}
+
+// The prefixes of the names of the tables of a session in its store.
+const (
+	idmapTable  = "idmap:"
+	uniqueTable = "unique:"
+)
+
+// encodeStored encodes v for a store: the name of its type, a zero byte and its JSON encoding.
+func encodeStored(v interface{}) ([]byte, error) {
+	t, b, err := encodeSessionValue(v)
+	if err != nil {
+		return nil, err
+	}
+	return append(append([]byte(t), 0), b...), nil
+}
+
+func decodeStored(b []byte) (interface{}, error) {
+	i := bytes.IndexByte(b, 0)
+	if i < 0 {
+		return nil, fmt.Errorf("session store: malformed value: %q", b)
+	}
+	return decodeSessionValue(string(b[:i]), b[i+1:])
+}
+
+// Len returns the number of values mapped in the named table.
+func (s *Session) Len(table string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.tables[table])
+}
+
+// claim claims output for input in the named table, reporting false if it is already claimed for another input.
+func (s *Session) claim(table string, output, input interface{}) (bool, error) {
+	if s.store == nil {
+		return s.cache(&s.claimed, table, output, input) == input, nil
+	}
+	s.mu.Lock()
+	claimer, ok := s.claimed[table][output]
+	s.mu.Unlock()
+	if ok {
+		return claimer == input, nil
+	}
+	key, err := encodeStored(output)
+	if err != nil {
+		return false, err
+	}
+	value, err := encodeStored(input)
+	if err != nil {
+		return false, err
+	}
+	stored, err := s.store.PutMapping(context.Background(), uniqueTable+table, key, value)
+	if err != nil {
+		return false, fmt.Errorf("session store: %w", err)
+	}
+	if claimer, err = decodeStored(stored); err != nil {
+		return false, err
+	}
+	return s.cache(&s.claimed, table, output, claimer) == input, nil
+}
@@ -0,0 +1,218 @@
+package ccopy
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSessionMap(t *testing.T) {
+	s := NewSession()
+	n := 0
+	next := func(interface{}) interface{} {
+		n++
+		return n
+	}
+	if v := s.Map("user", "a", next); v != 1 {
+		t.Fatalf("got: %v, expected 1", v)
+	}
+	if v := s.Map("user", "b", next); v != 2 {
+		t.Fatalf("got: %v, expected 2", v)
+	}
+	if v := s.Map("user", "a", next); v != 1 {
+		t.Fatalf("got: %v, expected 1", v)
+	}
+	if v := s.Map("order", "a", next); v != 3 {
+		t.Fatalf("got: %v, expected 3", v)
+	}
+	if l := s.Len("user"); l != 2 {
+		t.Fatalf("got len: %d, expected 2", l)
+	}
+}
+
+func TestCopySession(t *testing.T) {
+	type Order struct {
+		ID     int `ccopy:"idmap=order"`
+		UserID int `ccopy:"idmap=user"`
+	}
+	type User struct {
+		ID int `ccopy:"idmap=user"`
+	}
+	n := 100
+	next := func(int) int {
+		n++
+		return n
+	}
+	c := Config{"user": next, "order": next}
+	s := NewSession()
+	ui, err := c.CopySession(s, []User{{ID: 1}, {ID: 2}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	oi, err := c.CopySession(s, []Order{{ID: 1, UserID: 2}, {ID: 2, UserID: 2}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	users, orders := ui.([]User), oi.([]Order)
+	if users[0].ID != 101 || users[1].ID != 102 {
+		t.Fatalf("got users: %v, expected ids 101 and 102", users)
+	}
+	if orders[0].UserID != users[1].ID || orders[1].UserID != users[1].ID {
+		t.Fatalf("got orders: %v, expected user id: %d", orders, users[1].ID)
+	}
+	if orders[0].ID != 103 || orders[1].ID != 104 {
+		t.Fatalf("got orders: %v, expected ids 103 and 104", orders)
+	}
+}
+
+func TestCopySessionMissingCustomizer(t *testing.T) {
+	type T struct {
+		ID int `ccopy:"idmap=user"`
+	}
+	if _, err := (Config{}).Copy(T{ID: 1}); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestNewSeededSession(t *testing.T) {
+	a, b := NewSeededSession(7), NewSeededSession(7)
+	for i := 0; i < 10; i++ {
+		if x, y := a.Rand().Int63(), b.Rand().Int63(); x != y {
+			t.Fatalf("got: %d and %d, expected equal values", x, y)
+		}
+	}
+}
+
+type sessionID struct {
+	Source string
+	N      int
+}
+
+func TestSessionState(t *testing.T) {
+	RegisterSessionType(sessionID{})
+	type T struct {
+		ID    string `ccopy:"idmap=id"`
+		Email string `ccopy:"email,unique"`
+	}
+	n := 0
+	config := Config{
+		"id":    func(string) string { n++; return fmt.Sprintf("id%d", n) },
+		"email": func(string) string { return "user@example.com" },
+	}
+	s := NewSeededSession(7)
+	if _, err := config.CopySession(s, []T{{ID: "a", Email: "a@example.com"}, {ID: "b", Email: "b@example.com"}}); err != nil {
+		t.Fatal(err)
+	}
+	s.Map("struct", sessionID{Source: "x", N: 1}, func(interface{}) interface{} { return [16]byte{1} })
+	var b bytes.Buffer
+	if err := s.WriteJSON(&b); err != nil {
+		t.Fatal(err)
+	}
+	restored, err := ReadSession(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.Rand().Int63() != NewSeededSession(7).Rand().Int63() {
+		t.Fatal("expected the seed of the session to be restored")
+	}
+	if v := restored.Map("struct", sessionID{Source: "x", N: 1}, nil); v != [16]byte{1} {
+		t.Fatalf("expected the mapped struct, got: %v", v)
+	}
+	res, err := config.CopySession(restored, []T{{ID: "b", Email: "c@example.com"}, {ID: "c"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []T{{ID: "id2", Email: "user3@example.com"}, {ID: "id3", Email: "user4@example.com"}}
+	if diff := cmp.Diff(expected, res); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestSessionStateInvalid(t *testing.T) {
+	type unregistered struct{ A int }
+	s := NewSession()
+	s.Map("t", unregistered{}, func(interface{}) interface{} { return 1 })
+	if _, err := s.State(); err == nil {
+		t.Fatal("expected an error for an unregistered type")
+	}
+	if _, err := RestoreSession(&SessionState{Version: SessionVersion + 1}); err == nil {
+		t.Fatal("expected an error for an unsupported version")
+	}
+	if _, err := RestoreSession(&SessionState{Version: 1, Tables: []TableState{{Name: "t", Entries: []EntryState{{KeyType: "unknown", Key: []byte("1")}}}}}); err == nil {
+		t.Fatal("expected an error for an unknown type")
+	}
+}
+
+// memoryStore is a SessionStore in memory.
+type memoryStore struct {
+	mu sync.Mutex
+	m  map[string][]byte
+}
+
+func (s *memoryStore) GetMapping(ctx context.Context, table string, key []byte) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, ok := s.m[table+"/"+string(key)]
+	return v, ok, nil
+}
+
+func (s *memoryStore) PutMapping(ctx context.Context, table string, key, value []byte) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.m == nil {
+		s.m = make(map[string][]byte)
+	}
+	if v, ok := s.m[table+"/"+string(key)]; ok {
+		return v, nil
+	}
+	s.m[table+"/"+string(key)] = value
+	return value, nil
+}
+
+type failingStore struct{}
+
+func (failingStore) GetMapping(context.Context, string, []byte) ([]byte, bool, error) {
+	return nil, false, errors.New("unavailable")
+}
+
+func (failingStore) PutMapping(context.Context, string, []byte, []byte) ([]byte, error) {
+	return nil, errors.New("unavailable")
+}
+
+func TestStoredSession(t *testing.T) {
+	type T struct {
+		ID    string `ccopy:"idmap=id"`
+		Email string `ccopy:"email,unique"`
+	}
+	// the workers have their own customizers, which agree only through the store
+	worker := func(prefix string) Config {
+		n := 0
+		return Config{
+			"id":    func(string) string { n++; return fmt.Sprintf("%s%d", prefix, n) },
+			"email": func(string) string { return "user@example.com" },
+		}
+	}
+	store := &memoryStore{}
+	res1, err := worker("a").CopySession(NewStoredSession(store), []T{{ID: "x", Email: "x@example.com"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	res2, err := worker("b").CopySession(NewStoredSession(store), []T{{ID: "y", Email: "y@example.com"}, {ID: "x", Email: "x@example.com"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]T{{ID: "a1", Email: "user@example.com"}}, res1); diff != "" {
+		t.Fatal(diff)
+	}
+	if diff := cmp.Diff([]T{{ID: "b1", Email: "user2@example.com"}, {ID: "a1", Email: "user@example.com"}}, res2); diff != "" {
+		t.Fatal(diff)
+	}
+	if _, err := worker("c").CopySession(NewStoredSession(failingStore{}), T{ID: "x"}); err == nil {
+		t.Fatal("expected the error of the store")
+	}
+}
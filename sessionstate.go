@@ -0,0 +1,227 @@
+package ccopy
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// SessionVersion is the version of the format of the SessionState written by this version of the package.
+const SessionVersion = 1
+
+// SessionState is the state of a Session, to be saved and restored, so that long running or distributed
+// anonymization jobs keep consistent mappings. It can be encoded as JSON, and stored by the sessionstore package.
+// The values of the tables are encoded as JSON, along with the names of their types,
+// which must be registered with RegisterSessionType, except for the basic types and [16]byte.
+type SessionState struct {
+	Version int
+	// Seed is the seed of the random generator of seeded sessions, which restart from it.
+	Seed   int64 `json:",omitempty"`
+	Seeded bool  `json:",omitempty"`
+	// Tables are the tables of the pseudonyms of Session.Map, and of the fields tagged with "idmap=name".
+	Tables []TableState `json:",omitempty"`
+	// Claimed are the tables of the values claimed by the fields tagged with "name,unique".
+	Claimed []TableState `json:",omitempty"`
+}
+
+// TableState is a table of a SessionState.
+type TableState struct {
+	Name    string
+	Entries []EntryState
+}
+
+// EntryState is an entry of a table of a SessionState, mapping Key to Value.
+type EntryState struct {
+	KeyType   string
+	Key       json.RawMessage
+	ValueType string
+	Value     json.RawMessage
+}
+
+// sessionTypes holds the types of the values of session tables that can be restored, by name.
+var sessionTypes = struct {
+	sync.RWMutex
+	m map[string]reflect.Type
+}{m: make(map[string]reflect.Type)}
+
+func init() {
+	for _, v := range []interface{}{
+		false, "", int(0), int8(0), int16(0), int32(0), int64(0), uint(0), uint8(0), uint16(0), uint32(0), uint64(0),
+		float32(0), float64(0), [16]byte{},
+	} {
+		RegisterSessionType(v)
+	}
+}
+
+// RegisterSessionType registers the type of v, so the values of that type can be restored in sessions.
+// The values must be encoded to JSON and decoded back to equal values.
+// It panics if v is nil, or if another type of the same name is registered.
+func RegisterSessionType(v interface{}) {
+	t := reflect.TypeOf(v)
+	if t == nil {
+		panic("ccopy: register session type nil")
+	}
+	sessionTypes.Lock()
+	defer sessionTypes.Unlock()
+	if other, ok := sessionTypes.m[t.String()]; ok && other != t {
+		panic(fmt.Sprintf("ccopy: register session type %s: another type has the same name", t))
+	}
+	sessionTypes.m[t.String()] = t
+}
+
+func sessionType(name string) (reflect.Type, bool) {
+	sessionTypes.RLock()
+	defer sessionTypes.RUnlock()
+	t, ok := sessionTypes.m[name]
+	return t, ok
+}
+
+// State returns the state of the session.
+// It returns an error if some value of the tables is of a type that is not registered with RegisterSessionType.
+func (s *Session) State() (*SessionState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st := &SessionState{Version: SessionVersion, Seed: s.seed, Seeded: s.seeded}
+	if !s.seeded {
+		st.Seed = 0
+	}
+	var err error
+	if st.Tables, err = tableStates(s.tables); err != nil {
+		return nil, err
+	}
+	if st.Claimed, err = tableStates(s.claimed); err != nil {
+		return nil, err
+	}
+	return st, nil
+}
+
+func tableStates(tables map[string]map[interface{}]interface{}) ([]TableState, error) {
+	var states []TableState
+	for name, t := range tables {
+		ts := TableState{Name: name}
+		for k, v := range t {
+			e, err := entryState(k, v)
+			if err != nil {
+				return nil, fmt.Errorf("session table %s: %w", name, err)
+			}
+			ts.Entries = append(ts.Entries, e)
+		}
+		sort.Slice(ts.Entries, func(i, j int) bool {
+			a, b := ts.Entries[i], ts.Entries[j]
+			if a.KeyType != b.KeyType {
+				return a.KeyType < b.KeyType
+			}
+			return string(a.Key) < string(b.Key)
+		})
+		states = append(states, ts)
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].Name < states[j].Name })
+	return states, nil
+}
+
+func entryState(k, v interface{}) (EntryState, error) {
+	var e EntryState
+	var err error
+	if e.KeyType, e.Key, err = encodeSessionValue(k); err != nil {
+		return e, err
+	}
+	e.ValueType, e.Value, err = encodeSessionValue(v)
+	return e, err
+}
+
+func encodeSessionValue(v interface{}) (string, json.RawMessage, error) {
+	if v == nil {
+		return "", json.RawMessage("null"), nil
+	}
+	t := reflect.TypeOf(v)
+	if registered, ok := sessionType(t.String()); !ok || registered != t {
+		return "", nil, fmt.Errorf("type %s is not registered with RegisterSessionType", t)
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", nil, fmt.Errorf("encode value of type %s: %w", t, err)
+	}
+	return t.String(), b, nil
+}
+
+func decodeSessionValue(typeName string, b json.RawMessage) (interface{}, error) {
+	if typeName == "" {
+		return nil, nil
+	}
+	t, ok := sessionType(typeName)
+	if !ok {
+		return nil, fmt.Errorf("type %s is not registered with RegisterSessionType", typeName)
+	}
+	p := reflect.New(t)
+	if err := json.Unmarshal(b, p.Interface()); err != nil {
+		return nil, fmt.Errorf("decode value of type %s: %w", t, err)
+	}
+	return p.Elem().Interface(), nil
+}
+
+// RestoreSession returns a session with the state st.
+// It returns an error if the version of st is not supported, or if some value cannot be decoded.
+func RestoreSession(st *SessionState) (*Session, error) {
+	if st.Version < 1 || st.Version > SessionVersion {
+		return nil, fmt.Errorf("unsupported session version: %d", st.Version)
+	}
+	s := &Session{seed: st.Seed, seeded: st.Seeded}
+	var err error
+	if s.tables, err = restoreTables(st.Tables); err != nil {
+		return nil, err
+	}
+	if s.claimed, err = restoreTables(st.Claimed); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func restoreTables(states []TableState) (map[string]map[interface{}]interface{}, error) {
+	if len(states) == 0 {
+		return nil, nil
+	}
+	tables := make(map[string]map[interface{}]interface{}, len(states))
+	for _, ts := range states {
+		t := tables[ts.Name]
+		if t == nil {
+			t = make(map[interface{}]interface{}, len(ts.Entries))
+			tables[ts.Name] = t
+		}
+		for _, e := range ts.Entries {
+			k, err := decodeSessionValue(e.KeyType, e.Key)
+			if err != nil {
+				return nil, fmt.Errorf("session table %s: %w", ts.Name, err)
+			}
+			if k != nil && !reflect.TypeOf(k).Comparable() {
+				return nil, fmt.Errorf("session table %s: keys of type %s are not comparable", ts.Name, e.KeyType)
+			}
+			v, err := decodeSessionValue(e.ValueType, e.Value)
+			if err != nil {
+				return nil, fmt.Errorf("session table %s: %w", ts.Name, err)
+			}
+			t[k] = v
+		}
+	}
+	return tables, nil
+}
+
+// WriteJSON writes the state of the session as JSON.
+func (s *Session) WriteJSON(w io.Writer) error {
+	st, err := s.State()
+	if err != nil {
+		return err
+	}
+	return json.NewEncoder(w).Encode(st)
+}
+
+// ReadSession returns the session whose state is read as JSON from r, as written by WriteJSON.
+func ReadSession(r io.Reader) (*Session, error) {
+	var st SessionState
+	if err := json.NewDecoder(r).Decode(&st); err != nil {
+		return nil, fmt.Errorf("read session: %w", err)
+	}
+	return RestoreSession(&st)
+}
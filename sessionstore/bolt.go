@@ -0,0 +1,59 @@
+package sessionstore
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/gadumitrachioaiei/ccopy"
+	bolt "go.etcd.io/bbolt"
+)
+
+// boltBucket is the bucket of the sessions in bolt databases, holding their states by name, as JSON.
+var boltBucket = []byte("ccopy_sessions")
+
+// ErrNotFound is returned when no session is saved under a name in a bolt database.
+var ErrNotFound = errors.New("sessionstore: session not found")
+
+// SaveBolt saves the state of s in db under name, replacing the state saved under the same name, if any.
+func SaveBolt(db *bolt.DB, name string, s *ccopy.Session) error {
+	st, err := s.State()
+	if err != nil {
+		return err
+	}
+	b, err := json.Marshal(st)
+	if err != nil {
+		return fmt.Errorf("sessionstore: %w", err)
+	}
+	return db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists(boltBucket)
+		if err != nil {
+			return fmt.Errorf("sessionstore: %w", err)
+		}
+		return bucket.Put([]byte(name), b)
+	})
+}
+
+// LoadBolt restores the session saved in db under name by SaveBolt.
+// It returns ErrNotFound if no session is saved under name.
+func LoadBolt(db *bolt.DB, name string) (*ccopy.Session, error) {
+	var st ccopy.SessionState
+	err := db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(boltBucket)
+		if bucket == nil {
+			return ErrNotFound
+		}
+		b := bucket.Get([]byte(name))
+		if b == nil {
+			return ErrNotFound
+		}
+		if err := json.Unmarshal(b, &st); err != nil {
+			return fmt.Errorf("sessionstore: load session %s: %w", name, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ccopy.RestoreSession(&st)
+}
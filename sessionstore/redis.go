@@ -0,0 +1,41 @@
+package sessionstore
+
+import (
+	"context"
+	"errors"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisStore is a ccopy.SessionStore in Redis, for the sessions of horizontally scaled workers,
+// created with ccopy.NewStoredSession. Every table is a hash, whose key is the table name with the prefix.
+type RedisStore struct {
+	Client redis.UniversalClient
+	// Prefix is prefixed to the names of the tables, like the name of the anonymization job.
+	Prefix string
+}
+
+// GetMapping returns the value mapped to key in table, if any.
+func (s *RedisStore) GetMapping(ctx context.Context, table string, key []byte) ([]byte, bool, error) {
+	v, err := s.Client.HGet(ctx, s.Prefix+table, string(key)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return v, true, nil
+}
+
+// PutMapping maps key to value in table, unless key is already mapped, and returns the value mapped to key.
+// The mappings never change once set, so the value mapped by another worker is read after failing to set it.
+func (s *RedisStore) PutMapping(ctx context.Context, table string, key, value []byte) ([]byte, error) {
+	set, err := s.Client.HSetNX(ctx, s.Prefix+table, string(key), value).Result()
+	if err != nil {
+		return nil, err
+	}
+	if set {
+		return value, nil
+	}
+	return s.Client.HGet(ctx, s.Prefix+table, string(key)).Bytes()
+}
@@ -0,0 +1,123 @@
+package sessionstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gadumitrachioaiei/ccopy"
+	"github.com/redis/go-redis/v9"
+	bolt "go.etcd.io/bbolt"
+	_ "modernc.org/sqlite"
+)
+
+type user struct {
+	ID    string `ccopy:"idmap=id"`
+	Email string `ccopy:"email,unique"`
+}
+
+// session returns a session after copying users, and the config of the copy.
+func session(t *testing.T) (*ccopy.Session, ccopy.Config) {
+	n := 0
+	config := ccopy.Config{
+		"id":    func(string) string { n++; return fmt.Sprintf("id%d", n) },
+		"email": func(string) string { return "user@example.com" },
+	}
+	s := ccopy.NewSession()
+	if _, err := config.CopySession(s, []user{{ID: "a", Email: "a@example.com"}, {ID: "b", Email: "b@example.com"}}); err != nil {
+		t.Fatal(err)
+	}
+	return s, config
+}
+
+// check checks that restored has the mappings of the session.
+func check(t *testing.T, restored *ccopy.Session, config ccopy.Config) {
+	if restored.Len("id") != 2 {
+		t.Fatalf("expected 2 ids, got: %d", restored.Len("id"))
+	}
+	res, err := config.CopySession(restored, []user{{ID: "b", Email: "c@example.com"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u := res.([]user)[0]; u.ID != "id2" || u.Email != "user3@example.com" {
+		t.Fatalf("expected the mappings of the saved session, got: %+v", u)
+	}
+}
+
+func TestSQL(t *testing.T) {
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "sessions.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	ctx := context.Background()
+	s, config := session(t)
+	// saving twice replaces the first state
+	for i := 0; i < 2; i++ {
+		if err := SaveSQL(ctx, db, "job", s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	restored, err := LoadSQL(ctx, db, "job")
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(t, restored, config)
+	if _, err := LoadSQL(ctx, db, "other"); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected sql.ErrNoRows, got: %v", err)
+	}
+}
+
+func TestBolt(t *testing.T) {
+	db, err := bolt.Open(filepath.Join(t.TempDir(), "sessions.bolt"), 0o600, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if _, err := LoadBolt(db, "job"); err != ErrNotFound {
+		t.Fatalf("expected ErrNotFound, got: %v", err)
+	}
+	s, config := session(t)
+	if err := SaveBolt(db, "job", s); err != nil {
+		t.Fatal(err)
+	}
+	restored, err := LoadBolt(db, "job")
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(t, restored, config)
+}
+
+func TestRedisStore(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+	store := &RedisStore{Client: client, Prefix: "job:"}
+	// the workers agree on the first mappings stored
+	first := func(prefix string) ccopy.Config {
+		n := 0
+		return ccopy.Config{
+			"id":    func(string) string { n++; return fmt.Sprintf("%s%d", prefix, n) },
+			"email": func(string) string { return "user@example.com" },
+		}
+	}
+	for _, worker := range []string{"a", "b"} {
+		res, err := first(worker).CopySession(ccopy.NewStoredSession(store), []user{{ID: "x", Email: "x@example.com"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if u := res.([]user)[0]; u.ID != "a1" || u.Email != "user@example.com" {
+			t.Fatalf("worker %s: expected the mappings of worker a, got: %+v", worker, u)
+		}
+	}
+	if _, ok, err := store.GetMapping(context.Background(), "other", []byte("x")); ok || err != nil {
+		t.Fatalf("expected no mapping, got: %v, %v", ok, err)
+	}
+	if !server.Exists("job:idmap:id") {
+		t.Fatalf("expected the hash of the table, got keys: %v", server.Keys())
+	}
+}
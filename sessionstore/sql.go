@@ -0,0 +1,111 @@
+// Package sessionstore saves the states of ccopy Sessions in databases, and restores them, or stores their mappings in Redis,
+// so that anonymization jobs running in several processes, or resumed later, share the same mappings.
+package sessionstore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/gadumitrachioaiei/ccopy"
+)
+
+// sqlSchema creates the tables of the sessions in SQL databases.
+const sqlSchema = `CREATE TABLE IF NOT EXISTS ccopy_sessions (
+	name TEXT PRIMARY KEY,
+	version INTEGER NOT NULL,
+	seed INTEGER NOT NULL,
+	seeded INTEGER NOT NULL
+);
+CREATE TABLE IF NOT EXISTS ccopy_session_entries (
+	session TEXT NOT NULL,
+	claimed INTEGER NOT NULL,
+	tbl TEXT NOT NULL,
+	key_type TEXT NOT NULL,
+	key TEXT NOT NULL,
+	value_type TEXT NOT NULL,
+	value TEXT NOT NULL
+)`
+
+// SaveSQL saves the state of s in db under name, replacing the state saved under the same name, if any.
+// The tables ccopy_sessions and ccopy_session_entries are created if needed, with one row per mapped value,
+// so the mappings can be queried. It is meant for SQLite, and other databases using ? placeholders.
+func SaveSQL(ctx context.Context, db *sql.DB, name string, s *ccopy.Session) error {
+	st, err := s.State()
+	if err != nil {
+		return err
+	}
+	if _, err := db.ExecContext(ctx, sqlSchema); err != nil {
+		return fmt.Errorf("sessionstore: create tables: %w", err)
+	}
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("sessionstore: %w", err)
+	}
+	defer tx.Rollback()
+	if _, err := tx.ExecContext(ctx, `DELETE FROM ccopy_session_entries WHERE session = ?`, name); err != nil {
+		return fmt.Errorf("sessionstore: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM ccopy_sessions WHERE name = ?`, name); err != nil {
+		return fmt.Errorf("sessionstore: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO ccopy_sessions (name, version, seed, seeded) VALUES (?, ?, ?, ?)`,
+		name, st.Version, st.Seed, st.Seeded); err != nil {
+		return fmt.Errorf("sessionstore: %w", err)
+	}
+	insert, err := tx.PrepareContext(ctx, `INSERT INTO ccopy_session_entries (session, claimed, tbl, key_type, key, value_type, value) VALUES (?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return fmt.Errorf("sessionstore: %w", err)
+	}
+	defer insert.Close()
+	for claimed, tables := range [][]ccopy.TableState{st.Tables, st.Claimed} {
+		for _, t := range tables {
+			for _, e := range t.Entries {
+				if _, err := insert.ExecContext(ctx, name, claimed, t.Name, e.KeyType, string(e.Key), e.ValueType, string(e.Value)); err != nil {
+					return fmt.Errorf("sessionstore: %w", err)
+				}
+			}
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("sessionstore: %w", err)
+	}
+	return nil
+}
+
+// LoadSQL restores the session saved in db under name by SaveSQL.
+// It returns an error wrapping sql.ErrNoRows if no session is saved under name.
+func LoadSQL(ctx context.Context, db *sql.DB, name string) (*ccopy.Session, error) {
+	var st ccopy.SessionState
+	err := db.QueryRowContext(ctx, `SELECT version, seed, seeded FROM ccopy_sessions WHERE name = ?`, name).Scan(&st.Version, &st.Seed, &st.Seeded)
+	if err != nil {
+		return nil, fmt.Errorf("sessionstore: load session %s: %w", name, err)
+	}
+	rows, err := db.QueryContext(ctx, `SELECT claimed, tbl, key_type, key, value_type, value FROM ccopy_session_entries WHERE session = ? ORDER BY claimed, tbl`, name)
+	if err != nil {
+		return nil, fmt.Errorf("sessionstore: load session %s: %w", name, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var claimed bool
+		var table, key, value string
+		var e ccopy.EntryState
+		if err := rows.Scan(&claimed, &table, &e.KeyType, &key, &e.ValueType, &value); err != nil {
+			return nil, fmt.Errorf("sessionstore: load session %s: %w", name, err)
+		}
+		e.Key, e.Value = []byte(key), []byte(value)
+		tables := &st.Tables
+		if claimed {
+			tables = &st.Claimed
+		}
+		if n := len(*tables); n == 0 || (*tables)[n-1].Name != table {
+			*tables = append(*tables, ccopy.TableState{Name: table})
+		}
+		t := &(*tables)[len(*tables)-1]
+		t.Entries = append(t.Entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("sessionstore: load session %s: %w", name, err)
+	}
+	return ccopy.RestoreSession(&st)
+}
@@ -0,0 +1,139 @@
+package ccopy
+
+import (
+	"reflect"
+	"sync"
+	"unsafe"
+)
+
+// Shadow evaluates a candidate Copier, like one with new rules or scopes, on the values copied by the current Copier,
+// so a change of policy can be validated on production traffic before it is enforced, like by Swap.
+// The values are copied once, by the current Copier, and the actions of both Copiers, as resolved by ResolveAction,
+// are compared for the values held by the copied object, from its fields, elements or values down to the values handled as a whole.
+// The values held by interfaces are not compared, as their paths are not known without values.
+// It is safe for concurrent use.
+type Shadow struct {
+	current   *Copier
+	candidate *Copier
+	// actions caches the actions of both Copiers, by shadowKey
+	actions sync.Map
+}
+
+// ShadowDiff is a value handled differently by the candidate Copier of a Shadow than by the current Copier.
+type ShadowDiff struct {
+	// Path is the path of the value, like Order.Items[3].Buyer.Email.
+	Path string
+	// Current and Candidate are the actions of the Copiers on the value.
+	Current   Action
+	Candidate Action
+}
+
+// NewShadow returns a Shadow evaluating candidate on the values copied by current.
+func NewShadow(current, candidate *Copier) *Shadow {
+	return &Shadow{current: current, candidate: candidate}
+}
+
+// Copy deep copies obj with the current Copier, like Copier.Copy, and returns the values of obj
+// the candidate Copier handles differently, in the order of the values, like Diff.
+func (s *Shadow) Copy(obj interface{}) (interface{}, []ShadowDiff, error) {
+	v, err := s.current.Copy(obj)
+	return v, s.Diff(obj), err
+}
+
+// Diff returns the values of obj the candidate Copier handles differently than the current Copier, without copying obj.
+// The values held by a value handled differently are not returned.
+func (s *Shadow) Diff(obj interface{}) []ShadowDiff {
+	v := reflect.ValueOf(obj)
+	if !v.IsValid() {
+		return nil
+	}
+	w := &shadowWalker{s: s, root: v.Type(), keys: s.current.live().keysInPaths, seen: make(map[scanned]bool)}
+	w.walk(v)
+	return w.diffs
+}
+
+// shadowKey identifies the values at a path, written like the paths of rules, in values of type root.
+type shadowKey struct {
+	root reflect.Type
+	path string
+}
+
+// resolve returns the actions of the current and the candidate Copiers on the values at path in values of type root,
+// false if the path cannot be resolved.
+func (s *Shadow) resolve(root reflect.Type, path string) ([2]Action, bool) {
+	key := shadowKey{root: root, path: path}
+	if a, ok := s.actions.Load(key); ok {
+		return a.([2]Action), true
+	}
+	current, err := s.current.ResolveAction(root, path)
+	if err != nil {
+		return [2]Action{}, false
+	}
+	candidate, err := s.candidate.ResolveAction(root, path)
+	if err != nil {
+		return [2]Action{}, false
+	}
+	a := [2]Action{current, candidate}
+	s.actions.Store(key, a)
+	return a, true
+}
+
+type shadowWalker struct {
+	s    *Shadow
+	root reflect.Type
+	path []pathElem
+	// keys writes the keys of maps as they are in the paths, with the KeysInPaths option of the current Copier
+	keys  bool
+	seen  map[scanned]bool
+	diffs []ShadowDiff
+}
+
+func (w *shadowWalker) walk(v reflect.Value) {
+	// pointers take no step in the paths
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return
+		}
+		key := scanned{p: unsafe.Pointer(v.Pointer()), t: v.Type()}
+		if w.seen[key] {
+			return
+		}
+		w.seen[key] = true
+		v = v.Elem()
+	}
+	// the paths select values held by the object, which is copied by its kind
+	if len(w.path) > 0 {
+		actions, ok := w.s.resolve(w.root, patternPath(w.root, w.path))
+		if !ok {
+			return
+		}
+		if actions[0] != actions[1] {
+			w.diffs = append(w.diffs, ShadowDiff{Path: formatPath(w.root, w.path, w.keys), Current: actions[0], Candidate: actions[1]})
+			return
+		}
+		if actions[0].Kind != ActionCopy {
+			return
+		}
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < v.NumField(); i++ {
+			w.path = append(w.path, pathElem{kind: segField, field: t.Field(i).Name})
+			w.walk(v.Field(i))
+			w.path = w.path[:len(w.path)-1]
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			w.path = append(w.path, pathElem{kind: segIndex, index: i})
+			w.walk(v.Index(i))
+			w.path = w.path[:len(w.path)-1]
+		}
+	case reflect.Map:
+		for _, e := range sortedEntries(v) {
+			w.path = append(w.path, pathElem{kind: segKey, key: e[0]})
+			w.walk(e[1])
+			w.path = w.path[:len(w.path)-1]
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package ccopy
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestShadow(t *testing.T) {
+	type Address struct {
+		Street string
+		City   string
+	}
+	type User struct {
+		Name   string `ccopy:"redact"`
+		Email  string
+		Phones []string
+		Home   *Address
+	}
+	config := Config{"redact": func(string) string { return "" }, "hash": func(string) string { return "#" }}
+	current, err := NewCopier(config, Options{Rules: Rules{"User.Email": "redact"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	candidate, err := NewCopier(config, Options{Rules: Rules{"User.Email": "hash", "User.Phones[]": "redact", "User.Home.Street": "redact"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewShadow(current, candidate)
+	u := User{Name: "name", Email: "a@b.c", Phones: []string{"1", "2"}, Home: &Address{Street: "street", City: "city"}}
+	v, diffs, err := s.Copy(u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(User{Phones: []string{"1", "2"}, Home: &Address{Street: "street", City: "city"}}, v); diff != "" {
+		t.Fatalf("expected the copy of the current copier: %s", diff)
+	}
+	var got []string
+	for _, d := range diffs {
+		got = append(got, d.Path+": "+d.Current.Kind.String()+" "+d.Current.Customizer+" -> "+d.Candidate.Kind.String()+" "+d.Candidate.Customizer)
+	}
+	expected := []string{
+		"User.Email: rule redact -> rule hash",
+		"User.Phones[0]: copy  -> rule redact",
+		"User.Phones[1]: copy  -> rule redact",
+		"User.Home.Street: copy  -> rule redact",
+	}
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Fatal(diff)
+	}
+	if diffs := s.Diff(User{Name: "name"}); len(diffs) != 1 || diffs[0].Path != "User.Email" {
+		t.Fatalf("got: %+v, expected a single difference at User.Email", diffs)
+	}
+	if diffs := NewShadow(current, current).Diff(u); len(diffs) != 0 {
+		t.Fatalf("got: %+v, expected no difference with the same copier", diffs)
+	}
+}
+
+func TestShadowKeysInPaths(t *testing.T) {
+	type Directory struct {
+		Emails map[string]string
+	}
+	config := Config{"redact": func(string) string { return "" }}
+	candidate, err := NewCopier(config, Options{Rules: Rules{"Directory.Emails{}": "redact"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := Directory{Emails: map[string]string{"john": "john@example.com"}}
+	current, err := NewCopier(config, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diffs := NewShadow(current, candidate).Diff(d); len(diffs) != 1 || strings.Contains(diffs[0].Path, "john") {
+		t.Fatalf("got: %+v, expected a single difference with the key hashed", diffs)
+	}
+	if current, err = NewCopier(config, Options{KeysInPaths: true}); err != nil {
+		t.Fatal(err)
+	}
+	if diffs := NewShadow(current, candidate).Diff(d); len(diffs) != 1 || diffs[0].Path != `Directory.Emails["john"]` {
+		t.Fatalf("got: %+v, expected a single difference with the key", diffs)
+	}
+}
@@ -0,0 +1,90 @@
+package ccopy
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Subtree is the number of elements held by the containers found at a path, with indexes and keys left out,
+// like Order.Items[].Tags for the Tags of all the items of an order.
+type Subtree struct {
+	Path     string
+	Elements int
+}
+
+// limited reports whether the copy checks the MaxElements or MaxBytes options.
+func (c *state) limited() bool {
+	return c.maxElements > 0 || c.maxBytes > 0
+}
+
+// limit counts the elements of a container being copied, at the current path, and fails the copy
+// with an *ErrTooBig error if it exceeds the MaxElements or MaxBytes options.
+func (c *state) limit(elements int) error {
+	if !c.limited() {
+		return nil
+	}
+	c.elements += elements
+	if c.tooBigBreakdown > 0 && elements > 0 {
+		if c.breakdown == nil {
+			c.breakdown = make(map[string]int)
+		}
+		c.breakdown[patternPath(c.root, c.path)] += elements
+	}
+	if c.maxElements > 0 && c.elements > c.maxElements || c.maxBytes > 0 && c.bytes > c.maxBytes {
+		return &ErrTooBig{Elements: c.elements, Bytes: c.bytes, MaxElements: c.maxElements, MaxBytes: c.maxBytes,
+			Path: c.pathString(), Breakdown: c.largest()}
+	}
+	return nil
+}
+
+// largest returns the TooBigBreakdown subtrees of the breakdown holding the most elements.
+func (c *state) largest() []Subtree {
+	if len(c.breakdown) == 0 {
+		return nil
+	}
+	subtrees := make([]Subtree, 0, len(c.breakdown))
+	for path, n := range c.breakdown {
+		subtrees = append(subtrees, Subtree{Path: path, Elements: n})
+	}
+	sort.Slice(subtrees, func(i, j int) bool {
+		if subtrees[i].Elements != subtrees[j].Elements {
+			return subtrees[i].Elements > subtrees[j].Elements
+		}
+		return subtrees[i].Path < subtrees[j].Path
+	})
+	if len(subtrees) > c.tooBigBreakdown {
+		subtrees = subtrees[:c.tooBigBreakdown]
+	}
+	return subtrees
+}
+
+// fastElements returns the elements of the field dst copied by its fast path.
+func fastElements(dst reflect.Value) int {
+	switch dst.Type() {
+	case stringsType, stringMapType:
+		return dst.Len()
+	}
+	return 0
+}
+
+// patternPath formats a path from a value of type root like formatPath, with "[]" for the indexes
+// and "{}" for the keys, like the paths of rules: Order.Items[].Tags.
+func patternPath(root reflect.Type, path []pathElem) string {
+	var b strings.Builder
+	if root != nil {
+		b.WriteString(typePath(root))
+	}
+	for _, e := range path {
+		switch e.kind {
+		case segField:
+			b.WriteString(".")
+			b.WriteString(e.field)
+		case segIndex:
+			b.WriteString("[]")
+		case segKey:
+			b.WriteString("{}")
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,63 @@
+package ccopy
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCopyTooBig(t *testing.T) {
+	type Item struct {
+		Tags  []string
+		Notes map[string]string
+	}
+	type Order struct {
+		Items []Item
+		Blob  []byte
+	}
+	obj := Order{Items: []Item{
+		{Tags: make([]string, 10), Notes: map[string]string{"a": "b"}},
+		{Tags: make([]string, 90)},
+	}}
+	c, err := NewCopier(Config{}, Options{MaxElements: 100, TooBigBreakdown: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.Copy(obj)
+	var e *ErrTooBig
+	if !errors.As(err, &e) {
+		t.Fatalf("got error: %v, expected *ErrTooBig", err)
+	}
+	if e.Elements != 103 || e.Path != "Order.Items[1].Tags" {
+		t.Fatalf("got error: %+v", e)
+	}
+	expected := []Subtree{{Path: "Order.Items[].Tags", Elements: 100}, {Path: "Order.Items", Elements: 2}}
+	if diff := cmp.Diff(expected, e.Breakdown); diff != "" {
+		t.Fatal(diff)
+	}
+	if !strings.Contains(err.Error(), "Order.Items[].Tags: 100 elements") {
+		t.Fatalf("got error: %v, expected the breakdown", err)
+	}
+
+	obj.Items[1].Tags = obj.Items[1].Tags[:80]
+	if _, err := c.Copy(obj); err != nil {
+		t.Fatal(err)
+	}
+	if _, errs := c.CopyPartial(Order{Blob: make([]byte, 200), Items: []Item{{}}}); len(errs) != 1 || !errors.As(errs[0], &e) {
+		t.Fatalf("got errors: %v, expected the copy to fail", errs)
+	}
+
+	c, err = NewCopier(Config{}, Options{MaxBytes: 1000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Copy(Order{Blob: make([]byte, 500)}); err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.Copy(Order{Blob: make([]byte, 2000)})
+	if !errors.As(err, &e) || e.Bytes < 2000 || e.Breakdown != nil {
+		t.Fatalf("got error: %v, expected *ErrTooBig", err)
+	}
+}
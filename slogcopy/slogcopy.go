@@ -0,0 +1,67 @@
+// Package slogcopy logs values with log/slog after customizing them with a ccopy.Copier,
+// so that logs never hold the personal data of the logged values.
+package slogcopy
+
+import (
+	"log/slog"
+
+	"github.com/gadumitrachioaiei/ccopy"
+)
+
+// Fallback is what is logged when a value fails to be customized.
+type Fallback int
+
+const (
+	// FallbackPartial logs what was customized successfully, with the marker.
+	FallbackPartial Fallback = iota
+	// FallbackMarker logs only the marker.
+	FallbackMarker
+)
+
+// Logger customizes values for logging.
+type Logger struct {
+	c *ccopy.Copier
+	// Fallback is what is logged when a value fails to be customized.
+	Fallback Fallback
+	// Marker is the key of the boolean logged with the values that failed to be customized,
+	// "redaction_failed" if empty.
+	Marker string
+}
+
+// New returns a Logger customizing values with c.
+func New(c *ccopy.Copier) *Logger {
+	return &Logger{c: c}
+}
+
+// Value returns a slog.LogValuer customizing v when it is logged.
+// The customized value is logged as it is if the copy succeeds.
+// Otherwise it is logged as a group of the marker and, with FallbackPartial, of the partial copy under "value",
+// as returned by ccopy.Copier.CopyPartial. The original value is never logged.
+func (l *Logger) Value(v interface{}) slog.LogValuer {
+	return valuer{l: l, v: v}
+}
+
+// Attr returns an attribute of key and v, customized like Value.
+func (l *Logger) Attr(key string, v interface{}) slog.Attr {
+	return slog.Any(key, l.Value(v))
+}
+
+type valuer struct {
+	l *Logger
+	v interface{}
+}
+
+func (v valuer) LogValue() slog.Value {
+	c, errs := v.l.c.CopyPartial(v.v)
+	if len(errs) == 0 {
+		return slog.AnyValue(c)
+	}
+	marker := v.l.Marker
+	if marker == "" {
+		marker = "redaction_failed"
+	}
+	if v.l.Fallback == FallbackMarker || c == nil {
+		return slog.GroupValue(slog.Bool(marker, true))
+	}
+	return slog.GroupValue(slog.Bool(marker, true), slog.Any("value", c))
+}
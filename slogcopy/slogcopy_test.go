@@ -0,0 +1,50 @@
+package slogcopy
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/gadumitrachioaiei/ccopy"
+)
+
+type User struct {
+	Name  string
+	Email string `ccopy:"email"`
+	Token string `ccopy:"token"`
+}
+
+func TestValue(t *testing.T) {
+	c, err := ccopy.NewCopier(ccopy.Config{"email": func(string) string { return "x@example.com" }}, ccopy.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+	l := New(c)
+	user := User{Name: "bob", Email: "bob@example.com", Token: "secret"}
+
+	logger.Info("login", l.Attr("user", user))
+	if s := buf.String(); strings.Contains(s, "secret") || strings.Contains(s, "bob@example.com") ||
+		!strings.Contains(s, "user.redaction_failed=true") || !strings.Contains(s, "x@example.com") {
+		t.Fatalf("got %s, expected the partial copy with the marker", s)
+	}
+
+	buf.Reset()
+	l.Fallback, l.Marker = FallbackMarker, "unsafe"
+	logger.Info("login", l.Attr("user", user))
+	if s := buf.String(); strings.Contains(s, "bob") || !strings.Contains(s, "user.unsafe=true") {
+		t.Fatalf("got %s, expected only the marker", s)
+	}
+
+	buf.Reset()
+	c, err = ccopy.NewCopier(ccopy.Config{"email": func(string) string { return "x@example.com" }, "token": func(string) string { return "" }}, ccopy.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Info("login", New(c).Attr("user", user))
+	if s := buf.String(); strings.Contains(s, "secret") || strings.Contains(s, "redaction_failed") || !strings.Contains(s, "x@example.com") {
+		t.Fatalf("got %s, expected the customized user", s)
+	}
+}
@@ -0,0 +1,112 @@
+package ccopy
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Snapshot is a deep copy of a value, taken at some time.
+type Snapshot[T any] struct {
+	Time  time.Time
+	Value T
+}
+
+// Snapshotter takes deep copies of a source value, periodically or on demand, and retains the latest of them.
+// The retained snapshots must not be modified, as they are shared by all the callers.
+type Snapshotter[T any] struct {
+	copier *Copier
+	source *T
+	locker sync.Locker
+	keep   int
+	now    func() time.Time
+
+	mu        sync.RWMutex
+	snapshots []Snapshot[T] // oldest first
+}
+
+// NewSnapshotter returns a snapshotter of the value pointed to by source, whose copies are made by c,
+// retaining the latest keep snapshots.
+// If locker is not nil, it is held while copying the source, so writers of the source holding it are excluded.
+func NewSnapshotter[T any](c *Copier, source *T, locker sync.Locker, keep int) *Snapshotter[T] {
+	if keep < 1 {
+		keep = 1
+	}
+	return &Snapshotter[T]{copier: c, source: source, locker: locker, keep: keep, now: time.Now}
+}
+
+// Snapshot takes a snapshot of the source now.
+func (s *Snapshotter[T]) Snapshot() (Snapshot[T], error) {
+	if s.locker != nil {
+		s.locker.Lock()
+	}
+	now := s.now()
+	v, err := s.copier.Copy(*s.source)
+	if s.locker != nil {
+		s.locker.Unlock()
+	}
+	if err != nil {
+		return Snapshot[T]{}, err
+	}
+	value, _ := v.(T)
+	snapshot := Snapshot[T]{Time: now, Value: value}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// concurrent snapshots can finish in another order than the one they were taken in
+	i := sort.Search(len(s.snapshots), func(i int) bool { return s.snapshots[i].Time.After(now) })
+	s.snapshots = append(s.snapshots, Snapshot[T]{})
+	copy(s.snapshots[i+1:], s.snapshots[i:])
+	s.snapshots[i] = snapshot
+	if len(s.snapshots) > s.keep {
+		copy(s.snapshots, s.snapshots[1:])
+		s.snapshots = s.snapshots[:len(s.snapshots)-1]
+	}
+	return snapshot, nil
+}
+
+// Run takes a snapshot every interval, until the context is done or a snapshot fails.
+// It returns the error of the failed snapshot, or the error of the context.
+func (s *Snapshotter[T]) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if _, err := s.Snapshot(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// Latest returns the latest snapshot, and false if no snapshot was taken yet.
+func (s *Snapshotter[T]) Latest() (Snapshot[T], bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if len(s.snapshots) == 0 {
+		return Snapshot[T]{}, false
+	}
+	return s.snapshots[len(s.snapshots)-1], true
+}
+
+// At returns the latest snapshot taken at or before t, and false if there is no such retained snapshot.
+func (s *Snapshotter[T]) At(t time.Time) (Snapshot[T], bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	i := sort.Search(len(s.snapshots), func(i int) bool { return s.snapshots[i].Time.After(t) })
+	if i == 0 {
+		return Snapshot[T]{}, false
+	}
+	return s.snapshots[i-1], true
+}
+
+// Snapshots returns the retained snapshots, oldest first.
+func (s *Snapshotter[T]) Snapshots() []Snapshot[T] {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]Snapshot[T](nil), s.snapshots...)
+}
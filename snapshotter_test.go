@@ -0,0 +1,115 @@
+package ccopy
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSnapshotter(t *testing.T) {
+	c, err := NewCopier(Config{}, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var mu sync.Mutex
+	state := []int{0}
+	s := NewSnapshotter(c, &state, &mu, 2)
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := start
+	s.now = func() time.Time { return now }
+
+	if _, ok := s.Latest(); ok {
+		t.Fatal("expected no snapshot")
+	}
+	for i := 1; i <= 3; i++ {
+		if _, err := s.Snapshot(); err != nil {
+			t.Fatal(err)
+		}
+		mu.Lock()
+		state[0] = i
+		mu.Unlock()
+		now = now.Add(time.Minute)
+	}
+	if l := len(s.Snapshots()); l != 2 {
+		t.Fatalf("got %d snapshots, expected 2", l)
+	}
+	latest, ok := s.Latest()
+	if !ok || latest.Value[0] != 2 || !latest.Time.Equal(start.Add(2*time.Minute)) {
+		t.Fatalf("got latest snapshot: %v, expected value 2", latest)
+	}
+	at, ok := s.At(start.Add(90 * time.Second))
+	if !ok || at.Value[0] != 1 {
+		t.Fatalf("got snapshot: %v, expected value 1", at)
+	}
+	if _, ok := s.At(start); ok {
+		t.Fatal("expected the first snapshot not to be retained")
+	}
+}
+
+func TestSnapshotterConcurrent(t *testing.T) {
+	type State struct {
+		N int `ccopy:"wait"`
+	}
+	release := make(chan struct{})
+	var calls int32
+	c, err := NewCopier(Config{"wait": func(n int) int {
+		// the first snapshot finishes after the second one
+		if atomic.AddInt32(&calls, 1) == 1 {
+			<-release
+		}
+		return n
+	}}, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewSnapshotter(c, &State{}, nil, 3)
+	var ticks int64
+	s.now = func() time.Time { return time.Unix(atomic.AddInt64(&ticks, 1), 0) }
+	done := make(chan error)
+	go func() {
+		_, err := s.Snapshot()
+		done <- err
+	}()
+	for atomic.LoadInt32(&calls) == 0 {
+		runtime.Gosched()
+	}
+	if _, err := s.Snapshot(); err != nil {
+		t.Fatal(err)
+	}
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	snapshots := s.Snapshots()
+	if len(snapshots) != 2 || snapshots[0].Time.Unix() != 1 || snapshots[1].Time.Unix() != 2 {
+		t.Fatalf("got snapshots: %v, expected them in time order", snapshots)
+	}
+	if latest, _ := s.Latest(); latest.Time.Unix() != 2 {
+		t.Fatalf("got latest snapshot: %v, expected the second one", latest)
+	}
+}
+
+func TestSnapshotterRun(t *testing.T) {
+	c, err := NewCopier(Config{}, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	state := 1
+	s := NewSnapshotter(c, &state, nil, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- s.Run(ctx, time.Millisecond) }()
+	for {
+		if _, ok := s.Latest(); ok {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Fatalf("got error: %v, expected: %v", err, context.Canceled)
+	}
+}
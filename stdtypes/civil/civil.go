@@ -0,0 +1,19 @@
+// Package civil registers with ccopy the civil dates and times of cloud.google.com/go/civil as atomic:
+// they are values without pointers, so they are copied as they are, and rules do not select their fields.
+//
+// It is a module of its own, so that only its importers depend on cloud.google.com/go,
+// and it is meant to be imported for its side effects:
+//
+//	import _ "github.com/gadumitrachioaiei/ccopy/stdtypes/civil"
+package civil
+
+import (
+	"cloud.google.com/go/civil"
+	"github.com/gadumitrachioaiei/ccopy"
+)
+
+func init() {
+	ccopy.RegisterAtomic(civil.Date{})
+	ccopy.RegisterAtomic(civil.Time{})
+	ccopy.RegisterAtomic(civil.DateTime{})
+}
@@ -0,0 +1,35 @@
+package civil
+
+import (
+	"reflect"
+	"testing"
+
+	"cloud.google.com/go/civil"
+	"github.com/gadumitrachioaiei/ccopy"
+)
+
+func TestCopy(t *testing.T) {
+	type Booking struct {
+		Day   civil.Date
+		Start civil.DateTime
+	}
+	c, err := ccopy.NewCopier(ccopy.Config{"redact": func(int) int { return 0 }}, ccopy.Options{Rules: ccopy.Rules{"Booking.Day.Year": "redact"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	obj := Booking{Day: civil.Date{Year: 2024, Month: 5, Day: 17}, Start: civil.DateTime{Date: civil.Date{Year: 2024, Month: 5, Day: 17}, Time: civil.Time{Hour: 9}}}
+	v, err := ccopy.CopyWith(c, obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != obj {
+		t.Fatalf("got: %v, expected the dates as they are: %v", v, obj)
+	}
+	a, err := c.ResolveAction(reflect.TypeOf(obj), "Booking.Day")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Kind != ccopy.ActionAtomic {
+		t.Fatalf("got action: %+v, expected the dates to be atomic", a)
+	}
+}
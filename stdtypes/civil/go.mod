@@ -0,0 +1,8 @@
+module github.com/gadumitrachioaiei/ccopy/stdtypes/civil
+
+go 1.22.0
+
+require (
+	cloud.google.com/go v0.112.0
+	github.com/gadumitrachioaiei/ccopy v0.0.0-20261015030205-5f8663826b31
+)
@@ -0,0 +1,17 @@
+// Package decimal registers with ccopy the decimals of github.com/shopspring/decimal,
+// whose state is unexported, as atomic: a decimal is immutable, so it is copied as it is.
+//
+// It is a module of its own, so that only its importers depend on shopspring/decimal,
+// and it is meant to be imported for its side effects:
+//
+//	import _ "github.com/gadumitrachioaiei/ccopy/stdtypes/decimal"
+package decimal
+
+import (
+	"github.com/gadumitrachioaiei/ccopy"
+	"github.com/shopspring/decimal"
+)
+
+func init() {
+	ccopy.RegisterAtomic(decimal.Decimal{})
+}
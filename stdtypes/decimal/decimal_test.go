@@ -0,0 +1,23 @@
+package decimal
+
+import (
+	"testing"
+
+	"github.com/gadumitrachioaiei/ccopy"
+	"github.com/shopspring/decimal"
+)
+
+func TestCopy(t *testing.T) {
+	type Invoice struct {
+		Total decimal.Decimal
+		Tax   decimal.NullDecimal
+	}
+	obj := Invoice{Total: decimal.RequireFromString("12.34"), Tax: decimal.NewNullDecimal(decimal.RequireFromString("1.5"))}
+	v, err := ccopy.CopyT(ccopy.Config{}, obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !v.Total.Equal(obj.Total) || !v.Tax.Valid || !v.Tax.Decimal.Equal(obj.Tax.Decimal) {
+		t.Fatalf("got: %v, expected: %v", v, obj)
+	}
+}
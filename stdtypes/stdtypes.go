@@ -0,0 +1,42 @@
+// Package stdtypes registers with ccopy the types of the standard library
+// that cannot be deep copied by reflection, because their state is unexported.
+//
+// It is meant to be imported for its side effects:
+//
+//	import _ "github.com/gadumitrachioaiei/ccopy/stdtypes"
+//
+// The types of common third-party packages are registered by its subpackages, which are modules of their own,
+// so that depending on ccopy does not depend on those packages: decimal for the decimals of shopspring/decimal,
+// and civil for the dates and times of cloud.google.com/go/civil.
+package stdtypes
+
+import (
+	"math/big"
+	"net/netip"
+	"regexp"
+
+	"github.com/gadumitrachioaiei/ccopy"
+)
+
+func init() {
+	ccopy.RegisterConverter(func(x big.Int) big.Int {
+		var c big.Int
+		c.Set(&x)
+		return c
+	})
+	ccopy.RegisterConverter(func(x big.Float) big.Float {
+		var c big.Float
+		c.Copy(&x)
+		return c
+	})
+	ccopy.RegisterConverter(func(x big.Rat) big.Rat {
+		var c big.Rat
+		c.Set(&x)
+		return c
+	})
+	ccopy.RegisterAtomic(netip.Addr{})
+	ccopy.RegisterAtomic(netip.AddrPort{})
+	ccopy.RegisterAtomic(netip.Prefix{})
+	// a compiled regexp is immutable and safe for concurrent use
+	ccopy.RegisterAtomic(regexp.Regexp{})
+}
@@ -0,0 +1,41 @@
+package stdtypes
+
+import (
+	"math/big"
+	"net/netip"
+	"regexp"
+	"testing"
+
+	"github.com/gadumitrachioaiei/ccopy"
+)
+
+func TestCopy(t *testing.T) {
+	type T struct {
+		Int  *big.Int
+		Rat  big.Rat
+		Addr netip.Addr
+		Re   *regexp.Regexp
+	}
+	obj := T{Int: big.NewInt(42), Rat: *big.NewRat(1, 3), Addr: netip.MustParseAddr("10.0.0.1"), Re: regexp.MustCompile("a+")}
+	vi, err := ccopy.Config{}.Copy(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := vi.(T)
+	if v.Int == obj.Int || v.Int.Cmp(obj.Int) != 0 {
+		t.Fatalf("got int: %v, expected a copy of: %v", v.Int, obj.Int)
+	}
+	if v.Rat.Cmp(&obj.Rat) != 0 {
+		t.Fatalf("got rat: %v, expected: %v", &v.Rat, &obj.Rat)
+	}
+	if v.Addr != obj.Addr {
+		t.Fatalf("got addr: %v, expected: %v", v.Addr, obj.Addr)
+	}
+	if !v.Re.MatchString("aa") {
+		t.Fatalf("got regexp: %v, expected: %v", v.Re, obj.Re)
+	}
+	obj.Int.SetInt64(1)
+	if v.Int.Int64() != 42 {
+		t.Fatalf("got int: %v, expected 42", v.Int)
+	}
+}
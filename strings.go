@@ -0,0 +1,55 @@
+package ccopy
+
+import (
+	"reflect"
+	"strings"
+)
+
+// StringPolicy is what a copy does with the strings it copies as they are, which share their bytes with the original,
+// as strings are immutable: a small copy of a huge buffer, like a few fields parsed from a large document,
+// keeps the whole buffer in memory as long as the copy is reachable.
+type StringPolicy int
+
+const (
+	// StringsShare copies the strings as they are, sharing their bytes with the original.
+	StringsShare StringPolicy = iota
+	// StringsClone copies the bytes of the strings, and of the strings returned by customizers,
+	// so the copies do not keep the memory of the original, like for long-lived snapshots.
+	// The strings of the fields tagged with "shallow" and of the types copied as a whole,
+	// like the types registered with RegisterAtomic, are not cloned. The clones are accounted like by CopyReport.
+	StringsClone
+)
+
+// cloneString returns a copy of ov, of kind string, with a copy of its bytes.
+func (c *state) cloneString(ov reflect.Value) reflect.Value {
+	v := reflect.New(ov.Type()).Elem()
+	v.SetString(strings.Clone(ov.String()))
+	c.account(ov.Type(), ov.Len())
+	return v
+}
+
+// holdsString reports whether the values of type t are strings, or hold strings through pointers, slices, maps and arrays.
+// It is meant for the types of fast paths, which are not recursive.
+func holdsString(t reflect.Type) bool {
+	for {
+		switch t.Kind() {
+		case reflect.String:
+			return true
+		case reflect.Map:
+			if holdsString(t.Key()) {
+				return true
+			}
+			t = t.Elem()
+		case reflect.Ptr, reflect.Slice, reflect.Array:
+			t = t.Elem()
+		default:
+			return false
+		}
+	}
+}
+
+// plain reports whether the values of type t are copied by assignment, like by plainType,
+// unless they are customized by the customizers registered with Config.RegisterType or hold strings to clone.
+func (c *state) plain(t reflect.Type) bool {
+	return plainType(t) && !c.plans.typeCustomized(t) && !(c.plans.cloneStrings && holdsString(t))
+}
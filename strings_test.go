@@ -0,0 +1,73 @@
+package ccopy
+
+import (
+	"strings"
+	"testing"
+	"unsafe"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCopyStrings(t *testing.T) {
+	type Payload struct {
+		Body string
+	}
+	type Event struct {
+		Name    string
+		Tags    []string
+		Attrs   map[string]string
+		Note    *string
+		Pairs   [][2]string
+		Short   string `ccopy:"short"`
+		Payload Payload
+		Raw     string `ccopy:"shallow"`
+	}
+	buf := strings.Repeat("x", 64)
+	note := buf[:3]
+	e := Event{Name: buf[:1], Tags: []string{buf[:2]}, Attrs: map[string]string{buf[:4]: buf[:5]}, Note: &note,
+		Pairs: [][2]string{{buf[:6], buf[:7]}}, Short: buf, Payload: Payload{Body: buf[:8]}, Raw: buf[:9]}
+	config := Config{"short": func(s string) string { return s[:2] }, "clone": strings.Clone}
+	shares := func(s string) bool {
+		return unsafe.StringData(s) == unsafe.StringData(buf)
+	}
+
+	c, err := NewCopier(config, Options{Strings: StringsClone})
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := c.Copy(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	copied := v.(Event)
+	if diff := cmp.Diff(e, copied, cmp.Comparer(func(a, b *string) bool { return *a == *b }), cmp.FilterPath(func(p cmp.Path) bool {
+		return p.String() == "Short"
+	}, cmp.Ignore())); diff != "" {
+		t.Fatal(diff)
+	}
+	var key string
+	for k := range copied.Attrs {
+		key = k
+	}
+	for _, s := range []string{copied.Name, copied.Tags[0], key, copied.Attrs[key], *copied.Note, copied.Pairs[0][0], copied.Pairs[0][1], copied.Short, copied.Payload.Body} {
+		if shares(s) {
+			t.Fatalf("expected %q to be cloned", s)
+		}
+	}
+	if !shares(copied.Raw) {
+		t.Fatal("expected the shallow field to share its bytes")
+	}
+
+	// by default, and at the paths not matched by the rules cloning them, the strings are shared
+	c, err = NewCopier(config, Options{Rules: Rules{"Event.Payload.**:string": "clone"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, err = c.Copy(e); err != nil {
+		t.Fatal(err)
+	}
+	copied = v.(Event)
+	if !shares(copied.Name) || !shares(copied.Tags[0]) || shares(copied.Payload.Body) {
+		t.Fatalf("expected the strings of the payload only to be cloned")
+	}
+}
@@ -0,0 +1,34 @@
+package ccopy
+
+import "reflect"
+
+// Swap replaces the config of the Copier with config, for the copies starting after it returns,
+// while the copies in progress go on with the former config. It is safe to call concurrently with the copies.
+//
+// Before replacing the config, Swap compiles the plans of the struct types copied so far with the new config,
+// so that a service updating its config does not compile them on every request at once after the swap.
+// It returns an error, keeping the former config, if the options of the Copier are not valid with the new config,
+// like when a customizer named by the CopyResults option is missing.
+func (c *Copier) Swap(config Config) error {
+	c.swap.Lock()
+	defer c.swap.Unlock()
+	next, err := NewCopier(config, c.options)
+	if err != nil {
+		return err
+	}
+	seen := make(map[reflect.Type]bool)
+	c.live().plans.m.Range(func(t, _ interface{}) bool {
+		next.plans.compile(t.(reflect.Type), seen)
+		return true
+	})
+	c.swapped.Store(next)
+	return nil
+}
+
+// live returns the Copier of the config of the last Swap, or c if its config was never swapped.
+func (c *Copier) live() *Copier {
+	if next, ok := c.swapped.Load().(*Copier); ok {
+		return next
+	}
+	return c
+}
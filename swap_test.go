@@ -0,0 +1,105 @@
+package ccopy
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestSwap(t *testing.T) {
+	type Address struct {
+		Street string `ccopy:"redact"`
+	}
+	type User struct {
+		Name string `ccopy:"redact"`
+		Home *Address
+	}
+	c, err := NewCopier(Config{"redact": func(string) string { return "old" }}, Options{CopyResults: map[string]bool{"redact": true}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	u := User{Name: "name", Home: &Address{Street: "street"}}
+	if v, err := CopyWith(c, u); err != nil || v.Name != "old" {
+		t.Fatalf("got: %v, %v, expected the old customizer", v, err)
+	}
+
+	if err := c.Swap(Config{"other": func(string) string { return "" }}); err == nil {
+		t.Fatal("expected error for the missing customizer of the CopyResults option")
+	}
+	if v, err := CopyWith(c, u); err != nil || v.Name != "old" {
+		t.Fatalf("got: %v, %v, expected the old customizer after a failed swap", v, err)
+	}
+
+	if err := c.Swap(Config{"redact": func(string) string { return "new" }}); err != nil {
+		t.Fatal(err)
+	}
+	// the plans of the types copied before the swap are compiled by it
+	warm := c.Stats()
+	if warm.Plans != 2 {
+		t.Fatalf("got %d plans after the swap, expected 2", warm.Plans)
+	}
+	v, err := CopyWith(c, u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Name != "new" || v.Home.Street != "new" {
+		t.Fatalf("got: %+v, %+v, expected the new customizer", v, v.Home)
+	}
+	if s := c.Stats(); s.Misses != warm.Misses {
+		t.Fatalf("got %d plan misses, expected %d: no plan compiled after the swap", s.Misses, warm.Misses)
+	}
+}
+
+func TestSwapConcurrent(t *testing.T) {
+	type T struct {
+		Name string `ccopy:"redact"`
+	}
+	c, err := NewCopier(Config{"redact": func(string) string { return "0" }}, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				v, err := CopyWith(c, T{Name: "name"})
+				if err != nil || v.Name != "0" && v.Name != "1" {
+					t.Errorf("got: %v, %v, expected the customizer of one of the configs", v, err)
+					return
+				}
+			}
+		}()
+	}
+	if err := c.Swap(Config{"redact": func(string) string { return "1" }}); err != nil {
+		t.Fatal(err)
+	}
+	wg.Wait()
+	if v, _ := CopyWith(c, T{Name: "name"}); v.Name != "1" {
+		t.Fatalf("got: %v, expected the customizer of the swapped config", v)
+	}
+}
+
+func TestSwapResolveAction(t *testing.T) {
+	type Token string
+	type Session struct {
+		Token Token
+	}
+	c, err := NewCopier(Config{}, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := Config{}
+	config.RegisterType(func(Token) Token { return "" })
+	if err := c.Swap(config); err != nil {
+		t.Fatal(err)
+	}
+	a, err := c.ResolveAction(reflect.TypeOf(Session{}), "Session.Token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Kind != ActionType {
+		t.Fatalf("got action: %s, expected the type customizer of the swapped config", a)
+	}
+}
@@ -0,0 +1,34 @@
+// Package templatecopy provides template functions applying the customizers of a ccopy.Copier,
+// for html/template and text/template, so server rendered pages mask the same data as the API responses.
+package templatecopy
+
+import (
+	"html/template"
+
+	"github.com/gadumitrachioaiei/ccopy"
+)
+
+// FuncMap returns the template functions customizing values with c, within session s:
+//
+//   - redact returns the copy of a value, customized by its tags and the rules of c, like {{(redact .User).Email}};
+//   - mask returns a value customized by the customizer of c of the given name, like {{mask "card" .CardNumber}};
+//   - pseudo returns the pseudonym of a value, by the customizer of c of the given name, which is the same
+//     for the same value in the session, like {{pseudo "user" .UserID}}, as for fields tagged with "idmap=user".
+//
+// The functions fail the execution of the template when a value fails to be customized,
+// so the original value is never rendered. A session per rendered page keeps the pseudonyms of a page consistent;
+// a nil session is a new session per call, which keeps no pseudonym.
+// The FuncMap can be used by text/template as well, converted to a text/template.FuncMap.
+func FuncMap(c *ccopy.Copier, s *ccopy.Session) template.FuncMap {
+	return template.FuncMap{
+		"redact": func(v interface{}) (interface{}, error) {
+			return c.CopySession(s, v)
+		},
+		"mask": func(name string, v interface{}) (interface{}, error) {
+			return c.Customize(s, name, v)
+		},
+		"pseudo": func(name string, v interface{}) (interface{}, error) {
+			return c.Customize(s, "idmap="+name, v)
+		},
+	}
+}
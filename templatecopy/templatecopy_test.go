@@ -0,0 +1,47 @@
+package templatecopy
+
+import (
+	"fmt"
+	"html/template"
+	"strings"
+	"testing"
+
+	"github.com/gadumitrachioaiei/ccopy"
+)
+
+type user struct {
+	ID    string
+	Email string `ccopy:"email"`
+	Card  string
+}
+
+func TestFuncMap(t *testing.T) {
+	n := 0
+	c, err := ccopy.NewCopier(ccopy.Config{
+		"email": func(string) string { return "redacted" },
+		"card":  func(s string) string { return "****" + s[len(s)-4:] },
+		"user":  func(string) string { n++; return fmt.Sprintf("user%d", n) },
+	}, ccopy.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := template.Must(template.New("page").Funcs(FuncMap(c, ccopy.NewSession())).Parse(
+		`{{range .}}{{pseudo "user" .ID}} {{(redact .).Email}} {{mask "card" .Card}};{{end}}`))
+	var b strings.Builder
+	err = tmpl.Execute(&b, []user{
+		{ID: "a", Email: "ann@example.com", Card: "4111111111111111"},
+		{ID: "b", Email: "bob@example.com", Card: "5500000000000004"},
+		{ID: "a", Email: "ann@example.com", Card: "4111111111111111"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "user1 redacted ****1111;user2 redacted ****0004;user1 redacted ****1111;"; b.String() != expected {
+		t.Fatalf("expected: %s, got: %s", expected, b.String())
+	}
+	tmpl = template.Must(template.New("page").Funcs(FuncMap(c, nil)).Parse(`{{mask "missing" .Card}}`))
+	b.Reset()
+	if err := tmpl.Execute(&b, user{Card: "4111111111111111"}); err == nil || strings.Contains(b.String(), "4111") {
+		t.Fatalf("expected an error and no card, got: %v, %s", err, b.String())
+	}
+}
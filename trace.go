@@ -0,0 +1,95 @@
+package ccopy
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Invocation is a call of a customizer by a copy.
+type Invocation struct {
+	// Tag is the name of the customizer in the config, without the idmap= prefix and the ,unique suffix.
+	Tag string
+	// Path is the path of the customized value.
+	Path string
+}
+
+func (i Invocation) String() string {
+	return i.Tag + " at " + i.Path
+}
+
+// CopyTrace deep copies an object, like Copy, and returns the trace of the copy: the invocations of
+// the customizers, in the order of the calls. A customizer called again for the same value, to make it unique,
+// is in the trace every time it is called, and one whose value is found mapped in the session is not.
+// Comparing the traces of copies of the same input with CompareTrace tracks down the nondeterminism
+// of pipelines, like copies of maps without the SortMapKeys option.
+func (c *Copier) CopyTrace(obj interface{}) (interface{}, []Invocation, error) {
+	st := &state{Copier: c.live(), onWarning: c.onWarning, tracing: true}
+	v, err := c.copyRoot(st, obj)
+	return v, st.trace, err
+}
+
+// ErrTraceMismatch is returned by CompareTrace when a trace differs from its baseline.
+type ErrTraceMismatch struct {
+	// Index is the position of the first invocation that differs.
+	Index int
+	// Expected is the invocation of the baseline, nil if the trace is longer than it.
+	Expected *Invocation
+	// Got is the invocation of the trace, nil if the trace is shorter than the baseline.
+	Got *Invocation
+}
+
+func (e *ErrTraceMismatch) Error() string {
+	switch {
+	case e.Expected == nil:
+		return fmt.Sprintf("trace mismatch at invocation %d: unexpected %s", e.Index, e.Got)
+	case e.Got == nil:
+		return fmt.Sprintf("trace mismatch at invocation %d: missing %s", e.Index, e.Expected)
+	}
+	return fmt.Sprintf("trace mismatch at invocation %d: expected %s, got %s", e.Index, e.Expected, e.Got)
+}
+
+// CompareTrace returns an *ErrTraceMismatch error for the first invocation of trace that differs from baseline,
+// nil if they are the same.
+func CompareTrace(baseline, trace []Invocation) error {
+	for i := 0; i < len(baseline) || i < len(trace); i++ {
+		var expected, got *Invocation
+		if i < len(baseline) {
+			expected = &baseline[i]
+		}
+		if i < len(trace) {
+			got = &trace[i]
+		}
+		if expected == nil || got == nil || *expected != *got {
+			return &ErrTraceMismatch{Index: i, Expected: expected, Got: got}
+		}
+	}
+	return nil
+}
+
+// WriteTrace writes trace to w, an invocation per line, as the tag and the path separated by a tab,
+// to be kept as the baseline of a golden test, read back with ReadTrace.
+func WriteTrace(w io.Writer, trace []Invocation) error {
+	bw := bufio.NewWriter(w)
+	for _, i := range trace {
+		if _, err := fmt.Fprintf(bw, "%s\t%s\n", i.Tag, i.Path); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// ReadTrace reads a trace written by WriteTrace.
+func ReadTrace(r io.Reader) ([]Invocation, error) {
+	var trace []Invocation
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		tag, path, ok := strings.Cut(scanner.Text(), "\t")
+		if !ok {
+			return nil, fmt.Errorf("invalid trace, at line %d: %q", line, scanner.Text())
+		}
+		trace = append(trace, Invocation{Tag: tag, Path: path})
+	}
+	return trace, scanner.Err()
+}
@@ -0,0 +1,71 @@
+package ccopy
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestCopyTrace(t *testing.T) {
+	type Member struct {
+		ID    int    `ccopy:"idmap=member"`
+		Email string `ccopy:"email,unique"`
+	}
+	type Team struct {
+		Name    string
+		Members map[string]Member
+	}
+	obj := Team{Members: map[string]Member{
+		"b": {ID: 2, Email: "b@example.com"},
+		"a": {ID: 1, Email: "a@example.com"},
+	}}
+	config := Config{
+		"member": func(id int) int { return id + 100 },
+		"email":  func(s string) string { return "x@example.com" },
+	}
+	c, err := NewCopier(config, Options{SortMapKeys: true, KeysInPaths: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, trace, err := c.CopyTrace(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Invocation{
+		{Tag: "member", Path: `Team.Members["a"].ID`},
+		{Tag: "email", Path: `Team.Members["a"].Email`},
+		{Tag: "member", Path: `Team.Members["b"].ID`},
+	}
+	// the email of b is customized again, up to the retries, then suffixed
+	for i := 0; i <= uniqueRetries; i++ {
+		expected = append(expected, Invocation{Tag: "email", Path: `Team.Members["b"].Email`})
+	}
+	if err := CompareTrace(expected, trace); err != nil {
+		t.Fatal(err)
+	}
+
+	var b bytes.Buffer
+	if err := WriteTrace(&b, trace); err != nil {
+		t.Fatal(err)
+	}
+	baseline, err := ReadTrace(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(baseline, trace) {
+		t.Fatalf("expected: %v, got: %v", trace, baseline)
+	}
+
+	err = CompareTrace(baseline[:2], trace)
+	var mismatch *ErrTraceMismatch
+	if !errors.As(err, &mismatch) || mismatch.Index != 2 || mismatch.Expected != nil || *mismatch.Got != trace[2] {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err.Error() != `trace mismatch at invocation 2: unexpected member at Team.Members["b"].ID` {
+		t.Fatalf("unexpected message: %v", err)
+	}
+	if _, err := ReadTrace(bytes.NewBufferString("email\n")); err == nil {
+		t.Fatal("expected error for invalid trace")
+	}
+}
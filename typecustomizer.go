@@ -0,0 +1,99 @@
+package ccopy
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// typePrefix starts the keys of the config of the customizers registered with RegisterType,
+// followed by the type they customize, qualified by the paths of the packages, like "type:time.Time".
+const typePrefix = "type:"
+
+// RegisterType adds fn to the config, as the customizer of every value of the type it customizes,
+// wherever it is found and whatever the tags of the fields holding it, like to truncate every time.Time.
+// fn has one of the signatures of the customizers of the config, like func(T) T, and customizes the values of type T
+// exactly: registering func(*sql.DB) *sql.DB customizes the pointers to sql.DB, and not the values they point to.
+// It is added under the key "type:" followed by T, qualified by the import paths of the packages of the types,
+// like "type:time.Time" or "type:*database/sql.DB", replacing the customizer of T, if any.
+// Tags and rules customizing a value take precedence over the customizer of its type.
+// It must be called before the config is used by copies, and it panics if fn is not a customizer.
+func (c Config) RegisterType(fn interface{}) {
+	ft := reflect.TypeOf(fn)
+	if ft == nil || ft.Kind() != reflect.Func || ft.NumIn() == 0 {
+		panic(fmt.Sprintf("ccopy: register type: expected a customizer, got: %T", fn))
+	}
+	t := ft.In(ft.NumIn() - 1)
+	if _, ok := signatureOf(ft, t); !ok {
+		panic(fmt.Sprintf("ccopy: register type: expected a customizer, got: %T", fn))
+	}
+	c[typePrefix+qualifiedName(t)] = fn
+}
+
+// qualifiedName returns the name of type t, qualified by the import paths of the packages of the named types,
+// so that types of the same name in packages of the same name are told apart, like github.com/org/app/user.ID.
+func qualifiedName(t reflect.Type) string {
+	if t.Name() != "" {
+		if t.PkgPath() == "" {
+			return t.Name()
+		}
+		return t.PkgPath() + "." + t.Name()
+	}
+	switch t.Kind() {
+	case reflect.Ptr:
+		return "*" + qualifiedName(t.Elem())
+	case reflect.Slice:
+		return "[]" + qualifiedName(t.Elem())
+	case reflect.Array:
+		return fmt.Sprintf("[%d]%s", t.Len(), qualifiedName(t.Elem()))
+	case reflect.Map:
+		return "map[" + qualifiedName(t.Key()) + "]" + qualifiedName(t.Elem())
+	}
+	return t.String()
+}
+
+// types returns the names of the customizers of c registered with RegisterType, by type customized.
+func (c Config) types() map[reflect.Type]string {
+	types := make(map[reflect.Type]string)
+	for name, fn := range c {
+		if !strings.HasPrefix(name, typePrefix) || fn == nil {
+			continue
+		}
+		ft := reflect.TypeOf(fn)
+		if ft.Kind() != reflect.Func || ft.NumIn() == 0 {
+			continue
+		}
+		types[ft.In(ft.NumIn()-1)] = name
+	}
+	return types
+}
+
+// typeCustomizers returns the names of the customizers of the config registered with RegisterType, by type customized.
+func (p *plans) typeCustomizers() map[reflect.Type]string {
+	types, _ := p.types.Load().(map[reflect.Type]string)
+	if types == nil {
+		types = p.config.types()
+		p.types.Store(types)
+	}
+	return types
+}
+
+// typeCustomized reports whether the values of type t, or the values it holds through pointers, slices, maps and arrays,
+// are customized by a customizer registered with RegisterType. It is meant for the types of fast paths, which are not recursive.
+func (p *plans) typeCustomized(t reflect.Type) bool {
+	types := p.typeCustomizers()
+	if len(types) == 0 {
+		return false
+	}
+	for {
+		if _, ok := types[t]; ok {
+			return true
+		}
+		switch t.Kind() {
+		case reflect.Ptr, reflect.Slice, reflect.Map, reflect.Array:
+			t = t.Elem()
+		default:
+			return false
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package ccopy
+
+import (
+	"database/sql"
+	htmltemplate "html/template"
+	"reflect"
+	"testing"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type typedID [4]byte
+
+func TestRegisterType(t *testing.T) {
+	type Event struct {
+		At      time.Time
+		Times   []time.Time
+		IDs     []typedID
+		Owner   typedID
+		Created time.Time `ccopy:"keep"`
+		DB      *sql.DB
+		Any     interface{}
+	}
+	c := Config{"keep": func(t time.Time) time.Time { return t }}
+	c.RegisterType(func(t time.Time) time.Time { return t.Truncate(time.Hour) })
+	c.RegisterType(func(typedID) typedID { return typedID{} })
+	c.RegisterType(func(*sql.DB) *sql.DB { return nil })
+	if _, ok := c["type:time.Time"]; !ok {
+		t.Fatalf("expected the customizer to be registered under its type, got: %v", c)
+	}
+
+	at := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	hour := at.Truncate(time.Hour)
+	e := Event{At: at, Times: []time.Time{at}, IDs: []typedID{{1}}, Owner: typedID{2}, Created: at, DB: &sql.DB{}, Any: at}
+	expected := Event{At: hour, Times: []time.Time{hour}, IDs: []typedID{{}}, Created: at, Any: hour}
+	v, err := c.Copy(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(expected, v, cmp.AllowUnexported(sql.DB{})); diff != "" {
+		t.Fatal(diff)
+	}
+
+	cp, err := NewCopier(c, Options{FlatStructs: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := cp.ResolveAction(reflect.TypeOf(e), "Event.Times[]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Kind != ActionType || a.Customizer != "type:time.Time" {
+		t.Fatalf("unexpected action: %+v", a)
+	}
+
+	type Flat struct {
+		Owner typedID
+		N     int
+	}
+	v, err = cp.Copy(Flat{Owner: typedID{3}, N: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(Flat{N: 1}, v); diff != "" {
+		t.Fatal(diff)
+	}
+
+	// the types of the same name in packages of the same name have their own customizers
+	html, text := htmltemplate.New("html"), texttemplate.New("text")
+	c.RegisterType(func(*htmltemplate.Template) *htmltemplate.Template { return html })
+	c.RegisterType(func(*texttemplate.Template) *texttemplate.Template { return text })
+	if _, ok := c["type:*html/template.Template"]; !ok {
+		t.Fatalf("expected the customizer to be registered under its qualified type, got: %v", c)
+	}
+	type Templates struct {
+		HTML *htmltemplate.Template
+		Text *texttemplate.Template
+	}
+	v, err = c.Copy(Templates{HTML: htmltemplate.New("a"), Text: texttemplate.New("b")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tv := v.(Templates); tv.HTML != html || tv.Text != text {
+		t.Fatalf("got: %+v, expected the templates of their customizers", tv)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic for a function that is not a customizer")
+		}
+	}()
+	c.RegisterType(func(int) string { return "" })
+}
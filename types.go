@@ -0,0 +1,75 @@
+package ccopy
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// typeHandlers holds the types copied by the registered functions, instead of by reflection.
+var typeHandlers = struct {
+	sync.RWMutex
+	m map[reflect.Type]reflect.Value
+}{m: make(map[reflect.Type]reflect.Value)}
+
+func init() {
+	RegisterAtomic(time.Time{})
+}
+
+// RegisterAtomic registers the type of v as atomic: its values are copied as they are, like time.Time.
+// This is meant for immutable types with unexported fields, that would be copied as zero values otherwise.
+// It panics if v is nil.
+func RegisterAtomic(v interface{}) {
+	if v == nil {
+		panic("ccopy: register atomic nil")
+	}
+	registerType(reflect.TypeOf(v), reflect.Value{})
+}
+
+// RegisterConverter registers fn, having the signature func(T) T, as the copy of every value of type T.
+// The returned value must not share memory with its argument.
+// It panics if fn does not have the expected signature.
+func RegisterConverter(fn interface{}) {
+	fv := reflect.ValueOf(fn)
+	if fv.Kind() != reflect.Func || fv.Type().NumIn() != 1 || fv.Type().NumOut() != 1 || fv.Type().In(0) != fv.Type().Out(0) {
+		panic(fmt.Sprintf("ccopy: register converter: expected func(T) T, got: %T", fn))
+	}
+	registerType(fv.Type().In(0), fv)
+}
+
+// RegisterHandler registers fn, having the signature func(T) (T, error), as the copy of every value of type T,
+// replacing the copy of the engine for the values of T and everything they hold, like RegisterConverter does,
+// but failing the copy when fn returns an error. It is meant for the types that know how to copy themselves,
+// like types with a Clone method.
+// The calls are counted in the Stats of the Copier, and the fields of type T are reported as customized by "handler"
+// in the coverage.
+// It panics if fn does not have the expected signature.
+func RegisterHandler(fn interface{}) {
+	fv := reflect.ValueOf(fn)
+	if fv.Kind() != reflect.Func || fv.Type().NumIn() != 1 || fv.Type().NumOut() != 2 || fv.Type().In(0) != fv.Type().Out(0) || fv.Type().Out(1) != errorType {
+		panic(fmt.Sprintf("ccopy: register handler: expected func(T) (T, error), got: %T", fn))
+	}
+	registerType(fv.Type().In(0), fv)
+}
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// isRawHandler reports whether fn, a handler of a type, was registered with RegisterHandler.
+func isRawHandler(fn reflect.Value) bool {
+	return fn.IsValid() && fn.Type().NumOut() == 2
+}
+
+func registerType(t reflect.Type, fn reflect.Value) {
+	typeHandlers.Lock()
+	defer typeHandlers.Unlock()
+	typeHandlers.m[t] = fn
+}
+
+// typeHandler returns the handler of type t, if any, which is invalid for atomic types.
+func typeHandler(t reflect.Type) (reflect.Value, bool) {
+	typeHandlers.RLock()
+	defer typeHandlers.RUnlock()
+	fn, ok := typeHandlers.m[t]
+	return fn, ok
+}
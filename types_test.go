@@ -0,0 +1,102 @@
+package ccopy
+
+import (
+	"errors"
+	"testing"
+)
+
+type celsius struct {
+	degrees float64
+}
+
+func TestRegisterConverter(t *testing.T) {
+	RegisterConverter(func(c celsius) celsius { return celsius{degrees: c.degrees} })
+	type T struct {
+		C *celsius
+	}
+	vi, err := Config{}.Copy(T{C: &celsius{degrees: 20}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := vi.(T); v.C.degrees != 20 {
+		t.Fatalf("got degrees: %v, expected 20", v.C.degrees)
+	}
+}
+
+func TestRegisterConverterInvalid(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic")
+		}
+	}()
+	RegisterConverter(func(int) string { return "" })
+}
+
+func TestRegisterConverterTopLevel(t *testing.T) {
+	RegisterConverter(func(c celsius) celsius { return celsius{degrees: c.degrees} })
+	vi, err := Config{}.Copy(map[string][]celsius{"a": {{degrees: 1}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := vi.(map[string][]celsius); v["a"][0].degrees != 1 {
+		t.Fatalf("got: %v, expected 1 degree", v)
+	}
+}
+
+// document copies itself, and fails for empty titles.
+type document struct {
+	Title string
+	pages []string
+}
+
+var errNoTitle = errors.New("no title")
+
+func (d document) Clone() (document, error) {
+	if d.Title == "" {
+		return document{}, errNoTitle
+	}
+	return document{Title: d.Title, pages: append([]string(nil), d.pages...)}, nil
+}
+
+func TestRegisterHandler(t *testing.T) {
+	RegisterHandler(document.Clone)
+	type Folder struct {
+		Accounts []document
+	}
+	c, err := NewCopier(Config{}, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	obj := Folder{Accounts: []document{{Title: "a", pages: []string{"p1"}}, {Title: "b"}}}
+	res, err := c.Copy(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	docs := res.(Folder).Accounts
+	if len(docs) != 2 || docs[0].pages[0] != "p1" || &docs[0].pages[0] == &obj.Accounts[0].pages[0] {
+		t.Fatalf("got %v, expected the documents to be cloned", docs)
+	}
+	if s := c.Stats(); s.HandlerCalls != 2 {
+		t.Fatalf("got %d handler calls, expected 2", s.HandlerCalls)
+	}
+
+	_, err = c.Copy(Folder{Accounts: []document{{Title: "a"}, {}}})
+	var handlerErr *ErrHandler
+	if !errors.Is(err, errNoTitle) || !errors.As(err, &handlerErr) || handlerErr.Path != "Folder.Accounts[1]" {
+		t.Fatalf("got %v, expected the error of the handler", err)
+	}
+
+	cv := c.Coverage(Folder{})
+	if len(cv.Fields) != 1 || cv.Fields[0].Customizer != "handler" {
+		t.Fatalf("got %v, expected the documents to be reported as handled", cv.Fields)
+	}
+}
+
+func TestRegisterHandlerInvalid(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected a panic")
+		}
+	}()
+	RegisterHandler(func(d document) document { return d })
+}
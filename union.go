@@ -0,0 +1,78 @@
+package ccopy
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// union is a struct type registered with RegisterUnion.
+type union struct {
+	// discriminator is the index of the discriminator field
+	discriminator int
+	// branches are the indexes of the branch fields, by value of the discriminator
+	branches map[interface{}]int
+	// isBranch is set for the indexes of the branch fields
+	isBranch map[int]bool
+}
+
+// unions holds the unions registered with RegisterUnion, by struct type.
+var unions = struct {
+	sync.RWMutex
+	m map[reflect.Type]*union
+}{m: make(map[reflect.Type]*union)}
+
+// RegisterUnion registers the struct type of v as a union: a struct holding one of several branches,
+// in the fields named by the values of branches, selected by the value of its discriminator field,
+// like a Kind field. The copies of the values of the type copy the discriminator as it is,
+// even if it is tagged or matched by rules, so it stays consistent with the branch, and copy and customize
+// only the active branch: the other branches are left out of the copy, as are all of them
+// when the discriminator has no branch.
+// The keys of branches are converted to the type of the discriminator.
+// Unions must be registered before the first copy of their values by a Copier.
+//
+// The oneof fields of protobuf messages need no registration: they are interfaces holding
+// a wrapper of only one of the branches, so only that branch is copied, and rules reach its values
+// through the wrapper field, like "Message.Contact.Email" for the Email branch of the oneof field Contact.
+//
+// It panics if v is not a struct, or if the fields or the values of branches do not exist in it.
+func RegisterUnion(v interface{}, discriminator string, branches map[interface{}]string) {
+	t := reflect.TypeOf(v)
+	if t == nil || t.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("ccopy: register union: expected a struct, got: %T", v))
+	}
+	d, ok := t.FieldByName(discriminator)
+	if !ok || len(d.Index) != 1 || !d.Type.Comparable() {
+		panic(fmt.Sprintf("ccopy: register union %s: no comparable discriminator field %s", t, discriminator))
+	}
+	u := &union{discriminator: d.Index[0], branches: make(map[interface{}]int), isBranch: make(map[int]bool)}
+	for value, name := range branches {
+		f, ok := t.FieldByName(name)
+		if !ok || len(f.Index) != 1 || f.Index[0] == u.discriminator {
+			panic(fmt.Sprintf("ccopy: register union %s: no branch field %s", t, name))
+		}
+		dv := reflect.ValueOf(value)
+		if !dv.IsValid() || !dv.Type().ConvertibleTo(d.Type) {
+			panic(fmt.Sprintf("ccopy: register union %s: discriminator value %v is not a %s", t, value, d.Type))
+		}
+		u.branches[dv.Convert(d.Type).Interface()] = f.Index[0]
+		u.isBranch[f.Index[0]] = true
+	}
+	unions.Lock()
+	defer unions.Unlock()
+	unions.m[t] = u
+}
+
+func unionOf(t reflect.Type) *union {
+	unions.RLock()
+	defer unions.RUnlock()
+	return unions.m[t]
+}
+
+// active returns the index of the active branch field of ov, a value of the union, or -1.
+func (u *union) active(ov reflect.Value) int {
+	if i, ok := u.branches[ov.Field(u.discriminator).Interface()]; ok {
+		return i
+	}
+	return -1
+}
@@ -0,0 +1,103 @@
+package ccopy
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type unionKind string
+
+type unionCard struct {
+	Number string `ccopy:"redact"`
+}
+
+type unionBank struct {
+	IBAN string `ccopy:"redact"`
+}
+
+type unionPayment struct {
+	Kind   unionKind `ccopy:"redact"`
+	Card   *unionCard
+	Bank   *unionBank
+	Amount int
+}
+
+// unionContact is like the interface of a protobuf oneof field
+type unionContact interface {
+	isContact()
+}
+
+type unionContactEmail struct {
+	Email string
+}
+
+type unionContactPhone struct {
+	Phone string
+}
+
+func (*unionContactEmail) isContact() {}
+func (*unionContactPhone) isContact() {}
+
+type unionMessage struct {
+	Contact unionContact
+}
+
+func TestRegisterUnion(t *testing.T) {
+	RegisterUnion(unionPayment{}, "Kind", map[interface{}]string{"card": "Card", "bank": "Bank"})
+	config := Config{"redact": func(s string) string { return "x" }}
+	obj := []unionPayment{
+		{Kind: "card", Card: &unionCard{Number: "4111"}, Bank: &unionBank{IBAN: "stale"}, Amount: 1},
+		{Kind: "bank", Card: &unionCard{Number: "stale"}, Bank: &unionBank{IBAN: "FR76"}, Amount: 2},
+		{Kind: "cash", Card: &unionCard{Number: "stale"}, Amount: 3},
+	}
+	res, err := config.Copy(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []unionPayment{
+		{Kind: "card", Card: &unionCard{Number: "x"}, Amount: 1},
+		{Kind: "bank", Bank: &unionBank{IBAN: "x"}, Amount: 2},
+		{Kind: "cash", Amount: 3},
+	}
+	if diff := cmp.Diff(expected, res); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestOneof(t *testing.T) {
+	c, err := NewCopier(Config{"redact": func(s string) string { return "x" }}, Options{Rules: Rules{"unionMessage.Contact.Email": "redact"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct{ obj, expected unionMessage }{
+		{unionMessage{Contact: &unionContactEmail{Email: "a@example.com"}}, unionMessage{Contact: &unionContactEmail{Email: "x"}}},
+		{unionMessage{Contact: &unionContactPhone{Phone: "555"}}, unionMessage{Contact: &unionContactPhone{Phone: "555"}}},
+	} {
+		res, err := c.Copy(test.obj)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(test.expected, res); diff != "" {
+			t.Fatal(diff)
+		}
+	}
+}
+
+func TestRegisterUnionInvalid(t *testing.T) {
+	for _, register := range []func(){
+		func() { RegisterUnion(0, "Kind", nil) },
+		func() { RegisterUnion(unionPayment{}, "Missing", nil) },
+		func() { RegisterUnion(unionPayment{}, "Kind", map[interface{}]string{"card": "Missing"}) },
+		func() { RegisterUnion(unionPayment{}, "Kind", map[interface{}]string{1.5: "Card"}) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatal("expected a panic")
+				}
+			}()
+			register()
+		}()
+	}
+}
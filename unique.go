@@ -0,0 +1,68 @@
+package ccopy
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// uniqueOption is the tag option of the fields whose customized values must be unique in the session,
+// like "email,unique".
+const uniqueOption = ",unique"
+
+// uniqueRetries is how many times a customizer is called again when its output is already taken,
+// which resolves the collisions of randomized customizers.
+const uniqueRetries = 8
+
+// ErrNotUnique is returned when the customized value of a field tagged with "name,unique"
+// is already the customized value of another value, and the collision cannot be resolved.
+type ErrNotUnique struct {
+	Tag  string
+	Path string
+}
+
+func (e *ErrNotUnique) Error() string {
+	return fmt.Sprintf("customized value is not unique for: %s, at: %s", e.Tag, e.Path)
+}
+
+// customizeUnique customizes ov with the customizer name, making sure that the customized value
+// is produced for no other value in the session.
+// A taken output is resolved by calling the customizer again, then, for strings, by adding a numeric suffix:
+// before the last "@", so that emails stay emails, or at the end.
+func (c *state) customizeUnique(name string, ov reflect.Value) (reflect.Value, error) {
+	if !ov.Type().Comparable() {
+		return reflect.Zero(ov.Type()), &ErrIncomparable{Tag: name, Path: c.pathString(), Type: ov.Type(), Unique: true}
+	}
+	var v reflect.Value
+	for i := 0; i <= uniqueRetries; i++ {
+		var err error
+		if v, err = c.customize(name, ov); err != nil {
+			return v, err
+		}
+		if ok, err := c.session.claim(name, v.Interface(), ov.Interface()); err != nil {
+			return reflect.Zero(ov.Type()), err
+		} else if ok {
+			return v, nil
+		}
+	}
+	if v.Kind() != reflect.String {
+		return reflect.Zero(ov.Type()), &ErrNotUnique{Tag: name, Path: c.pathString()}
+	}
+	for n := 2; ; n++ {
+		s := reflect.New(v.Type()).Elem()
+		s.SetString(suffixed(v.String(), n))
+		if ok, err := c.session.claim(name, s.Interface(), ov.Interface()); err != nil {
+			return reflect.Zero(ov.Type()), err
+		} else if ok {
+			return s, nil
+		}
+	}
+}
+
+func suffixed(s string, n int) string {
+	if i := strings.LastIndex(s, "@"); i >= 0 {
+		return s[:i] + strconv.Itoa(n) + s[i:]
+	}
+	return s + strconv.Itoa(n)
+}
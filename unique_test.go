@@ -0,0 +1,60 @@
+package ccopy
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestUnique(t *testing.T) {
+	type Member struct {
+		Email string `ccopy:"email,unique"`
+		Name  string `ccopy:"idmap=name,unique"`
+	}
+	config := Config{
+		"email": func(s string) string { return "user@example.com" },
+		"name":  func(s string) string { return "anonymous" },
+	}
+	s := NewSession()
+	res, err := config.CopySession(s, []Member{{"a@x.com", "Ann"}, {"b@x.com", "Bob"}, {"a@x.com", "Ann"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Member{{"user@example.com", "anonymous"}, {"user2@example.com", "anonymous2"}, {"user@example.com", "anonymous"}}
+	if diff := cmp.Diff(res, expected); diff != "" {
+		t.Fatal(diff)
+	}
+	res, err = config.CopySession(s, Member{"c@x.com", "Bob"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(res, Member{"user3@example.com", "anonymous2"}); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestUniqueRetries(t *testing.T) {
+	type Player struct {
+		Number int `ccopy:"number,unique"`
+	}
+	s := NewSeededSession(1)
+	config := Config{"number": func(n int) int { return s.Rand().Intn(4) }}
+	res, err := config.CopySession(s, []Player{{10}, {11}, {12}, {13}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	seen := make(map[int]bool)
+	for _, p := range res.([]Player) {
+		seen[p.Number] = true
+	}
+	if len(seen) != 4 {
+		t.Fatalf("got %v, expected 4 distinct numbers", res)
+	}
+
+	_, err = Config{"number": func(n int) int { return 0 }}.Copy([]Player{{10}, {11}})
+	var notUnique *ErrNotUnique
+	if !errors.As(err, &notUnique) || notUnique.Path != "[]ccopy.Player[1].Number" {
+		t.Fatalf("got %v, expected an ErrNotUnique", err)
+	}
+}
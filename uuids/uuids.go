@@ -0,0 +1,104 @@
+// Package uuids provides ccopy customizers for UUIDs.
+//
+// The customizers work on [16]byte, so they accept any UUID type defined as a 16 byte array,
+// like the ones of github.com/google/uuid and github.com/gofrs/uuid,
+// and on strings in the canonical textual form.
+package uuids
+
+import (
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	mrand "math/rand"
+
+	"github.com/gadumitrachioaiei/ccopy"
+)
+
+// Preserve keeps a UUID as it is.
+func Preserve(id [16]byte) [16]byte {
+	return id
+}
+
+// V5 returns a customizer replacing a UUID with the version 5 UUID of its bytes in namespace.
+// The mapping is deterministic, so it preserves relationships across unrelated copies,
+// but anyone knowing the namespace and a source UUID can verify it.
+func V5(namespace [16]byte) func([16]byte) [16]byte {
+	return func(id [16]byte) [16]byte {
+		h := sha1.New()
+		h.Write(namespace[:])
+		h.Write(id[:])
+		var u [16]byte
+		copy(u[:], h.Sum(nil))
+		u[6] = (u[6] & 0x0f) | 0x50
+		u[8] = (u[8] & 0x3f) | 0x80
+		return u
+	}
+}
+
+// Random replaces a UUID with a random version 4 UUID, read from crypto/rand.
+func Random([16]byte) [16]byte {
+	var u [16]byte
+	if _, err := rand.Read(u[:]); err != nil {
+		panic(fmt.Sprintf("uuids: read random: %v", err))
+	}
+	return v4(u)
+}
+
+// RandomFrom returns a customizer replacing a UUID with a random version 4 UUID generated by r,
+// like the generator of a ccopy.Session, so the replacements can be reproduced with the same seed.
+func RandomFrom(r *mrand.Rand) func([16]byte) [16]byte {
+	return func([16]byte) [16]byte {
+		var u [16]byte
+		binary.LittleEndian.PutUint64(u[:8], r.Uint64())
+		binary.LittleEndian.PutUint64(u[8:], r.Uint64())
+		return v4(u)
+	}
+}
+
+func v4(u [16]byte) [16]byte {
+	u[6] = (u[6] & 0x0f) | 0x40
+	u[8] = (u[8] & 0x3f) | 0x80
+	return u
+}
+
+// Mapped returns a customizer that replaces a UUID using fn the first time it is seen in the session,
+// and with the same value afterwards, keeping foreign keys consistent across the copied objects.
+// Table names the mapping in the session, so different kinds of identifiers can be mapped independently.
+func Mapped(s *ccopy.Session, table string, fn func([16]byte) [16]byte) func([16]byte) [16]byte {
+	return func(id [16]byte) [16]byte {
+		return s.Map(table, id, func(v interface{}) interface{} { return fn(v.([16]byte)) }).([16]byte)
+	}
+}
+
+// String adapts a customizer of UUIDs to UUIDs in textual form.
+// Strings that are not valid UUIDs, like empty strings, are kept as they are.
+func String(fn func([16]byte) [16]byte) func(string) string {
+	return func(s string) string {
+		id, err := Parse(s)
+		if err != nil {
+			return s
+		}
+		return Format(fn(id))
+	}
+}
+
+// Parse parses a UUID in the canonical textual form xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx.
+func Parse(s string) ([16]byte, error) {
+	var id [16]byte
+	if len(s) != 36 || s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+		return id, fmt.Errorf("invalid uuid: %q", s)
+	}
+	src := []byte(s[:8] + s[9:13] + s[14:18] + s[19:23] + s[24:])
+	if _, err := hex.Decode(id[:], src); err != nil {
+		return id, fmt.Errorf("invalid uuid: %q", s)
+	}
+	return id, nil
+}
+
+// Format formats a UUID in the canonical textual form.
+func Format(id [16]byte) string {
+	s := hex.EncodeToString(id[:])
+	return s[:8] + "-" + s[8:12] + "-" + s[12:16] + "-" + s[16:20] + "-" + s[20:]
+}
@@ -0,0 +1,80 @@
+package uuids
+
+import (
+	"testing"
+
+	"github.com/gadumitrachioaiei/ccopy"
+)
+
+// UUID is defined like the UUID types of the popular uuid packages.
+type UUID [16]byte
+
+func TestV5(t *testing.T) {
+	ns, err := Parse("6ba7b810-9dad-11d1-80b4-00c04fd430c8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, _ := Parse("0b1a7a5e-1f62-4b8a-9bd6-1f1a3b9f0c11")
+	a, b := V5(ns)(id), V5(ns)(id)
+	if a != b || a == id {
+		t.Fatalf("got: %s and %s, expected equal and different from: %s", Format(a), Format(b), Format(id))
+	}
+	if a[6]>>4 != 5 {
+		t.Fatalf("got version: %d, expected 5", a[6]>>4)
+	}
+}
+
+func TestMapped(t *testing.T) {
+	type Order struct {
+		ID     UUID `ccopy:"order"`
+		UserID UUID `ccopy:"user"`
+	}
+	type User struct {
+		ID     UUID `ccopy:"user"`
+		Orders []Order
+	}
+	s := ccopy.NewSession()
+	c := ccopy.Config{"user": Mapped(s, "user", Random), "order": Random}
+	u := User{ID: UUID{1}, Orders: []Order{{ID: UUID{2}, UserID: UUID{1}}}}
+	vi, err := c.Copy(u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := vi.(User)
+	if v.ID == u.ID || v.Orders[0].UserID != v.ID {
+		t.Fatalf("got user id: %v and order user id: %v, expected equal and changed", v.ID, v.Orders[0].UserID)
+	}
+	wi, err := c.Copy(u.Orders[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w := wi.(Order); w.UserID != v.ID {
+		t.Fatalf("got order user id: %v, expected: %v", w.UserID, v.ID)
+	}
+}
+
+func TestString(t *testing.T) {
+	s := "0b1a7a5e-1f62-4b8a-9bd6-1f1a3b9f0c11"
+	if got := String(Preserve)(s); got != s {
+		t.Fatalf("got: %s, expected: %s", got, s)
+	}
+	if got := String(Random)(s); got == s {
+		t.Fatal("expected a different uuid")
+	}
+	if got := String(Random)("not a uuid"); got != "not a uuid" {
+		t.Fatalf("got: %s, expected the invalid uuid to be kept", got)
+	}
+}
+
+func TestRandomFrom(t *testing.T) {
+	a, b := RandomFrom(ccopy.NewRand(1)), RandomFrom(ccopy.NewRand(1))
+	for i := 0; i < 3; i++ {
+		x, y := a([16]byte{}), b([16]byte{})
+		if x != y {
+			t.Fatalf("got: %s and %s, expected equal uuids", Format(x), Format(y))
+		}
+		if x[6]>>4 != 4 {
+			t.Fatalf("got version: %d, expected 4", x[6]>>4)
+		}
+	}
+}
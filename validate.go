@@ -0,0 +1,112 @@
+package ccopy
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Validate checks the tags of the struct types reachable from the type of prototype against the config, up front,
+// instead of on the copies reaching them: it returns an *ErrConfig error holding an *ErrMissingCustomizer error
+// for every tag naming no customizer, and an *ErrBadCustomizerSignature error for every customizer that cannot customize
+// the field tagged with its name, in the order of the fields, with their paths written like the paths of rules;
+// and an *ErrCannotDive or *ErrIncomparable error for every tag with options the type of its field does not support.
+// The types held by interfaces are not known without values, and are not checked.
+func (c Config) Validate(prototype interface{}) error {
+	t := reflect.TypeOf(prototype)
+	if t == nil {
+		return ErrInvalidValue
+	}
+	v := &validator{config: c, seen: make(map[reflect.Type]bool)}
+	v.walk(t, typePath(t))
+	if len(v.errs) > 0 {
+		return &ErrConfig{Errs: v.errs}
+	}
+	return nil
+}
+
+type validator struct {
+	config Config
+	seen   map[reflect.Type]bool
+	errs   []error
+}
+
+// walk checks the tags of the struct types reachable from type t, found at path.
+func (v *validator) walk(t reflect.Type, path string) {
+	if _, ok := typeHandler(t); ok {
+		return
+	}
+	switch t.Kind() {
+	case reflect.Ptr:
+		v.walk(t.Elem(), path)
+	case reflect.Slice, reflect.Array:
+		v.walk(t.Elem(), path+"[]")
+	case reflect.Map:
+		v.walk(t.Elem(), path+"{}")
+	case reflect.Struct:
+		if v.seen[t] {
+			return
+		}
+		v.seen[t] = true
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			fpath := path + "." + f.Name
+			tag := f.Tag.Get(tagCcopy)
+			switch tag {
+			case "":
+				v.walk(f.Type, fpath)
+			case tagSkip, tagShallow:
+			default:
+				v.check(tag, f.Type, fpath)
+			}
+		}
+	}
+}
+
+// check checks the customizers of tag, customizing the values of type t found at path.
+func (v *validator) check(tag string, t reflect.Type, path string) {
+	for strings.HasPrefix(tag, divePrefix) {
+		tag = tag[len(divePrefix):]
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		switch t.Kind() {
+		case reflect.Slice, reflect.Array:
+			path += "[]"
+		case reflect.Map:
+			path += "{}"
+		default:
+			v.errs = append(v.errs, &ErrCannotDive{Tag: tag, Path: path, Type: t})
+			return
+		}
+		t = t.Elem()
+	}
+	name, idmap := strings.CutPrefix(tag, idmapPrefix)
+	name, unique := strings.CutSuffix(name, uniqueOption)
+	if (idmap || unique) && !t.Comparable() {
+		v.errs = append(v.errs, &ErrIncomparable{Tag: tag, Path: path, Type: t, Unique: !idmap})
+		return
+	}
+	links := []string{name}
+	if _, ok := v.config[name]; !ok {
+		links = v.config.chain(name)
+	}
+	for _, link := range links {
+		fn, ok := v.config[link]
+		base, args := splitTag(link)
+		if !ok {
+			fn = v.config[base]
+		} else {
+			args = ""
+		}
+		if fn == nil {
+			v.errs = append(v.errs, &ErrMissingCustomizer{Tag: base, Path: path})
+			continue
+		}
+		if sig, ok := signatureOf(reflect.TypeOf(fn), t); !ok || args != "" && sig != sigArgs {
+			v.errs = append(v.errs, &ErrBadCustomizerSignature{Tag: link, Path: path, Customizer: reflect.TypeOf(fn), Type: t})
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package ccopy
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestValidate(t *testing.T) {
+	type Card struct {
+		Number string `ccopy:"mask,keep=4"`
+		Holder string `ccopy:"trim,name"`
+	}
+	type Customer struct {
+		Name    string   `ccopy:"name"`
+		Age     int      `ccopy:"name"`
+		Phones  []string `ccopy:"dive,phone"`
+		Cards   map[string]*Card
+		Notes   []byte `ccopy:"-"`
+		Friends []*Customer
+		Labels  map[string]string `ccopy:"dive,missing"`
+		Email   string            `ccopy:"idmap=name,unique"`
+		Count   int               `ccopy:"dive,name"`
+	}
+	c := Config{
+		"name":  func(s string) string { return s },
+		"trim":  func(s string) string { return s },
+		"mask":  func(s string) string { return s },
+		"phone": func(s string) string { return s },
+	}
+	err := c.Validate(&Customer{})
+	var invalid *ErrConfig
+	if !errors.As(err, &invalid) {
+		t.Fatalf("got error: %v, expected *ErrConfig", err)
+	}
+	var problems []string
+	for _, err := range invalid.Errs {
+		var missing *ErrMissingCustomizer
+		var bad *ErrBadCustomizerSignature
+		switch {
+		case errors.As(err, &missing):
+			problems = append(problems, "missing "+missing.Tag+" at "+missing.Path)
+		case errors.As(err, &bad):
+			problems = append(problems, "bad "+bad.Tag+" at "+bad.Path)
+		default:
+			problems = append(problems, err.Error())
+		}
+	}
+	expected := []string{
+		"bad name at Customer.Age",
+		"bad mask,keep=4 at Customer.Cards{}.Number",
+		"missing missing at Customer.Labels{}",
+		"cannot dive into values of type int for: name, at: Customer.Count",
+	}
+	if diff := cmp.Diff(expected, problems); diff != "" {
+		t.Fatal(diff)
+	}
+	var missing *ErrMissingCustomizer
+	if !errors.As(err, &missing) || missing.Path != "Customer.Labels{}" {
+		t.Fatalf("got error: %v, expected it to wrap *ErrMissingCustomizer", err)
+	}
+
+	if err := c.Validate([]struct {
+		Name string `ccopy:"trim,name"`
+	}{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Validate(nil); err != ErrInvalidValue {
+		t.Fatalf("got error: %v, expected ErrInvalidValue", err)
+	}
+}
@@ -0,0 +1,88 @@
+package ccopy
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// WarningKind is the kind of a surprising behavior of a copy, that does not make it fail.
+type WarningKind int
+
+const (
+	// WarningUnexportedField is the warning of an unexported field that is not copied.
+	WarningUnexportedField WarningKind = iota + 1
+	// WarningAliased is the warning of a channel or function value that is shared by the copy and the original.
+	WarningAliased
+	// WarningNaNKey is the warning of a map entry whose key is or contains NaN, that is copied.
+	WarningNaNKey
+	// WarningKeyCollision is the warning of a map entry whose copied key collides with the copy of another key.
+	WarningKeyCollision
+	// WarningCostly is the warning of a struct type whose estimated cost exceeds the CostLimits option.
+	WarningCostly
+	// WarningAliasedResult is the warning of a customizer returning a value that shares memory with the value it customizes,
+	// with the AliasedResultsWarn policy.
+	WarningAliasedResult
+)
+
+func (k WarningKind) String() string {
+	switch k {
+	case WarningUnexportedField:
+		return "unexported field"
+	case WarningAliased:
+		return "aliased"
+	case WarningNaNKey:
+		return "NaN map key"
+	case WarningKeyCollision:
+		return "map key collision"
+	case WarningCostly:
+		return "costly type"
+	case WarningAliasedResult:
+		return "aliased customizer result"
+	}
+	return fmt.Sprintf("WarningKind(%d)", int(k))
+}
+
+// Warning describes a surprising behavior of a copy.
+// Only the first occurrence of a warning is reported for every type and field, in a copy.
+type Warning struct {
+	Kind WarningKind
+	// Path is the path of the first value the warning is about.
+	Path string
+	// Type is the type of the value, or the type of the struct for unexported fields.
+	Type reflect.Type
+	// Field is the name of the unexported field.
+	Field string
+}
+
+func (w Warning) String() string {
+	if w.Field != "" {
+		return fmt.Sprintf("%s: %s.%s, at: %s", w.Kind, w.Type, w.Field, w.Path)
+	}
+	return fmt.Sprintf("%s: %s, at: %s", w.Kind, w.Type, w.Path)
+}
+
+type warningKey struct {
+	kind  WarningKind
+	t     reflect.Type
+	field string
+}
+
+// warn reports a warning, unless it was already reported or nobody listens.
+func (c *state) warn(kind WarningKind, t reflect.Type, field string) {
+	if c.onWarning == nil {
+		return
+	}
+	key := warningKey{kind: kind, t: t, field: field}
+	if c.warned[key] {
+		return
+	}
+	if c.warned == nil {
+		c.warned = make(map[warningKey]bool)
+	}
+	c.warned[key] = true
+	path := c.pathString()
+	if field != "" {
+		path += "." + field
+	}
+	c.onWarning(Warning{Kind: kind, Path: path, Type: t, Field: field})
+}
@@ -0,0 +1,42 @@
+package ccopy
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCopyWarnings(t *testing.T) {
+	type Inner struct {
+		Done   chan bool
+		hidden int
+	}
+	type T struct {
+		Inners []Inner
+		Fn     func()
+		NilFn  func()
+	}
+	var received []Warning
+	c, err := NewCopier(Config{}, Options{OnWarning: func(w Warning) { received = append(received, w) }})
+	if err != nil {
+		t.Fatal(err)
+	}
+	obj := T{Inners: []Inner{{Done: make(chan bool)}, {Done: make(chan bool)}}, Fn: func() {}}
+	_, warnings, err := c.CopyWarnings(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Warning{
+		{Kind: WarningUnexportedField, Path: "T.Inners[0].hidden", Type: reflect.TypeOf(Inner{}), Field: "hidden"},
+		{Kind: WarningAliased, Path: "T.Inners[0].Done", Type: reflect.TypeOf(make(chan bool))},
+		{Kind: WarningAliased, Path: "T.Fn", Type: reflect.TypeOf(func() {})},
+	}
+	opt := cmp.Comparer(func(a, b reflect.Type) bool { return a == b })
+	if diff := cmp.Diff(warnings, expected, opt); diff != "" {
+		t.Fatal(diff)
+	}
+	if diff := cmp.Diff(received, expected, opt); diff != "" {
+		t.Fatal(diff)
+	}
+}
@@ -0,0 +1,63 @@
+// Package zapcopy logs values with go.uber.org/zap after customizing them with a ccopy.Copier,
+// so that logs never hold the personal data of the logged values.
+package zapcopy
+
+import (
+	"github.com/gadumitrachioaiei/ccopy"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Fallback is what is logged when a value fails to be customized.
+type Fallback int
+
+const (
+	// FallbackPartial logs what was customized successfully, with the marker.
+	FallbackPartial Fallback = iota
+	// FallbackMarker logs only the marker.
+	FallbackMarker
+)
+
+// Logger customizes values for logging.
+type Logger struct {
+	c *ccopy.Copier
+	// Fallback is what is logged when a value fails to be customized.
+	Fallback Fallback
+	// Marker is the key of the boolean logged with the values that failed to be customized,
+	// "redaction_failed" if empty.
+	Marker string
+}
+
+// New returns a Logger customizing values with c.
+func New(c *ccopy.Copier) *Logger {
+	return &Logger{c: c}
+}
+
+// Field returns a field of key and v, customized when it is logged.
+// The customized value is logged under "value", as a reflected value, if the copy succeeds.
+// Otherwise the marker is logged and, with FallbackPartial, the partial copy under "value",
+// as returned by ccopy.Copier.CopyPartial. The original value is never logged.
+func (l *Logger) Field(key string, v interface{}) zap.Field {
+	return zap.Object(key, object{l: l, v: v})
+}
+
+type object struct {
+	l *Logger
+	v interface{}
+}
+
+func (o object) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	c, errs := o.l.c.CopyPartial(o.v)
+	if len(errs) == 0 {
+		return enc.AddReflected("value", c)
+	}
+	marker := o.l.Marker
+	if marker == "" {
+		marker = "redaction_failed"
+	}
+	enc.AddBool(marker, true)
+	if o.l.Fallback == FallbackMarker || c == nil {
+		return nil
+	}
+	return enc.AddReflected("value", c)
+}
@@ -0,0 +1,56 @@
+package zapcopy
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gadumitrachioaiei/ccopy"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+type User struct {
+	Name  string
+	Email string `ccopy:"email"`
+	Token string `ccopy:"token"`
+}
+
+func logged(t *testing.T, f zap.Field) map[string]interface{} {
+	core, logs := observer.New(zapcore.InfoLevel)
+	zap.New(core).Info("login", f)
+	entries := logs.All()
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries, expected 1", len(entries))
+	}
+	return entries[0].ContextMap()["user"].(map[string]interface{})
+}
+
+func TestField(t *testing.T) {
+	c, err := ccopy.NewCopier(ccopy.Config{"email": func(string) string { return "x@example.com" }}, ccopy.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := New(c)
+	user := User{Name: "bob", Email: "bob@example.com", Token: "secret"}
+
+	m := logged(t, l.Field("user", user))
+	if m["redaction_failed"] != true || m["value"].(User).Token != "" || m["value"].(User).Email != "x@example.com" {
+		t.Fatalf("got %v, expected the partial copy with the marker", m)
+	}
+
+	l.Fallback = FallbackMarker
+	m = logged(t, l.Field("user", user))
+	if _, ok := m["value"]; ok || m["redaction_failed"] != true {
+		t.Fatalf("got %v, expected only the marker", m)
+	}
+
+	c, err = ccopy.NewCopier(ccopy.Config{"email": func(string) string { return "x@example.com" }, "token": strings.ToUpper}, ccopy.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m = logged(t, New(c).Field("user", user))
+	if _, ok := m["redaction_failed"]; ok || m["value"].(User).Token != "SECRET" {
+		t.Fatalf("got %v, expected the customized user", m)
+	}
+}